@@ -4,19 +4,26 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	twitchirc "github.com/gempir/go-twitch-irc/v4"
 	"github.com/pbuckles22/PBChatBot/internal/commands"
+	"github.com/pbuckles22/PBChatBot/internal/metrics"
+	"github.com/pbuckles22/PBChatBot/internal/music"
+	"github.com/pbuckles22/PBChatBot/internal/timers"
 	"github.com/pbuckles22/PBChatBot/internal/twitch"
 	"gopkg.in/yaml.v3"
 )
 
 type BotAuthConfig struct {
-	BotName      string `yaml:"bot_name"`
-	OAuth        string `yaml:"oauth"`
+	BotName string `yaml:"bot_name"`
+	// Optional and unused: access tokens always come from the refresh flow,
+	// so a stale value here has no effect
+	OAuth        string `yaml:"oauth,omitempty"`
 	ClientID     string `yaml:"client_id"`
 	ClientSecret string `yaml:"client_secret"`
 	RefreshToken string `yaml:"refresh_token"`
@@ -57,9 +64,6 @@ func loadBotAuthConfig(path string) (*BotAuthConfig, error) {
 	if config.BotName == "" {
 		return nil, fmt.Errorf("bot_name is required")
 	}
-	if config.OAuth == "" {
-		return nil, fmt.Errorf("oauth token is required")
-	}
 	if config.ClientID == "" {
 		return nil, fmt.Errorf("client_id is required")
 	}
@@ -69,6 +73,9 @@ func loadBotAuthConfig(path string) (*BotAuthConfig, error) {
 	if config.RefreshToken == "" {
 		return nil, fmt.Errorf("refresh_token is required")
 	}
+	if config.OAuth != "" {
+		log.Printf("Ignoring oauth in %s; access tokens are fetched with the refresh token", path)
+	}
 
 	return config, nil
 }
@@ -100,22 +107,47 @@ func loadChannelConfig(path string) (*ChannelConfig, error) {
 func main() {
 	log.Println("Starting PBChatBot...")
 
+	// LOG_LEVEL=debug turns on the per-command trace logs
+	if os.Getenv("LOG_LEVEL") == "debug" {
+		slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	}
+
 	// Get channel name from environment variable
 	channelName := os.Getenv("CHANNEL_NAME")
 	if channelName == "" {
 		log.Fatal("CHANNEL_NAME environment variable is required")
 	}
 
-	// Get bot name from environment variable
-	botName := os.Getenv("BOT_NAME")
-	if botName == "" {
-		log.Fatal("BOT_NAME environment variable is required")
-	}
+	// Create auth manager, reading credentials from the environment when
+	// BOT_AUTH_ENV=1 and from the bot's auth secrets file otherwise
+	var authManager *twitch.AuthManager
+	var secretsPath string
+	if os.Getenv("BOT_AUTH_ENV") == "1" {
+		authManager = twitch.NewAuthManager("", "", "", "")
+		if err := authManager.LoadFromEnvironment(); err != nil {
+			log.Fatalf("Failed to load bot auth from environment: %v", err)
+		}
+	} else {
+		// Get bot name from environment variable
+		botName := os.Getenv("BOT_NAME")
+		if botName == "" {
+			log.Fatal("BOT_NAME environment variable is required")
+		}
 
-	// Load bot auth config
-	botAuthConfig, err := loadBotAuthConfig(fmt.Sprintf("configs/bots/%s_auth_secrets.yaml", botName))
-	if err != nil {
-		log.Fatalf("Failed to load bot auth configuration: %v", err)
+		// Load bot auth config
+		secretsPath = fmt.Sprintf("configs/bots/%s_auth_secrets.yaml", botName)
+		botAuthConfig, err := loadBotAuthConfig(secretsPath)
+		if err != nil {
+			log.Fatalf("Failed to load bot auth configuration: %v", err)
+		}
+
+		authManager = twitch.NewAuthManager(
+			botAuthConfig.ClientID,
+			botAuthConfig.ClientSecret,
+			botAuthConfig.RefreshToken,
+			secretsPath,
+		)
+		authManager.BotName = botAuthConfig.BotName
 	}
 
 	// Load channel config
@@ -125,27 +157,20 @@ func main() {
 	}
 
 	// Verify bot names match
-	if botAuthConfig.BotName != channelConfig.BotName {
+	if authManager.BotName != channelConfig.BotName {
 		log.Fatalf("Bot name mismatch: auth config has %s, channel config has %s",
-			botAuthConfig.BotName, channelConfig.BotName)
+			authManager.BotName, channelConfig.BotName)
 	}
 
 	log.Printf("Loaded configuration for bot: %s, channel: %s",
-		botAuthConfig.BotName, channelConfig.Channel)
-
-	// Create auth manager
-	authManager := twitch.NewAuthManager(
-		botAuthConfig.ClientID,
-		botAuthConfig.ClientSecret,
-		botAuthConfig.RefreshToken,
-		fmt.Sprintf("configs/bots/%s_auth_secrets.yaml", botName),
-	)
+		authManager.BotName, channelConfig.Channel)
 
 	// Create command manager
-	cm := commands.NewCommandManager(
-		"!", // Hardcoded command prefix
-		channelConfig.DataPath,
-		channelConfig.Channel,
+	cm := commands.NewCommandManagerWithOptions(
+		commands.WithPrefix("!"), // Hardcoded command prefix
+		commands.WithDataPath(channelConfig.DataPath),
+		commands.WithChannel(channelConfig.Channel),
+		commands.WithMaxQueueSize(channelConfig.Commands.Queue.MaxSize),
 	)
 	commands.RegisterBasicCommands(cm)
 	commands.RegisterUptimeCommand(cm)
@@ -155,13 +180,79 @@ func main() {
 	bot := twitch.NewBot(
 		channelConfig.Channel,
 		authManager,
-		fmt.Sprintf("configs/bots/%s_auth_secrets.yaml", botName),
-		botAuthConfig.BotName,
+		secretsPath,
+		authManager.BotName,
 	)
+	commands.RegisterChatStatsCommand(cm, bot.GetChannelStats())
+	commands.RegisterRankCommand(cm, bot.GetChannelStats())
+	commands.RegisterLastStatsCommand(cm, bot.GetChannelStats())
+	commands.RegisterSlowModeCommand(cm, bot)
+	commands.RegisterConnStatusCommand(cm, bot)
+	commands.RegisterRoomModeCommand(cm, bot)
+	cm.SetAnnouncer(bot)
+	cm.SetFollowChecker(bot)
+	cm.SetAccountChecker(bot)
+	cm.SetWhisperer(bot)
+	cm.SetChannelSender(bot)
+	cm.SetLatencyReporter(bot)
+	cm.SetCountdownTimer(commands.NewCountdownTimer(nil, bot.Say))
+	cm.SetAutoAdvancer(commands.NewAutoAdvancer(nil, bot.Say))
+	cm.SetUpdateChecker(commands.NewUpdateChecker(commands.LatestReleaseURL))
+	cm.SetPollManager(commands.NewPollManager(bot.Say))
+	cm.SetHypeTrain(commands.NewHypeTrain(nil, bot.Say))
+	timerInterval := time.Duration(cm.GetConfig().TimerAnnounceInterval) * time.Second
+	cm.SetTimerManager(commands.NewTimerManager(nil, bot.Say, timerInterval))
+	if provider, err := music.NewProvider(cm.GetConfig(), cm.GetConfigPath()); err != nil {
+		log.Printf("Error setting up !song: %v", err)
+	} else if provider != nil {
+		cm.SetMusicProvider(provider)
+	}
+
+	// Re-register non-basic commands after a !resetbot
+	cm.OnReset(func(cm *commands.CommandManager) {
+		commands.RegisterUptimeCommand(cm)
+		commands.RegisterAuthCommand(cm, authManager)
+		commands.RegisterChatStatsCommand(cm, bot.GetChannelStats())
+		commands.RegisterRankCommand(cm, bot.GetChannelStats())
+		commands.RegisterLastStatsCommand(cm, bot.GetChannelStats())
+		commands.RegisterSlowModeCommand(cm, bot)
+		commands.RegisterConnStatusCommand(cm, bot)
+		commands.RegisterRoomModeCommand(cm, bot)
+	})
+
+	// Restore runtime aliases now that their targets are registered
+	if err := cm.LoadAliases(); err != nil {
+		log.Printf("Error loading command aliases: %v", err)
+	}
+
+	// Restore a chat relay left running by a previous run
+	if err := cm.LoadRelay(); err != nil {
+		log.Printf("Error loading chat relay: %v", err)
+	}
+
+	// Restore the stream topic set by a previous run
+	if err := cm.LoadTopic(); err != nil {
+		log.Printf("Error loading stream topic: %v", err)
+	}
+
+	// Restore command usage caps set with !restrict
+	if err := cm.LoadRestrictions(); err != nil {
+		log.Printf("Error loading command restrictions: %v", err)
+	}
+
+	// Set up timed messages
+	timerManager := timers.NewManager(nil, bot.Say)
+	if interval := cm.GetConfig().Commands.Queue.PeriodicAnnounceInterval; interval > 0 {
+		timerManager.Add(timers.NewQueueAnnounceTimer(cm.GetQueue(), time.Duration(interval)*time.Second))
+	}
+	if interval := cm.GetConfig().TopicAnnounceInterval; interval > 0 {
+		timerManager.Add(cm.NewTopicAnnounceTimer(time.Duration(interval) * time.Minute))
+	}
 
 	// Register command handlers
-	bot.RegisterCommandHandler(func(message twitchirc.PrivateMessage) string {
-		if response, isCommand := cm.HandleMessage(message); isCommand && response != "" {
+	bot.RegisterCommandHandler(func(ctx context.Context, message twitchirc.PrivateMessage) string {
+		timerManager.RecordActivity()
+		if response, isCommand := cm.HandleMessageContext(ctx, message); isCommand && response != "" {
 			return response
 		}
 		return ""
@@ -171,11 +262,46 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Stop immediately if the token belongs to a different account
+	bot.SetOnIdentityMismatch(func(err error) {
+		log.Fatalf("Refusing to run: %v", err)
+	})
+
+	// Start !restrict caps over for each new stream
+	bot.SetOnStreamStart(cm.ResetRestrictionUses)
+
+	// Stop if Twitch rejects the login and the token can't be refreshed
+	bot.SetOnAuthFailure(func(err error) {
+		log.Fatalf("Stopping: %v", err)
+	})
+
 	// Connect to Twitch
 	if err := bot.Connect(ctx); err != nil {
 		log.Fatalf("Error connecting to Twitch: %v", err)
 	}
 
+	// Start timed messages
+	go timerManager.Run(ctx, time.Second)
+
+	// Save chat points once a minute
+	go cm.GetPoints().AutoSave(ctx, time.Minute)
+
+	// Save !restrict use counts once a minute
+	go cm.AutoSaveRestrictions(ctx, time.Minute)
+
+	// Serve metrics when METRICS_ADDR (e.g. ":9090") is set
+	if addr := os.Getenv("METRICS_ADDR"); addr != "" {
+		go func() {
+			sources := map[string]metrics.Source{
+				"command_latency": func() interface{} { return cm.GetCommandLatency().Snapshot() },
+				"reconnects":      func() interface{} { return bot.ReconnectStats() },
+			}
+			if err := metrics.Serve(ctx, addr, sources); err != nil {
+				log.Printf("Metrics server error: %v", err)
+			}
+		}()
+	}
+
 	// Set up graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -192,4 +318,13 @@ func main() {
 	// Graceful shutdown
 	log.Println("Shutting down gracefully...")
 	cancel() // Cancel the context to stop token refresh loop
+	if err := cm.GetPoints().Save(); err != nil {
+		log.Printf("Error saving points: %v", err)
+	}
+	if err := cm.SaveRestrictions(); err != nil {
+		log.Printf("Error saving command restrictions: %v", err)
+	}
+	if err := bot.EndStatsSession(); err != nil {
+		log.Printf("Error saving channel stats: %v", err)
+	}
 }
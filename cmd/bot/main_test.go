@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadBotAuthConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name: "refresh flow without oauth",
+			yaml: "bot_name: mybot\nclient_id: id\nclient_secret: secret\nrefresh_token: refresh\n",
+		},
+		{
+			name: "stale oauth is accepted",
+			yaml: "bot_name: mybot\noauth: \"oauth:stale\"\nclient_id: id\nclient_secret: secret\nrefresh_token: refresh\n",
+		},
+		{
+			name:    "oauth without a refresh token",
+			yaml:    "bot_name: mybot\noauth: \"oauth:token\"\nclient_id: id\nclient_secret: secret\n",
+			wantErr: "refresh_token is required",
+		},
+		{
+			name:    "missing client secret",
+			yaml:    "bot_name: mybot\nclient_id: id\nrefresh_token: refresh\n",
+			wantErr: "client_secret is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "mybot_auth_secrets.yaml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0600); err != nil {
+				t.Fatalf("Failed to write auth file: %v", err)
+			}
+
+			config, err := loadBotAuthConfig(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected the config to load, got %v", err)
+			}
+			if config.BotName != "mybot" || config.RefreshToken != "refresh" {
+				t.Errorf("Unexpected config: %+v", config)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+// Command spotifyauth authorizes the bot to read the track playing on a
+// Spotify account and saves the refresh token to a channel config:
+//
+//	go run ./cmd/spotifyauth -config configs/channels/mychannel_config_secrets.yaml
+//
+// The redirect URI must be registered on the Spotify app; the command
+// listens on it to catch the authorization code.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/config"
+	"github.com/pbuckles22/PBChatBot/internal/music"
+)
+
+// authTimeout is how long to wait for the streamer to approve access
+const authTimeout = 5 * time.Minute
+
+func main() {
+	configPath := flag.String("config", "", "channel config file to save the refresh token to (required)")
+	clientID := flag.String("client-id", "", "Spotify app client ID (defaults to spotify.client_id in the config)")
+	redirectURI := flag.String("redirect-uri", "http://127.0.0.1:8888/callback", "redirect URI registered on the Spotify app")
+	flag.Parse()
+
+	if *configPath == "" {
+		log.Fatal("-config is required")
+	}
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Error loading config: %v", err)
+	}
+	if *clientID == "" {
+		*clientID = cfg.Spotify.ClientID
+	}
+	if *clientID == "" {
+		log.Fatal("No client ID: pass -client-id or set spotify.client_id in the config")
+	}
+
+	redirect, err := url.Parse(*redirectURI)
+	if err != nil || redirect.Host == "" {
+		log.Fatalf("Invalid redirect URI %q", *redirectURI)
+	}
+	verifier, challenge, err := music.NewPKCEVerifier()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	code, err := waitForCode(redirect, music.SpotifyAuthURL(*clientID, *redirectURI, challenge))
+	if err != nil {
+		log.Fatalf("Error authorizing: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	refreshToken, err := music.ExchangeSpotifyCode(ctx, *clientID, code, *redirectURI, verifier)
+	if err != nil {
+		log.Fatalf("Error exchanging authorization code: %v", err)
+	}
+
+	if err := config.SetField(*configPath, *clientID, "spotify", "client_id"); err != nil {
+		log.Fatalf("Error saving client ID: %v", err)
+	}
+	if err := config.SetField(*configPath, refreshToken, "spotify", "refresh_token"); err != nil {
+		log.Fatalf("Error saving refresh token: %v", err)
+	}
+	fmt.Printf("Saved the Spotify refresh token to %s. Set music_provider: \"spotify\" to enable !song.\n", *configPath)
+}
+
+// waitForCode prints the authorize URL and serves redirect until Spotify
+// sends the streamer back with an authorization code
+func waitForCode(redirect *url.URL, authURL string) (string, error) {
+	listener, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return "", fmt.Errorf("error listening on %s: %w", redirect.Host, err)
+	}
+
+	codes := make(chan string, 1)
+	failures := make(chan error, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc(redirect.Path, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if reason := query.Get("error"); reason != "" {
+			fmt.Fprintln(w, "Spotify access was not granted. You can close this tab.")
+			select {
+			case failures <- fmt.Errorf("spotify returned %s", reason):
+			default:
+			}
+			return
+		}
+		code := query.Get("code")
+		if code == "" {
+			http.Error(w, "missing code", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintln(w, "Spotify authorized. You can close this tab.")
+		select {
+		case codes <- code:
+		default:
+		}
+	})
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Close()
+
+	fmt.Printf("Open this URL while logged in to the streamer's Spotify account:\n\n%s\n\n", authURL)
+	select {
+	case code := <-codes:
+		return code, nil
+	case err := <-failures:
+		return "", err
+	case <-time.After(authTimeout):
+		return "", errors.New("timed out waiting for authorization")
+	}
+}
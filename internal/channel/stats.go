@@ -7,8 +7,11 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/utils"
 )
 
 // StreamSession represents a single streaming session
@@ -49,12 +52,29 @@ type ChannelStats struct {
 
 	// File paths
 	statsPath string
+
+	// Retention policy applied on save (0 keeps everything)
+	maxSessions   int
+	maxSessionAge time.Duration
+
+	// Cached chatter ranking for GetChatterRank
+	ranking *chatterRanking
+}
+
+// rankCacheTTL is how long a chatter ranking is reused before being rebuilt
+const rankCacheTTL = 60 * time.Second
+
+// chatterRanking is a sorted index of all-time chatter message counts
+type chatterRanking struct {
+	ranks   map[string]int // lowercase username -> rank (1 = most messages)
+	counts  map[string]int // lowercase username -> all-time messages
+	builtAt time.Time
 }
 
 // NewChannelStats creates a new ChannelStats instance
 func NewChannelStats(dataPath string) *ChannelStats {
 	stats := &ChannelStats{
-		statsPath: filepath.Join(dataPath, "channel_stats.json"),
+		statsPath: filepath.Join(utils.EnsureWritableDataPath(dataPath), "channel_stats.json"),
 	}
 
 	// Load existing stats if available
@@ -65,6 +85,40 @@ func NewChannelStats(dataPath string) *ChannelStats {
 	return stats
 }
 
+// SetRetention limits the session history kept on disk to the last
+// maxSessions sessions and to sessions that ended within maxAgeDays days.
+// Either limit can be 0 to disable it. Overall totals are unaffected.
+func (s *ChannelStats) SetRetention(maxSessions, maxAgeDays int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.maxSessions = maxSessions
+	s.maxSessionAge = time.Duration(maxAgeDays) * 24 * time.Hour
+}
+
+// pruneSessions drops sessions that fall outside the retention policy.
+// The caller must hold s.mu.
+func (s *ChannelStats) pruneSessions() {
+	if s.maxSessionAge > 0 {
+		cutoff := time.Now().Add(-s.maxSessionAge)
+		kept := s.Sessions[:0]
+		for _, session := range s.Sessions {
+			if !session.EndTime.Before(cutoff) {
+				kept = append(kept, session)
+			}
+		}
+		s.Sessions = kept
+	}
+	if s.maxSessions > 0 && len(s.Sessions) > s.maxSessions {
+		s.Sessions = append([]StreamSession(nil), s.Sessions[len(s.Sessions)-s.maxSessions:]...)
+	}
+}
+
+// GetStatsPath returns the file the stats are persisted to
+func (s *ChannelStats) GetStatsPath() string {
+	return s.statsPath
+}
+
 // StartSession starts tracking a new stream session
 func (s *ChannelStats) StartSession(game, title string, viewers int) {
 	s.mu.Lock()
@@ -142,11 +196,29 @@ func (s *ChannelStats) UpdateSession(game, title string, viewers int, chatMessag
 	}
 
 	// Update session data
+	s.updateStreamInfo(game, title, viewers)
+	s.CurrentSession.ChatMessages = chatMessages
+	s.CurrentSession.UniqueChatters = uniqueChatters
+}
+
+// UpdateStreamInfo updates the current session's game, title and viewer
+// counts, leaving the chat counts it has recorded alone
+func (s *ChannelStats) UpdateStreamInfo(game, title string, viewers int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.CurrentSession == nil {
+		return
+	}
+	s.updateStreamInfo(game, title, viewers)
+}
+
+// updateStreamInfo does the work of UpdateStreamInfo. The caller must hold
+// s.mu and CurrentSession must be set.
+func (s *ChannelStats) updateStreamInfo(game, title string, viewers int) {
 	s.CurrentSession.Game = game
 	s.CurrentSession.Title = title
 	s.CurrentSession.Viewers = viewers
-	s.CurrentSession.ChatMessages = chatMessages
-	s.CurrentSession.UniqueChatters = uniqueChatters
 
 	// Update peak viewers
 	if viewers > s.CurrentSession.PeakViewers {
@@ -182,6 +254,82 @@ func (s *ChannelStats) RecordChatMessage(username string) {
 	// Update session chatter counts
 	s.CurrentSession.ChatMessages++
 	s.CurrentSession.ChatterCounts[username]++
+	s.ranking = nil
+}
+
+// GetChatterRank returns a user's all-time rank by message count, the number
+// of ranked chatters and the user's message count. Users with the same count
+// share a rank. A user with no messages ranks one past the last chatter.
+func (s *ChannelStats) GetChatterRank(username string) (rank int, chatters int, messages int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ranking == nil || time.Since(s.ranking.builtAt) > rankCacheTTL {
+		s.ranking = s.buildChatterRanking()
+	}
+
+	key := strings.ToLower(username)
+	chatters = len(s.ranking.counts)
+	if rank, ok := s.ranking.ranks[key]; ok {
+		return rank, chatters, s.ranking.counts[key]
+	}
+	return chatters + 1, chatters, 0
+}
+
+// buildChatterRanking ranks chatters by all-time messages, including the
+// current session. Callers must hold s.mu.
+func (s *ChannelStats) buildChatterRanking() *chatterRanking {
+	counts := make(map[string]int)
+	for user, count := range s.ChatterTotals {
+		counts[strings.ToLower(user)] += count
+	}
+	if s.CurrentSession != nil {
+		for user, count := range s.CurrentSession.ChatterCounts {
+			counts[strings.ToLower(user)] += count
+		}
+	}
+
+	users := make([]string, 0, len(counts))
+	for user := range counts {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return counts[users[i]] > counts[users[j]] })
+
+	ranks := make(map[string]int, len(users))
+	for i, user := range users {
+		if i > 0 && counts[user] == counts[users[i-1]] {
+			ranks[user] = ranks[users[i-1]]
+		} else {
+			ranks[user] = i + 1
+		}
+	}
+
+	return &chatterRanking{ranks: ranks, counts: counts, builtAt: time.Now()}
+}
+
+// GetCurrentSessionChatStats returns the chat message count and unique chatter
+// count for the in-progress session. ok is false when no session is active.
+func (s *ChannelStats) GetCurrentSessionChatStats() (messages int, uniqueChatters int, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.CurrentSession == nil {
+		return 0, 0, false
+	}
+
+	return s.CurrentSession.ChatMessages, len(s.CurrentSession.ChatterCounts), true
+}
+
+// LastSession returns the most recently completed session, or false if no
+// session has ended yet
+func (s *ChannelStats) LastSession() (StreamSession, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.Sessions) == 0 {
+		return StreamSession{}, false
+	}
+	return s.Sessions[len(s.Sessions)-1], true
 }
 
 // endCurrentSession ends the current session and saves it to history
@@ -197,7 +345,9 @@ func (s *ChannelStats) endCurrentSession() {
 	// Add to sessions history
 	s.Sessions = append(s.Sessions, *s.CurrentSession)
 
-	// Update overall stats
+	// Update overall stats. These are kept as running totals so they
+	// survive sessions being pruned from the history.
+	previousStreamTime := s.TotalStreamTime
 	s.TotalStreamTime += s.CurrentSession.Duration
 	s.TotalSessions++
 	s.TotalChatMessages += s.CurrentSession.ChatMessages
@@ -208,32 +358,27 @@ func (s *ChannelStats) endCurrentSession() {
 	for user, count := range s.CurrentSession.ChatterCounts {
 		s.ChatterTotals[user] += count
 	}
+	s.ranking = nil
 
 	// Update unique chatters
-	unique := make(map[string]struct{})
-	for _, session := range s.Sessions {
-		for user := range session.ChatterCounts {
-			unique[user] = struct{}{}
-		}
-	}
-	s.UniqueChatters = len(unique)
+	s.UniqueChatters = len(s.ChatterTotals)
 
 	if s.CurrentSession.PeakViewers > s.MaxViewers {
 		s.MaxViewers = s.CurrentSession.PeakViewers
 	}
 
 	// Update average viewers
-	totalViewerTime := 0.0
-	for _, session := range s.Sessions {
-		totalViewerTime += session.AverageViewers * session.Duration.Seconds()
+	totalViewerTime := s.AverageViewers*previousStreamTime.Seconds() +
+		s.CurrentSession.AverageViewers*s.CurrentSession.Duration.Seconds()
+	if s.TotalStreamTime > 0 {
+		s.AverageViewers = totalViewerTime / s.TotalStreamTime.Seconds()
 	}
-	s.AverageViewers = totalViewerTime / s.TotalStreamTime.Seconds()
 
 	// Save the end time of this session
 	s.LastSessionEnd = s.CurrentSession.EndTime
 
 	// Save stats
-	if err := s.Save(); err != nil {
+	if err := s.save(); err != nil {
 		log.Printf("Error saving channel stats: %v", err)
 	}
 
@@ -321,10 +466,17 @@ func (s *ChannelStats) GetLastMonthStats() *ChannelStats {
 	return s.GetStatsForPeriod(start, end)
 }
 
-// Save saves the stats to disk
+// Save prunes old sessions per the retention policy and saves the stats to disk
 func (s *ChannelStats) Save() error {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.save()
+}
+
+// save does the work of Save. The caller must hold s.mu.
+func (s *ChannelStats) save() error {
+	s.pruneSessions()
 
 	data, err := json.MarshalIndent(s, "", "  ")
 	if err != nil {
@@ -354,6 +506,7 @@ func (s *ChannelStats) Load() error {
 	if err := json.Unmarshal(data, s); err != nil {
 		return fmt.Errorf("error unmarshaling stats: %w", err)
 	}
+	s.ranking = nil
 
 	return nil
 }
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	twitchirc "github.com/gempir/go-twitch-irc/v4"
+)
+
+// AccountChecker looks up when a user's Twitch account was created
+type AccountChecker interface {
+	AccountCreatedAt(username string) (time.Time, error)
+}
+
+// SetAccountChecker sets the account lookup used by the min_account_days join gate
+func (cm *CommandManager) SetAccountChecker(checker AccountChecker) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.accountChecker = checker
+	cm.accountCreated = make(map[string]time.Time)
+}
+
+// checkAccountAge returns a rejection message if the user's account is newer
+// than queue.min_account_days, or "" if they may join. Mods bypass the check,
+// and lookup failures let the user through rather than blocking the queue.
+func (cm *CommandManager) checkAccountAge(message twitchirc.PrivateMessage) string {
+	cm.mu.RLock()
+	checker := cm.accountChecker
+	minDays := 0
+	if cm.config != nil {
+		minDays = cm.config.Commands.Queue.MinAccountDays
+	}
+	cm.mu.RUnlock()
+
+	if checker == nil || minDays <= 0 || isModerator(message) {
+		return ""
+	}
+
+	username := message.User.Name
+	createdAt, err := cm.accountCreatedAt(checker, username)
+	if err != nil {
+		log.Printf("Error checking account age for %s: %v", username, err)
+		return ""
+	}
+
+	age := time.Since(createdAt)
+	if age < time.Duration(minDays)*24*time.Hour {
+		return fmt.Sprintf("%s, your account must be at least %d days old to join the queue (it is %d days old).",
+			username, minDays, int(age.Hours()/24))
+	}
+	return ""
+}
+
+// accountCreatedAt returns when the user's account was created, looking it
+// up only the first time
+func (cm *CommandManager) accountCreatedAt(checker AccountChecker, username string) (time.Time, error) {
+	key := strings.ToLower(username)
+
+	cm.mu.RLock()
+	createdAt, ok := cm.accountCreated[key]
+	cm.mu.RUnlock()
+	if ok {
+		return createdAt, nil
+	}
+
+	createdAt, err := checker.AccountCreatedAt(username)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	cm.mu.Lock()
+	cm.accountCreated[key] = createdAt
+	cm.mu.Unlock()
+	return createdAt, nil
+}
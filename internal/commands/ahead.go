@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+)
+
+// maxAheadNames is the most names !ahead lists before counting the rest
+const maxAheadNames = 5
+
+// HandleAhead handles the !ahead command, listing who is ahead of the caller
+// in the queue
+func HandleAhead(message twitch.PrivateMessage, args []string) string {
+	queue := GetCommandManager().GetQueue()
+	if !queue.IsEnabled() {
+		return "Queue system is currently disabled."
+	}
+
+	users := queue.List()
+	position := queue.Position(message.User.Name)
+	if position == -1 || position > len(users) {
+		return fmt.Sprintf("@%s, you are not in the queue!", message.User.Name)
+	}
+	if position == 1 {
+		return fmt.Sprintf("@%s, nobody is ahead of you. You're next!", message.User.Name)
+	}
+
+	ahead := users[:position-1]
+	shown := ahead
+	if len(shown) > maxAheadNames {
+		shown = shown[:maxAheadNames]
+	}
+	response := "Ahead of you: " + strings.Join(shown, ", ")
+	if more := len(ahead) - len(shown); more > 0 {
+		response += fmt.Sprintf(" (+%d more)", more)
+	}
+	return response
+}
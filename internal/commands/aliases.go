@@ -0,0 +1,366 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gempir/go-twitch-irc/v4"
+)
+
+// Limits on runtime aliases, which any viewer can create
+const (
+	maxAliases      = 50 // Per channel
+	maxAliasNameLen = 25
+)
+
+// aliasNamePattern is the characters an alias name may use
+var aliasNamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// modHandlerCommands are the moderator commands registered without ModOnly
+// or IsPrivileged. Aliasing one takes a moderator, as for a ModOnly command.
+var modHandlerCommands = map[string]bool{
+	"clear":        true,
+	"clearqueue":   true,
+	"connstatus":   true,
+	"disable":      true,
+	"echo":         true,
+	"enable":       true,
+	"endqueue":     true,
+	"exportconfig": true,
+	"kill":         true,
+	"move":         true,
+	"pausequeue":   true,
+	"pop":          true,
+	"rawconfig":    true,
+	"remove":       true,
+	"resetbot":     true,
+	"restart":      true,
+	"restoreauto":  true,
+	"restorequeue": true,
+	"savequeue":    true,
+	"startqueue":   true,
+	"startrelay":   true,
+	"stoprelay":    true,
+	"unpausequeue": true,
+}
+
+// aliasesFile returns the path of the channel's runtime alias file
+func (cm *CommandManager) aliasesFile() string {
+	return filepath.Join(cm.queue.GetDataPath(), fmt.Sprintf("aliases_%s.json", cm.channel))
+}
+
+// lookupCommand returns the registered command for a name or alias (case-insensitive)
+func (cm *CommandManager) lookupCommand(name string) (*Command, bool) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	cmd, exists := cm.commands[strings.ToLower(name)]
+	return cmd, exists
+}
+
+// AddAlias registers a runtime alias that invokes the target command.
+// The alias is stored against the target's primary command name, so
+// aliasing an alias points straight at the underlying command.
+func (cm *CommandManager) AddAlias(alias, target string) error {
+	if err := cm.addAlias(alias, target); err != nil {
+		return err
+	}
+	return cm.SaveAliases()
+}
+
+// addAlias registers a runtime alias without persisting it
+func (cm *CommandManager) addAlias(alias, target string) error {
+	alias = strings.ToLower(alias)
+	target = strings.ToLower(target)
+
+	if alias == target {
+		return fmt.Errorf("!%s would create a circular alias", alias)
+	}
+	if len(alias) > maxAliasNameLen {
+		return fmt.Errorf("alias names can be at most %d characters", maxAliasNameLen)
+	}
+	if !aliasNamePattern.MatchString(alias) {
+		return fmt.Errorf("alias names can only use letters, numbers and underscores")
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if _, exists := cm.commands[alias]; exists {
+		return fmt.Errorf("!%s is already a command", alias)
+	}
+	if len(cm.aliases) >= maxAliases {
+		return fmt.Errorf("this channel already has the maximum of %d aliases", maxAliases)
+	}
+
+	targetCmd, exists := cm.commands[target]
+	if !exists {
+		return fmt.Errorf("!%s is not a command", target)
+	}
+
+	// Resolve runtime aliases to the command they point at
+	base := targetCmd.Name
+	if resolved, isAlias := cm.aliases[base]; isAlias {
+		base = resolved
+	}
+
+	baseCmd := cm.commands[base]
+	aliasCmd := &Command{
+		Name:         alias,
+		Description:  fmt.Sprintf("Alias for !%s", base),
+		Handler:      baseCmd.Handler,
+		ModOnly:      baseCmd.ModOnly,
+		IsPrivileged: baseCmd.IsPrivileged,
+		Cooldown:     baseCmd.Cooldown,
+		Category:     baseCmd.Category,
+		Metadata:     baseCmd.Metadata,
+		Silent:       baseCmd.Silent,
+	}
+	cm.commands[alias] = aliasCmd
+	cm.cooldown.SetCooldown(alias, aliasCmd.Cooldown)
+
+	if cm.aliases == nil {
+		cm.aliases = make(map[string]string)
+	}
+	cm.aliases[alias] = base
+	return nil
+}
+
+// aliasNeedsModerator reports whether creating or removing an alias for cmd
+// takes a moderator: cmd, or the command it aliases, is ModOnly or one of
+// modHandlerCommands
+func (cm *CommandManager) aliasNeedsModerator(cmd *Command) bool {
+	name := cmd.Name
+	if target, isAlias := cm.GetAliases()[name]; isAlias {
+		name = target
+	}
+	return cmd.ModOnly || modHandlerCommands[name]
+}
+
+// RemoveAlias removes a runtime alias
+func (cm *CommandManager) RemoveAlias(alias string) error {
+	alias = strings.ToLower(alias)
+
+	cm.mu.Lock()
+	if _, exists := cm.aliases[alias]; !exists {
+		cm.mu.Unlock()
+		return fmt.Errorf("!%s is not an alias", alias)
+	}
+	delete(cm.aliases, alias)
+	delete(cm.commands, alias)
+	cm.mu.Unlock()
+
+	return cm.SaveAliases()
+}
+
+// GetAliases returns a copy of the runtime aliases (alias -> target command)
+func (cm *CommandManager) GetAliases() map[string]string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	aliases := make(map[string]string, len(cm.aliases))
+	for alias, target := range cm.aliases {
+		aliases[alias] = target
+	}
+	return aliases
+}
+
+// SaveAliases saves the runtime aliases to disk
+func (cm *CommandManager) SaveAliases() error {
+	aliases := cm.GetAliases()
+
+	// Ensure the data directory exists
+	if err := os.MkdirAll(cm.queue.GetDataPath(), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(aliases, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal aliases: %w", err)
+	}
+
+	if err := os.WriteFile(cm.aliasesFile(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write aliases: %w", err)
+	}
+
+	return nil
+}
+
+// LoadAliases loads the runtime aliases from disk and registers them.
+// This should be called after all other commands have been registered.
+func (cm *CommandManager) LoadAliases() error {
+	cm.mu.Lock()
+	cm.aliases = make(map[string]string)
+	cm.mu.Unlock()
+
+	data, err := os.ReadFile(cm.aliasesFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // No aliases saved yet
+		}
+		return fmt.Errorf("failed to read aliases: %w", err)
+	}
+
+	var aliases map[string]string
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return fmt.Errorf("failed to unmarshal aliases: %w", err)
+	}
+
+	// Skip aliases that can't be restored (e.g. their command was renamed)
+	// rather than dropping the rest
+	for alias, target := range aliases {
+		if err := cm.addAlias(alias, target); err != nil {
+			log.Printf("Skipping alias !%s: %v", alias, err)
+		}
+	}
+	return nil
+}
+
+// HandleAlias handles the !alias command
+func HandleAlias(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	if len(args) < 2 {
+		return "Usage: !alias !<alias> !<command>"
+	}
+
+	alias := strings.TrimPrefix(args[0], cm.prefix)
+	target := strings.TrimPrefix(args[1], cm.prefix)
+
+	// Aliasing a restricted command requires the same privileges
+	if targetCmd, exists := cm.lookupCommand(target); exists {
+		if cm.aliasNeedsModerator(targetCmd) && !isModerator(message) {
+			return "Only moderators can create aliases for moderator commands."
+		}
+		if targetCmd.IsPrivileged && !isPrivileged(message) {
+			return "Only moderators and VIPs can create aliases for privileged commands."
+		}
+	}
+
+	if err := cm.AddAlias(alias, target); err != nil {
+		return fmt.Sprintf("Error creating alias: %v", err)
+	}
+	return fmt.Sprintf("!%s is now an alias for !%s", strings.ToLower(alias), strings.ToLower(target))
+}
+
+// HandleAliases handles the !aliases command
+func HandleAliases(message twitch.PrivateMessage, args []string) string {
+	aliases := GetCommandManager().GetAliases()
+	if len(aliases) == 0 {
+		return "No aliases have been defined."
+	}
+
+	names := make([]string, 0, len(aliases))
+	for alias := range aliases {
+		names = append(names, alias)
+	}
+	sort.Strings(names)
+
+	entries := make([]string, len(names))
+	for i, alias := range names {
+		entries[i] = fmt.Sprintf("!%s -> !%s", alias, aliases[alias])
+	}
+	return fmt.Sprintf("Aliases: %s", strings.Join(entries, ", "))
+}
+
+// CommandAliases returns every alias of each command that has one, keyed by
+// command name. Both built-in aliases and runtime !alias aliases are
+// included, each list sorted.
+func (cm *CommandManager) CommandAliases() map[string][]string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	aliases := make(map[string][]string)
+	seen := make(map[*Command]bool)
+	for _, cmd := range cm.commands {
+		if seen[cmd] {
+			continue
+		}
+		seen[cmd] = true
+		for _, alias := range cmd.Aliases {
+			aliases[cmd.Name] = append(aliases[cmd.Name], strings.ToLower(alias))
+		}
+	}
+	for alias, target := range cm.aliases {
+		aliases[target] = append(aliases[target], alias)
+	}
+	for _, list := range aliases {
+		sort.Strings(list)
+	}
+	return aliases
+}
+
+// HandleListAliases handles the !listaliases command, showing the aliases of
+// one command or of every command that has any
+func HandleListAliases(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	aliases := cm.CommandAliases()
+
+	if len(args) > 0 {
+		name := strings.TrimPrefix(args[0], cm.prefix)
+		cmd, exists := cm.lookupCommand(name)
+		if !exists {
+			return fmt.Sprintf("Unknown command: %s%s", cm.prefix, name)
+		}
+		// An alias resolves to the command it runs
+		base := cmd.Name
+		if target, isAlias := cm.GetAliases()[base]; isAlias {
+			base = target
+		}
+		if len(aliases[base]) == 0 {
+			return fmt.Sprintf("%s%s has no aliases.", cm.prefix, base)
+		}
+		return fmt.Sprintf("Aliases for %s%s: %s.", cm.prefix, base, prefixAll(cm.prefix, aliases[base]))
+	}
+
+	if len(aliases) == 0 {
+		return "No commands have aliases."
+	}
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	entries := make([]string, len(names))
+	for i, name := range names {
+		entries[i] = fmt.Sprintf("%s%s(%s)", cm.prefix, name, prefixAll(cm.prefix, aliases[name]))
+	}
+	return fmt.Sprintf("Commands with aliases (%d): %s", len(names), strings.Join(entries, ", "))
+}
+
+// prefixAll joins names with the command prefix added to each
+func prefixAll(prefix string, names []string) string {
+	prefixed := make([]string, len(names))
+	for i, name := range names {
+		prefixed[i] = prefix + name
+	}
+	return strings.Join(prefixed, ", ")
+}
+
+// HandleUnalias handles the !unalias command
+func HandleUnalias(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	if len(args) < 1 {
+		return "Usage: !unalias !<alias>"
+	}
+
+	alias := strings.TrimPrefix(args[0], cm.prefix)
+	if aliasCmd, exists := cm.lookupCommand(alias); exists {
+		if cm.aliasNeedsModerator(aliasCmd) && !isModerator(message) {
+			return "Only moderators can remove aliases for moderator commands."
+		}
+		if aliasCmd.IsPrivileged && !isPrivileged(message) {
+			return "Only moderators and VIPs can remove aliases for privileged commands."
+		}
+	}
+
+	if err := cm.RemoveAlias(alias); err != nil {
+		return fmt.Sprintf("Error removing alias: %v", err)
+	}
+	return fmt.Sprintf("Alias !%s removed", strings.ToLower(alias))
+}
@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"fmt"
+)
+
+// Announcer posts highlighted announcements to chat
+type Announcer interface {
+	Announce(message string)
+}
+
+// SetAnnouncer sets where queue announcements are sent
+func (cm *CommandManager) SetAnnouncer(announcer Announcer) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.announcer = announcer
+}
+
+// recordJoin counts a successful queue join and sends any announcements
+// configured for queue-full or join milestones
+func (cm *CommandManager) recordJoin() {
+	cm.mu.Lock()
+	cm.joinCount++
+	joins := cm.joinCount
+	announcer := cm.announcer
+	cfg := cm.config
+	cm.mu.Unlock()
+
+	if announcer == nil || cfg == nil {
+		return
+	}
+
+	queueCfg := cfg.Commands.Queue
+	if queueCfg.AnnounceQueueFull && queueCfg.MaxSize > 0 && cm.queue.Size() == queueCfg.MaxSize {
+		announcer.Announce(fmt.Sprintf("The queue is full! (%d/%d)", queueCfg.MaxSize, queueCfg.MaxSize))
+	}
+	if every := queueCfg.AnnounceMilestoneEvery; every > 0 && joins%every == 0 {
+		announcer.Announce(fmt.Sprintf("Milestone: %d queue joins this session!", joins))
+	}
+}
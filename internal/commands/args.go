@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// requireArgs returns a usage error if fewer than n arguments were provided
+func requireArgs(args []string, n int, usage string) error {
+	if len(args) < n {
+		return fmt.Errorf("Usage: %s", usage)
+	}
+	return nil
+}
+
+// parsePositiveInt parses arg as a number greater than zero.
+// what describes the value in the error message (e.g. "target position").
+func parsePositiveInt(arg string, what string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("Invalid %s. Please specify a positive number.", what)
+	}
+	return n, nil
+}
+
+// findUser returns the queued username matching name case-insensitively,
+// preserving the capitalization stored in the queue. Returns "" if not found.
+func findUser(users []string, name string) string {
+	for _, user := range users {
+		if strings.EqualFold(user, name) {
+			return user
+		}
+	}
+	return ""
+}
+
+// resolveUserOrPosition interprets arg as either a 1-based queue position or
+// a username, returning the exact queued username and its position.
+func resolveUserOrPosition(users []string, arg string) (string, int, error) {
+	if position, err := strconv.Atoi(arg); err == nil {
+		if position < 1 || position > len(users) {
+			return "", 0, fmt.Errorf("Invalid position. Queue has %d users.", len(users))
+		}
+		return users[position-1], position, nil
+	}
+
+	username := findUser(users, arg)
+	if username == "" {
+		return "", 0, fmt.Errorf("%s is not in the queue!", arg)
+	}
+	for i, user := range users {
+		if user == username {
+			return username, i + 1, nil
+		}
+	}
+	return username, 0, nil
+}
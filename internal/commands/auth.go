@@ -6,18 +6,13 @@ import (
 
 	twitchirc "github.com/gempir/go-twitch-irc/v4"
 	twitchauth "github.com/pbuckles22/PBChatBot/internal/twitch"
-	"github.com/pbuckles22/PBChatBot/internal/utils"
 )
 
-// formatTimeET formats a time in the channel's configured timezone
-func formatTimeET(t time.Time, timezone string) string {
-	return utils.FormatTimeForDisplay(t, timezone)
-}
-
 // RegisterAuthCommand registers the auth command
 func RegisterAuthCommand(cm *CommandManager, authManager *twitchauth.AuthManager) {
 	cm.RegisterCommand(&Command{
 		Name:        "auth",
+		Category:    CategoryModeration,
 		Description: "Refreshes the bot's authentication token",
 		ModOnly:     true, // Only moderators can use this command
 		Handler: func(message twitchirc.PrivateMessage, args []string) string {
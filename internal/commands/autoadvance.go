@@ -0,0 +1,174 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+	"github.com/pbuckles22/PBChatBot/internal/queue"
+)
+
+// autoAdvanceMinInterval is the shortest time allowed between auto-pops
+const autoAdvanceMinInterval = 10 * time.Second
+
+// ErrAutoAdvanceRunning is returned when starting auto-advance while it's already running
+var ErrAutoAdvanceRunning = errors.New("auto-advance is already running")
+
+// AutoAdvancer pops the front of the queue on a fixed interval and announces
+// who is up, until stopped or the queue runs out. Only one can run at a time.
+type AutoAdvancer struct {
+	mu       sync.Mutex
+	clock    CountdownClock
+	send     func(string)
+	queue    *queue.Queue
+	interval time.Duration
+	timer    CountdownStopper
+	active   bool
+	// Incremented on every start and stop so stale callbacks are ignored
+	generation int
+}
+
+// NewAutoAdvancer creates an auto-advancer that announces pops using send.
+// If clock is nil, pops are scheduled with time.Timer.
+func NewAutoAdvancer(clock CountdownClock, send func(string)) *AutoAdvancer {
+	if clock == nil {
+		clock = realCountdownClock{}
+	}
+	return &AutoAdvancer{
+		clock: clock,
+		send:  send,
+	}
+}
+
+// Start pops the front of q every interval
+func (a *AutoAdvancer) Start(q *queue.Queue, interval time.Duration) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.active {
+		return ErrAutoAdvanceRunning
+	}
+	a.active = true
+	a.generation++
+	a.queue = q
+	a.interval = interval
+	a.schedule(a.generation)
+	return nil
+}
+
+// schedule arranges the next pop. Caller must hold the lock.
+func (a *AutoAdvancer) schedule(generation int) {
+	a.timer = a.clock.AfterFunc(a.interval, func() {
+		a.advance(generation)
+	})
+}
+
+// advance pops the next user if auto-advance is still running, stopping
+// once the queue is empty
+func (a *AutoAdvancer) advance(generation int) {
+	a.mu.Lock()
+	if !a.active || a.generation != generation {
+		a.mu.Unlock()
+		return
+	}
+
+	var text string
+	user, err := a.queue.Pop()
+	remaining := a.queue.Size() + len(a.queue.ListVIP())
+	switch {
+	case err != nil:
+		a.stopLocked()
+		text = fmt.Sprintf("Auto-advance stopped: %v.", err)
+	case remaining == 0:
+		a.stopLocked()
+		text = fmt.Sprintf("@%s, you're up! The queue is now empty, so auto-advance has stopped.", user)
+	default:
+		a.schedule(generation)
+		text = fmt.Sprintf("@%s, you're up! (%d left in queue, next in %s)", user, remaining, formatCountdown(a.interval))
+	}
+	a.mu.Unlock()
+
+	a.send(text)
+}
+
+// Stop ends auto-advance. It returns false if it wasn't running.
+func (a *AutoAdvancer) Stop() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.active {
+		return false
+	}
+	a.stopLocked()
+	return true
+}
+
+// stopLocked cancels the pending pop. Caller must hold the lock.
+func (a *AutoAdvancer) stopLocked() {
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+	a.timer = nil
+	a.queue = nil
+	a.active = false
+	a.generation++
+}
+
+// IsActive reports whether auto-advance is running
+func (a *AutoAdvancer) IsActive() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.active
+}
+
+// SetAutoAdvancer sets the auto-advancer used by !autoadvance
+func (cm *CommandManager) SetAutoAdvancer(autoAdvance *AutoAdvancer) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.autoAdvance = autoAdvance
+}
+
+// HandleAutoAdvance handles the !autoadvance command
+func HandleAutoAdvance(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	cm.mu.RLock()
+	autoAdvance := cm.autoAdvance
+	cm.mu.RUnlock()
+
+	if autoAdvance == nil {
+		return "Auto-advance is not available."
+	}
+	if len(args) < 1 {
+		return "Usage: !autoadvance <interval> or !autoadvance off"
+	}
+
+	if strings.EqualFold(args[0], "off") {
+		if !autoAdvance.Stop() {
+			return "Auto-advance is not running."
+		}
+		return "Auto-advance stopped."
+	}
+
+	interval, err := time.ParseDuration(args[0])
+	if err != nil || interval <= 0 {
+		return fmt.Sprintf("Invalid interval: %s (use e.g. 90s or 5m)", args[0])
+	}
+	if interval < autoAdvanceMinInterval {
+		return fmt.Sprintf("The auto-advance interval must be at least %s.", formatCountdown(autoAdvanceMinInterval))
+	}
+
+	q := cm.GetQueue()
+	if !q.IsEnabled() {
+		return "Queue system is currently disabled."
+	}
+	if q.Size() == 0 && len(q.ListVIP()) == 0 {
+		return "Queue is empty."
+	}
+	if err := autoAdvance.Start(q, interval); err != nil {
+		return "Auto-advance is already running. Use !autoadvance off first."
+	}
+	return fmt.Sprintf("Auto-advance started: popping the next user every %s.", formatCountdown(interval))
+}
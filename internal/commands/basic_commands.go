@@ -4,18 +4,21 @@ package commands
 func RegisterBasicCommands(cm *CommandManager) {
 	cm.RegisterCommand(&Command{
 		Name:        "help",
+		Category:    CategoryGeneral,
 		Description: "Show the list of available commands",
 		Handler:     HandleHelp,
 	})
 
 	cm.RegisterCommand(&Command{
 		Name:        "ping",
+		Category:    CategoryGeneral,
 		Description: "Check if the bot is alive",
 		Handler:     HandlePing,
 	})
 
 	cm.RegisterCommand(&Command{
 		Name:        "savequeue",
+		Category:    CategoryQueue,
 		Aliases:     []string{"svq"},
 		Description: "Save the queue state",
 		Handler:     HandleSaveState,
@@ -23,12 +26,14 @@ func RegisterBasicCommands(cm *CommandManager) {
 
 	cm.RegisterCommand(&Command{
 		Name:        "endqueue",
+		Category:    CategoryQueue,
 		Description: "End the queue system",
 		Handler:     HandleEndQueue,
 	})
 
 	cm.RegisterCommand(&Command{
 		Name:        "clearqueue",
+		Category:    CategoryQueue,
 		Aliases:     []string{"cq"},
 		Description: "Clear all users from the queue",
 		Handler:     HandleClearQueue,
@@ -36,6 +41,7 @@ func RegisterBasicCommands(cm *CommandManager) {
 
 	cm.RegisterCommand(&Command{
 		Name:        "queue",
+		Category:    CategoryQueue,
 		Aliases:     []string{"q"},
 		Description: "Show the current queue",
 		Handler:     HandleQueue,
@@ -43,41 +49,132 @@ func RegisterBasicCommands(cm *CommandManager) {
 
 	cm.RegisterCommand(&Command{
 		Name:        "join",
+		Category:    CategoryQueue,
 		Aliases:     []string{"j"},
 		Description: "Join the queue",
 		Handler:     HandleJoin,
 	})
 
+	cm.RegisterCommand(&Command{
+		Name:        "joinvip",
+		Category:    CategoryQueue,
+		Aliases:     []string{"jv"},
+		Description: "Join the VIP line (VIPs and subscribers)",
+		Handler:     HandleJoinVIP,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "queuevip",
+		Category:    CategoryQueue,
+		Aliases:     []string{"qv"},
+		Description: "Show the VIP line",
+		Handler:     HandleQueueVIP,
+	})
+
 	cm.RegisterCommand(&Command{
 		Name:        "leave",
+		Category:    CategoryQueue,
 		Aliases:     []string{"l"},
 		Description: "Leave the queue",
 		Handler:     HandleLeave,
 	})
 
+	cm.RegisterCommand(&Command{
+		Name:        "unqueue",
+		Category:    CategoryQueue,
+		Description: "Leave the queue (use !leave instead)",
+		Handler:     HandleUnqueue,
+		Metadata:    map[string]interface{}{MetadataAliasAddedAt: unqueueAddedAt},
+	})
+
 	cm.RegisterCommand(&Command{
 		Name:        "position",
+		Category:    CategoryQueue,
 		Aliases:     []string{"pos"},
 		Description: "Show your position in the queue",
 		Handler:     HandlePosition,
 	})
 
+	cm.RegisterCommand(&Command{
+		Name:        "ahead",
+		Category:    CategoryQueue,
+		Description: "Show who is ahead of you in the queue",
+		Handler:     HandleAhead,
+	})
+
 	cm.RegisterCommand(&Command{
 		Name:        "pop",
+		Category:    CategoryQueue,
 		Aliases:     []string{"p"},
 		Description: "Pop users from the queue",
 		Handler:     HandlePop,
 	})
 
+	cm.RegisterCommand(&Command{
+		Name:        "lobby",
+		Category:    CategoryQueue,
+		Description: "Show the lobby code, or set it (mods and VIPs)",
+		Handler:     HandleLobby,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "autoadvance",
+		Category:    CategoryQueue,
+		Description: "Pop the next user on a timer until stopped or the queue is empty",
+		Handler:     HandleAutoAdvance,
+		ModOnly:     true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:         "skipto",
+		Category:     CategoryQueue,
+		Description:  "Skip everyone ahead of a user in the queue",
+		Handler:      HandleSkipTo,
+		IsPrivileged: true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "nowserving",
+		Category:    CategoryQueue,
+		Description: "Show who has been popped since the last !clearserved",
+		Handler:     HandleNowServing,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:         "clearserved",
+		Category:     CategoryQueue,
+		Description:  "Empty the now-serving list when a batch is finished",
+		Handler:      HandleClearServed,
+		IsPrivileged: true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:         "promotesub",
+		Category:     CategoryQueue,
+		Description:  "Move the highest-positioned subscriber to the front of the queue",
+		Handler:      HandlePromoteSub,
+		IsPrivileged: true,
+	})
+
 	cm.RegisterCommand(&Command{
 		Name:        "move",
+		Category:    CategoryQueue,
 		Aliases:     []string{"m", "mv"},
 		Description: "Move a user in the queue",
 		Handler:     HandleMove,
 	})
 
+	cm.RegisterCommand(&Command{
+		Name:         "movebatch",
+		Category:     CategoryQueue,
+		Description:  "Move several users to consecutive positions in the queue",
+		Handler:      HandleMoveBatch,
+		IsPrivileged: true,
+	})
+
 	cm.RegisterCommand(&Command{
 		Name:        "remove",
+		Category:    CategoryQueue,
 		Aliases:     []string{"r"},
 		Description: "Remove a user from the queue",
 		Handler:     HandleRemove,
@@ -85,13 +182,104 @@ func RegisterBasicCommands(cm *CommandManager) {
 
 	cm.RegisterCommand(&Command{
 		Name:        "clear",
+		Category:    CategoryQueue,
 		Aliases:     []string{"c"},
 		Description: "Clear the queue",
 		Handler:     HandleClear,
 	})
 
+	cm.RegisterCommand(&Command{
+		Name:        "reserve",
+		Category:    CategoryQueue,
+		Description: "Hold a queue position for a user (mod only)",
+		Handler:     HandleReserve,
+		ModOnly:     true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "unreserve",
+		Category:    CategoryQueue,
+		Description: "Release a user's reserved queue position (mod only)",
+		Handler:     HandleUnreserve,
+		ModOnly:     true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "pin",
+		Category:    CategoryQueue,
+		Description: "Keep a user at their queue position through moves and pops (mod only)",
+		Handler:     HandlePin,
+		ModOnly:     true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "unpin",
+		Category:    CategoryQueue,
+		Description: "Release a pinned user (mod only)",
+		Handler:     HandleUnpin,
+		ModOnly:     true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "queuetext",
+		Category:    CategoryQueue,
+		Description: "Whisper yourself the queue as one line for spreadsheets (mod only)",
+		Handler:     HandleQueueText,
+		ModOnly:     true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "queuedupes",
+		Category:    CategoryQueue,
+		Description: "Report queue entries that normalize to the same login (mod only)",
+		Handler:     HandleQueueDupes,
+		ModOnly:     true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:         "qban",
+		Category:     CategoryQueue,
+		Description:  "Bar a user from ever joining the queue",
+		Handler:      HandleQueueBan,
+		IsPrivileged: true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:         "qunban",
+		Category:     CategoryQueue,
+		Description:  "Let a barred user join the queue again",
+		Handler:      HandleQueueUnban,
+		IsPrivileged: true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:         "qbanlist",
+		Category:     CategoryQueue,
+		Description:  "List users barred from the queue",
+		Handler:      HandleQueueBanList,
+		IsPrivileged: true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "undoclear",
+		Category:    CategoryQueue,
+		Aliases:     []string{"uc"},
+		Description: "Restore the queue from the last clear",
+		Handler:     HandleUndoClear,
+		ModOnly:     true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:         "resetlimits",
+		Category:     CategoryQueue,
+		Description:  "Clear everyone's rejoin cooldown without clearing the queue",
+		Handler:      HandleResetLimits,
+		IsPrivileged: true,
+	})
+
 	cm.RegisterCommand(&Command{
 		Name:        "enable",
+		Category:    CategoryQueue,
 		Aliases:     []string{"e"},
 		Description: "Enable the queue system",
 		Handler:     HandleEnable,
@@ -99,6 +287,7 @@ func RegisterBasicCommands(cm *CommandManager) {
 
 	cm.RegisterCommand(&Command{
 		Name:        "disable",
+		Category:    CategoryQueue,
 		Aliases:     []string{"d"},
 		Description: "Disable the queue system",
 		Handler:     HandleDisable,
@@ -106,6 +295,7 @@ func RegisterBasicCommands(cm *CommandManager) {
 
 	cm.RegisterCommand(&Command{
 		Name:        "pausequeue",
+		Category:    CategoryQueue,
 		Aliases:     []string{"pq"},
 		Description: "Pause the queue system",
 		Handler:     HandlePause,
@@ -113,6 +303,7 @@ func RegisterBasicCommands(cm *CommandManager) {
 
 	cm.RegisterCommand(&Command{
 		Name:        "unpausequeue",
+		Category:    CategoryQueue,
 		Aliases:     []string{"uq"},
 		Description: "Unpause the queue system",
 		Handler:     HandleUnpause,
@@ -120,6 +311,7 @@ func RegisterBasicCommands(cm *CommandManager) {
 
 	cm.RegisterCommand(&Command{
 		Name:        "restorequeue",
+		Category:    CategoryQueue,
 		Aliases:     []string{"rq"},
 		Description: "Load the queue state",
 		Handler:     HandleLoadState,
@@ -127,6 +319,7 @@ func RegisterBasicCommands(cm *CommandManager) {
 
 	cm.RegisterCommand(&Command{
 		Name:        "restoreauto",
+		Category:    CategoryQueue,
 		Aliases:     []string{"ra"},
 		Description: "Restore from auto-save (for testing crash recovery)",
 		Handler:     HandleRestoreAuto,
@@ -134,6 +327,7 @@ func RegisterBasicCommands(cm *CommandManager) {
 
 	cm.RegisterCommand(&Command{
 		Name:        "kill",
+		Category:    CategoryModeration,
 		Aliases:     []string{"k"},
 		Description: "Shutdown the bot",
 		Handler:     HandleKill,
@@ -141,13 +335,262 @@ func RegisterBasicCommands(cm *CommandManager) {
 
 	cm.RegisterCommand(&Command{
 		Name:        "restart",
+		Category:    CategoryModeration,
 		Aliases:     []string{"rs"},
 		Description: "Restart the bot",
 		Handler:     HandleRestart,
 	})
 
+	cm.RegisterCommand(&Command{
+		Name:        "resetbot",
+		Category:    CategoryModeration,
+		Description: "Reset bot state and reload config (broadcaster only)",
+		Handler:     HandleResetBot,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:         "resetcooldown",
+		Category:     CategoryModeration,
+		Description:  "Clear a command's cooldown for yourself or another user",
+		Handler:      HandleResetCooldown,
+		IsPrivileged: true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "restrict",
+		Category:    CategoryModeration,
+		Description: "Cap how many times a user can run a command this session",
+		Handler:     HandleRestrict,
+		ModOnly:     true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "unrestrict",
+		Category:    CategoryModeration,
+		Description: "Lift a user's !restrict cap on a command",
+		Handler:     HandleUnrestrict,
+		ModOnly:     true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "showconfig",
+		Category:    CategoryModeration,
+		Description: "Show non-sensitive config values",
+		Handler:     HandleShowConfig,
+		ModOnly:     true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "commandcount",
+		Category:    CategoryModeration,
+		Description: "Show how many commands are registered",
+		Handler:     HandleCommandCount,
+		ModOnly:     true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "timezone",
+		Category:    CategoryModeration,
+		Description: "Show or change the channel's display timezone",
+		Handler:     HandleTimezone,
+		ModOnly:     true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "exportconfig",
+		Category:    CategoryModeration,
+		Description: "Export the current config to a file (broadcaster only)",
+		Handler:     HandleExportConfig,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "rawconfig",
+		Category:    CategoryModeration,
+		Description: "Write the config YAML, or one section of it, to a file (broadcaster only)",
+		Handler:     HandleRawConfig,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "echo",
+		Category:    CategoryFun,
+		Description: "Send text to chat as the bot (broadcaster only)",
+		Handler:     HandleEcho,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "countdown",
+		Category:    CategoryFun,
+		Description: "Start or cancel a countdown announced in chat",
+		Handler:     HandleCountdown,
+		ModOnly:     true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "timer",
+		Category:    CategoryFun,
+		Description: "Set, cancel or list named timers announced in chat",
+		Handler:     HandleTimer,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "topic",
+		Category:    CategoryGeneral,
+		Description: "Show the stream topic, or set it (mod only)",
+		Handler:     HandleTopic,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "cleartopic",
+		Category:    CategoryModeration,
+		Description: "Clear the stream topic (mod only)",
+		Handler:     HandleClearTopic,
+		ModOnly:     true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "status",
+		Category:    CategoryGeneral,
+		Description: "Show the queue status and stream topic",
+		Handler:     HandleStatus,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "song",
+		Category:    CategoryGeneral,
+		Aliases:     []string{"music"},
+		Description: "Show the song currently playing on stream",
+		Handler:     HandleSong,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "version",
+		Category:    CategoryGeneral,
+		Description: "Show the bot's version and whether an update is available",
+		Handler:     HandleVersion,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "points",
+		Category:    CategoryStats,
+		Description: "Show how many points you have",
+		Handler:     HandlePoints,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "addpoints",
+		Category:    CategoryModeration,
+		Description: "Give a user points (mod only)",
+		Handler:     HandleAddPoints,
+		ModOnly:     true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "removepoints",
+		Category:    CategoryModeration,
+		Description: "Take points from a user (mod only)",
+		Handler:     HandleRemovePoints,
+		ModOnly:     true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "pointsleaderboard",
+		Category:    CategoryStats,
+		Description: "Show the top 5 point holders",
+		Handler:     HandlePointsLeaderboard,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "hype",
+		Category:    CategoryFun,
+		Description: "Post the hype train message sequence, or cancel it",
+		Handler:     HandleHype,
+		ModOnly:     true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "strawpoll",
+		Category:    CategoryFun,
+		Aliases:     []string{"straw"},
+		Description: "Open a 60-second straw poll",
+		Handler:     HandleStrawPoll,
+		ModOnly:     true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "vote",
+		Category:    CategoryFun,
+		Description: "Vote in the current straw poll",
+		Handler:     HandleVote,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "endpoll",
+		Category:    CategoryFun,
+		Description: "Close the current straw poll early",
+		Handler:     HandleEndPoll,
+		ModOnly:     true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "giveaway",
+		Category:    CategoryFun,
+		Description: "Start, end or re-pick a giveaway",
+		Handler:     HandleGiveaway,
+		ModOnly:     true,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "enter",
+		Category:    CategoryFun,
+		Description: "Enter the current giveaway",
+		Handler:     HandleEnter,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "startrelay",
+		Category:    CategoryModeration,
+		Description: "Relay chat to another channel (broadcaster only)",
+		Handler:     HandleStartRelay,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "stoprelay",
+		Category:    CategoryModeration,
+		Description: "Stop relaying chat to another channel (broadcaster only)",
+		Handler:     HandleStopRelay,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "alias",
+		Category:    CategoryModeration,
+		Description: "Create a runtime alias for a command",
+		Handler:     HandleAlias,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "aliases",
+		Category:    CategoryModeration,
+		Description: "List runtime command aliases",
+		Handler:     HandleAliases,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "listaliases",
+		Category:    CategoryGeneral,
+		Description: "List the aliases of a command, or of every command",
+		Handler:     HandleListAliases,
+	})
+
+	cm.RegisterCommand(&Command{
+		Name:        "unalias",
+		Category:    CategoryModeration,
+		Description: "Remove a runtime command alias",
+		Handler:     HandleUnalias,
+	})
+
 	cm.RegisterCommand(&Command{
 		Name:        "startqueue",
+		Category:    CategoryQueue,
 		Aliases:     []string{"sq"},
 		Description: "Start the queue system",
 		Handler:     HandleStartQueue,
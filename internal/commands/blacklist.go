@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+)
+
+// HandleQueueBan handles the !qban command, barring a user from the queue
+func HandleQueueBan(message twitch.PrivateMessage, args []string) string {
+	if err := requireArgs(args, 1, "!qban <username>"); err != nil {
+		return err.Error()
+	}
+
+	username := strings.TrimPrefix(args[0], "@")
+	if !GetCommandManager().GetQueue().Ban(username) {
+		return fmt.Sprintf("%s is already barred from the queue.", username)
+	}
+	return fmt.Sprintf("%s is now barred from joining the queue.", username)
+}
+
+// HandleQueueUnban handles the !qunban command, lifting a queue ban
+func HandleQueueUnban(message twitch.PrivateMessage, args []string) string {
+	if err := requireArgs(args, 1, "!qunban <username>"); err != nil {
+		return err.Error()
+	}
+
+	username := strings.TrimPrefix(args[0], "@")
+	if !GetCommandManager().GetQueue().Unban(username) {
+		return fmt.Sprintf("%s isn't barred from the queue.", username)
+	}
+	return fmt.Sprintf("%s can join the queue again.", username)
+}
+
+// HandleQueueBanList handles the !qbanlist command
+func HandleQueueBanList(message twitch.PrivateMessage, args []string) string {
+	banned := GetCommandManager().GetQueue().ListBanned()
+	if len(banned) == 0 {
+		return "Nobody is barred from the queue."
+	}
+	return fmt.Sprintf("Barred from the queue: %s (%d total)", strings.Join(banned, ", "), len(banned))
+}
@@ -0,0 +1,24 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/gempir/go-twitch-irc/v4"
+	channelstats "github.com/pbuckles22/PBChatBot/internal/channel"
+)
+
+// RegisterChatStatsCommand registers the chatstats command
+func RegisterChatStatsCommand(cm *CommandManager, stats *channelstats.ChannelStats) {
+	cm.RegisterCommand(&Command{
+		Name:        "chatstats",
+		Category:    CategoryStats,
+		Description: "Shows chat activity for the current session",
+		Handler: func(message twitch.PrivateMessage, args []string) string {
+			messages, chatters, active := stats.GetCurrentSessionChatStats()
+			if !active {
+				return "No session is currently being tracked."
+			}
+			return fmt.Sprintf("This session: %d chat messages from %d unique chatters", messages, chatters)
+		},
+	})
+}
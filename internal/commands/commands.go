@@ -1,14 +1,22 @@
 package commands
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	twitchirc "github.com/gempir/go-twitch-irc/v4"
 	"github.com/pbuckles22/PBChatBot/internal/config"
+	"github.com/pbuckles22/PBChatBot/internal/metrics"
+	"github.com/pbuckles22/PBChatBot/internal/music"
 	"github.com/pbuckles22/PBChatBot/internal/queue"
+	"github.com/pbuckles22/PBChatBot/internal/trace"
 )
 
 // Command represents a chat command that can be executed by users.
@@ -30,6 +38,38 @@ type Command struct {
 	IsPrivileged bool
 	// Cooldown configuration for the command
 	Cooldown CooldownConfig
+	// Heading the command is listed under in !help (e.g. CategoryQueue)
+	Category string
+	// Keywords that run the command without the prefix when a chat message
+	// is exactly one of them (case-insensitive). Empty for most commands.
+	Triggers []string
+	// Extra details read by the command's handler, keyed by the Metadata*
+	// constants (e.g. MetadataAliasAddedAt). Nil for most commands.
+	Metadata map[string]interface{}
+	// If true, the handler still runs (after the usual permission, cooldown
+	// and usage-limit checks) but its reply is never sent to chat
+	Silent bool
+}
+
+// Help categories used by the built-in commands
+const (
+	CategoryGeneral    = "General"
+	CategoryQueue      = "Queue"
+	CategoryModeration = "Moderation"
+	CategoryStats      = "Stats"
+	CategoryFun        = "Fun"
+	// CategoryOther is used for commands registered without a category
+	CategoryOther = "Other"
+)
+
+// DefaultHelpCategoryOrder is the order !help lists categories in. Categories
+// not in the order are listed after these, alphabetically.
+var DefaultHelpCategoryOrder = []string{
+	CategoryGeneral,
+	CategoryQueue,
+	CategoryStats,
+	CategoryFun,
+	CategoryModeration,
 }
 
 // CommandManager handles the registration and execution of all chat commands.
@@ -38,6 +78,9 @@ type CommandManager struct {
 	// Map of command names/aliases to their Command objects
 	// Keys are lowercase to ensure case-insensitive matching
 	commands map[string]*Command
+	// Prefix-less keyword triggers (normalized with normalizeTrigger) to
+	// the commands they run
+	triggers map[string]*Command
 	// Character that must prefix all commands (e.g., "!")
 	prefix string
 	// Queue system for managing user entries
@@ -52,22 +95,237 @@ type CommandManager struct {
 	config *config.Config
 	// Time when the bot started
 	startTime time.Time
+	// Channel this manager serves, used to locate its config file
+	channel string
+	// Directory the queue and other channel state are saved in
+	dataPath string
+	// Overrides the config's queue max_size when > 0 (set by WithMaxQueueSize)
+	maxQueueSize int
+	// Cooldown given to commands registered without one
+	defaultCooldown CooldownConfig
+	// Path of the channel's config file, re-read on reset and updated by !timezone
+	configPath string
+	// Registration functions re-run by Reset after the basic commands
+	resetHooks []func(*CommandManager)
+	// Runtime aliases created with !alias (alias -> target command name)
+	aliases map[string]string
+	// Destination for highlighted queue announcements (nil disables them)
+	announcer Announcer
+	// Number of successful queue joins this session
+	joinCount int
+	// Follow age lookup for the min_follow_days join gate (nil disables it)
+	followChecker FollowChecker
+	// Recent follow age lookups keyed by lowercase username
+	followCache map[string]followCacheEntry
+	// Account creation lookup for the min_account_days join gate (nil disables it)
+	accountChecker AccountChecker
+	// Account creation times keyed by lowercase username. They never
+	// change, so entries don't expire.
+	accountCreated map[string]time.Time
+	// Sender for whisper notifications (nil disables them)
+	whisperer Whisperer
+	// Time of the last "Unknown command" reply, for rate limiting
+	lastUnknownReply time.Time
+	// Minimum time between "Unknown command" replies
+	unknownReplyInterval time.Duration
+	// Active chat relay to another channel (nil when not relaying)
+	relay *MessageRelay
+	// Delivers relayed messages to other channels (nil disables relaying)
+	channelSender ChannelSender
+	// Runs !countdown announcements (nil disables the command)
+	countdown *CountdownTimer
+	// Current time source, replaced in tests (nil uses time.Now)
+	now func() time.Time
+	// Pops the queue on a timer for !autoadvance (nil disables the command)
+	autoAdvance *AutoAdvancer
+	// Latest release lookup for !version (nil disables update checks)
+	updateChecker *UpdateChecker
+	// Runs !strawpoll polls (nil disables polls)
+	polls *PollManager
+	// How long each command handler takes to run
+	latency *metrics.CommandLatency
+	// Giveaway entries and draws for !giveaway and !enter
+	giveaways *GiveawayManager
+	// Named !timer countdowns (nil disables the command)
+	chatTimers *TimerManager
+	// Stream topic set with !topic ("" when none is set)
+	streamTopic string
+	// Looks up the current track for !song (nil disables the command)
+	musicProvider music.MusicProvider
+	// Channel points earned by chatting
+	points *PointsManager
+	// Runtime toggles that persist across restarts
+	settings *Settings
+	// Posts the !hype message sequence (nil disables the command)
+	hype *HypeTrain
+	// Round-trip time to the chat server for !ping (nil omits it)
+	latencyReporter LatencyReporter
+	// Set when a !restrict cap counted a use that hasn't been saved yet
+	restrictionUsesDirty atomic.Bool
 }
 
 // NewCommandManager creates a new command manager
 func NewCommandManager(prefix string, dataPath string, channel string) *CommandManager {
+	return NewCommandManagerWithOptions(
+		WithPrefix(prefix),
+		WithDataPath(dataPath),
+		WithChannel(channel),
+	)
+}
+
+// NewCommandManagerWithOptions creates a new command manager configured by
+// opts. Without options it uses DefaultPrefix, the default data path, no
+// channel and DefaultCooldownConfig.
+func NewCommandManagerWithOptions(opts ...Option) *CommandManager {
 	cm := &CommandManager{
-		commands:   make(map[string]*Command),
-		prefix:     prefix,
-		queue:      queue.NewQueue(dataPath, channel),
-		shutdownCh: make(chan struct{}),
-		cooldown:   NewCooldownManager(),
-		startTime:  time.Now(),
+		commands:        make(map[string]*Command),
+		triggers:        make(map[string]*Command),
+		prefix:          DefaultPrefix,
+		defaultCooldown: DefaultCooldownConfig(),
+		shutdownCh:      make(chan struct{}),
+		cooldown:        NewCooldownManager(),
+		startTime:       time.Now(),
+		aliases:         make(map[string]string),
+		followCache:     make(map[string]followCacheEntry),
+		accountCreated:  make(map[string]time.Time),
+		latency:         metrics.NewCommandLatency(),
+		giveaways:       NewGiveawayManager(nil, nil),
+
+		unknownReplyInterval: DefaultUnknownCommandReplyInterval,
+	}
+	for _, opt := range opts {
+		opt(cm)
 	}
+
+	cm.queue = queue.NewQueue(cm.dataPath, cm.channel)
+	cm.configPath = channelConfigPath(cm.channel)
+	cm.config = cm.applyOverrides(loadConfig(cm.configPath, cm.channel, cm.dataPath))
+	if window := cm.config.Commands.Queue.UndoClearWindow; window != nil {
+		cm.queue.SetUndoClearWindow(time.Duration(*window) * time.Second)
+	}
+	cm.settings = NewSettings(cm.settingsFile())
+	if err := cm.settings.Load(); err != nil {
+		log.Printf("Error loading channel settings: %v", err)
+	}
+	cm.points = NewPointsManager(filepath.Join(cm.queue.GetDataPath(), fmt.Sprintf("points_%s.json", cm.channel)))
+	if interleave := cm.config.Commands.Queue.VIPInterleave; interleave > 0 {
+		cm.queue.SetVIPInterleave(interleave)
+	}
+	if cooldown := cm.config.Commands.Queue.RejoinCooldown; cooldown > 0 {
+		cm.queue.SetRejoinCooldown(time.Duration(cooldown) * time.Second)
+	}
+	cm.queue.SetBlacklistBlocksMods(cm.config.Commands.Queue.BlacklistBlocksMods)
+	cm.queue.SetMaxSize(cm.config.Commands.Queue.MaxSize)
+	cm.queue.SetWaitlistEnabled(cm.config.Commands.Queue.Waitlist)
+	cm.queue.SetPromotionHandler(cm.announcePromotions)
 	SetCommandManager(cm)
 	return cm
 }
 
+// applyOverrides applies settings given as options on top of a loaded config
+func (cm *CommandManager) applyOverrides(cfg *config.Config) *config.Config {
+	if cm.maxQueueSize > 0 {
+		cfg.Commands.Queue.MaxSize = cm.maxQueueSize
+	}
+	return cfg
+}
+
+// channelConfigPath returns the default config file location for a channel
+func channelConfigPath(channel string) string {
+	return fmt.Sprintf("configs/channels/%s_config_secrets.yaml", channel)
+}
+
+// loadConfig loads the channel's config file, falling back to defaults if it can't be read
+func loadConfig(path string, channel string, dataPath string) *config.Config {
+	cfg, err := config.Load(path)
+	if err != nil {
+		log.Printf("Error loading config: %v", err)
+		return &config.Config{
+			Channel:  channel,
+			DataPath: dataPath,
+		}
+	}
+	return cfg
+}
+
+// GetPrefix returns the character(s) that must prefix every command
+func (cm *CommandManager) GetPrefix() string {
+	return cm.prefix
+}
+
+// GetChannel returns the channel this manager serves
+func (cm *CommandManager) GetChannel() string {
+	return cm.channel
+}
+
+// GetConfigPath returns the path of the channel's config file
+func (cm *CommandManager) GetConfigPath() string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.configPath
+}
+
+// SetConfigPath changes where the channel's config file is read from and saved to
+func (cm *CommandManager) SetConfigPath(path string) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.configPath = path
+}
+
+// OnReset registers a function that re-registers commands after a reset.
+// Commands registered outside RegisterBasicCommands (e.g. uptime, auth)
+// should be re-registered through a hook so they survive !resetbot.
+func (cm *CommandManager) OnReset(hook func(*CommandManager)) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.resetHooks = append(cm.resetHooks, hook)
+}
+
+// Reset performs a soft reset of the manager's in-memory state.
+// It clears the command registry and cooldowns, re-registers the basic
+// commands and any reset hooks, re-reads the channel config and reloads
+// the queue from disk, keeping it enabled or disabled as before. The IRC connection is left untouched.
+// Returns the number of commands registered after the reset.
+func (cm *CommandManager) Reset() int {
+	cm.mu.Lock()
+	cm.commands = make(map[string]*Command)
+	cm.triggers = make(map[string]*Command)
+	hooks := make([]func(*CommandManager), len(cm.resetHooks))
+	copy(hooks, cm.resetHooks)
+	cm.mu.Unlock()
+	cm.cooldown.Reset()
+
+	RegisterBasicCommands(cm)
+	for _, hook := range hooks {
+		hook(cm)
+	}
+	if err := cm.LoadAliases(); err != nil {
+		log.Printf("Error reloading aliases during reset: %v", err)
+	}
+
+	// Re-read the channel config; keep the previous one if it can't be loaded
+	cfg, err := config.Load(cm.GetConfigPath())
+	if err != nil {
+		log.Printf("Error reloading config during reset: %v", err)
+	} else {
+		cm.SetConfig(cm.applyOverrides(cfg))
+		cm.queue.SetMaxSize(cfg.Commands.Queue.MaxSize)
+		cm.queue.SetWaitlistEnabled(cfg.Commands.Queue.Waitlist)
+	}
+
+	if err := cm.settings.Load(); err != nil {
+		log.Printf("Error reloading channel settings during reset: %v", err)
+	}
+
+	// Reload the queue from its auto-save file. Whether it's enabled or
+	// paused is left as it was, so a reset doesn't re-open an ended queue.
+	if err := cm.queue.LoadState(); err != nil {
+		log.Printf("Error reloading queue state during reset: %v", err)
+	}
+
+	return len(cm.GetCommandList())
+}
+
 // RequestShutdown signals that the bot should shut down.
 // This is typically called by the kill command.
 func (cm *CommandManager) RequestShutdown() {
@@ -95,9 +353,20 @@ func (cm *CommandManager) RegisterCommand(cmd *Command) {
 		cm.commands[strings.ToLower(alias)] = cmd
 	}
 
+	// Register prefix-less keyword triggers, from the command and the config
+	triggers := cmd.Triggers
+	if cm.config != nil {
+		triggers = append(triggers[:len(triggers):len(triggers)], cm.config.Commands.Triggers[cmd.Name]...)
+	}
+	for _, trigger := range triggers {
+		if key := normalizeTrigger(trigger); key != "" {
+			cm.triggers[key] = cmd
+		}
+	}
+
 	// Set default cooldown if not specified
 	if cmd.Cooldown == (CooldownConfig{}) {
-		cmd.Cooldown = DefaultCooldownConfig()
+		cmd.Cooldown = cm.defaultCooldown
 	}
 	cm.cooldown.SetCooldown(cmd.Name, cmd.Cooldown)
 }
@@ -110,13 +379,46 @@ func isPrivileged(message twitchirc.PrivateMessage) bool {
 		message.User.Badges["vip"] > 0
 }
 
+// isModerator checks if a user is a moderator or the broadcaster.
+func isModerator(message twitchirc.PrivateMessage) bool {
+	return message.User.Badges["moderator"] > 0 || message.User.Badges["broadcaster"] > 0
+}
+
+// isBroadcaster checks if a user is the channel's broadcaster.
+func isBroadcaster(message twitchirc.PrivateMessage) bool {
+	return message.User.Badges["broadcaster"] > 0
+}
+
 // HandleMessage processes incoming chat messages and executes commands if present.
 // Returns a tuple containing:
 // - response: The message to send back to chat (empty if no response needed)
 // - isCommand: True if the message was a command attempt (even if invalid)
 func (cm *CommandManager) HandleMessage(message twitchirc.PrivateMessage) (response string, isCommand bool) {
+	return cm.HandleMessageContext(context.Background(), message)
+}
+
+// HandleMessageContext is HandleMessage with a context carrying the
+// message's trace ID (see package trace). A new trace ID is generated if ctx
+// has none. Debug log entries for the message are tagged with it.
+func (cm *CommandManager) HandleMessageContext(ctx context.Context, message twitchirc.PrivateMessage) (response string, isCommand bool) {
+	ctx = trace.Ensure(ctx)
+
+	// Keyword triggers run only when the whole message matches, and only for
+	// users allowed to run the command right now; otherwise the message is
+	// treated as normal chat
+	cm.mu.RLock()
+	trigger, triggered := cm.triggers[normalizeTrigger(message.Message)]
+	cm.mu.RUnlock()
+	if triggered && permissionDenied(trigger, message) == "" && cm.cooldown.CheckCooldown(trigger.Name, message) == 0 &&
+		cm.checkRestriction(trigger, message) == "" {
+		trace.Logger(ctx).Debug("handling keyword trigger", "user", message.User.Name, "command", trigger.Name)
+		return cm.runHandler(ctx, trigger, message, nil), true
+	}
+
 	// Check if the message starts with the command prefix
 	if !strings.HasPrefix(message.Message, cm.prefix) {
+		cm.points.Earn(message.User.Name)
+		cm.relayMessage(message)
 		return "", false
 	}
 
@@ -135,21 +437,20 @@ func (cm *CommandManager) HandleMessage(message twitchirc.PrivateMessage) (respo
 
 	if !exists {
 		// Message started with prefix but command wasn't found
-		return "", true
+		trace.Logger(ctx).Debug("unknown command", "user", message.User.Name, "command", parts[0])
+		return cm.unknownCommandReply(parts[0]), true
 	}
 
-	// Check if this is a mod-only command
-	if command.ModOnly && message.User.Badges["moderator"] == 0 && message.User.Badges["broadcaster"] == 0 {
-		return "This command can only be used by moderators.", true
-	}
-
-	// Check if this is a privileged command
-	if command.IsPrivileged && !isPrivileged(message) {
-		return "This command can only be used by moderators and VIPs.", true
+	logger := trace.Logger(ctx).With("user", message.User.Name, "command", command.Name)
+	logger.Debug("handling command", "args", parts[1:])
+	if reply := permissionDenied(command, message); reply != "" {
+		logger.Debug("permission denied")
+		return reply, true
 	}
 
 	// Check cooldown
 	if remaining := cm.cooldown.CheckCooldown(command.Name, message); remaining > 0 {
+		logger.Debug("command on cooldown", "remaining", remaining)
 		// Only show cooldown message if we haven't shown it for this cooldown period
 		if cm.cooldown.ShouldShowCooldownMessage(command.Name, message) {
 			// Update the last message time
@@ -161,8 +462,54 @@ func (cm *CommandManager) HandleMessage(message twitchirc.PrivateMessage) (respo
 		return "", true
 	}
 
-	// Execute the command's handler and return its response
-	return command.Handler(message, parts[1:]), true
+	if reply := cm.checkRestriction(command, message); reply != "" {
+		logger.Debug("usage limit reached")
+		return reply, true
+	}
+
+	return cm.runHandler(ctx, command, message, parts[1:]), true
+}
+
+// permissionDenied returns the reply for a user who may not run command,
+// or "" if they may
+func permissionDenied(command *Command, message twitchirc.PrivateMessage) string {
+	// Check if this is a mod-only command
+	if command.ModOnly && !isModerator(message) {
+		return "This command can only be used by moderators."
+	}
+
+	// Check if this is a privileged command
+	if command.IsPrivileged && !isPrivileged(message) {
+		return "This command can only be used by moderators and VIPs."
+	}
+	return ""
+}
+
+// runHandler executes the command's handler, timing it for the latency metrics,
+// and starts the user's cooldown for it. The reply of a Silent command is dropped.
+func (cm *CommandManager) runHandler(ctx context.Context, command *Command, message twitchirc.PrivateMessage, args []string) string {
+	start := time.Now()
+	response := command.Handler(message, args)
+	elapsed := time.Since(start)
+	cm.latency.Observe(command.Name, elapsed)
+	cm.cooldown.UpdateLastUsage(command.Name, message)
+	if command.Silent {
+		response = ""
+	}
+	trace.Logger(ctx).Debug("command handled", "user", message.User.Name, "command", command.Name,
+		"duration", elapsed, "responded", response != "", "silent", command.Silent)
+	return response
+}
+
+// normalizeTrigger returns the lookup key for a keyword trigger or a chat
+// message compared against one
+func normalizeTrigger(text string) string {
+	return strings.ToLower(strings.TrimSpace(text))
+}
+
+// GetCommandLatency returns the per-command handler latency tracker
+func (cm *CommandManager) GetCommandLatency() *metrics.CommandLatency {
+	return cm.latency
 }
 
 // GetCommandList returns a deduplicated list of all registered commands.
@@ -177,20 +524,78 @@ func (cm *CommandManager) GetCommandList() []Command {
 		uniqueCommands[cmd.Name] = *cmd
 	}
 
-	// Convert map to slice for return
+	// Convert map to slice for return, sorted by category then name so
+	// callers such as !help list commands in the same order every time
 	commands := make([]Command, 0, len(uniqueCommands))
 	for _, cmd := range uniqueCommands {
 		commands = append(commands, cmd)
 	}
+	sort.Slice(commands, func(i, j int) bool {
+		if commands[i].Category != commands[j].Category {
+			return commands[i].Category < commands[j].Category
+		}
+		return commands[i].Name < commands[j].Name
+	})
 	return commands
 }
 
+// CommandCount returns the number of registered commands and the number of
+// names they answer to, including aliases
+func (cm *CommandManager) CommandCount() (unique, withAliases int) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	names := make(map[string]bool)
+	for _, cmd := range cm.commands {
+		names[cmd.Name] = true
+	}
+	return len(names), len(cm.commands)
+}
+
 // GetQueue returns the queue manager instance.
 // This allows commands to interact with the queue system.
 func (cm *CommandManager) GetQueue() *queue.Queue {
 	return cm.queue
 }
 
+// GetConfig returns the channel configuration
+func (cm *CommandManager) GetConfig() *config.Config {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.config
+}
+
+// SetConfig replaces the channel configuration
+func (cm *CommandManager) SetConfig(cfg *config.Config) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.config = cfg
+}
+
+// GetCooldownManager returns the cooldown manager instance
+func (cm *CommandManager) GetCooldownManager() *CooldownManager {
+	return cm.cooldown
+}
+
+// SetClock replaces the time source used for date-based command behavior.
+// A nil clock restores time.Now.
+func (cm *CommandManager) SetClock(now func() time.Time) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.now = now
+}
+
+// currentTime returns the time from the manager's clock
+func (cm *CommandManager) currentTime() time.Time {
+	cm.mu.RLock()
+	now := cm.now
+	cm.mu.RUnlock()
+	if now == nil {
+		return time.Now()
+	}
+	return now()
+}
+
 // GetBotStartTime returns the time when the bot started
 func (cm *CommandManager) GetBotStartTime() time.Time {
 	return cm.startTime
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	twitchirc "github.com/gempir/go-twitch-irc/v4"
+	twitchbot "github.com/pbuckles22/PBChatBot/internal/twitch"
+)
+
+// ConnStatusReporter reports the bot's connection state for each channel it
+// has joined. *twitch.Bot implements it.
+type ConnStatusReporter interface {
+	ConnStatus() []twitchbot.ChannelStatus
+}
+
+// RegisterConnStatusCommand registers the connstatus command
+func RegisterConnStatusCommand(cm *CommandManager, reporter ConnStatusReporter) {
+	cm.RegisterCommand(&Command{
+		Name:        "connstatus",
+		Category:    CategoryModeration,
+		Description: "Show the bot's connection state for each joined channel (broadcaster only)",
+		Handler: func(message twitchirc.PrivateMessage, args []string) string {
+			if !isBroadcaster(message) {
+				return "This command can only be used by the broadcaster."
+			}
+			return FormatConnStatus(reporter.ConnStatus(), time.Now())
+		},
+	})
+}
+
+// FormatConnStatus formats channel states as
+// "[ch1: ✓ 5s ago] [ch2: ✗ reconnecting (attempt 3)]", where the time is
+// since the last chat message seen in the channel
+func FormatConnStatus(statuses []twitchbot.ChannelStatus, now time.Time) string {
+	if len(statuses) == 0 {
+		return "Not connected to any channels."
+	}
+
+	parts := make([]string, len(statuses))
+	for i, status := range statuses {
+		var state string
+		switch {
+		case status.Connected && status.LastMessage.IsZero():
+			state = "✓ no messages yet"
+		case status.Connected:
+			state = fmt.Sprintf("✓ %s ago", formatAgo(now.Sub(status.LastMessage)))
+		case status.Reconnecting():
+			state = fmt.Sprintf("✗ reconnecting (attempt %d)", status.ReconnectAttempts)
+		default:
+			state = "✗ disconnected"
+		}
+		parts[i] = fmt.Sprintf("[%s: %s]", status.Channel, state)
+	}
+	return strings.Join(parts, " ")
+}
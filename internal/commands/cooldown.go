@@ -2,6 +2,7 @@ package commands
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -45,15 +46,19 @@ type CooldownManager struct {
 	lastUsage map[string]map[string]time.Time
 	// Map of command names to user last cooldown message times
 	lastMessage map[string]map[string]time.Time
-	mu          sync.RWMutex
+	// Per-user usage caps set with !restrict, keyed by lowercase username
+	// and then command name. Reset leaves them in place.
+	restrictions map[string]map[string]*UsageLimit
+	mu           sync.RWMutex
 }
 
 // NewCooldownManager creates a new cooldown manager
 func NewCooldownManager() *CooldownManager {
 	return &CooldownManager{
-		configs:     make(map[string]CooldownConfig),
-		lastUsage:   make(map[string]map[string]time.Time),
-		lastMessage: make(map[string]map[string]time.Time),
+		configs:      make(map[string]CooldownConfig),
+		lastUsage:    make(map[string]map[string]time.Time),
+		lastMessage:  make(map[string]map[string]time.Time),
+		restrictions: make(map[string]map[string]*UsageLimit),
 	}
 }
 
@@ -71,6 +76,37 @@ func (cm *CooldownManager) SetCooldown(commandName string, config CooldownConfig
 	}
 }
 
+// Reset clears all cooldown configurations and usage history
+func (cm *CooldownManager) Reset() {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cm.configs = make(map[string]CooldownConfig)
+	cm.lastUsage = make(map[string]map[string]time.Time)
+	cm.lastMessage = make(map[string]map[string]time.Time)
+}
+
+// ResetCommand clears a user's last usage of a command so their next use
+// isn't throttled. It returns false if the user had no usage recorded.
+func (cm *CooldownManager) ResetCommand(commandName, username string) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cleared := false
+	for user := range cm.lastUsage[commandName] {
+		if strings.EqualFold(user, username) {
+			delete(cm.lastUsage[commandName], user)
+			cleared = true
+		}
+	}
+	for user := range cm.lastMessage[commandName] {
+		if strings.EqualFold(user, username) {
+			delete(cm.lastMessage[commandName], user)
+		}
+	}
+	return cleared
+}
+
 // GetUserType determines the user type based on their badges
 func GetUserType(message twitch.PrivateMessage) UserType {
 	if message.User.Badges["broadcaster"] > 0 {
@@ -202,3 +238,27 @@ func FormatCooldown(d time.Duration) string {
 	}
 	return fmt.Sprintf("%.1fm", d.Minutes())
 }
+
+// HandleResetCooldown handles the !resetcooldown command
+func HandleResetCooldown(message twitch.PrivateMessage, args []string) string {
+	if len(args) < 1 {
+		return "Usage: !resetcooldown <command> [user]"
+	}
+
+	cm := GetCommandManager()
+	name := strings.TrimPrefix(args[0], cm.prefix)
+	cmd, exists := cm.lookupCommand(name)
+	if !exists {
+		return fmt.Sprintf("Unknown command: %s%s", cm.prefix, name)
+	}
+
+	username := message.User.Name
+	if len(args) > 1 {
+		username = strings.TrimPrefix(args[1], "@")
+	}
+
+	if !cm.cooldown.ResetCommand(cmd.Name, username) {
+		return fmt.Sprintf("%s has no %s%s cooldown to reset.", username, cm.prefix, cmd.Name)
+	}
+	return fmt.Sprintf("Reset the %s%s cooldown for %s.", cm.prefix, cmd.Name, username)
+}
@@ -0,0 +1,204 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+)
+
+// countdownCheckpoints are the remaining times announced during a countdown
+var countdownCheckpoints = []time.Duration{
+	5 * time.Minute,
+	3 * time.Minute,
+	1 * time.Minute,
+	30 * time.Second,
+	10 * time.Second,
+	0,
+}
+
+// countdownMaxDuration is the longest countdown that can be started
+const countdownMaxDuration = time.Hour
+
+// ErrCountdownRunning is returned when starting a countdown while one is active
+var ErrCountdownRunning = errors.New("a countdown is already running")
+
+// CountdownClock schedules countdown announcements. It can be replaced in tests.
+type CountdownClock interface {
+	AfterFunc(d time.Duration, f func()) CountdownStopper
+}
+
+// CountdownStopper cancels a scheduled announcement
+type CountdownStopper interface {
+	Stop() bool
+}
+
+// realCountdownClock schedules announcements with time.Timer
+type realCountdownClock struct{}
+
+// AfterFunc runs f after d using a time.Timer
+func (realCountdownClock) AfterFunc(d time.Duration, f func()) CountdownStopper {
+	return time.AfterFunc(d, f)
+}
+
+// CountdownTimer posts countdown announcements to chat. Only one countdown
+// can run at a time.
+type CountdownTimer struct {
+	mu     sync.Mutex
+	clock  CountdownClock
+	send   func(string)
+	timers []CountdownStopper
+	active bool
+	// Incremented on every start and cancel so stale callbacks are ignored
+	generation int
+}
+
+// NewCountdownTimer creates a countdown timer that posts messages using send.
+// If clock is nil, announcements are scheduled with time.Timer.
+func NewCountdownTimer(clock CountdownClock, send func(string)) *CountdownTimer {
+	if clock == nil {
+		clock = realCountdownClock{}
+	}
+	return &CountdownTimer{
+		clock: clock,
+		send:  send,
+	}
+}
+
+// Start begins a countdown of total, posting at each checkpoint below total
+// and finally posting message (or "Time's up!" if message is empty)
+func (c *CountdownTimer) Start(total time.Duration, message string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.active {
+		return ErrCountdownRunning
+	}
+	c.active = true
+	c.generation++
+	generation := c.generation
+
+	if message == "" {
+		message = "Time's up!"
+	}
+
+	c.timers = nil
+	for _, checkpoint := range countdownCheckpoints {
+		if checkpoint >= total && checkpoint != 0 {
+			continue
+		}
+		text := fmt.Sprintf("%s remaining!", formatCountdown(checkpoint))
+		if checkpoint == 0 {
+			text = message
+		}
+		final := checkpoint == 0
+		c.timers = append(c.timers, c.clock.AfterFunc(total-checkpoint, func() {
+			c.fire(generation, text, final)
+		}))
+	}
+	return nil
+}
+
+// fire posts a scheduled announcement if its countdown is still running
+func (c *CountdownTimer) fire(generation int, text string, final bool) {
+	c.mu.Lock()
+	if !c.active || c.generation != generation {
+		c.mu.Unlock()
+		return
+	}
+	if final {
+		c.active = false
+		c.timers = nil
+	}
+	c.mu.Unlock()
+
+	c.send(text)
+}
+
+// Cancel stops the active countdown. It returns false if none was running.
+func (c *CountdownTimer) Cancel() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.active {
+		return false
+	}
+	for _, t := range c.timers {
+		t.Stop()
+	}
+	c.timers = nil
+	c.active = false
+	c.generation++
+	return true
+}
+
+// IsActive reports whether a countdown is running
+func (c *CountdownTimer) IsActive() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.active
+}
+
+// formatCountdown formats a remaining time as e.g. "5m", "30s" or "1m30s"
+func formatCountdown(d time.Duration) string {
+	d = d.Round(time.Second)
+	minutes := int(d / time.Minute)
+	seconds := int((d % time.Minute) / time.Second)
+	switch {
+	case minutes > 0 && seconds > 0:
+		return fmt.Sprintf("%dm%ds", minutes, seconds)
+	case minutes > 0:
+		return fmt.Sprintf("%dm", minutes)
+	default:
+		return fmt.Sprintf("%ds", seconds)
+	}
+}
+
+// SetCountdownTimer sets the timer used by !countdown
+func (cm *CommandManager) SetCountdownTimer(countdown *CountdownTimer) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.countdown = countdown
+}
+
+// HandleCountdown handles the !countdown command
+func HandleCountdown(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	cm.mu.RLock()
+	countdown := cm.countdown
+	cm.mu.RUnlock()
+
+	if countdown == nil {
+		return "Countdowns are not available."
+	}
+	if len(args) < 1 {
+		return "Usage: !countdown <duration> [message] or !countdown cancel"
+	}
+
+	if strings.EqualFold(args[0], "cancel") {
+		if !countdown.Cancel() {
+			return "No countdown is running."
+		}
+		return "Countdown cancelled."
+	}
+
+	total, err := time.ParseDuration(args[0])
+	if err != nil || total <= 0 {
+		return fmt.Sprintf("Invalid duration: %s (use e.g. 90s or 5m)", args[0])
+	}
+	if total > countdownMaxDuration {
+		return fmt.Sprintf("Countdowns can be at most %s.", formatCountdown(countdownMaxDuration))
+	}
+
+	text := strings.Join(args[1:], " ")
+	if err := countdown.Start(total, text); err != nil {
+		return "A countdown is already running. Use !countdown cancel first."
+	}
+	if text == "" {
+		return fmt.Sprintf("Countdown started: %s!", formatCountdown(total))
+	}
+	return fmt.Sprintf("Countdown started: %s until %s", formatCountdown(total), text)
+}
@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"time"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+)
+
+// MetadataAliasAddedAt is the Command.Metadata key holding the time.Time a
+// deprecated alias was added
+const MetadataAliasAddedAt = "aliasAddedAt"
+
+// deprecationNoticePeriod is how long a deprecated alias points users at
+// its replacement
+const deprecationNoticePeriod = 30 * 24 * time.Hour
+
+// unqueueAddedAt is when !unqueue was added as an alias for !leave
+var unqueueAddedAt = time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+
+// withDeprecationNotice prepends a note pointing at replacement to response
+// while the named command is within its deprecation notice period
+func (cm *CommandManager) withDeprecationNotice(name, replacement, response string) string {
+	cmd, exists := cm.lookupCommand(name)
+	if !exists {
+		return response
+	}
+	addedAt, ok := cmd.Metadata[MetadataAliasAddedAt].(time.Time)
+	if !ok || cm.currentTime().Sub(addedAt) >= deprecationNoticePeriod {
+		return response
+	}
+	return "(Note: use " + cm.prefix + replacement + " going forward) " + response
+}
+
+// HandleUnqueue handles the !unqueue command, an alias for !leave that
+// points users at !leave for its first 30 days
+func HandleUnqueue(message twitch.PrivateMessage, args []string) string {
+	return GetCommandManager().withDeprecationNotice("unqueue", "leave", HandleLeave(message, args))
+}
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+)
+
+// echoMaxLength keeps echoed text under Twitch's chat message limit
+const echoMaxLength = 450
+
+// HandleEcho handles the !echo command, sending the given text to chat verbatim
+func HandleEcho(message twitch.PrivateMessage, args []string) string {
+	if !isBroadcaster(message) {
+		return "This command can only be used by the broadcaster."
+	}
+
+	text := echoText(message.Message)
+	if text == "" {
+		return "Usage: !echo <text>"
+	}
+	if len(text) > echoMaxLength {
+		return fmt.Sprintf("Echo text is too long (%d/%d characters).", len(text), echoMaxLength)
+	}
+
+	// Don't let echoed text look like the bot replying to someone else
+	if strings.HasPrefix(text, "@") {
+		mentioned := strings.TrimLeftFunc(text[1:], unicode.IsSpace)
+		if end := strings.IndexFunc(mentioned, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+		}); end != -1 {
+			mentioned = mentioned[:end]
+		}
+
+		botName := GetCommandManager().GetConfig().BotName
+		if botName == "" || !strings.EqualFold(mentioned, botName) {
+			return "Echo text can't start with @ followed by a username other than the bot."
+		}
+	}
+
+	return text
+}
+
+// echoText returns everything after the command name, preserving spacing
+func echoText(raw string) string {
+	raw = strings.TrimSpace(raw)
+	end := strings.IndexFunc(raw, unicode.IsSpace)
+	if end == -1 {
+		return ""
+	}
+	return strings.TrimSpace(raw[end:])
+}
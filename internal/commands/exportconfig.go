@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gempir/go-twitch-irc/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// ExportConfig writes the current in-memory config, with secrets redacted,
+// to a timestamped YAML file in the data path. Returns the file name.
+func (cm *CommandManager) ExportConfig() (string, error) {
+	cfg := cm.GetConfig()
+	if cfg == nil {
+		return "", fmt.Errorf("no config loaded")
+	}
+
+	data, err := yaml.Marshal(cfg.Redacted())
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	// Ensure the data directory exists
+	dataPath := cm.queue.GetDataPath()
+	if err := os.MkdirAll(dataPath, 0755); err != nil {
+		return "", fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	filename := fmt.Sprintf("config_export_%s_%s.yaml", cm.channel, time.Now().Format("20060102_150405"))
+	if err := os.WriteFile(filepath.Join(dataPath, filename), data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write config export: %w", err)
+	}
+
+	return filename, nil
+}
+
+// HandleExportConfig handles the !exportconfig command
+func HandleExportConfig(message twitch.PrivateMessage, args []string) string {
+	if !isBroadcaster(message) {
+		return "This command can only be used by the broadcaster."
+	}
+
+	filename, err := GetCommandManager().ExportConfig()
+	if err != nil {
+		return fmt.Sprintf("Error exporting config: %v", err)
+	}
+	return fmt.Sprintf("Config exported to %s.", filename)
+}
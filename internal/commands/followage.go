@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	twitchirc "github.com/gempir/go-twitch-irc/v4"
+)
+
+// followCacheTTL is how long a follow age lookup is reused before asking Twitch again
+const followCacheTTL = 5 * time.Minute
+
+// FollowChecker looks up how long a user has followed the channel
+type FollowChecker interface {
+	// FollowAge returns the follow duration; the bool is false if the user doesn't follow
+	FollowAge(username string) (time.Duration, bool, error)
+}
+
+// followCacheEntry is a cached follow age lookup
+type followCacheEntry struct {
+	age       time.Duration
+	following bool
+	fetchedAt time.Time
+}
+
+// SetFollowChecker sets the follow age lookup used by the min_follow_days join gate
+func (cm *CommandManager) SetFollowChecker(checker FollowChecker) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.followChecker = checker
+	cm.followCache = make(map[string]followCacheEntry)
+}
+
+// checkFollowAge returns a rejection message if the user hasn't followed for
+// queue.min_follow_days, or "" if they may join. Mods bypass the check, and
+// lookup failures let the user through rather than blocking the queue.
+func (cm *CommandManager) checkFollowAge(message twitchirc.PrivateMessage) string {
+	cm.mu.RLock()
+	checker := cm.followChecker
+	minDays := 0
+	if cm.config != nil {
+		minDays = cm.config.Commands.Queue.MinFollowDays
+	}
+	cm.mu.RUnlock()
+
+	if checker == nil || minDays <= 0 || isModerator(message) {
+		return ""
+	}
+
+	username := message.User.Name
+	age, following, err := cm.followAge(checker, username)
+	if err != nil {
+		log.Printf("Error checking follow age for %s: %v", username, err)
+		return ""
+	}
+
+	required := time.Duration(minDays) * 24 * time.Hour
+	if !following {
+		return fmt.Sprintf("%s, you must follow the channel for at least %d days to join the queue.", username, minDays)
+	}
+	if age < required {
+		return fmt.Sprintf("%s, you must follow for at least %d days to join the queue (following for %d days).",
+			username, minDays, int(age.Hours()/24))
+	}
+	return ""
+}
+
+// followAge returns the user's follow age, using a cached lookup when it's recent
+func (cm *CommandManager) followAge(checker FollowChecker, username string) (time.Duration, bool, error) {
+	key := strings.ToLower(username)
+
+	cm.mu.RLock()
+	entry, ok := cm.followCache[key]
+	cm.mu.RUnlock()
+	if ok && time.Since(entry.fetchedAt) < followCacheTTL {
+		return entry.age + time.Since(entry.fetchedAt), entry.following, nil
+	}
+
+	age, following, err := checker.FollowAge(username)
+	if err != nil {
+		return 0, false, err
+	}
+
+	cm.mu.Lock()
+	cm.followCache[key] = followCacheEntry{age: age, following: following, fetchedAt: time.Now()}
+	cm.mu.Unlock()
+	return age, following, nil
+}
@@ -0,0 +1,263 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+	"github.com/pbuckles22/PBChatBot/internal/timers"
+)
+
+// DefaultGiveawayWindow is how long a giveaway accepts entries
+const DefaultGiveawayWindow = 5 * time.Minute
+
+var (
+	// ErrGiveawayRunning is returned when starting a giveaway while one is open
+	ErrGiveawayRunning = errors.New("a giveaway is already running")
+	// ErrNoGiveaway is returned when there is no giveaway to enter or pick from
+	ErrNoGiveaway = errors.New("no giveaway is running")
+	// ErrGiveawayClosed is returned when entering after the window has closed
+	ErrGiveawayClosed = errors.New("the giveaway is closed to new entries")
+	// ErrGiveawaySubOnly is returned when a non-subscriber enters a sub-only giveaway
+	ErrGiveawaySubOnly = errors.New("the giveaway is for subscribers only")
+	// ErrAlreadyEntered is returned when a user enters twice
+	ErrAlreadyEntered = errors.New("already entered")
+	// ErrNoEntries is returned when there is nobody left to pick
+	ErrNoEntries = errors.New("no eligible entries")
+)
+
+// GiveawayEntry is one user's giveaway entry
+type GiveawayEntry struct {
+	Username  string
+	EnteredAt time.Time
+}
+
+// systemClock is the timers.Clock backed by the system time
+type systemClock struct{}
+
+// Now returns the current system time
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// GiveawayManager runs giveaways: an entry window followed by random draws
+type GiveawayManager struct {
+	mu     sync.Mutex
+	clock  timers.Clock
+	rng    *rand.Rand
+	window time.Duration
+
+	active   bool
+	open     bool
+	subOnly  bool
+	deadline time.Time
+	// Entries in the order they were made, plus a lowercase index for duplicates
+	entries []GiveawayEntry
+	entered map[string]bool
+	// Users already drawn, skipped by re-picks
+	winners map[string]bool
+}
+
+// NewGiveawayManager creates a giveaway manager. If clock is nil the system
+// clock is used; if rng is nil a time-seeded source is used.
+func NewGiveawayManager(clock timers.Clock, rng *rand.Rand) *GiveawayManager {
+	if clock == nil {
+		clock = systemClock{}
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return &GiveawayManager{
+		clock:  clock,
+		rng:    rng,
+		window: DefaultGiveawayWindow,
+	}
+}
+
+// SetWindow sets how long new giveaways accept entries
+func (g *GiveawayManager) SetWindow(window time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.window = window
+}
+
+// Window returns how long new giveaways accept entries
+func (g *GiveawayManager) Window() time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.window
+}
+
+// Start opens a new giveaway, replacing any finished one
+func (g *GiveawayManager) Start(subOnly bool) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.open {
+		return ErrGiveawayRunning
+	}
+	g.active = true
+	g.open = true
+	g.subOnly = subOnly
+	g.deadline = g.clock.Now().Add(g.window)
+	g.entries = nil
+	g.entered = make(map[string]bool)
+	g.winners = make(map[string]bool)
+	return nil
+}
+
+// Enter adds username to the open giveaway
+func (g *GiveawayManager) Enter(username string, isSubscriber bool) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.active {
+		return ErrNoGiveaway
+	}
+	now := g.clock.Now()
+	if !g.open || now.After(g.deadline) {
+		return ErrGiveawayClosed
+	}
+	if g.subOnly && !isSubscriber {
+		return ErrGiveawaySubOnly
+	}
+	key := strings.ToLower(username)
+	if g.entered[key] {
+		return ErrAlreadyEntered
+	}
+
+	g.entered[key] = true
+	g.entries = append(g.entries, GiveawayEntry{Username: username, EnteredAt: now})
+	return nil
+}
+
+// End closes the entry window and draws a winner. It returns the number of
+// entries along with the winner.
+func (g *GiveawayManager) End() (string, int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.active {
+		return "", 0, ErrNoGiveaway
+	}
+	g.open = false
+	winner, err := g.pick()
+	return winner, len(g.entries), err
+}
+
+// Pick draws another winner from the closed giveaway, skipping anyone
+// already drawn (e.g. a winner who didn't respond)
+func (g *GiveawayManager) Pick() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.active {
+		return "", ErrNoGiveaway
+	}
+	g.open = false
+	return g.pick()
+}
+
+// Entries returns a copy of the current entries in the order they were made
+func (g *GiveawayManager) Entries() []GiveawayEntry {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	entries := make([]GiveawayEntry, len(g.entries))
+	copy(entries, g.entries)
+	return entries
+}
+
+// pick draws a random entry that hasn't won yet. Caller must hold the lock.
+func (g *GiveawayManager) pick() (string, error) {
+	var eligible []string
+	for _, entry := range g.entries {
+		if !g.winners[strings.ToLower(entry.Username)] {
+			eligible = append(eligible, entry.Username)
+		}
+	}
+	if len(eligible) == 0 {
+		return "", ErrNoEntries
+	}
+
+	winner := eligible[g.rng.Intn(len(eligible))]
+	g.winners[strings.ToLower(winner)] = true
+	return winner, nil
+}
+
+// isSubscriber checks if a user is subscribed to the channel
+func isSubscriber(message twitch.PrivateMessage) bool {
+	return message.User.Badges["subscriber"] > 0 || message.User.Badges["founder"] > 0
+}
+
+// GetGiveaways returns the channel's giveaway manager
+func (cm *CommandManager) GetGiveaways() *GiveawayManager {
+	return cm.giveaways
+}
+
+// HandleGiveaway handles the !giveaway command
+func HandleGiveaway(message twitch.PrivateMessage, args []string) string {
+	giveaways := GetCommandManager().GetGiveaways()
+	usage := "Usage: !giveaway start [sub-only], !giveaway end or !giveaway pick"
+	if len(args) < 1 {
+		return usage
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "start":
+		subOnly := len(args) > 1 && strings.EqualFold(args[1], "sub-only")
+		if len(args) > 1 && !subOnly {
+			return usage
+		}
+		if err := giveaways.Start(subOnly); err != nil {
+			return "A giveaway is already running. Use !giveaway end first."
+		}
+		window := formatCountdown(giveaways.Window())
+		if subOnly {
+			return fmt.Sprintf("Sub-only giveaway started! Subscribers, type !enter in the next %s to enter.", window)
+		}
+		return fmt.Sprintf("Giveaway started! Type !enter in the next %s to enter.", window)
+
+	case "end":
+		winner, entries, err := giveaways.End()
+		switch {
+		case errors.Is(err, ErrNoGiveaway):
+			return "There is no giveaway running."
+		case errors.Is(err, ErrNoEntries):
+			return "Giveaway closed with no entries."
+		}
+		return fmt.Sprintf("Giveaway closed (%d entered). The winner is @%s!", entries, winner)
+
+	case "pick":
+		winner, err := giveaways.Pick()
+		switch {
+		case errors.Is(err, ErrNoGiveaway):
+			return "There is no giveaway to pick from."
+		case errors.Is(err, ErrNoEntries):
+			return "No eligible entries left to pick from."
+		}
+		return fmt.Sprintf("New winner: @%s!", winner)
+	}
+	return usage
+}
+
+// HandleEnter handles the !enter command
+func HandleEnter(message twitch.PrivateMessage, args []string) string {
+	giveaways := GetCommandManager().GetGiveaways()
+
+	switch err := giveaways.Enter(message.User.Name, isSubscriber(message)); {
+	case err == nil:
+		// Stay quiet on successful entries to avoid flooding chat
+		return ""
+	case errors.Is(err, ErrNoGiveaway):
+		return "There is no giveaway running."
+	case errors.Is(err, ErrAlreadyEntered):
+		return fmt.Sprintf("@%s, you're already entered.", message.User.Name)
+	default:
+		return fmt.Sprintf("@%s, %v.", message.User.Name, err)
+	}
+}
@@ -1,12 +1,17 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gempir/go-twitch-irc/v4"
+	"github.com/pbuckles22/PBChatBot/internal/queue"
 )
 
 // commandManager is a package-level variable that holds the command manager instance
@@ -22,10 +27,53 @@ func GetCommandManager() *CommandManager {
 	return commandManager
 }
 
+// commandHelp describes one command: its aliases, description, who can use
+// it and its cooldown. Aliases, including runtime ones, resolve to the command
+// they point at.
+func (cm *CommandManager) commandHelp(name string) string {
+	name = strings.ToLower(strings.TrimPrefix(name, cm.prefix))
+
+	if base, isAlias := cm.GetAliases()[name]; isAlias {
+		name = base
+	}
+	cmd, exists := cm.lookupCommand(name)
+	if !exists {
+		return fmt.Sprintf("No such command: %s%s", cm.prefix, name)
+	}
+
+	info := cm.prefix + cmd.Name
+	if len(cmd.Aliases) > 0 {
+		aliases := make([]string, len(cmd.Aliases))
+		for i, alias := range cmd.Aliases {
+			aliases[i] = cm.prefix + alias
+		}
+		info = fmt.Sprintf("%s (%s)", info, strings.Join(aliases, ", "))
+	}
+
+	permission := "Everyone"
+	if cmd.ModOnly {
+		permission = "Moderators only"
+	} else if cmd.IsPrivileged {
+		permission = "Moderators/VIPs"
+	}
+
+	cooldown := cmd.Cooldown
+	return fmt.Sprintf("%s: %s | Permission: %s | Cooldown: %s (viewers), %s (VIPs), %s (mods), %s (broadcaster)",
+		info, cmd.Description, permission,
+		formatCountdown(cooldown.Regular), formatCountdown(cooldown.VIP),
+		formatCountdown(cooldown.Mod), formatCountdown(cooldown.Broadcaster))
+}
+
 // HandleHelp shows the list of available commands
 func HandleHelp(message twitch.PrivateMessage, args []string) string {
+	// !help <command> describes a single command
+	if len(args) > 0 {
+		return commandManager.commandHelp(args[0])
+	}
+
 	commands := commandManager.GetCommandList()
-	var commandList []string
+	byCategory := make(map[string][]string)
+	total := 0
 
 	// Build the list of commands to display based on user permissions
 	for _, cmd := range commands {
@@ -57,59 +105,109 @@ func HandleHelp(message twitch.PrivateMessage, args []string) string {
 			cmdInfo = fmt.Sprintf("%s [Mod/VIP]", cmdInfo)
 		}
 
-		commandList = append(commandList, cmdInfo)
+		category := cmd.Category
+		if category == "" {
+			category = CategoryOther
+		}
+		byCategory[category] = append(byCategory[category], cmdInfo)
+		total++
 	}
 
-	if len(commandList) == 0 {
+	if total == 0 {
 		return "No commands available."
 	}
 
-	// Group commands by category
-	var baseCommands []string
-	var queueCommands []string
-
-	for _, cmd := range commandList {
-		// Base commands that are always available
-		if strings.Contains(cmd, "help") || strings.Contains(cmd, "ping") || strings.Contains(cmd, "uptime") {
-			baseCommands = append(baseCommands, cmd)
-		} else {
-			queueCommands = append(queueCommands, cmd)
-		}
-	}
-
-	// Build the response
+	// Build the response, one heading per category
 	var response strings.Builder
 	response.WriteString("Available commands:\n")
 
-	if len(baseCommands) > 0 {
-		response.WriteString("Base Commands:\n")
-		for _, cmd := range baseCommands {
+	for i, category := range commandManager.helpCategoryOrder(byCategory) {
+		if i > 0 {
+			response.WriteString("\n")
+		}
+		response.WriteString(fmt.Sprintf("%s Commands:\n", category))
+		for _, cmd := range byCategory[category] {
 			response.WriteString(fmt.Sprintf("• %s\n", cmd))
 		}
 	}
 
-	if len(queueCommands) > 0 {
-		response.WriteString("\nQueue Commands:\n")
-		for _, cmd := range queueCommands {
-			response.WriteString(fmt.Sprintf("• %s\n", cmd))
+	return response.String()
+}
+
+// helpCategoryOrder returns the categories present in byCategory in the
+// order !help lists them: help_category_order from the config (or
+// DefaultHelpCategoryOrder), then any remaining categories alphabetically,
+// with CategoryOther last
+func (cm *CommandManager) helpCategoryOrder(byCategory map[string][]string) []string {
+	order := DefaultHelpCategoryOrder
+	if cfg := cm.GetConfig(); cfg != nil && len(cfg.HelpCategoryOrder) > 0 {
+		order = cfg.HelpCategoryOrder
+	}
+
+	var categories []string
+	listed := make(map[string]bool)
+	for _, category := range order {
+		if _, ok := byCategory[category]; ok && !listed[category] {
+			categories = append(categories, category)
+			listed[category] = true
 		}
 	}
 
-	return response.String()
+	var rest []string
+	for category := range byCategory {
+		if !listed[category] && category != CategoryOther {
+			rest = append(rest, category)
+		}
+	}
+	sort.Strings(rest)
+	categories = append(categories, rest...)
+	if _, ok := byCategory[CategoryOther]; ok && !listed[CategoryOther] {
+		categories = append(categories, CategoryOther)
+	}
+	return categories
 }
 
-// HandlePing checks if the bot is alive
+// HandlePing checks if the bot is alive, with the round-trip time of the
+// connection's last IRC PING once one has been measured
 func HandlePing(message twitch.PrivateMessage, args []string) string {
+	if commandManager == nil {
+		return "Pong! 🏓"
+	}
+	if latency := commandManager.chatLatency(); latency > 0 {
+		return fmt.Sprintf("Pong! 🏓 (RTT: %dms)", latency.Milliseconds())
+	}
 	return "Pong! 🏓"
 }
 
+// HandleCommandCount reports how many commands are registered, with and
+// without aliases
+func HandleCommandCount(message twitch.PrivateMessage, args []string) string {
+	unique, withAliases := commandManager.CommandCount()
+	return fmt.Sprintf("PBChatBot has %d registered commands (%d including aliases).", unique, withAliases)
+}
+
 // HandleStartQueue starts the queue system
 func HandleStartQueue(message twitch.PrivateMessage, args []string) string {
 	queue := commandManager.GetQueue()
 	if queue.IsEnabled() {
 		return "Queue system is already running!"
 	}
+
+	// An argument overrides the configured max size until the next start
+	maxSize := commandManager.GetConfig().Commands.Queue.MaxSize
+	if len(args) > 0 {
+		var err error
+		maxSize, err = parsePositiveInt(args[0], "max queue size")
+		if err != nil {
+			return err.Error() + " Usage: !startqueue [max size]"
+		}
+	}
+	queue.SetMaxSize(maxSize)
 	queue.Enable()
+
+	if limit := queue.MaxSize(); limit > 0 {
+		return fmt.Sprintf("@%s has started the queue system (max %d users)!", message.User.Name, limit)
+	}
 	return fmt.Sprintf("@%s has started the queue system!", message.User.Name)
 }
 
@@ -119,8 +217,45 @@ func HandleEndQueue(message twitch.PrivateMessage, args []string) string {
 	if !queue.IsEnabled() {
 		return "Queue system is already disabled!"
 	}
-	queue.Disable()
-	return fmt.Sprintf("@%s has ended the queue system!", message.User.Name)
+
+	// By default the queue is kept in the auto-save for !restoreauto
+	preserve := true
+	if len(args) > 0 {
+		switch strings.ToLower(args[0]) {
+		case "save":
+		case "nosave":
+			preserve = false
+		default:
+			return "Usage: !endqueue [save|nosave]"
+		}
+	}
+
+	// Capture the summary before End empties the queue
+	var summaryFile string
+	if cfg := commandManager.GetConfig(); cfg != nil && cfg.Commands.Queue.SessionSummary {
+		filename, err := commandManager.WriteSessionSummary(queue.SessionSummary())
+		if err != nil {
+			log.Printf("Error writing queue session summary: %v", err)
+		}
+		summaryFile = filename
+	}
+
+	saved := 0
+	if preserve {
+		saved = queue.Size()
+	}
+	if err := queue.End(preserve); err != nil {
+		log.Printf("Error saving ended queue: %v", err)
+	}
+
+	response := fmt.Sprintf("@%s has ended the queue system!", message.User.Name)
+	if saved > 0 {
+		response += fmt.Sprintf(" %d user(s) saved; use !restoreauto to bring them back.", saved)
+	}
+	if summaryFile != "" {
+		response += fmt.Sprintf(" Session summary saved to %s.", summaryFile)
+	}
+	return response
 }
 
 // HandleClearQueue clears all users from the queue
@@ -130,52 +265,264 @@ func HandleClearQueue(message twitch.PrivateMessage, args []string) string {
 		return "Queue system is currently disabled."
 	}
 	count := queue.Clear()
-	return fmt.Sprintf("Queue cleared (%d users removed)", count)
+	return fmt.Sprintf("Queue cleared (%d users removed)%s", count, undoClearHint(count))
+}
+
+// undoClearHint tells mods how long they have to undo a clear
+func undoClearHint(count int) string {
+	if count == 0 {
+		return ""
+	}
+	window := commandManager.GetQueue().GetUndoClearWindow()
+	if window <= 0 {
+		return "."
+	}
+	return fmt.Sprintf(". Use !undoclear within %s to restore.", formatAgo(window))
+}
+
+// HandleUndoClear handles the !undoclear command
+func HandleUndoClear(message twitch.PrivateMessage, args []string) string {
+	queue := commandManager.GetQueue()
+	if !queue.IsEnabled() {
+		return "Queue system is currently disabled."
+	}
+
+	if queue.GetUndoClearWindow() <= 0 {
+		return "Undoing a clear is disabled for this channel."
+	}
+	count, err := queue.UndoClear()
+	if err != nil {
+		return "Nothing to restore. The last clear has expired or the queue hasn't been cleared."
+	}
+	return fmt.Sprintf("Restored %d user(s) from the last clear (%d total)", count, queue.Size())
+}
+
+// recentlyServedWindow is how long after being popped a rejoin is annotated
+const recentlyServedWindow = time.Hour
+
+// HandleResetLimits handles the !resetlimits command, clearing everyone's
+// rejoin cooldown without touching the queue
+func HandleResetLimits(message twitch.PrivateMessage, args []string) string {
+	count := GetCommandManager().GetQueue().ResetLimits()
+	return fmt.Sprintf("Queue limits reset. Cleared rejoin cooldowns for %d user(s).", count)
+}
+
+// DefaultAlreadyQueuedMessage is the reply to a duplicate !join
+const DefaultAlreadyQueuedMessage = "@{user} you're already in the queue at position {position}"
+
+// alreadyQueuedResponse formats the already_queued_message template if err
+// says username is already in the queue
+func (cm *CommandManager) alreadyQueuedResponse(username string, err error) (string, bool) {
+	var dup *queue.AlreadyQueuedError
+	if !errors.As(err, &dup) {
+		return "", false
+	}
+	template := cm.GetConfig().Commands.Queue.AlreadyQueuedMessage
+	if template == "" {
+		template = DefaultAlreadyQueuedMessage
+	}
+	return strings.NewReplacer(
+		"{user}", username,
+		"{position}", strconv.Itoa(dup.Position),
+	).Replace(template), true
+}
+
+// joinResponse builds the response for a user who just joined the queue,
+// noting when they were recently served so rejoins aren't confusing.
+func joinResponse(cm *CommandManager, username string) string {
+	pos := cm.GetQueue().Position(username)
+	if servedAt, ok := cm.GetQueue().LastServed(username); ok {
+		if ago := time.Since(servedAt); ago < recentlyServedWindow {
+			return fmt.Sprintf("Welcome back %s, joined at position %d (you were served %s ago)", username, pos, formatAgo(ago))
+		}
+	}
+	total := cm.GetQueue().Size()
+	return fmt.Sprintf("%s joined queue at position %d (%d total)", username, pos, total)
+}
+
+// formatAgo formats a duration compactly (e.g. "45s", "4m", "1h5m")
+func formatAgo(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	}
 }
 
 // HandleJoin handles the !join command
 func HandleJoin(message twitch.PrivateMessage, args []string) string {
 	cm := GetCommandManager()
 	if !cm.GetQueue().IsEnabled() {
-		return "Queue system is currently disabled."
+		reply, _ := cm.joinBlockedResponse(message.User.Name, queue.ErrQueueDisabled)
+		return reply
+	}
+
+	if reason := cm.checkFollowAge(message); reason != "" {
+		return reason
+	}
+	if reason := cm.checkAccountAge(message); reason != "" {
+		return reason
 	}
 
 	// If no arguments provided, add the command user
 	if len(args) == 0 {
-		err := cm.GetQueue().Add(message.User.Name, isPrivileged(message))
+		waitlisted, reply, err := cm.paidAdd(message, message.User.Name)
+		if reply != "" {
+			return reply
+		}
+		if reply, ok := cm.alreadyQueuedResponse(message.User.Name, err); ok {
+			return reply
+		}
+		if reply, ok := cm.joinBlockedResponse(message.User.Name, err); ok {
+			return reply
+		}
 		if err != nil {
 			return fmt.Sprintf("Error joining queue: %v", err)
 		}
-		pos := cm.GetQueue().Position(message.User.Name)
-		total := cm.GetQueue().Size()
-		return fmt.Sprintf("%s joined queue at position %d (%d total)", message.User.Name, pos, total)
+		var response string
+		if waitlisted {
+			response = waitlistResponse(cm, message.User.Name)
+		} else {
+			cm.GetQueue().SetSubscriber(message.User.Name, isSubscriber(message))
+			cm.recordJoin()
+			response = joinResponse(cm, message.User.Name)
+		}
+		if cm.quietJoinNotice(message.User.Name, waitlisted) {
+			return ""
+		}
+		if cm.whisperNotice(message.User.Name, response) {
+			return ""
+		}
+		return response
 	}
 
 	// If arguments provided and user is privileged, add all specified users
 	if isPrivileged(message) {
-		var responses []string
-		for _, username := range args {
-			// Use the exact username provided in the command
-			err := cm.GetQueue().Add(username, true)
-			if err != nil {
-				responses = append(responses, fmt.Sprintf("Error adding %s: %v", username, err))
-			} else {
-				pos := cm.GetQueue().Position(username)
-				total := cm.GetQueue().Size()
-				responses = append(responses, fmt.Sprintf("%s joined queue at position %d (%d total)", username, pos, total))
-			}
+		if len(args) > 1 {
+			return bulkJoin(cm, args)
+		}
+		// Use the exact username provided in the command
+		waitlisted, err := cm.waitlistIfFull(args[0], cm.GetQueue().Add(args[0], true))
+		if err != nil {
+			return fmt.Sprintf("Error adding %s: %v", args[0], err)
+		}
+		if waitlisted {
+			return waitlistResponse(cm, args[0])
 		}
-		return strings.Join(responses, " ")
+		cm.recordJoin()
+		return joinResponse(cm, args[0])
 	}
 
 	// If not privileged, only add the first user with exact case
-	err := cm.GetQueue().Add(args[0], false)
+	waitlisted, reply, err := cm.paidAdd(message, args[0])
+	if reply != "" {
+		return reply
+	}
+	if reply, ok := cm.alreadyQueuedResponse(args[0], err); ok {
+		return reply
+	}
+	if reply, ok := cm.joinBlockedResponse(args[0], err); ok {
+		return reply
+	}
 	if err != nil {
 		return fmt.Sprintf("Error joining queue: %v", err)
 	}
-	pos := cm.GetQueue().Position(args[0])
-	total := cm.GetQueue().Size()
-	return fmt.Sprintf("%s joined queue at position %d (%d total)", args[0], pos, total)
+	if waitlisted {
+		return waitlistResponse(cm, args[0])
+	}
+	cm.recordJoin()
+	return joinResponse(cm, args[0])
+}
+
+// paidAdd adds username to the queue (or the waitlist if it's full) on
+// behalf of the user who sent message. Viewers pay queue.join_cost for it,
+// refunded if the join fails; a waitlist spot costs the same, since it turns
+// into a queue spot without another !join. reply is set instead when the
+// viewer can't afford to join.
+func (cm *CommandManager) paidAdd(message twitch.PrivateMessage, username string) (waitlisted bool, reply string, err error) {
+	privileged := isPrivileged(message)
+	var charged int64
+	if !privileged {
+		if charged, reply = cm.chargeJoinCost(message.User.Name); reply != "" {
+			return false, reply, nil
+		}
+	}
+
+	waitlisted, err = cm.waitlistIfFull(username, cm.GetQueue().Add(username, privileged))
+	if err != nil && charged > 0 {
+		cm.GetPoints().Add(message.User.Name, charged)
+	}
+	return waitlisted, "", err
+}
+
+// bulkJoin adds several users for a mod and summarizes the result in one
+// line, e.g. "Added 8 users (positions 3-10). Skipped 2 (already queued)."
+// so long lists don't overflow the chat message limit.
+func bulkJoin(cm *CommandManager, usernames []string) string {
+	added := 0
+	first, last := 0, 0
+	var reasons []string
+	skipped := make(map[string]int)
+	for _, username := range usernames {
+		// Use the exact username provided in the command
+		if err := cm.GetQueue().Add(username, true); err != nil {
+			reason := joinSkipReason(err)
+			if skipped[reason] == 0 {
+				reasons = append(reasons, reason)
+			}
+			skipped[reason]++
+			continue
+		}
+		cm.recordJoin()
+		added++
+		pos := cm.GetQueue().Position(username)
+		if first == 0 || pos < first {
+			first = pos
+		}
+		if pos > last {
+			last = pos
+		}
+	}
+
+	var response string
+	switch {
+	case added == 0:
+		response = "Added 0 users."
+	case added == 1:
+		response = fmt.Sprintf("Added 1 user (position %d).", first)
+	default:
+		response = fmt.Sprintf("Added %d users (positions %d-%d).", added, first, last)
+	}
+
+	switch len(reasons) {
+	case 0:
+		return response
+	case 1:
+		return fmt.Sprintf("%s Skipped %d (%s).", response, skipped[reasons[0]], reasons[0])
+	}
+	total := 0
+	counts := make([]string, len(reasons))
+	for i, reason := range reasons {
+		total += skipped[reason]
+		counts[i] = fmt.Sprintf("%s: %d", reason, skipped[reason])
+	}
+	return fmt.Sprintf("%s Skipped %d (%s).", response, total, strings.Join(counts, ", "))
+}
+
+// joinSkipReason describes why a user couldn't be added in a bulk join
+func joinSkipReason(err error) string {
+	var dup *queue.AlreadyQueuedError
+	switch {
+	case errors.As(err, &dup):
+		return "already queued"
+	case errors.Is(err, queue.ErrQueueFull):
+		return "queue full"
+	default:
+		return err.Error()
+	}
 }
 
 // HandleLeave handles the !leave command
@@ -191,15 +538,13 @@ func HandleLeave(message twitch.PrivateMessage, args []string) string {
 	}
 
 	// Get the current queue to find the exact case of the username
-	users := cm.GetQueue().List()
-	var exactUsername string
-	for _, user := range users {
-		if strings.EqualFold(user, username) {
-			exactUsername = user
-			break
-		}
+	exactUsername := cm.GetQueue().Find(username)
+	if exactUsername == "" {
+		exactUsername = findUser(cm.GetQueue().ListVIP(), username)
+	}
+	if exactUsername == "" {
+		exactUsername = findUser(cm.GetQueue().ListWaitlist(), username)
 	}
-
 	if exactUsername == "" {
 		return fmt.Sprintf("%s is not in the queue!", username)
 	}
@@ -213,8 +558,27 @@ func HandleLeave(message twitch.PrivateMessage, args []string) string {
 // HandleQueue shows the current queue
 func HandleQueue(message twitch.PrivateMessage, args []string) string {
 	queue := commandManager.GetQueue()
+	if commandManager.RequestedFormat(message, args) == ResponseFormatJSON {
+		var users []string
+		if queue.IsEnabled() {
+			users = queue.List()
+		}
+		return formatQueueJSON(users)
+	}
 	if !queue.IsEnabled() {
-		return "Queue system is currently disabled."
+		// Show what was saved when the queue was turned off, if anything
+		saved, err := queue.PeekSavedState()
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Error reading saved queue state: %v", err)
+		}
+		if len(saved) == 0 {
+			return "Queue system is currently disabled."
+		}
+		count := fmt.Sprintf("%d users", len(saved))
+		if len(saved) == 1 {
+			count = "1 user"
+		}
+		return fmt.Sprintf("⏸ Queue is paused. Last state: %s (%s).", strings.Join(saved, ", "), count)
 	}
 
 	users := queue.List()
@@ -222,13 +586,47 @@ func HandleQueue(message twitch.PrivateMessage, args []string) string {
 		return "The queue is currently empty."
 	}
 
+	for _, arg := range args {
+		if arg == allFlag {
+			if !isModerator(message) {
+				return "Only moderators can dump the full queue."
+			}
+			filename, err := commandManager.DumpQueue()
+			if err != nil {
+				return fmt.Sprintf("Error dumping queue: %v", err)
+			}
+			return fmt.Sprintf("Wrote all %d users in the queue to %s.", len(users), filename)
+		}
+	}
+	if len(args) > 0 {
+		if start, end, ok, err := parseQueueRange(args[0]); err != nil {
+			return err.Error()
+		} else if ok {
+			return formatQueueRange(users, start, end)
+		}
+	}
+
 	// Build numbered list of users in queue
 	var userList []string
 	for i, user := range users {
 		userList = append(userList, fmt.Sprintf("%d) %s", i+1, user))
 	}
 
-	return fmt.Sprintf("Queue: %s (%d total)", strings.Join(users, ", "), len(users))
+	// Flag reserved slots so chat knows why someone absent is near the front
+	names := make([]string, len(users))
+	for i, user := range users {
+		names[i] = user
+		if queue.IsReserved(user) {
+			names[i] = user + " (reserved)"
+		} else if queue.IsPinned(user) {
+			names[i] = user + " (pinned)"
+		}
+	}
+
+	if waiting := len(queue.ListWaitlist()); waiting > 0 {
+		return fmt.Sprintf("Queue: %s (%d total, %d on the waitlist)", strings.Join(names, ", "), len(users), waiting)
+	}
+	return fmt.Sprintf("Queue: %s (%d total)", strings.Join(names, ", "), len(users))
 }
 
 // HandlePosition shows a user's position in the queue
@@ -241,29 +639,28 @@ func HandlePosition(message twitch.PrivateMessage, args []string) string {
 	// If no arguments, show position of command user
 	if len(args) == 0 {
 		position := queue.Position(message.User.Name)
-		if position == -1 {
+		var response string
+		if position != -1 {
+			response = fmt.Sprintf("%s is at position %d", message.User.Name, position)
+		} else if waiting := queue.WaitlistPosition(message.User.Name); waiting != -1 {
+			response = fmt.Sprintf("%s is #%d on the waitlist", message.User.Name, waiting)
+		} else {
 			return fmt.Sprintf("@%s, you are not in the queue!", message.User.Name)
 		}
-		return fmt.Sprintf("%s is at position %d", message.User.Name, position)
+		if commandManager.whisperNotice(message.User.Name, response) {
+			return ""
+		}
+		return response
 	}
 
-	// Try to parse argument as a position number
-	position, err := strconv.Atoi(args[0])
-	if err == nil {
-		// If it's a valid number, get the user at that position
-		users := queue.List()
-		if position < 1 || position > len(users) {
-			return fmt.Sprintf("Invalid position. Queue has %d users.", len(users))
-		}
-		username := users[position-1]
-		return fmt.Sprintf("User at position %d is %s", position, username)
+	username, position, err := resolveUserOrPosition(queue.List(), args[0])
+	if err != nil {
+		return err.Error()
 	}
 
-	// If not a number, treat as username
-	username := args[0]
-	position = queue.Position(username)
-	if position == -1 {
-		return fmt.Sprintf("%s is not in the queue!", username)
+	// A numeric argument asks who is at that position
+	if _, err := strconv.Atoi(args[0]); err == nil {
+		return fmt.Sprintf("User at position %d is %s", position, username)
 	}
 	return fmt.Sprintf("%s is at position %d", username, position)
 }
@@ -278,9 +675,9 @@ func HandlePop(message twitch.PrivateMessage, args []string) string {
 	count := 1
 	if len(args) > 0 {
 		var err error
-		count, err = strconv.Atoi(args[0])
-		if err != nil || count < 1 {
-			return "Invalid number of users to pop. Please specify a positive number."
+		count, err = parsePositiveInt(args[0], "number of users to pop")
+		if err != nil {
+			return err.Error()
 		}
 	}
 
@@ -293,63 +690,126 @@ func HandlePop(message twitch.PrivateMessage, args []string) string {
 		return "Queue is empty."
 	}
 
-	// Format the response
-	var response strings.Builder
-	response.WriteString("Popped: ")
-	for i, user := range users {
-		if i > 0 {
-			response.WriteString(", ")
-		}
-		response.WriteString(user)
+	// List at most max_pop_names users
+	if announcement, ok := cm.servedAnnouncement(users); ok {
+		return announcement
 	}
+	return "Popped: " + formatNameList(users, cm.maxPopNames())
+}
 
-	return response.String()
+// formatNameList joins users with commas, listing at most limit names and
+// counting the rest, e.g. "a, b, c ...and 4 more"
+func formatNameList(users []string, limit int) string {
+	shown := users
+	if len(users) > limit {
+		shown = users[:limit]
+	}
+	list := strings.Join(shown, ", ")
+	if more := len(users) - len(shown); more > 0 {
+		list += fmt.Sprintf(" ...and %d more", more)
+	}
+	return list
 }
 
-// HandleRemove handles the !remove command
-func HandleRemove(message twitch.PrivateMessage, args []string) string {
+// HandleNowServing handles the !nowserving command
+func HandleNowServing(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	users := cm.GetQueue().NowServing()
+	if len(users) == 0 {
+		return "Nobody is being served right now."
+	}
+	return "Now serving: " + formatNameList(users, cm.maxPopNames())
+}
+
+// HandleClearServed handles the !clearserved command
+func HandleClearServed(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	count := cm.GetQueue().ClearNowServing()
+	if count == 0 {
+		return "Nobody is being served right now."
+	}
+	return fmt.Sprintf("Cleared %d user(s) from now serving.", count)
+}
+
+// DefaultMaxPopNames is how many popped users !pop lists by default
+const DefaultMaxPopNames = 10
+
+// maxPopNames returns the configured max_pop_names, or DefaultMaxPopNames
+func (cm *CommandManager) maxPopNames() int {
+	if cfg := cm.GetConfig(); cfg != nil && cfg.Commands.Queue.MaxPopNames > 0 {
+		return cfg.Commands.Queue.MaxPopNames
+	}
+	return DefaultMaxPopNames
+}
+
+// HandleSkipTo handles the !skipto command, skipping everyone ahead of a user
+func HandleSkipTo(message twitch.PrivateMessage, args []string) string {
 	cm := GetCommandManager()
 	if !cm.GetQueue().IsEnabled() {
 		return "Queue system is currently disabled."
 	}
 
-	if len(args) < 1 {
-		return "Usage: !remove <username> or !remove <position>"
+	if err := requireArgs(args, 1, "!skipto <username> or !skipto <position>"); err != nil {
+		return err.Error()
 	}
 
-	// Try to parse the argument as a position number
-	position, err := strconv.Atoi(args[0])
-	if err == nil {
-		// If it's a valid number, get the user at that position
-		users := cm.GetQueue().List()
-		if position < 1 || position > len(users) {
-			return fmt.Sprintf("Invalid position. Queue has %d users.", len(users))
-		}
-		username := users[position-1]
-		if cm.GetQueue().Remove(username) {
-			return fmt.Sprintf("%s (position %d) removed from queue", username, position)
-		}
-		return fmt.Sprintf("Error removing user at position %d", position)
+	username, _, err := resolveUserOrPosition(cm.GetQueue().List(), args[0])
+	if err != nil {
+		return err.Error()
 	}
 
-	// If not a number, treat as username
-	username := args[0]
-	// Get the current queue to find the exact case of the username
-	users := cm.GetQueue().List()
-	var exactUsername string
-	for _, user := range users {
-		if strings.EqualFold(user, username) {
-			exactUsername = user
-			break
-		}
+	skipped, err := cm.GetQueue().SkipTo(username)
+	if err != nil {
+		return fmt.Sprintf("Error skipping to %s: %v", username, err)
+	}
+	if len(skipped) == 0 {
+		return fmt.Sprintf("%s is already at the front of the queue.", username)
 	}
+	if len(skipped) == 1 {
+		return fmt.Sprintf("Skipped 1 user. %s is now at the front of the queue.", username)
+	}
+	return fmt.Sprintf("Skipped %d users. %s is now at the front of the queue.", len(skipped), username)
+}
 
-	if exactUsername == "" {
-		return fmt.Sprintf("%s is not in the queue!", username)
+// HandlePromoteSub handles the !promotesub command, moving the
+// highest-positioned subscriber to the front of the queue
+func HandlePromoteSub(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	if !cm.GetQueue().IsEnabled() {
+		return "Queue system is currently disabled."
 	}
 
-	if cm.GetQueue().Remove(exactUsername) {
-		return fmt.Sprintf("%s removed from queue", exactUsername)
+	username, position, err := cm.GetQueue().PromoteNextSubscriber()
+	if errors.Is(err, queue.ErrNoSubscribers) {
+		return "There are no subscribers in the queue."
+	}
+	if err != nil {
+		return fmt.Sprintf("Error promoting subscriber: %v", err)
+	}
+	if position == 1 {
+		return fmt.Sprintf("%s is already at the front of the queue.", username)
+	}
+	return fmt.Sprintf("Promoted subscriber %s from position %d to the front of the queue!", username, position)
+}
+
+// HandleRemove handles the !remove command
+func HandleRemove(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	if !cm.GetQueue().IsEnabled() {
+		return "Queue system is currently disabled."
+	}
+
+	if err := requireArgs(args, 1, "!remove <username> or !remove <position>"); err != nil {
+		return err.Error()
+	}
+
+	username, position, err := resolveUserOrPosition(cm.GetQueue().List(), args[0])
+	if err != nil {
+		return err.Error()
+	}
+
+	if cm.GetQueue().Remove(username) {
+		return fmt.Sprintf("%s (position %d) removed from queue", username, position)
 	}
 	return fmt.Sprintf("Error removing %s from the queue.", username)
 }
@@ -361,50 +821,77 @@ func HandleMove(message twitch.PrivateMessage, args []string) string {
 		return "Queue system is currently disabled."
 	}
 
-	if len(args) < 2 {
-		return "Usage: !move <username/position> <position>"
+	if err := requireArgs(args, 2, "!move <username/position> <position/front/end>"); err != nil {
+		return err.Error()
 	}
 
-	// Get the current queue
-	users := cm.GetQueue().List()
-	var exactUsername string
+	username, _, err := resolveUserOrPosition(cm.GetQueue().List(), args[0])
+	if err != nil {
+		return err.Error()
+	}
 
-	// Try to parse first argument as a position number
-	fromPosition, err := strconv.Atoi(args[0])
-	if err == nil {
-		// If it's a valid number, get the user at that position
-		if fromPosition < 1 || fromPosition > len(users) {
-			return fmt.Sprintf("Invalid from position. Queue has %d users.", len(users))
+	// Handle front/back/end keywords
+	switch strings.ToLower(args[1]) {
+	case "front":
+		if err := cm.GetQueue().MoveToFront(username); err != nil {
+			return fmt.Sprintf("Error moving user: %v", err)
 		}
-		exactUsername = users[fromPosition-1]
-	} else {
-		// If not a number, treat as username
-		username := args[0]
-		// Find the exact case of the username
-		for _, user := range users {
-			if strings.EqualFold(user, username) {
-				exactUsername = user
-				break
-			}
+		cm.notifyPosition(username)
+		return fmt.Sprintf("%s moved to position 1", username)
+	case "back", "end":
+		if err := cm.GetQueue().MoveToEnd(username); err != nil {
+			return fmt.Sprintf("Error moving user: %v", err)
 		}
-	}
-
-	if exactUsername == "" {
-		return fmt.Sprintf("%s is not in the queue!", args[0])
+		cm.notifyPosition(username)
+		return fmt.Sprintf("%s moved to position %d", username, cm.GetQueue().Size())
 	}
 
 	// Parse the target position
-	toPosition, err := strconv.Atoi(args[1])
+	toPosition, err := parsePositiveInt(args[1], "target position")
 	if err != nil {
-		return "Invalid target position. Please provide a number."
+		return err.Error()
 	}
 
-	err = cm.GetQueue().MoveUser(exactUsername, toPosition)
+	err = cm.GetQueue().MoveUser(username, toPosition)
 	if err != nil {
 		return fmt.Sprintf("Error moving user: %v", err)
 	}
+	cm.notifyPosition(username)
 
-	return fmt.Sprintf("%s moved to position %d", exactUsername, toPosition)
+	return fmt.Sprintf("%s moved to position %d", username, toPosition)
+}
+
+// HandleMoveBatch handles the !movebatch command, placing several users at
+// consecutive positions in the order given
+func HandleMoveBatch(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	if !cm.GetQueue().IsEnabled() {
+		return "Queue system is currently disabled."
+	}
+
+	if err := requireArgs(args, 2, "!movebatch <position> <user1> [user2 ...]"); err != nil {
+		return err.Error()
+	}
+
+	position, err := parsePositiveInt(args[0], "target position")
+	if err != nil {
+		return err.Error()
+	}
+
+	moved, err := cm.GetQueue().MoveBatch(args[1:], position)
+	if err != nil {
+		return fmt.Sprintf("Error moving users: %v", err)
+	}
+	for _, username := range moved {
+		cm.notifyPosition(username)
+	}
+
+	// The position is clamped to the end of the queue
+	first := cm.GetQueue().Position(moved[0])
+	if len(moved) == 1 {
+		return fmt.Sprintf("%s moved to position %d", moved[0], first)
+	}
+	return fmt.Sprintf("Moved %s to positions %d-%d", strings.Join(moved, ", "), first, first+len(moved)-1)
 }
 
 // HandlePause pauses the queue system
@@ -414,9 +901,13 @@ func HandlePause(message twitch.PrivateMessage, args []string) string {
 		return "Queue system is not enabled"
 	}
 
-	if err := cm.GetQueue().Pause(); err != nil {
+	reason := strings.TrimSpace(strings.Join(args, " "))
+	if err := cm.GetQueue().PauseWithReason(reason); err != nil {
 		return fmt.Sprintf("Error pausing queue: %v", err)
 	}
+	if reason != "" {
+		return fmt.Sprintf("Queue is now paused: %s. No new entries can be added until the queue is unpaused.", reason)
+	}
 	return "Queue is now paused. No new entries can be added until the queue is unpaused."
 }
 
@@ -469,7 +960,7 @@ func HandleLoadState(message twitch.PrivateMessage, args []string) string {
 			return "Queue system has been started!"
 		}
 		// Provide more specific error message
-		if os.IsNotExist(err) {
+		if errors.Is(err, os.ErrNotExist) {
 			return "No backup file found. Use !savequeue to create a backup first."
 		}
 		return fmt.Sprintf("Error loading queue state: %v", err)
@@ -487,14 +978,15 @@ func HandleRestoreAuto(message twitch.PrivateMessage, args []string) string {
 	cm := GetCommandManager()
 	queue := cm.GetQueue()
 
-	// If queue is disabled, enable it first
+	// Restore from the auto-save file (simulating crash recovery), including
+	// a queue kept by !endqueue. This happens before enabling the queue,
+	// since enabling discards the kept users.
 	wasDisabled := !queue.IsEnabled()
+	err := queue.RestoreAutoSave()
 	if wasDisabled {
 		queue.Enable()
 	}
-
-	// Try to restore from the auto-save file (simulating crash recovery)
-	if err := queue.LoadState(); err != nil {
+	if err != nil {
 		if wasDisabled {
 			return "Queue system has been started!"
 		}
@@ -522,6 +1014,22 @@ func HandleRestart(message twitch.PrivateMessage, args []string) string {
 	return "Bot restart initiated. See you soon! 🔄"
 }
 
+// HandleResetBot handles the !resetbot command
+func HandleResetBot(message twitch.PrivateMessage, args []string) string {
+	if !isBroadcaster(message) {
+		return "This command can only be used by the broadcaster."
+	}
+
+	// Require explicit confirmation since this wipes in-memory state
+	if len(args) == 0 || !strings.EqualFold(args[0], "confirm") {
+		return "This will reset all bot state and reload config from disk. Use !resetbot confirm to proceed."
+	}
+
+	cm := GetCommandManager()
+	count := cm.Reset()
+	return fmt.Sprintf("Bot state reset. Re-loaded %d commands, queue state loaded from disk.", count)
+}
+
 // HandleEnable handles the !enable command
 func HandleEnable(message twitch.PrivateMessage, args []string) string {
 	cm := GetCommandManager()
@@ -544,5 +1052,8 @@ func HandleClear(message twitch.PrivateMessage, args []string) string {
 	}
 
 	count := cm.GetQueue().Clear()
+	if count > 0 {
+		return fmt.Sprintf("Queue cleared! Removed %d user(s)%s", count, undoClearHint(count))
+	}
 	return fmt.Sprintf("Queue cleared! Removed %d user(s).", count)
 }
@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+)
+
+// DefaultHypeDelay is the pause between hype messages when hype_delay isn't set
+const DefaultHypeDelay = 3 * time.Second
+
+// DefaultHypeMessages is the sequence !hype posts when hype_messages isn't set
+var DefaultHypeMessages = []string{"PogChamp PogChamp PogChamp", "GET HYPED!", "Let's GO! 🚀"}
+
+var (
+	// ErrHypeRunning is returned when starting a hype train while one is running
+	ErrHypeRunning = errors.New("a hype train is already running")
+	// ErrNoHypeMessages is returned when starting a hype train with no messages
+	ErrNoHypeMessages = errors.New("no hype messages to send")
+)
+
+// HypeTrain posts a sequence of messages to chat with a pause between each.
+// Only one sequence can run at a time.
+type HypeTrain struct {
+	mu    sync.Mutex
+	sleep func(time.Duration)
+	send  func(string)
+	// Closed when the running sequence's goroutine exits
+	done    chan struct{}
+	running bool
+	// Incremented on every start and cancel so a cancelled sequence stops
+	generation int
+}
+
+// NewHypeTrain creates a hype train that posts messages using send. If sleep
+// is nil, time.Sleep is used to wait between messages.
+func NewHypeTrain(sleep func(time.Duration), send func(string)) *HypeTrain {
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	return &HypeTrain{
+		sleep: sleep,
+		send:  send,
+	}
+}
+
+// Start posts messages in order from a goroutine, waiting delay between them
+func (h *HypeTrain) Start(messages []string, delay time.Duration) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.running {
+		return ErrHypeRunning
+	}
+	if len(messages) == 0 {
+		return ErrNoHypeMessages
+	}
+	h.running = true
+	h.generation++
+	h.done = make(chan struct{})
+
+	go h.run(h.generation, h.done, append([]string(nil), messages...), delay)
+	return nil
+}
+
+// run sends the sequence, stopping early if it is cancelled
+func (h *HypeTrain) run(generation int, done chan struct{}, messages []string, delay time.Duration) {
+	defer close(done)
+
+	for i, message := range messages {
+		if i > 0 {
+			h.sleep(delay)
+		}
+		h.mu.Lock()
+		if h.generation != generation {
+			h.mu.Unlock()
+			return
+		}
+		h.mu.Unlock()
+
+		h.send(message)
+	}
+
+	h.mu.Lock()
+	if h.generation == generation {
+		h.running = false
+	}
+	h.mu.Unlock()
+}
+
+// Cancel stops the running sequence before its next message. It returns
+// false if none was running.
+func (h *HypeTrain) Cancel() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.running {
+		return false
+	}
+	h.running = false
+	h.generation++
+	return true
+}
+
+// IsRunning reports whether a sequence is being posted
+func (h *HypeTrain) IsRunning() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.running
+}
+
+// Wait blocks until the most recently started sequence has finished or
+// noticed it was cancelled
+func (h *HypeTrain) Wait() {
+	h.mu.Lock()
+	done := h.done
+	h.mu.Unlock()
+
+	if done != nil {
+		<-done
+	}
+}
+
+// SetHypeTrain sets the hype train used by !hype
+func (cm *CommandManager) SetHypeTrain(hype *HypeTrain) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.hype = hype
+}
+
+// HandleHype handles the !hype command
+func HandleHype(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	cm.mu.RLock()
+	hype := cm.hype
+	cm.mu.RUnlock()
+
+	if hype == nil {
+		return "Hype trains are not available."
+	}
+
+	if len(args) > 0 {
+		if !strings.EqualFold(args[0], "cancel") {
+			return "Usage: !hype or !hype cancel"
+		}
+		if !hype.Cancel() {
+			return "No hype train is running."
+		}
+		return "Hype train cancelled."
+	}
+
+	cfg := cm.GetConfig()
+	messages := cfg.HypeMessages
+	if len(messages) == 0 {
+		messages = DefaultHypeMessages
+	}
+	delay := time.Duration(cfg.HypeDelay) * time.Second
+	if delay <= 0 {
+		delay = DefaultHypeDelay
+	}
+
+	if err := hype.Start(messages, delay); err != nil {
+		return "A hype train is already running. Use !hype cancel to stop it."
+	}
+	// The sequence itself is the response
+	return ""
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/queue"
+)
+
+// Reasons a !join can be blocked, used as keys in the queue config's
+// join_blocked_messages
+const (
+	JoinBlockedDisabled = "disabled"
+	JoinBlockedPaused   = "paused"
+	JoinBlockedFull     = "full"
+	JoinBlockedBanned   = "banned"
+	JoinBlockedCooldown = "cooldown"
+)
+
+// DefaultJoinBlockedMessages are the replies to a blocked !join, keyed by
+// reason. {user} is filled in everywhere, {wait} for cooldowns and {reason}
+// for pauses.
+var DefaultJoinBlockedMessages = map[string]string{
+	JoinBlockedDisabled: "@{user} the queue is disabled right now, so there is nothing to join.",
+	JoinBlockedPaused:   "@{user} the queue is paused, so nobody can join until it resumes.",
+	JoinBlockedFull:     "@{user} the queue is full. Try again when a spot opens.",
+	JoinBlockedBanned:   "@{user} you're barred from joining the queue.",
+	JoinBlockedCooldown: "@{user} you were served recently and can rejoin in {wait}.",
+}
+
+// joinBlockedReason maps a queue error to the reason it blocked a join, or
+// "" if err isn't a blocking condition
+func joinBlockedReason(err error) (reason string, wait time.Duration) {
+	var cooldown *queue.RejoinCooldownError
+	switch {
+	case errors.Is(err, queue.ErrQueueDisabled):
+		return JoinBlockedDisabled, 0
+	case errors.Is(err, queue.ErrQueuePaused):
+		return JoinBlockedPaused, 0
+	case errors.Is(err, queue.ErrQueueFull):
+		return JoinBlockedFull, 0
+	case errors.Is(err, queue.ErrUserBanned):
+		return JoinBlockedBanned, 0
+	case errors.As(err, &cooldown):
+		return JoinBlockedCooldown, cooldown.Wait
+	default:
+		return "", 0
+	}
+}
+
+// joinBlockedResponse formats the configured message for the condition that
+// stopped username from joining, if err is one
+func (cm *CommandManager) joinBlockedResponse(username string, err error) (string, bool) {
+	reason, wait := joinBlockedReason(err)
+	if reason == "" {
+		return "", false
+	}
+	template := cm.GetConfig().Commands.Queue.JoinBlockedMessages[reason]
+	if template == "" {
+		template = DefaultJoinBlockedMessages[reason]
+	}
+
+	// Show the !pausequeue reason, at the end unless the template places it
+	pauseReason := ""
+	if reason == JoinBlockedPaused {
+		pauseReason = cm.GetQueue().PauseReason()
+		if pauseReason != "" && !strings.Contains(template, "{reason}") {
+			template += " Reason: {reason}"
+		}
+	}
+	return strings.NewReplacer(
+		"{user}", username,
+		"{wait}", wait.Round(time.Second).String(),
+		"{reason}", pauseReason,
+	).Replace(template), true
+}
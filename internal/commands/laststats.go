@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/gempir/go-twitch-irc/v4"
+	channelstats "github.com/pbuckles22/PBChatBot/internal/channel"
+)
+
+// RegisterLastStatsCommand registers the laststats command
+func RegisterLastStatsCommand(cm *CommandManager, stats *channelstats.ChannelStats) {
+	cm.RegisterCommand(&Command{
+		Name:        "laststats",
+		Category:    CategoryStats,
+		Description: "Shows a recap of the last completed stream session",
+		Handler: func(message twitch.PrivateMessage, args []string) string {
+			return HandleLastStats(stats, message, args)
+		},
+	})
+}
+
+// HandleLastStats handles the !laststats command
+func HandleLastStats(stats *channelstats.ChannelStats, message twitch.PrivateMessage, args []string) string {
+	session, ok := stats.LastSession()
+	if !ok {
+		return "No completed sessions yet."
+	}
+
+	// Chat is counted per chatter as it happens, while UniqueChatters is only
+	// set by stream polling, so use whichever saw more
+	chatters := session.UniqueChatters
+	if counted := len(session.ChatterCounts); counted > chatters {
+		chatters = counted
+	}
+	return fmt.Sprintf("Last stream (%s): %s long, peak %d viewers, %d chat messages from %d unique chatters.",
+		session.StartTime.Format("Jan 2"), formatAgo(session.Duration), session.PeakViewers,
+		session.ChatMessages, chatters)
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+)
+
+// noLobbyCode fills {lobby_code} in the served announcement when !lobby
+// hasn't set a code
+const noLobbyCode = "(not set)"
+
+// maxLobbyCodeLength is the longest lobby code !lobby accepts, in characters
+const maxLobbyCodeLength = 32
+
+// LobbyCode returns the lobby code set with !lobby, or "" if there isn't one
+func (cm *CommandManager) LobbyCode() string {
+	var code string
+	cm.GetSettings().Get(SettingLobbyCode, &code)
+	return code
+}
+
+// servedAnnouncement formats the served_message template for the users a
+// !pop just served. It returns false if no template is configured.
+func (cm *CommandManager) servedAnnouncement(users []string) (string, bool) {
+	template := cm.GetConfig().Commands.Queue.ServedMessage
+	if template == "" {
+		return "", false
+	}
+	code := cm.LobbyCode()
+	if code == "" {
+		code = noLobbyCode
+	}
+	return strings.NewReplacer(
+		"{user}", formatNameList(users, cm.maxPopNames()),
+		"{lobby_code}", code,
+	).Replace(template), true
+}
+
+// HandleLobby handles the !lobby command. Everyone can see the lobby code;
+// mods and VIPs can set or clear it.
+func HandleLobby(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	if len(args) == 0 {
+		if code := cm.LobbyCode(); code != "" {
+			return fmt.Sprintf("Lobby code: %s", code)
+		}
+		return "No lobby code is set."
+	}
+
+	if !isPrivileged(message) {
+		return "Only moderators and VIPs can set the lobby code."
+	}
+
+	if strings.EqualFold(args[0], "clear") {
+		if err := cm.GetSettings().Delete(SettingLobbyCode); err != nil {
+			log.Printf("Error saving lobby code: %v", err)
+			return "Error clearing the lobby code."
+		}
+		return "Lobby code cleared."
+	}
+
+	code := strings.TrimSpace(strings.Join(args, " "))
+	if code == "" {
+		return "Usage: !lobby <code> or !lobby clear"
+	}
+	if utf8.RuneCountInString(code) > maxLobbyCodeLength {
+		return fmt.Sprintf("Lobby code is too long. Use at most %d characters.", maxLobbyCodeLength)
+	}
+	if err := cm.GetSettings().Set(SettingLobbyCode, code); err != nil {
+		log.Printf("Error saving lobby code: %v", err)
+		return "Error saving the lobby code."
+	}
+	return fmt.Sprintf("Lobby code set to %s", code)
+}
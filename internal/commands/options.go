@@ -0,0 +1,44 @@
+package commands
+
+// DefaultPrefix is the command prefix used when WithPrefix isn't given
+const DefaultPrefix = "!"
+
+// Option configures a CommandManager built with NewCommandManagerWithOptions
+type Option func(*CommandManager)
+
+// WithPrefix sets the character(s) that must prefix every command
+func WithPrefix(prefix string) Option {
+	return func(cm *CommandManager) {
+		cm.prefix = prefix
+	}
+}
+
+// WithDataPath sets the directory the queue and other channel state are saved in
+func WithDataPath(dataPath string) Option {
+	return func(cm *CommandManager) {
+		cm.dataPath = dataPath
+	}
+}
+
+// WithChannel sets the channel the manager serves, which also selects its
+// config file (configs/channels/<channel>_config_secrets.yaml)
+func WithChannel(channel string) Option {
+	return func(cm *CommandManager) {
+		cm.channel = channel
+	}
+}
+
+// WithMaxQueueSize overrides the queue max_size from the channel config.
+// The override is kept across config reloads; 0 leaves the config value.
+func WithMaxQueueSize(size int) Option {
+	return func(cm *CommandManager) {
+		cm.maxQueueSize = size
+	}
+}
+
+// WithCooldownConfig sets the cooldown given to commands registered without one
+func WithCooldownConfig(cooldown CooldownConfig) Option {
+	return func(cm *CommandManager) {
+		cm.defaultCooldown = cooldown
+	}
+}
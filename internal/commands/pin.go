@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+)
+
+// HandlePin handles the !pin command, keeping a queued user at their
+// position until they're unpinned
+func HandlePin(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	if !cm.GetQueue().IsEnabled() {
+		return "Queue system is currently disabled."
+	}
+
+	if err := requireArgs(args, 1, "!pin <username>"); err != nil {
+		return err.Error()
+	}
+
+	username := cm.GetQueue().Find(strings.TrimPrefix(args[0], "@"))
+	if username == "" || !cm.GetQueue().Pin(username) {
+		return fmt.Sprintf("%s is not in the queue.", args[0])
+	}
+	return fmt.Sprintf("Pinned %s at position %d", username, cm.GetQueue().Position(username))
+}
+
+// HandleUnpin handles the !unpin command
+func HandleUnpin(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	if !cm.GetQueue().IsEnabled() {
+		return "Queue system is currently disabled."
+	}
+
+	if err := requireArgs(args, 1, "!unpin <username>"); err != nil {
+		return err.Error()
+	}
+
+	username := cm.GetQueue().Find(strings.TrimPrefix(args[0], "@"))
+	if username == "" || !cm.GetQueue().Unpin(username) {
+		return fmt.Sprintf("%s isn't pinned.", args[0])
+	}
+	return fmt.Sprintf("Unpinned %s", username)
+}
@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"time"
+)
+
+// LatencyReporter reports the round-trip time to the chat server.
+// *twitch.Bot implements it.
+type LatencyReporter interface {
+	Latency() time.Duration
+}
+
+// SetLatencyReporter sets where !ping gets the round-trip time it reports
+func (cm *CommandManager) SetLatencyReporter(reporter LatencyReporter) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.latencyReporter = reporter
+}
+
+// chatLatency returns the last measured round-trip time to the chat server,
+// or 0 if none is known
+func (cm *CommandManager) chatLatency() time.Duration {
+	cm.mu.RLock()
+	reporter := cm.latencyReporter
+	cm.mu.RUnlock()
+	if reporter == nil {
+		return 0
+	}
+	return reporter.Latency()
+}
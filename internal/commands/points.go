@@ -0,0 +1,262 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+)
+
+// pointsLeaderboardSize is how many users !pointsleaderboard shows
+const pointsLeaderboardSize = 5
+
+// ErrNotEnoughPoints is returned when spending more points than a user has
+var ErrNotEnoughPoints = errors.New("not enough points")
+
+// PointsBalance is one user's point total
+type PointsBalance struct {
+	Username string
+	Points   int64
+}
+
+// PointsManager tracks the channel's points, keyed by lowercase username.
+// Mod adjustments and spending are saved right away; chat earnings are
+// saved by AutoSave to avoid a disk write per message.
+type PointsManager struct {
+	mu     sync.Mutex
+	path   string
+	points map[string]int64
+	dirty  bool
+}
+
+// NewPointsManager creates a points manager saved to path, loading any
+// existing balances
+func NewPointsManager(path string) *PointsManager {
+	pm := &PointsManager{
+		path:   path,
+		points: make(map[string]int64),
+	}
+	if err := pm.load(); err != nil {
+		log.Printf("Error loading points: %v", err)
+	}
+	return pm
+}
+
+// Earn gives username one point for chatting
+func (pm *PointsManager) Earn(username string) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.points[strings.ToLower(username)]++
+	pm.dirty = true
+}
+
+// Balance returns username's points
+func (pm *PointsManager) Balance(username string) int64 {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	return pm.points[strings.ToLower(username)]
+}
+
+// Add changes username's points by amount (negative to remove), never going
+// below zero, and returns the new balance
+func (pm *PointsManager) Add(username string, amount int64) (int64, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	key := strings.ToLower(username)
+	balance := pm.points[key] + amount
+	if balance < 0 {
+		balance = 0
+	}
+	pm.points[key] = balance
+	return balance, pm.saveLocked()
+}
+
+// Spend removes amount points from username, failing with
+// ErrNotEnoughPoints if they can't afford it
+func (pm *PointsManager) Spend(username string, amount int64) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	key := strings.ToLower(username)
+	if pm.points[key] < amount {
+		return ErrNotEnoughPoints
+	}
+	pm.points[key] -= amount
+	return pm.saveLocked()
+}
+
+// Leaderboard returns the n users with the most points, highest first
+func (pm *PointsManager) Leaderboard(n int) []PointsBalance {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	balances := make([]PointsBalance, 0, len(pm.points))
+	for username, points := range pm.points {
+		if points > 0 {
+			balances = append(balances, PointsBalance{Username: username, Points: points})
+		}
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		if balances[i].Points != balances[j].Points {
+			return balances[i].Points > balances[j].Points
+		}
+		return balances[i].Username < balances[j].Username
+	})
+	if len(balances) > n {
+		balances = balances[:n]
+	}
+	return balances
+}
+
+// Save writes the balances to disk
+func (pm *PointsManager) Save() error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	return pm.saveLocked()
+}
+
+// AutoSave saves earned points every interval until ctx is cancelled
+func (pm *PointsManager) AutoSave(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pm.mu.Lock()
+			var err error
+			if pm.dirty {
+				err = pm.saveLocked()
+			}
+			pm.mu.Unlock()
+			if err != nil {
+				log.Printf("Error saving points: %v", err)
+			}
+		}
+	}
+}
+
+// saveLocked writes the balances to disk. Caller must hold the lock.
+func (pm *PointsManager) saveLocked() error {
+	// Ensure the data directory exists
+	if err := os.MkdirAll(filepath.Dir(pm.path), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(pm.points, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal points: %w", err)
+	}
+
+	if err := os.WriteFile(pm.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write points: %w", err)
+	}
+	pm.dirty = false
+	return nil
+}
+
+// load reads saved balances, if any
+func (pm *PointsManager) load() error {
+	data, err := os.ReadFile(pm.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read points: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &pm.points); err != nil {
+		return fmt.Errorf("failed to parse points: %w", err)
+	}
+	return nil
+}
+
+// GetPoints returns the channel's points manager
+func (cm *CommandManager) GetPoints() *PointsManager {
+	return cm.points
+}
+
+// chargeJoinCost takes the queue.join_cost from username. It returns a reply
+// to send instead of joining if they can't afford it.
+func (cm *CommandManager) chargeJoinCost(username string) (int64, string) {
+	cost := cm.GetConfig().Commands.Queue.JoinCost
+	if cost <= 0 {
+		return 0, ""
+	}
+	if err := cm.points.Spend(username, cost); err != nil {
+		if errors.Is(err, ErrNotEnoughPoints) {
+			return 0, fmt.Sprintf("@%s, joining the queue costs %d points. You have %d.", username, cost, cm.points.Balance(username))
+		}
+		log.Printf("Error charging join cost: %v", err)
+	}
+	return cost, ""
+}
+
+// parsePointsArgs parses "<user> <amount>" for !addpoints and !removepoints
+func parsePointsArgs(args []string, usage string) (string, int64, error) {
+	if err := requireArgs(args, 2, usage); err != nil {
+		return "", 0, err
+	}
+	amount, err := parsePositiveInt(args[1], "amount")
+	if err != nil {
+		return "", 0, err
+	}
+	return strings.TrimPrefix(args[0], "@"), int64(amount), nil
+}
+
+// HandlePoints handles the !points command
+func HandlePoints(message twitch.PrivateMessage, args []string) string {
+	balance := GetCommandManager().GetPoints().Balance(message.User.Name)
+	return fmt.Sprintf("@%s, you have %d points.", message.User.Name, balance)
+}
+
+// HandleAddPoints handles the !addpoints command
+func HandleAddPoints(message twitch.PrivateMessage, args []string) string {
+	username, amount, err := parsePointsArgs(args, "!addpoints <user> <amount>")
+	if err != nil {
+		return err.Error()
+	}
+	balance, err := GetCommandManager().GetPoints().Add(username, amount)
+	if err != nil {
+		log.Printf("Error saving points: %v", err)
+	}
+	return fmt.Sprintf("Gave %s %d points. They now have %d.", username, amount, balance)
+}
+
+// HandleRemovePoints handles the !removepoints command
+func HandleRemovePoints(message twitch.PrivateMessage, args []string) string {
+	username, amount, err := parsePointsArgs(args, "!removepoints <user> <amount>")
+	if err != nil {
+		return err.Error()
+	}
+	balance, err := GetCommandManager().GetPoints().Add(username, -amount)
+	if err != nil {
+		log.Printf("Error saving points: %v", err)
+	}
+	return fmt.Sprintf("Took %d points from %s. They now have %d.", amount, username, balance)
+}
+
+// HandlePointsLeaderboard handles the !pointsleaderboard command
+func HandlePointsLeaderboard(message twitch.PrivateMessage, args []string) string {
+	top := GetCommandManager().GetPoints().Leaderboard(pointsLeaderboardSize)
+	if len(top) == 0 {
+		return "Nobody has any points yet."
+	}
+	parts := make([]string, len(top))
+	for i, entry := range top {
+		parts[i] = fmt.Sprintf("%d. %s (%d)", i+1, entry.Username, entry.Points)
+	}
+	return "Top points: " + strings.Join(parts, ", ")
+}
@@ -0,0 +1,283 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+)
+
+// DefaultPollDuration is how long a straw poll stays open
+const DefaultPollDuration = 60 * time.Second
+
+// pollMaxOptions is the most options a straw poll can have
+const pollMaxOptions = 5
+
+var (
+	// ErrPollRunning is returned when opening a poll while one is open
+	ErrPollRunning = errors.New("a poll is already running")
+	// ErrNoPoll is returned when voting or closing with no open poll
+	ErrNoPoll = errors.New("no poll is running")
+	// ErrAlreadyVoted is returned when a user votes a second time
+	ErrAlreadyVoted = errors.New("already voted")
+)
+
+// Poll is an open straw poll
+type Poll struct {
+	Question string
+	Options  []string
+	// Each voter's chosen option (lowercase username -> 1-based option)
+	Votes map[string]int
+}
+
+// PollManager runs straw polls, announcing results when a poll closes
+type PollManager struct {
+	mu       sync.Mutex
+	send     func(string)
+	duration time.Duration
+	poll     *Poll
+	timer    *time.Timer
+}
+
+// NewPollManager creates a poll manager that announces results using send
+func NewPollManager(send func(string)) *PollManager {
+	return &PollManager{
+		send:     send,
+		duration: DefaultPollDuration,
+	}
+}
+
+// SetDuration sets how long new polls stay open
+func (pm *PollManager) SetDuration(d time.Duration) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.duration = d
+}
+
+// Open starts a poll that closes automatically after the poll duration
+func (pm *PollManager) Open(question string, options []string) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if pm.poll != nil {
+		return ErrPollRunning
+	}
+	poll := &Poll{
+		Question: question,
+		Options:  options,
+		Votes:    make(map[string]int),
+	}
+	pm.poll = poll
+	pm.timer = time.AfterFunc(pm.duration, func() {
+		if results, err := pm.closePoll(poll); err == nil {
+			pm.send(results)
+		}
+	})
+	return nil
+}
+
+// Vote records username's choice (1-based). Each user can vote once.
+func (pm *PollManager) Vote(username string, option int) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if pm.poll == nil {
+		return ErrNoPoll
+	}
+	if option < 1 || option > len(pm.poll.Options) {
+		return fmt.Errorf("option must be between 1 and %d", len(pm.poll.Options))
+	}
+	key := strings.ToLower(username)
+	if _, voted := pm.poll.Votes[key]; voted {
+		return ErrAlreadyVoted
+	}
+	pm.poll.Votes[key] = option
+	return nil
+}
+
+// Close ends the open poll early and returns the results
+func (pm *PollManager) Close() (string, error) {
+	pm.mu.Lock()
+	poll := pm.poll
+	pm.mu.Unlock()
+
+	if poll == nil {
+		return "", ErrNoPoll
+	}
+	return pm.closePoll(poll)
+}
+
+// Duration returns how long new polls stay open
+func (pm *PollManager) Duration() time.Duration {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	return pm.duration
+}
+
+// closePoll closes poll if it is still the open poll and returns its results
+func (pm *PollManager) closePoll(poll *Poll) (string, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if pm.poll != poll {
+		return "", ErrNoPoll
+	}
+	if pm.timer != nil {
+		pm.timer.Stop()
+		pm.timer = nil
+	}
+	pm.poll = nil
+	return FormatPollResults(poll.Options, poll.Votes), nil
+}
+
+// FormatPollResults formats vote counts as
+// "Poll results — a: 34 (47%), b: 28 (39%) — Winner: a!"
+func FormatPollResults(options []string, votes map[string]int) string {
+	counts := make([]int, len(options))
+	for _, option := range votes {
+		if option >= 1 && option <= len(options) {
+			counts[option-1]++
+		}
+	}
+
+	total := 0
+	best := 0
+	for _, count := range counts {
+		total += count
+		if count > best {
+			best = count
+		}
+	}
+	if total == 0 {
+		return "Poll results — no votes were cast."
+	}
+
+	parts := make([]string, len(options))
+	var winners []string
+	for i, option := range options {
+		percent := int(math.Round(float64(counts[i]) * 100 / float64(total)))
+		parts[i] = fmt.Sprintf("%s: %d (%d%%)", option, counts[i], percent)
+		if counts[i] == best {
+			winners = append(winners, option)
+		}
+	}
+
+	if len(winners) > 1 {
+		return fmt.Sprintf("Poll results — %s — Tie: %s!", strings.Join(parts, ", "), strings.Join(winners, ", "))
+	}
+	return fmt.Sprintf("Poll results — %s — Winner: %s!", strings.Join(parts, ", "), winners[0])
+}
+
+// splitQuoted splits s on whitespace, keeping "double quoted" phrases together
+func splitQuoted(s string) []string {
+	var fields []string
+	var current strings.Builder
+	inQuotes := false
+	hasField := false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			hasField = true
+		case unicode.IsSpace(r) && !inQuotes:
+			if hasField {
+				fields = append(fields, current.String())
+				current.Reset()
+				hasField = false
+			}
+		default:
+			current.WriteRune(r)
+			hasField = true
+		}
+	}
+	if hasField {
+		fields = append(fields, current.String())
+	}
+	return fields
+}
+
+// SetPollManager sets the manager used by !strawpoll, !vote and !endpoll
+func (cm *CommandManager) SetPollManager(polls *PollManager) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.polls = polls
+}
+
+// getPollManager returns the poll manager, or nil if polls aren't available
+func (cm *CommandManager) getPollManager() *PollManager {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.polls
+}
+
+// HandleStrawPoll handles the !strawpoll command
+func HandleStrawPoll(message twitch.PrivateMessage, args []string) string {
+	polls := GetCommandManager().getPollManager()
+	if polls == nil {
+		return "Polls are not available."
+	}
+
+	fields := splitQuoted(echoText(message.Message))
+	if len(fields) < 3 || len(fields) > pollMaxOptions+1 {
+		return fmt.Sprintf(`Usage: !strawpoll "<question>" <option1> <option2> [...] (2-%d options)`, pollMaxOptions)
+	}
+	question, options := fields[0], fields[1:]
+
+	if err := polls.Open(question, options); err != nil {
+		return "A poll is already running. Use !endpoll to close it first."
+	}
+
+	choices := make([]string, len(options))
+	for i, option := range options {
+		choices[i] = fmt.Sprintf("!vote %d (%s)", i+1, option)
+	}
+	return fmt.Sprintf("Poll: %s — vote with %s — closes in %s", question, strings.Join(choices, ", "), formatCountdown(polls.Duration()))
+}
+
+// HandleVote handles the !vote command
+func HandleVote(message twitch.PrivateMessage, args []string) string {
+	polls := GetCommandManager().getPollManager()
+	if polls == nil {
+		return "Polls are not available."
+	}
+	if len(args) < 1 {
+		return "Usage: !vote <option number>"
+	}
+
+	option, err := strconv.Atoi(args[0])
+	if err != nil {
+		return "Usage: !vote <option number>"
+	}
+
+	switch err := polls.Vote(message.User.Name, option); {
+	case err == nil:
+		// Stay quiet on successful votes to avoid flooding chat
+		return ""
+	case errors.Is(err, ErrNoPoll):
+		return "There is no poll running."
+	case errors.Is(err, ErrAlreadyVoted):
+		return fmt.Sprintf("@%s, you've already voted in this poll.", message.User.Name)
+	default:
+		return fmt.Sprintf("@%s, %v.", message.User.Name, err)
+	}
+}
+
+// HandleEndPoll handles the !endpoll command
+func HandleEndPoll(message twitch.PrivateMessage, args []string) string {
+	polls := GetCommandManager().getPollManager()
+	if polls == nil {
+		return "Polls are not available."
+	}
+
+	results, err := polls.Close()
+	if err != nil {
+		return "There is no poll running."
+	}
+	return results
+}
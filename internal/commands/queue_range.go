@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// allFlag asks !queue to write the whole queue to a file
+const allFlag = "--all"
+
+// parseQueueRange parses a "<start>-<end>" position range. ok is false if
+// arg isn't a range at all.
+func parseQueueRange(arg string) (start, end int, ok bool, err error) {
+	from, to, found := strings.Cut(arg, "-")
+	if !found {
+		return 0, 0, false, nil
+	}
+	start, startErr := strconv.Atoi(from)
+	end, endErr := strconv.Atoi(to)
+	if startErr != nil || endErr != nil {
+		return 0, 0, false, nil
+	}
+
+	if start < 1 {
+		return 0, 0, true, fmt.Errorf("Invalid range. Positions start at 1.")
+	}
+	if end < start {
+		return 0, 0, true, fmt.Errorf("Invalid range. The end must not be before the start.")
+	}
+	return start, end, true, nil
+}
+
+// formatQueueRange lists positions start through end of users, clamping end
+// to the queue size, e.g. "Queue (pos 5-10 of 25): 5) user5, ..., 10) user10."
+func formatQueueRange(users []string, start, end int) string {
+	if start > len(users) {
+		return fmt.Sprintf("The queue only has %d user(s).", len(users))
+	}
+	if end > len(users) {
+		end = len(users)
+	}
+
+	entries := make([]string, 0, end-start+1)
+	for pos := start; pos <= end; pos++ {
+		entries = append(entries, fmt.Sprintf("%d) %s", pos, users[pos-1]))
+	}
+	return fmt.Sprintf("Queue (pos %d-%d of %d): %s.", start, end, len(users), strings.Join(entries, ", "))
+}
+
+// DumpQueue writes the full queue, one numbered user per line, to a
+// timestamped file in the data path. Returns the file name.
+func (cm *CommandManager) DumpQueue() (string, error) {
+	var sb strings.Builder
+	for i, user := range cm.queue.List() {
+		fmt.Fprintf(&sb, "%d) %s\n", i+1, user)
+	}
+
+	// Ensure the data directory exists
+	dataPath := cm.queue.GetDataPath()
+	if err := os.MkdirAll(dataPath, 0755); err != nil {
+		return "", fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	filename := fmt.Sprintf("queue_dump_%s_%s.txt", cm.channel, time.Now().Format("20060102_150405"))
+	if err := os.WriteFile(filepath.Join(dataPath, filename), []byte(sb.String()), 0644); err != nil {
+		return "", fmt.Errorf("failed to write queue dump: %w", err)
+	}
+
+	return filename, nil
+}
@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+)
+
+// HandleQueueDupes handles the !queuedupes command, reporting queue entries
+// that look like the same user spelled slightly differently
+func HandleQueueDupes(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	if !cm.GetQueue().IsEnabled() {
+		return "Queue system is currently disabled."
+	}
+
+	dupes := cm.GetQueue().Duplicates()
+	if len(dupes) == 0 {
+		return "No duplicate entries in the queue."
+	}
+
+	// Quote each name so hidden characters and stray spaces are visible
+	groups := make([]string, len(dupes))
+	for i, group := range dupes {
+		entries := make([]string, len(group))
+		for j, entry := range group {
+			entries[j] = fmt.Sprintf("#%d %q", entry.Position, entry.Username)
+		}
+		groups[i] = strings.Join(entries, " = ")
+	}
+	return fmt.Sprintf("Possible duplicates (%d): %s. Use !remove <position> to clean up.", len(dupes), strings.Join(groups, "; "))
+}
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+)
+
+// queueTextSeparators are the separators !queuetext accepts by name
+var queueTextSeparators = map[string]string{
+	"comma": ",",
+	"pipe":  "|",
+}
+
+// HandleQueueText handles the !queuetext command, whispering the caller the
+// queue as one separated line for pasting into a spreadsheet
+func HandleQueueText(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	if !cm.GetQueue().IsEnabled() {
+		return "Queue system is currently disabled."
+	}
+
+	separator := ","
+	if len(args) > 0 {
+		var ok bool
+		if separator, ok = queueTextSeparators[strings.ToLower(args[0])]; !ok {
+			return "Usage: !queuetext [comma|pipe]"
+		}
+	}
+
+	users := cm.GetQueue().List()
+	if len(users) == 0 {
+		return "Queue is empty."
+	}
+
+	parts := joinLines(users, separator, maxChatResponseLength)
+	for _, part := range parts {
+		if !cm.whisper(message.User.Name, part) {
+			return "Couldn't whisper you the queue. Check that the bot can send whispers."
+		}
+	}
+	if len(parts) > 1 {
+		return fmt.Sprintf("Whispered the queue (%d users) to @%s in %d parts.", len(users), message.User.Name, len(parts))
+	}
+	return fmt.Sprintf("Whispered the queue (%d users) to @%s.", len(users), message.User.Name)
+}
+
+// joinLines joins items with separator into as few lines as possible, each
+// at most limit bytes. Items are never split across lines.
+func joinLines(items []string, separator string, limit int) []string {
+	var lines []string
+	var line strings.Builder
+	for _, item := range items {
+		if line.Len() > 0 && line.Len()+len(separator)+len(item) > limit {
+			lines = append(lines, line.String())
+			line.Reset()
+		}
+		if line.Len() > 0 {
+			line.WriteString(separator)
+		}
+		line.WriteString(item)
+	}
+	if line.Len() > 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/gempir/go-twitch-irc/v4"
+	channelstats "github.com/pbuckles22/PBChatBot/internal/channel"
+)
+
+// RegisterRankCommand registers the rank command
+func RegisterRankCommand(cm *CommandManager, stats *channelstats.ChannelStats) {
+	cm.RegisterCommand(&Command{
+		Name:        "rank",
+		Category:    CategoryStats,
+		Description: "Shows a user's all-time chat rank",
+		Handler: func(message twitch.PrivateMessage, args []string) string {
+			return HandleRank(stats, message, args)
+		},
+	})
+}
+
+// HandleRank handles the !rank command. Mods can pass a username to look up
+// someone else.
+func HandleRank(stats *channelstats.ChannelStats, message twitch.PrivateMessage, args []string) string {
+	if len(args) > 0 && !isModerator(message) {
+		return "Only moderators can look up another user's rank."
+	}
+
+	if len(args) == 0 {
+		rank, chatters, messages := stats.GetChatterRank(message.User.Name)
+		return fmt.Sprintf("@%s, you're rank %d of %d chatters (%d messages).", message.User.Name, rank, chatters, messages)
+	}
+
+	rank, chatters, messages := stats.GetChatterRank(args[0])
+	return fmt.Sprintf("%s is rank %d of %d chatters (%d messages).", args[0], rank, chatters, messages)
+}
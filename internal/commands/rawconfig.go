@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gempir/go-twitch-irc/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// RawConfig writes the config as YAML, with secrets redacted (see
+// config.Config.Redacted), to a timestamped file in the data path. If field is non-empty
+// (e.g. "commands.cooldowns"), only that section is written. Returns the
+// file name.
+func (cm *CommandManager) RawConfig(field string) (string, error) {
+	cfg := cm.GetConfig()
+	if cfg == nil {
+		return "", fmt.Errorf("no config loaded")
+	}
+
+	var root yaml.Node
+	if err := root.Encode(cfg.Redacted()); err != nil {
+		return "", fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	out := &root
+	if field != "" {
+		if out = findConfigNode(&root, field); out == nil {
+			return "", fmt.Errorf("no config field %q", field)
+		}
+	}
+
+	data, err := yaml.Marshal(out)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	// Ensure the data directory exists
+	dataPath := cm.queue.GetDataPath()
+	if err := os.MkdirAll(dataPath, 0755); err != nil {
+		return "", fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	filename := fmt.Sprintf("rawconfig_%s_%s.yaml", cm.channel, time.Now().Format("20060102_150405"))
+	if err := os.WriteFile(filepath.Join(dataPath, filename), data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write raw config: %w", err)
+	}
+
+	return filename, nil
+}
+
+// findConfigNode returns the value node at a dotted YAML path such as
+// "commands.queue.max_size", or nil if there is no such field
+func findConfigNode(node *yaml.Node, path string) *yaml.Node {
+	for _, key := range strings.Split(path, ".") {
+		if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+			node = node.Content[0]
+		}
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+// HandleRawConfig handles the !rawconfig command
+func HandleRawConfig(message twitch.PrivateMessage, args []string) string {
+	if !isBroadcaster(message) {
+		return "This command can only be used by the broadcaster."
+	}
+
+	field := ""
+	if len(args) > 0 {
+		field = strings.ToLower(args[0])
+	}
+
+	filename, err := GetCommandManager().RawConfig(field)
+	if err != nil {
+		return fmt.Sprintf("Error writing raw config: %v", err)
+	}
+	return fmt.Sprintf("Raw config written to %s.", filename)
+}
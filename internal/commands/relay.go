@@ -0,0 +1,247 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+)
+
+// relayMaxPerSecond caps how many messages are forwarded per second
+const relayMaxPerSecond = 5
+
+// ChannelSender sends chat messages to a channel other than the bot's own,
+// joining it on first use, and leaves it again when the relay stops
+type ChannelSender interface {
+	SayTo(channel, message string)
+	Depart(channel string)
+}
+
+// SetChannelSender sets how relayed messages are delivered
+func (cm *CommandManager) SetChannelSender(sender ChannelSender) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.channelSender = sender
+}
+
+// MessageRelay forwards non-command chat from one channel to another
+type MessageRelay struct {
+	Source string
+	Target string
+
+	mu sync.Mutex
+	// Send times within the last second, for rate limiting
+	sent []time.Time
+}
+
+// NewMessageRelay creates a relay from source to target
+func NewMessageRelay(source, target string) *MessageRelay {
+	return &MessageRelay{
+		Source: strings.ToLower(source),
+		Target: strings.ToLower(target),
+	}
+}
+
+// Format returns the relayed form of a chat message
+func (r *MessageRelay) Format(message twitch.PrivateMessage) string {
+	user := message.User.DisplayName
+	if user == "" {
+		user = message.User.Name
+	}
+	return fmt.Sprintf("[relay: %s] %s: %s", r.Source, user, message.Message)
+}
+
+// allow reports whether another message may be relayed at now, recording it if so
+func (r *MessageRelay) allow(now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := now.Add(-time.Second)
+	kept := r.sent[:0]
+	for _, t := range r.sent {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	r.sent = kept
+
+	if len(r.sent) >= relayMaxPerSecond {
+		return false
+	}
+	r.sent = append(r.sent, now)
+	return true
+}
+
+// relayState is the on-disk form of the active relay
+type relayState struct {
+	Target string `json:"target"`
+}
+
+// relayFile returns the path of the channel's relay config file
+func (cm *CommandManager) relayFile() string {
+	return filepath.Join(cm.queue.GetDataPath(), fmt.Sprintf("relay_%s.json", cm.channel))
+}
+
+// StartRelay starts forwarding this channel's chat to target and persists it
+func (cm *CommandManager) StartRelay(target string) error {
+	target = strings.ToLower(strings.TrimPrefix(target, "#"))
+	if target == "" {
+		return errors.New("no target channel given")
+	}
+	if target == strings.ToLower(cm.channel) {
+		return errors.New("can't relay a channel to itself")
+	}
+
+	cm.mu.Lock()
+	if cm.relay != nil {
+		current := cm.relay.Target
+		cm.mu.Unlock()
+		return fmt.Errorf("already relaying to #%s", current)
+	}
+	cm.relay = NewMessageRelay(cm.channel, target)
+	cm.mu.Unlock()
+
+	if err := cm.saveRelay(target); err != nil {
+		cm.mu.Lock()
+		cm.relay = nil
+		cm.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// StopRelay stops the active relay, returning the channel it was sending to
+func (cm *CommandManager) StopRelay() (string, error) {
+	cm.mu.Lock()
+	if cm.relay == nil {
+		cm.mu.Unlock()
+		return "", errors.New("no relay is running")
+	}
+	target := cm.relay.Target
+	cm.relay = nil
+	sender := cm.channelSender
+	cm.mu.Unlock()
+
+	if sender != nil {
+		sender.Depart(target)
+	}
+	return target, cm.saveRelay("")
+}
+
+// GetRelayTarget returns the channel chat is being relayed to, or "" if none
+func (cm *CommandManager) GetRelayTarget() string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	if cm.relay == nil {
+		return ""
+	}
+	return cm.relay.Target
+}
+
+// relayMessage forwards a non-command message to the relay target, if any.
+// It returns false if no relay is running or the rate limit dropped it.
+func (cm *CommandManager) relayMessage(message twitch.PrivateMessage) bool {
+	cm.mu.RLock()
+	relay := cm.relay
+	sender := cm.channelSender
+	cm.mu.RUnlock()
+
+	if relay == nil || sender == nil {
+		return false
+	}
+	if !relay.allow(time.Now()) {
+		log.Printf("[Relay] Rate limit reached, dropping message from %s", message.User.Name)
+		return false
+	}
+	sender.SayTo(relay.Target, relay.Format(message))
+	return true
+}
+
+// saveRelay persists the relay target ("" when stopped)
+func (cm *CommandManager) saveRelay(target string) error {
+	if target == "" {
+		if err := os.Remove(cm.relayFile()); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove relay config: %w", err)
+		}
+		return nil
+	}
+
+	// Ensure the data directory exists
+	if err := os.MkdirAll(cm.queue.GetDataPath(), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(relayState{Target: target}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal relay config: %w", err)
+	}
+
+	if err := os.WriteFile(cm.relayFile(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write relay config: %w", err)
+	}
+
+	return nil
+}
+
+// LoadRelay restores a relay saved by a previous run
+func (cm *CommandManager) LoadRelay() error {
+	data, err := os.ReadFile(cm.relayFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read relay config: %w", err)
+	}
+
+	var state relayState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse relay config: %w", err)
+	}
+	if state.Target == "" {
+		return nil
+	}
+
+	cm.mu.Lock()
+	cm.relay = NewMessageRelay(cm.channel, state.Target)
+	cm.mu.Unlock()
+	return nil
+}
+
+// HandleStartRelay handles the !startrelay command
+func HandleStartRelay(message twitch.PrivateMessage, args []string) string {
+	if !isBroadcaster(message) {
+		return "This command can only be used by the broadcaster."
+	}
+	if len(args) < 1 {
+		return "Usage: !startrelay <channel>"
+	}
+
+	cm := GetCommandManager()
+	if err := cm.StartRelay(args[0]); err != nil {
+		return fmt.Sprintf("Could not start relay: %v", err)
+	}
+	return fmt.Sprintf("Relaying chat to #%s. Use !stoprelay to stop.", cm.GetRelayTarget())
+}
+
+// HandleStopRelay handles the !stoprelay command
+func HandleStopRelay(message twitch.PrivateMessage, args []string) string {
+	if !isBroadcaster(message) {
+		return "This command can only be used by the broadcaster."
+	}
+
+	target, err := GetCommandManager().StopRelay()
+	if target == "" {
+		return "No relay is running."
+	}
+	if err != nil {
+		log.Printf("Error saving relay config: %v", err)
+	}
+	return fmt.Sprintf("Stopped relaying chat to #%s.", target)
+}
@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+	"github.com/pbuckles22/PBChatBot/internal/queue"
+)
+
+// HandleReserve handles the !reserve command, holding a queue slot for a user
+// who hasn't arrived yet
+func HandleReserve(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	if !cm.GetQueue().IsEnabled() {
+		return "Queue system is currently disabled."
+	}
+
+	if err := requireArgs(args, 1, "!reserve <username> [position]"); err != nil {
+		return err.Error()
+	}
+
+	username := args[0]
+	position := 1
+	if len(args) > 1 {
+		var err error
+		if position, err = parsePositiveInt(args[1], "position"); err != nil {
+			return err.Error()
+		}
+	}
+
+	if err := cm.GetQueue().AddAtPosition(username, position, true); errors.Is(err, queue.ErrQueueFull) {
+		return fmt.Sprintf("The queue is full (%d max), so no slot can be reserved.", cm.GetQueue().MaxSize())
+	} else if err != nil {
+		return fmt.Sprintf("Error reserving slot: %v", err)
+	}
+	cm.GetQueue().SetReserved(username, true)
+
+	return fmt.Sprintf("Reserved position %d for %s", cm.GetQueue().Position(username), username)
+}
+
+// HandleUnreserve handles the !unreserve command, releasing a reserved slot
+func HandleUnreserve(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	if !cm.GetQueue().IsEnabled() {
+		return "Queue system is currently disabled."
+	}
+
+	if err := requireArgs(args, 1, "!unreserve <username>"); err != nil {
+		return err.Error()
+	}
+
+	username := cm.GetQueue().Find(args[0])
+	if username == "" || !cm.GetQueue().IsReserved(username) {
+		return fmt.Sprintf("%s doesn't have a reserved slot.", args[0])
+	}
+
+	cm.GetQueue().Remove(username)
+	return fmt.Sprintf("Released reserved slot for %s", username)
+}
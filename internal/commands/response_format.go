@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"encoding/json"
+
+	twitchirc "github.com/gempir/go-twitch-irc/v4"
+)
+
+// ResponseFormat selects how a command formats its reply
+type ResponseFormat int
+
+const (
+	// ResponseFormatText is the normal human-readable reply
+	ResponseFormatText ResponseFormat = iota
+	// ResponseFormatJSON is a compact JSON reply for overlays reading chat
+	ResponseFormatJSON
+)
+
+// jsonFlag requests a ResponseFormatJSON reply
+const jsonFlag = "--json"
+
+// maxChatResponseLength is the longest reply Twitch accepts in one message
+const maxChatResponseLength = 500
+
+// RequestedFormat returns the format requested by args. The --json flag is
+// honoured for moderators only; everyone else gets the text reply.
+func (cm *CommandManager) RequestedFormat(message twitchirc.PrivateMessage, args []string) ResponseFormat {
+	for _, arg := range args {
+		if arg == jsonFlag && isModerator(message) {
+			return ResponseFormatJSON
+		}
+	}
+	return ResponseFormatText
+}
+
+// queueJSON is the !queue --json reply
+type queueJSON struct {
+	Size      int      `json:"size"`
+	Users     []string `json:"users"`
+	Truncated bool     `json:"truncated,omitempty"`
+}
+
+// formatQueueJSON encodes users as a queueJSON reply that fits in one chat
+// message. Users are dropped from the end of the list until it fits, and the
+// reply is marked truncated; size always counts the whole queue.
+func formatQueueJSON(users []string) string {
+	reply := queueJSON{Size: len(users), Users: users}
+	if reply.Users == nil {
+		reply.Users = []string{}
+	}
+	for {
+		data, err := json.Marshal(reply)
+		if err != nil || len(data) <= maxChatResponseLength || len(reply.Users) == 0 {
+			return string(data)
+		}
+		reply.Users = reply.Users[:len(reply.Users)-1]
+		reply.Truncated = true
+	}
+}
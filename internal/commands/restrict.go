@@ -0,0 +1,255 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gempir/go-twitch-irc/v4"
+)
+
+// UsageLimit caps how many times a user may run a command
+type UsageLimit struct {
+	MaxUses int `json:"max_uses"`
+	Uses    int `json:"uses"`
+}
+
+// Restrict caps username's uses of commandName at maxUses, starting the
+// count from zero
+func (cm *CooldownManager) Restrict(username, commandName string, maxUses int) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	user := strings.ToLower(username)
+	if cm.restrictions == nil {
+		cm.restrictions = make(map[string]map[string]*UsageLimit)
+	}
+	if cm.restrictions[user] == nil {
+		cm.restrictions[user] = make(map[string]*UsageLimit)
+	}
+	cm.restrictions[user][commandName] = &UsageLimit{MaxUses: maxUses}
+}
+
+// Unrestrict lifts username's cap on commandName. It returns false if there
+// was none.
+func (cm *CooldownManager) Unrestrict(username, commandName string) bool {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	user := strings.ToLower(username)
+	if _, ok := cm.restrictions[user][commandName]; !ok {
+		return false
+	}
+	delete(cm.restrictions[user], commandName)
+	if len(cm.restrictions[user]) == 0 {
+		delete(cm.restrictions, user)
+	}
+	return true
+}
+
+// UseRestricted counts a use of commandName against the user's cap, if they
+// have one. It returns counted=true when a use was recorded and
+// allowed=false once the cap has been reached. The broadcaster is never
+// restricted.
+func (cm *CooldownManager) UseRestricted(commandName string, message twitch.PrivateMessage) (allowed, counted bool) {
+	if GetUserType(message) == UserTypeBroadcaster {
+		return true, false
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	limit, ok := cm.restrictions[strings.ToLower(message.User.Name)][commandName]
+	if !ok {
+		return true, false
+	}
+	if limit.Uses >= limit.MaxUses {
+		return false, false
+	}
+	limit.Uses++
+	return true, true
+}
+
+// ResetUses starts every cap's use count over from zero
+func (cm *CooldownManager) ResetUses() {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	for _, limits := range cm.restrictions {
+		for _, limit := range limits {
+			limit.Uses = 0
+		}
+	}
+}
+
+// Restrictions returns a copy of the usage caps keyed by lowercase username
+// and command name
+func (cm *CooldownManager) Restrictions() map[string]map[string]UsageLimit {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	restrictions := make(map[string]map[string]UsageLimit, len(cm.restrictions))
+	for user, limits := range cm.restrictions {
+		restrictions[user] = make(map[string]UsageLimit, len(limits))
+		for command, limit := range limits {
+			restrictions[user][command] = *limit
+		}
+	}
+	return restrictions
+}
+
+// SetRestrictions replaces the usage caps with restrictions
+func (cm *CooldownManager) SetRestrictions(restrictions map[string]map[string]UsageLimit) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cm.restrictions = make(map[string]map[string]*UsageLimit, len(restrictions))
+	for user, limits := range restrictions {
+		user = strings.ToLower(user)
+		cm.restrictions[user] = make(map[string]*UsageLimit, len(limits))
+		for command, limit := range limits {
+			limit := limit
+			cm.restrictions[user][command] = &limit
+		}
+	}
+}
+
+// restrictionsFile returns the path usage caps are saved to
+func (cm *CommandManager) restrictionsFile() string {
+	return filepath.Join(cm.queue.GetDataPath(), fmt.Sprintf("restrictions_%s.json", cm.channel))
+}
+
+// SaveRestrictions persists the usage caps and their counts
+func (cm *CommandManager) SaveRestrictions() error {
+	// Ensure the data directory exists
+	if err := os.MkdirAll(cm.queue.GetDataPath(), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(cm.cooldown.Restrictions(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal restrictions: %w", err)
+	}
+
+	if err := os.WriteFile(cm.restrictionsFile(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write restrictions file: %w", err)
+	}
+
+	return nil
+}
+
+// LoadRestrictions restores the usage caps saved by a previous run
+func (cm *CommandManager) LoadRestrictions() error {
+	data, err := os.ReadFile(cm.restrictionsFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read restrictions file: %w", err)
+	}
+
+	var restrictions map[string]map[string]UsageLimit
+	if err := json.Unmarshal(data, &restrictions); err != nil {
+		return fmt.Errorf("failed to parse restrictions file: %w", err)
+	}
+	cm.cooldown.SetRestrictions(restrictions)
+	return nil
+}
+
+// AutoSaveRestrictions saves counted uses every interval until ctx is
+// cancelled. Caps set or lifted by mods are saved right away; uses are
+// batched here to avoid a disk write per command.
+func (cm *CommandManager) AutoSaveRestrictions(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !cm.restrictionUsesDirty.Swap(false) {
+				continue
+			}
+			if err := cm.SaveRestrictions(); err != nil {
+				cm.restrictionUsesDirty.Store(true)
+				log.Printf("Error saving restrictions: %v", err)
+			}
+		}
+	}
+}
+
+// ResetRestrictionUses starts every !restrict cap over for a new stream
+func (cm *CommandManager) ResetRestrictionUses() {
+	cm.cooldown.ResetUses()
+	if err := cm.SaveRestrictions(); err != nil {
+		log.Printf("Error saving restrictions: %v", err)
+	}
+}
+
+// checkRestriction counts a use of command against the user's cap and
+// returns the reply for a user who has reached it, or "" if they may run it
+func (cm *CommandManager) checkRestriction(command *Command, message twitch.PrivateMessage) string {
+	allowed, counted := cm.cooldown.UseRestricted(command.Name, message)
+	if counted {
+		cm.restrictionUsesDirty.Store(true)
+	}
+	if !allowed {
+		return fmt.Sprintf("@%s, you've reached your limit for %s%s this session.", message.User.Name, cm.prefix, command.Name)
+	}
+	return ""
+}
+
+// HandleRestrict handles the !restrict command
+func HandleRestrict(message twitch.PrivateMessage, args []string) string {
+	if len(args) < 3 {
+		return "Usage: !restrict <username> <command> <maxuses>"
+	}
+
+	cm := GetCommandManager()
+	username := strings.TrimPrefix(args[0], "@")
+	name := strings.TrimPrefix(args[1], cm.prefix)
+	cmd, exists := cm.lookupCommand(name)
+	if !exists {
+		return fmt.Sprintf("Unknown command: %s%s", cm.prefix, name)
+	}
+	maxUses, err := strconv.Atoi(args[2])
+	if err != nil || maxUses < 0 {
+		return "Max uses must be a whole number of 0 or more."
+	}
+
+	cm.cooldown.Restrict(username, cmd.Name, maxUses)
+	if err := cm.SaveRestrictions(); err != nil {
+		log.Printf("Error saving restrictions: %v", err)
+	}
+	return fmt.Sprintf("%s can use %s%s %d more time(s) this session.", username, cm.prefix, cmd.Name, maxUses)
+}
+
+// HandleUnrestrict handles the !unrestrict command
+func HandleUnrestrict(message twitch.PrivateMessage, args []string) string {
+	if len(args) < 2 {
+		return "Usage: !unrestrict <username> <command>"
+	}
+
+	cm := GetCommandManager()
+	username := strings.TrimPrefix(args[0], "@")
+	name := strings.TrimPrefix(args[1], cm.prefix)
+	cmd, exists := cm.lookupCommand(name)
+	if !exists {
+		return fmt.Sprintf("Unknown command: %s%s", cm.prefix, name)
+	}
+
+	if !cm.cooldown.Unrestrict(username, cmd.Name) {
+		return fmt.Sprintf("%s has no limit on %s%s.", username, cm.prefix, cmd.Name)
+	}
+	if err := cm.SaveRestrictions(); err != nil {
+		log.Printf("Error saving restrictions: %v", err)
+	}
+	return fmt.Sprintf("Lifted the %s%s limit for %s.", cm.prefix, cmd.Name, username)
+}
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	twitchirc "github.com/gempir/go-twitch-irc/v4"
+	twitchbot "github.com/pbuckles22/PBChatBot/internal/twitch"
+)
+
+// RoomModeReporter reports the channel's current chat restrictions.
+// *twitch.Bot implements it.
+type RoomModeReporter interface {
+	RoomModes() (twitchbot.RoomModes, bool)
+}
+
+// RegisterRoomModeCommand registers the roommode command
+func RegisterRoomModeCommand(cm *CommandManager, reporter RoomModeReporter) {
+	cm.RegisterCommand(&Command{
+		Name:        "roommode",
+		Category:    CategoryModeration,
+		Description: "Show the channel's current chat restrictions",
+		ModOnly:     true,
+		Handler: func(message twitchirc.PrivateMessage, args []string) string {
+			modes, ok := reporter.RoomModes()
+			if !ok {
+				return "Room state hasn't been received from Twitch yet."
+			}
+			return FormatRoomModes(modes)
+		},
+	})
+}
+
+// FormatRoomModes formats chat restrictions as
+// "Chat modes: followers-only (10m), slow (30s), subs-only"
+func FormatRoomModes(modes twitchbot.RoomModes) string {
+	var active []string
+	if modes.EmoteOnly {
+		active = append(active, "emote-only")
+	}
+	switch {
+	case modes.FollowersOnly == 0:
+		active = append(active, "followers-only")
+	case modes.FollowersOnly > 0:
+		active = append(active, fmt.Sprintf("followers-only (%s)", formatAgo(time.Duration(modes.FollowersOnly)*time.Minute)))
+	}
+	if modes.Slow > 0 {
+		active = append(active, fmt.Sprintf("slow (%s)", formatAgo(time.Duration(modes.Slow)*time.Second)))
+	}
+	if modes.SubsOnly {
+		active = append(active, "subs-only")
+	}
+	if modes.UniqueChat {
+		active = append(active, "unique-chat")
+	}
+
+	if len(active) == 0 {
+		return "Chat modes: none"
+	}
+	return "Chat modes: " + strings.Join(active, ", ")
+}
@@ -0,0 +1,152 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/pbuckles22/PBChatBot/internal/utils"
+)
+
+// Keys of the settings saved in the channel settings file
+const (
+	// Seconds between bot responses set with !slowmode
+	SettingSlowMode = "slow_mode_seconds"
+	// Lobby code set with !lobby, shown in the served announcement
+	SettingLobbyCode = "lobby_code"
+)
+
+// Settings holds runtime toggles changed through chat commands that need to
+// survive a restart. Every change is written to disk straight away.
+type Settings struct {
+	mu     sync.Mutex
+	path   string
+	values map[string]json.RawMessage
+}
+
+// NewSettings creates an empty settings store saved at path
+func NewSettings(path string) *Settings {
+	return &Settings{
+		path:   path,
+		values: make(map[string]json.RawMessage),
+	}
+}
+
+// Path returns the file the settings are saved to
+func (s *Settings) Path() string {
+	return s.path
+}
+
+// Load replaces the in-memory settings with the ones saved on disk.
+// A missing file leaves no settings.
+func (s *Settings) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values = make(map[string]json.RawMessage)
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // No settings saved yet
+		}
+		return fmt.Errorf("failed to read settings: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.values); err != nil {
+		return fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	return nil
+}
+
+// Get decodes the setting stored under key into v. It returns false if the
+// setting isn't set or can't be decoded into v.
+func (s *Settings) Get(key string, v interface{}) bool {
+	s.mu.Lock()
+	raw, ok := s.values[key]
+	s.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+	return json.Unmarshal(raw, v) == nil
+}
+
+// Set stores v under key and saves the settings file. The previous value is
+// kept if the file can't be written.
+func (s *Settings) Set(key string, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal setting %s: %w", key, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous, existed := s.values[key]
+	s.values[key] = raw
+	if err := s.save(); err != nil {
+		if existed {
+			s.values[key] = previous
+		} else {
+			delete(s.values, key)
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete removes the setting stored under key and saves the settings file
+func (s *Settings) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous, existed := s.values[key]
+	if !existed {
+		return nil
+	}
+	delete(s.values, key)
+	if err := s.save(); err != nil {
+		s.values[key] = previous
+		return err
+	}
+	return nil
+}
+
+// Keys returns the names of the stored settings in sorted order
+func (s *Settings) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// save writes the settings file. Caller must hold the lock.
+func (s *Settings) save() error {
+	// Ensure the data directory exists
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s.values, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+	if err := utils.WriteFileAtomic(s.path, data); err != nil {
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+	return nil
+}
+
+// settingsFile returns the path the channel settings are saved to
+func (cm *CommandManager) settingsFile() string {
+	return filepath.Join(cm.queue.GetDataPath(), fmt.Sprintf("channel_settings_%s.json", cm.channel))
+}
+
+// GetSettings returns the channel's persistent settings
+func (cm *CommandManager) GetSettings() *Settings {
+	return cm.settings
+}
@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gempir/go-twitch-irc/v4"
+)
+
+// showConfigMaxLength keeps !showconfig output within a single chat message
+const showConfigMaxLength = 450
+
+// HandleShowConfig handles the !showconfig command
+func HandleShowConfig(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	cfg := cm.GetConfig()
+	if cfg == nil {
+		return "No config loaded."
+	}
+
+	fields := []string{
+		fmt.Sprintf("bot=%s", cfg.BotName),
+		fmt.Sprintf("channel=%s", cfg.Channel),
+		fmt.Sprintf("prefix=%s", cm.prefix),
+		fmt.Sprintf("timezone=%s", cfg.Timezone),
+		fmt.Sprintf("queue max=%d", cfg.Commands.Queue.MaxSize),
+		fmt.Sprintf("default position=%d", cfg.Commands.Queue.DefaultPosition),
+		fmt.Sprintf("default pop=%d", cfg.Commands.Queue.DefaultPopCount),
+		fmt.Sprintf("cooldowns default=%ds mod=%ds vip=%ds",
+			cfg.Commands.Cooldowns.Default,
+			cfg.Commands.Cooldowns.Moderator,
+			cfg.Commands.Cooldowns.VIP),
+	}
+	response := fmt.Sprintf("Config: %s", strings.Join(fields, " | "))
+	if len(response) <= showConfigMaxLength {
+		return response
+	}
+
+	// Too long for chat: show the most commonly asked-about values only
+	fields = []string{
+		fmt.Sprintf("channel=%s", cfg.Channel),
+		fmt.Sprintf("prefix=%s", cm.prefix),
+		fmt.Sprintf("queue max=%d", cfg.Commands.Queue.MaxSize),
+		fmt.Sprintf("timezone=%s", cfg.Timezone),
+	}
+	response = fmt.Sprintf("Config: %s | Use !exportconfig for the full view.", strings.Join(fields, " | "))
+	if len(response) > showConfigMaxLength {
+		return "Config is too long to show in chat. Use !exportconfig for the full view."
+	}
+	return response
+}
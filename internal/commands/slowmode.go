@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	twitchirc "github.com/gempir/go-twitch-irc/v4"
+	twitchbot "github.com/pbuckles22/PBChatBot/internal/twitch"
+)
+
+// RegisterSlowModeCommand registers the slowmode command
+func RegisterSlowModeCommand(cm *CommandManager, bot *twitchbot.Bot) {
+	// Restore the slow mode saved by a previous run
+	var saved int
+	if cm.GetSettings().Get(SettingSlowMode, &saved) {
+		bot.SetResponseThrottle(time.Duration(saved) * time.Second)
+	}
+
+	cm.RegisterCommand(&Command{
+		Name:        "slowmode",
+		Category:    CategoryModeration,
+		Description: "Set the minimum time between bot responses",
+		ModOnly:     true,
+		Handler: func(message twitchirc.PrivateMessage, args []string) string {
+			if len(args) == 0 {
+				if throttle := bot.GetResponseThrottle(); throttle > 0 {
+					return fmt.Sprintf("Bot slow mode is %s. Use !slowmode off to disable.", FormatCooldown(throttle))
+				}
+				return "Bot slow mode is off. Usage: !slowmode <seconds> or !slowmode off"
+			}
+
+			if strings.EqualFold(args[0], "off") {
+				bot.SetResponseThrottle(0)
+				if err := cm.GetSettings().Delete(SettingSlowMode); err != nil {
+					log.Printf("Error saving slow mode: %v", err)
+				}
+				return "Bot slow mode disabled."
+			}
+
+			seconds, err := parsePositiveInt(args[0], "number of seconds")
+			if err != nil {
+				return err.Error()
+			}
+			bot.SetResponseThrottle(time.Duration(seconds) * time.Second)
+			if err := cm.GetSettings().Set(SettingSlowMode, seconds); err != nil {
+				log.Printf("Error saving slow mode: %v", err)
+			}
+			return fmt.Sprintf("Bot slow mode enabled: responses will be at least %ds apart.", seconds)
+		},
+	})
+}
@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"log"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+	"github.com/pbuckles22/PBChatBot/internal/music"
+)
+
+// SetMusicProvider sets where !song looks up the current track
+func (cm *CommandManager) SetMusicProvider(provider music.MusicProvider) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.musicProvider = provider
+}
+
+// HandleSong handles the !song command
+func HandleSong(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	cm.mu.RLock()
+	provider := cm.musicProvider
+	cm.mu.RUnlock()
+
+	if provider == nil {
+		return "Song lookups are not set up for this channel."
+	}
+
+	track, err := provider.CurrentTrack()
+	if err != nil {
+		log.Printf("[Song] Error looking up current track: %v", err)
+		return "Couldn't look up the current song right now."
+	}
+	if track == nil {
+		return "Nothing is playing right now."
+	}
+	return "🎵 Now playing: " + track.String()
+}
@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/queue"
+)
+
+// WriteSessionSummary writes a queue session summary to a timestamped JSON
+// file in the data path so streamers can follow up with viewers afterward.
+// Returns the file name.
+func (cm *CommandManager) WriteSessionSummary(summary queue.SessionSummary) (string, error) {
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal session summary: %w", err)
+	}
+
+	// Ensure the data directory exists
+	dataPath := cm.queue.GetDataPath()
+	if err := os.MkdirAll(dataPath, 0755); err != nil {
+		return "", fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	filename := fmt.Sprintf("queue_summary_%s_%s.json", cm.channel, time.Now().Format("20060102_150405"))
+	if err := os.WriteFile(filepath.Join(dataPath, filename), data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write session summary: %w", err)
+	}
+
+	return filename, nil
+}
@@ -0,0 +1,275 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+)
+
+// DefaultTimerAnnounceInterval is how often a named timer posts its remaining time
+const DefaultTimerAnnounceInterval = 10 * time.Minute
+
+// maxChatTimers is the most named timers that can run at once
+const maxChatTimers = 5
+
+// maxTimerDuration is the longest named timer that can be set
+const maxTimerDuration = 24 * time.Hour
+
+var (
+	// ErrTimerExists is returned when setting a timer whose name is in use
+	ErrTimerExists = errors.New("a timer with that name is already running")
+	// ErrTimerNotFound is returned when cancelling a timer that isn't running
+	ErrTimerNotFound = errors.New("no timer with that name")
+	// ErrTooManyTimers is returned when the timer limit is reached
+	ErrTooManyTimers = fmt.Errorf("at most %d timers can run at once", maxChatTimers)
+)
+
+// TimerClock provides the current time and schedules timer announcements.
+// It can be replaced in tests.
+type TimerClock interface {
+	CountdownClock
+	Now() time.Time
+}
+
+// Now returns the current system time
+func (realCountdownClock) Now() time.Time {
+	return time.Now()
+}
+
+// chatTimer is one running named timer
+type chatTimer struct {
+	name    string
+	message string
+	endsAt  time.Time
+	// The next scheduled post; each post schedules the one after it
+	next CountdownStopper
+}
+
+// ActiveTimer describes a running named timer
+type ActiveTimer struct {
+	Name      string
+	Remaining time.Duration
+}
+
+// TimerManager runs named timers that post their remaining time to chat
+type TimerManager struct {
+	mu       sync.Mutex
+	clock    TimerClock
+	send     func(string)
+	interval time.Duration
+	timers   map[string]*chatTimer
+}
+
+// NewTimerManager creates a timer manager that posts every interval using
+// send. If clock is nil, announcements are scheduled with time.Timer; if
+// interval isn't positive, DefaultTimerAnnounceInterval is used.
+func NewTimerManager(clock TimerClock, send func(string), interval time.Duration) *TimerManager {
+	if clock == nil {
+		clock = realCountdownClock{}
+	}
+	if interval <= 0 {
+		interval = DefaultTimerAnnounceInterval
+	}
+	return &TimerManager{
+		clock:    clock,
+		send:     send,
+		interval: interval,
+		timers:   make(map[string]*chatTimer),
+	}
+}
+
+// Set starts a named timer of length total. Its remaining time is posted
+// every interval, and "Time's up!" plus message is posted when it expires.
+func (tm *TimerManager) Set(name string, total time.Duration, message string) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	key := strings.ToLower(name)
+	if _, exists := tm.timers[key]; exists {
+		return ErrTimerExists
+	}
+	if len(tm.timers) >= maxChatTimers {
+		return ErrTooManyTimers
+	}
+
+	timer := &chatTimer{
+		name:    name,
+		message: message,
+		endsAt:  tm.clock.Now().Add(total),
+	}
+	tm.scheduleNext(timer, 0, total)
+
+	tm.timers[key] = timer
+	return nil
+}
+
+// scheduleNext schedules timer's next post, elapsed into its run of total:
+// the remaining time one interval from now, or "Time's up!" if the timer
+// expires first. The caller must hold tm.mu.
+func (tm *TimerManager) scheduleNext(timer *chatTimer, elapsed, total time.Duration) {
+	next := elapsed + tm.interval
+	if next >= total {
+		expired := fmt.Sprintf("⏰ %s: Time's up!", timer.name)
+		if timer.message != "" {
+			expired += " " + timer.message
+		}
+		timer.next = tm.clock.AfterFunc(total-elapsed, func() {
+			tm.fire(timer, expired, total, total)
+		})
+		return
+	}
+
+	text := fmt.Sprintf("⏰ %s timer: %s remaining.", timer.name, formatRemaining(total-next))
+	timer.next = tm.clock.AfterFunc(tm.interval, func() {
+		tm.fire(timer, text, next, total)
+	})
+}
+
+// fire posts a scheduled announcement if its timer is still running, and
+// schedules the next one until the timer expires
+func (tm *TimerManager) fire(timer *chatTimer, text string, elapsed, total time.Duration) {
+	tm.mu.Lock()
+	key := strings.ToLower(timer.name)
+	if tm.timers[key] != timer {
+		tm.mu.Unlock()
+		return
+	}
+	if elapsed >= total {
+		delete(tm.timers, key)
+	} else {
+		tm.scheduleNext(timer, elapsed, total)
+	}
+	tm.mu.Unlock()
+
+	tm.send(text)
+}
+
+// Cancel stops a named timer before it expires
+func (tm *TimerManager) Cancel(name string) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	key := strings.ToLower(name)
+	timer, exists := tm.timers[key]
+	if !exists {
+		return ErrTimerNotFound
+	}
+	timer.next.Stop()
+	delete(tm.timers, key)
+	return nil
+}
+
+// List returns the running timers, soonest to expire first
+func (tm *TimerManager) List() []ActiveTimer {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	now := tm.clock.Now()
+	active := make([]ActiveTimer, 0, len(tm.timers))
+	for _, timer := range tm.timers {
+		active = append(active, ActiveTimer{Name: timer.name, Remaining: timer.endsAt.Sub(now)})
+	}
+	sort.Slice(active, func(i, j int) bool {
+		if active[i].Remaining != active[j].Remaining {
+			return active[i].Remaining < active[j].Remaining
+		}
+		return active[i].Name < active[j].Name
+	})
+	return active
+}
+
+// formatRemaining formats a remaining time in words, e.g. "20 minutes" or "30 seconds"
+func formatRemaining(d time.Duration) string {
+	if d >= time.Minute && d%time.Minute == 0 {
+		if minutes := int(d / time.Minute); minutes != 1 {
+			return fmt.Sprintf("%d minutes", minutes)
+		}
+		return "1 minute"
+	}
+	if d >= time.Minute {
+		return formatCountdown(d)
+	}
+	if seconds := int(d.Round(time.Second) / time.Second); seconds != 1 {
+		return fmt.Sprintf("%d seconds", seconds)
+	}
+	return "1 second"
+}
+
+// SetTimerManager sets the manager used by !timer
+func (cm *CommandManager) SetTimerManager(timers *TimerManager) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.chatTimers = timers
+}
+
+// HandleTimer handles the !timer command
+func HandleTimer(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	cm.mu.RLock()
+	timers := cm.chatTimers
+	cm.mu.RUnlock()
+
+	if timers == nil {
+		return "Timers are not available."
+	}
+
+	usage := `Usage: !timer set <name> <duration> ["message"], !timer cancel <name> or !timer list`
+	if len(args) < 1 {
+		return usage
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "list":
+		active := timers.List()
+		if len(active) == 0 {
+			return "No timers are running."
+		}
+		parts := make([]string, len(active))
+		for i, timer := range active {
+			parts[i] = fmt.Sprintf("%s (%s remaining)", timer.Name, formatCountdown(timer.Remaining))
+		}
+		return "Active timers: " + strings.Join(parts, ", ")
+
+	case "set":
+		if !isModerator(message) {
+			return "Only moderators can set timers."
+		}
+		// Re-split the raw text so a quoted message keeps its spacing
+		fields := splitQuoted(echoText(message.Message))
+		if len(fields) < 3 {
+			return usage
+		}
+		name := fields[1]
+		total, err := time.ParseDuration(fields[2])
+		if err != nil || total <= 0 {
+			return fmt.Sprintf("Invalid duration: %s (use e.g. 90s or 30m)", fields[2])
+		}
+		if total > maxTimerDuration {
+			return fmt.Sprintf("Timers can be at most %d hours.", int(maxTimerDuration/time.Hour))
+		}
+		text := strings.Join(fields[3:], " ")
+
+		if err := timers.Set(name, total, text); err != nil {
+			return fmt.Sprintf("Could not set timer %s: %v.", name, err)
+		}
+		return fmt.Sprintf("⏰ %s timer set for %s.", name, formatRemaining(total))
+
+	case "cancel":
+		if !isModerator(message) {
+			return "Only moderators can cancel timers."
+		}
+		if len(args) < 2 {
+			return usage
+		}
+		if err := timers.Cancel(args[1]); err != nil {
+			return fmt.Sprintf("No timer named %s is running.", args[1])
+		}
+		return fmt.Sprintf("⏰ %s timer cancelled.", args[1])
+	}
+	return usage
+}
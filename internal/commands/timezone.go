@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+	"github.com/pbuckles22/PBChatBot/internal/config"
+	"github.com/pbuckles22/PBChatBot/internal/utils"
+)
+
+// FormatTimeForDisplay formats t in the channel's current display timezone.
+// Commands should use this rather than reading the timezone once, so
+// changes made with !timezone take effect immediately.
+func (cm *CommandManager) FormatTimeForDisplay(t time.Time) string {
+	return utils.FormatTimeForDisplay(t, cm.GetConfig().Timezone)
+}
+
+// ErrUnknownTimezone is returned by SetTimezone for a name that isn't an
+// IANA timezone
+var ErrUnknownTimezone = errors.New("unknown timezone")
+
+// SetTimezone validates and applies a new display timezone, saving it to the
+// channel's config file. The in-memory change is kept even if saving fails.
+func (cm *CommandManager) SetTimezone(timezone string) error {
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return fmt.Errorf("%w %q", ErrUnknownTimezone, timezone)
+	}
+
+	// Swap in an updated copy rather than changing the config callers of
+	// GetConfig may be reading
+	cm.mu.Lock()
+	updated := *cm.config
+	updated.Timezone = timezone
+	cm.config = &updated
+	path := cm.configPath
+	cm.mu.Unlock()
+
+	if err := config.SetField(path, timezone, "timezone"); err != nil {
+		return fmt.Errorf("timezone changed but not saved: %w", err)
+	}
+	return nil
+}
+
+// HandleTimezone handles the !timezone command
+func HandleTimezone(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+
+	if len(args) == 0 {
+		return fmt.Sprintf("Timezone is %s (current time: %s)", cm.GetConfig().Timezone, cm.FormatTimeForDisplay(time.Now()))
+	}
+
+	if err := cm.SetTimezone(args[0]); errors.Is(err, ErrUnknownTimezone) {
+		return fmt.Sprintf("Invalid timezone: %s. Use an IANA name like America/New_York.", args[0])
+	} else if err != nil {
+		return fmt.Sprintf("Timezone set to %s for this session, but it couldn't be saved: %v", args[0], err)
+	}
+	return fmt.Sprintf("Timezone set to %s (current time: %s)", args[0], cm.FormatTimeForDisplay(time.Now()))
+}
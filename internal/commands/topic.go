@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+	"github.com/pbuckles22/PBChatBot/internal/timers"
+)
+
+// topicState is the on-disk form of the stream topic
+type topicState struct {
+	Topic string `json:"topic"`
+}
+
+// topicFile returns the path the stream topic is saved to
+func (cm *CommandManager) topicFile() string {
+	return filepath.Join(cm.queue.GetDataPath(), fmt.Sprintf("topic_%s.json", cm.channel))
+}
+
+// GetTopic returns the current stream topic ("" when none is set)
+func (cm *CommandManager) GetTopic() string {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.streamTopic
+}
+
+// SetTopic sets the stream topic and persists it. An empty topic clears it.
+func (cm *CommandManager) SetTopic(topic string) error {
+	topic = strings.TrimSpace(topic)
+
+	cm.mu.Lock()
+	previous := cm.streamTopic
+	cm.streamTopic = topic
+	cm.mu.Unlock()
+
+	if err := cm.saveTopic(topic); err != nil {
+		cm.mu.Lock()
+		cm.streamTopic = previous
+		cm.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// saveTopic persists the stream topic, removing the file when it's cleared
+func (cm *CommandManager) saveTopic(topic string) error {
+	if topic == "" {
+		if err := os.Remove(cm.topicFile()); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove topic file: %w", err)
+		}
+		return nil
+	}
+
+	// Ensure the data directory exists
+	if err := os.MkdirAll(cm.queue.GetDataPath(), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(topicState{Topic: topic}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal topic: %w", err)
+	}
+
+	if err := os.WriteFile(cm.topicFile(), data, 0644); err != nil {
+		return fmt.Errorf("failed to write topic file: %w", err)
+	}
+
+	return nil
+}
+
+// LoadTopic restores the stream topic saved by a previous run
+func (cm *CommandManager) LoadTopic() error {
+	data, err := os.ReadFile(cm.topicFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read topic file: %w", err)
+	}
+
+	var state topicState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse topic file: %w", err)
+	}
+
+	cm.mu.Lock()
+	cm.streamTopic = state.Topic
+	cm.mu.Unlock()
+	return nil
+}
+
+// NewTopicAnnounceTimer creates a timer that posts the stream topic every
+// interval while one is set
+func (cm *CommandManager) NewTopicAnnounceTimer(interval time.Duration) *timers.Timer {
+	return &timers.Timer{
+		Name:     "topic_announce",
+		Interval: interval,
+		Message: func() string {
+			if topic := cm.GetTopic(); topic != "" {
+				return "📌 Topic: " + topic
+			}
+			return ""
+		},
+	}
+}
+
+// HandleTopic handles the !topic command. Anyone can view the topic; only
+// moderators can set it.
+func HandleTopic(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+
+	if len(args) == 0 {
+		if topic := cm.GetTopic(); topic != "" {
+			return "📌 Topic: " + topic
+		}
+		return "No topic is set."
+	}
+
+	if !isModerator(message) {
+		return "Only moderators can set the topic."
+	}
+	if err := cm.SetTopic(echoText(message.Message)); err != nil {
+		return fmt.Sprintf("Error setting topic: %v", err)
+	}
+	return "📌 Topic set: " + cm.GetTopic()
+}
+
+// HandleClearTopic handles the !cleartopic command
+func HandleClearTopic(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	if cm.GetTopic() == "" {
+		return "No topic is set."
+	}
+	if err := cm.SetTopic(""); err != nil {
+		return fmt.Sprintf("Error clearing topic: %v", err)
+	}
+	return "Topic cleared."
+}
+
+// HandleStatus handles the !status command, summarizing the queue and topic
+func HandleStatus(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	q := cm.GetQueue()
+
+	var parts []string
+	switch {
+	case !q.IsEnabled():
+		parts = append(parts, "Queue: disabled")
+	case q.IsPaused():
+		parts = append(parts, fmt.Sprintf("Queue: paused (%d waiting)", q.Size()))
+	default:
+		parts = append(parts, fmt.Sprintf("Queue: open (%d waiting)", q.Size()))
+	}
+	if topic := cm.GetTopic(); topic != "" {
+		parts = append(parts, "Topic: "+topic)
+	}
+	return strings.Join(parts, " | ")
+}
@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultUnknownCommandReplyInterval is the minimum time between "Unknown command" replies
+const DefaultUnknownCommandReplyInterval = 30 * time.Second
+
+// SetUnknownCommandReplyInterval sets the minimum time between "Unknown command" replies
+func (cm *CommandManager) SetUnknownCommandReplyInterval(d time.Duration) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.unknownReplyInterval = d
+}
+
+// unknownCommandReply returns the reply for an unrecognized command, or "" if
+// unknown_command_reply is off or a reply was sent too recently. Random
+// prefixed chatter would otherwise get a reply to every message.
+func (cm *CommandManager) unknownCommandReply(name string) string {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if cm.config == nil || !cm.config.UnknownCommandReply {
+		return ""
+	}
+	if time.Since(cm.lastUnknownReply) < cm.unknownReplyInterval {
+		return ""
+	}
+	cm.lastUnknownReply = time.Now()
+	return fmt.Sprintf("Unknown command: %s%s. Try %shelp", cm.prefix, name, cm.prefix)
+}
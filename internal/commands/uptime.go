@@ -11,6 +11,7 @@ import (
 func RegisterUptimeCommand(cm *CommandManager) {
 	cm.RegisterCommand(&Command{
 		Name:        "uptime",
+		Category:    CategoryGeneral,
 		Aliases:     []string{"up"},
 		Description: "Shows how long the bot has been running",
 		Handler: func(message twitch.PrivateMessage, args []string) string {
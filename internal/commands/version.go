@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+)
+
+// Version is the bot's release version, set at build time with
+// -ldflags "-X github.com/pbuckles22/PBChatBot/internal/commands.Version=v1.2.0"
+var Version = "dev"
+
+// LatestReleaseURL is the GitHub API endpoint for the bot's latest release
+const LatestReleaseURL = "https://api.github.com/repos/pbuckles22/PBChatBot/releases/latest"
+
+// updateCheckCacheTTL is how long a latest release lookup is reused
+const updateCheckCacheTTL = time.Hour
+
+// updateCheckTimeout bounds the GitHub request so a slow API can't stall !version
+const updateCheckTimeout = 5 * time.Second
+
+// UpdateChecker looks up the tag of the bot's latest GitHub release
+type UpdateChecker struct {
+	mu         sync.Mutex
+	url        string
+	httpClient *http.Client
+	latest     string
+	fetchedAt  time.Time
+}
+
+// NewUpdateChecker creates a checker that reads the latest release from url
+func NewUpdateChecker(url string) *UpdateChecker {
+	return &UpdateChecker{
+		url:        url,
+		httpClient: &http.Client{Timeout: updateCheckTimeout},
+	}
+}
+
+// LatestVersion returns the tag name of the latest release. Successful
+// lookups are cached for an hour.
+func (u *UpdateChecker) LatestVersion() (string, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.latest != "" && time.Since(u.fetchedAt) < updateCheckCacheTTL {
+		return u.latest, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", u.url, nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := u.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", fmt.Errorf("error decoding response: %w", err)
+	}
+	if release.TagName == "" {
+		return "", fmt.Errorf("release has no tag name")
+	}
+
+	u.latest = release.TagName
+	u.fetchedAt = time.Now()
+	return u.latest, nil
+}
+
+// parseVersion splits a version like "v1.2.3" into its numeric parts. It
+// returns false for versions that aren't dotted numbers, such as "dev".
+func parseVersion(version string) ([]int, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	// Ignore pre-release and build suffixes (e.g. 1.2.3-rc1)
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	if version == "" {
+		return nil, false
+	}
+
+	var parts []int
+	for _, field := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, true
+}
+
+// isNewerVersion reports whether latest is a later release than current.
+// Versions that can't be compared are never reported as newer.
+func isNewerVersion(latest, current string) bool {
+	l, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	c, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	for i := 0; i < len(l) || i < len(c); i++ {
+		var lp, cp int
+		if i < len(l) {
+			lp = l[i]
+		}
+		if i < len(c) {
+			cp = c[i]
+		}
+		if lp != cp {
+			return lp > cp
+		}
+	}
+	return false
+}
+
+// SetUpdateChecker sets the release lookup used by !version when
+// update_check_enabled is on
+func (cm *CommandManager) SetUpdateChecker(checker *UpdateChecker) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.updateChecker = checker
+}
+
+// HandleVersion handles the !version command
+func HandleVersion(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	cm.mu.RLock()
+	checker := cm.updateChecker
+	enabled := cm.config != nil && cm.config.UpdateCheckEnabled
+	cm.mu.RUnlock()
+
+	response := fmt.Sprintf("PBChatBot %s (%s, %s/%s)", Version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	if checker == nil || !enabled {
+		return response
+	}
+
+	latest, err := checker.LatestVersion()
+	if err != nil {
+		log.Printf("[Version] Error checking for updates: %v", err)
+		return response
+	}
+	if isNewerVersion(latest, Version) {
+		response += fmt.Sprintf(" ⚠️ Update available: v%s!", strings.TrimPrefix(latest, "v"))
+	}
+	return response
+}
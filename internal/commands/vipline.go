@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+)
+
+// canJoinVIPLine reports whether a user may join the VIP line themselves:
+// VIPs, subscribers, moderators and the broadcaster
+func canJoinVIPLine(message twitch.PrivateMessage) bool {
+	return isPrivileged(message) || isSubscriber(message)
+}
+
+// HandleJoinVIP handles the !joinvip command
+func HandleJoinVIP(message twitch.PrivateMessage, args []string) string {
+	cm := GetCommandManager()
+	if !cm.GetQueue().IsEnabled() {
+		return "Queue system is currently disabled."
+	}
+
+	// Moderators can add someone else to the VIP line
+	username := message.User.Name
+	if len(args) > 0 {
+		if !isModerator(message) {
+			return "Only moderators can add other users to the VIP line."
+		}
+		username = args[0]
+	} else if !canJoinVIPLine(message) {
+		return fmt.Sprintf("%s, the VIP line is for VIPs and subscribers. Use !join to join the queue.", username)
+	}
+
+	if err := cm.GetQueue().AddVIP(username); err != nil {
+		return fmt.Sprintf("Error joining VIP line: %v", err)
+	}
+	return fmt.Sprintf("%s joined the VIP line at position %d", username, cm.GetQueue().VIPPosition(username))
+}
+
+// HandleQueueVIP shows the VIP line
+func HandleQueueVIP(message twitch.PrivateMessage, args []string) string {
+	queue := GetCommandManager().GetQueue()
+	if !queue.IsEnabled() {
+		return "Queue system is currently disabled."
+	}
+
+	users := queue.ListVIP()
+	if len(users) == 0 {
+		return "The VIP line is currently empty."
+	}
+
+	served := "served before the main queue"
+	if interleave := queue.GetVIPInterleave(); interleave > 1 {
+		served = fmt.Sprintf("served every %s pop", ordinal(interleave))
+	}
+	return fmt.Sprintf("VIP line: %s (%d total, %s)", strings.Join(users, ", "), len(users), served)
+}
+
+// ordinal formats n as "1st", "2nd", "3rd", "4th", ...
+func ordinal(n int) string {
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
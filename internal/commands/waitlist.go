@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pbuckles22/PBChatBot/internal/queue"
+)
+
+// waitlistIfFull puts username on the waitlist when err says the queue is
+// full and the waitlist is enabled. It reports whether the user was
+// waitlisted, and otherwise returns the error to report.
+func (cm *CommandManager) waitlistIfFull(username string, err error) (bool, error) {
+	q := cm.GetQueue()
+	if !errors.Is(err, queue.ErrQueueFull) || !q.WaitlistEnabled() {
+		return false, err
+	}
+	if err := q.AddWaitlist(username); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// waitlistResponse builds the response for a user who was just waitlisted
+func waitlistResponse(cm *CommandManager, username string) string {
+	return fmt.Sprintf("The queue is full, so %s is #%d on the waitlist and will join the queue when a spot opens.",
+		username, cm.GetQueue().WaitlistPosition(username))
+}
+
+// announcePromotions tells chat which waitlisted users moved into the queue
+func (cm *CommandManager) announcePromotions(users []string) {
+	cm.mu.RLock()
+	announcer := cm.announcer
+	cm.mu.RUnlock()
+	if announcer == nil {
+		return
+	}
+
+	if len(users) == 1 {
+		announcer.Announce(fmt.Sprintf("@%s, a spot opened up! You've moved from the waitlist into the queue at position %d.",
+			users[0], cm.GetQueue().Position(users[0])))
+		return
+	}
+	announcer.Announce(fmt.Sprintf("Spots opened up! Moved from the waitlist into the queue: %s", formatNameList(users, 10)))
+}
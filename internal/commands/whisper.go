@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"fmt"
+)
+
+// Whisperer sends private messages to individual users
+type Whisperer interface {
+	WhisperUser(username, message string) error
+}
+
+// SetWhisperer sets how whisper notifications are sent
+func (cm *CommandManager) SetWhisperer(whisperer Whisperer) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.whisperer = whisperer
+}
+
+// whisperNotice whispers message to username when whisper_notifications is
+// enabled. It returns false if nothing was whispered so the caller can reply
+// in chat instead.
+func (cm *CommandManager) whisperNotice(username, message string) bool {
+	cm.mu.RLock()
+	enabled := cm.config != nil && cm.config.WhisperNotifications
+	cm.mu.RUnlock()
+
+	if !enabled {
+		return false
+	}
+	return cm.whisper(username, message)
+}
+
+// whisper sends message to username, returning false if no whisperer is set
+// or the whisper failed
+func (cm *CommandManager) whisper(username, message string) bool {
+	cm.mu.RLock()
+	whisperer := cm.whisperer
+	cm.mu.RUnlock()
+
+	if whisperer == nil {
+		return false
+	}
+	// Failures are logged by the whisperer
+	return whisperer.WhisperUser(username, message) == nil
+}
+
+// quietJoinNotice whispers username a short confirmation of their own join
+// when queue.quiet_join is enabled. It returns false if nothing was
+// whispered so the caller can reply in chat instead.
+func (cm *CommandManager) quietJoinNotice(username string, waitlisted bool) bool {
+	cm.mu.RLock()
+	enabled := cm.config != nil && cm.config.Commands.Queue.QuietJoin
+	cm.mu.RUnlock()
+
+	if !enabled {
+		return false
+	}
+	if waitlisted {
+		return cm.whisper(username, fmt.Sprintf("You're #%d on the waitlist.", cm.GetQueue().WaitlistPosition(username)))
+	}
+	return cm.whisper(username, fmt.Sprintf("You're #%d in the queue.", cm.GetQueue().Position(username)))
+}
+
+// notifyPosition whispers username their current queue position after it changes
+func (cm *CommandManager) notifyPosition(username string) {
+	position := cm.queue.Position(username)
+	if position == -1 {
+		return
+	}
+	cm.whisperNotice(username, fmt.Sprintf("You are now at position %d in the queue", position))
+}
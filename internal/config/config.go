@@ -13,12 +13,92 @@ type Config struct {
 	Channel  string `yaml:"channel"`
 	DataPath string `yaml:"data_path"`
 	Timezone string `yaml:"timezone"` // Timezone for user-facing messages (e.g., "America/New_York", "America/Los_Angeles")
+	// Optional credentials for setups that keep them in the channel config
+	OAuth        string `yaml:"oauth,omitempty"`
+	ClientSecret string `yaml:"client_secret,omitempty"`
+	// Send position updates and join confirmations as whispers instead of chat
+	WhisperNotifications bool `yaml:"whisper_notifications"`
+	// Reply to unrecognized !commands instead of ignoring them
+	UnknownCommandReply bool `yaml:"unknown_command_reply"`
+	// Branding added to the start of every bot message (e.g. an emote)
+	ResponsePrefix string `yaml:"response_prefix"`
+	// Have !version check GitHub for a newer release
+	UpdateCheckEnabled bool `yaml:"update_check_enabled"`
+	// Seconds between !timer remaining-time posts (defaults to 600)
+	TimerAnnounceInterval int `yaml:"timer_announce_interval"`
+	// Minutes between !topic reminders in chat (0 disables)
+	TopicAnnounceInterval int `yaml:"topic_announce_interval"`
+	// Order of the category headings in !help (defaults to General, Queue,
+	// Stats, Fun, Moderation)
+	HelpCategoryOrder []string `yaml:"help_category_order"`
+	// Messages !hype posts in order, and the seconds to wait between them
+	HypeMessages []string `yaml:"hype_messages"`
+	HypeDelay    int      `yaml:"hype_delay"`
+	// How much stream session history to keep in channel_stats.json
+	// (0 keeps everything). Overall totals are kept either way.
+	StatsRetention struct {
+		MaxSessions int `yaml:"max_sessions"`
+		MaxAgeDays  int `yaml:"max_age_days"`
+	} `yaml:"stats_retention"`
+	// Where !song looks up the current track: "spotify", "lastfm" or "" (disabled)
+	MusicProvider string `yaml:"music_provider"`
+	Spotify       struct {
+		ClientID     string `yaml:"client_id"`
+		RefreshToken string `yaml:"refresh_token,omitempty"` // From the PKCE authorization flow
+	} `yaml:"spotify"`
+	LastFM struct {
+		APIKey   string `yaml:"api_key,omitempty"`
+		Username string `yaml:"username"`
+	} `yaml:"lastfm"`
 	Commands struct {
 		Queue struct {
 			MaxSize         int `yaml:"max_size"`
 			DefaultPosition int `yaml:"default_position"`
 			DefaultPopCount int `yaml:"default_pop_count"`
+			// Seconds between "N people in queue" announcements (0 disables)
+			PeriodicAnnounceInterval int `yaml:"periodic_announce_interval"`
+			// Seconds a cleared queue can be restored with !undoclear (0
+			// disables undo; unset means 60)
+			UndoClearWindow *int `yaml:"undo_clear_window"`
+			// Announce when the queue reaches max_size
+			AnnounceQueueFull bool `yaml:"announce_queue_full"`
+			// Send joins past max_size to a waitlist that fills freed slots
+			// instead of turning them away
+			Waitlist bool `yaml:"waitlist"`
+			// Announce every N queue joins in a session (0 disables)
+			AnnounceMilestoneEvery int `yaml:"announce_milestone_every"`
+			// Minimum days a viewer must have followed to join (0 disables)
+			MinFollowDays int `yaml:"min_follow_days"`
+			// Minimum Twitch account age in days to !join (0 disables, mods bypass)
+			MinAccountDays int `yaml:"min_account_days"`
+			// Write a summary of who was served to the data path on !endqueue
+			SessionSummary bool `yaml:"session_summary"`
+			// Serve every Nth pop from the !joinvip line (defaults to 3)
+			VIPInterleave int `yaml:"vip_interleave"`
+			// Seconds a served user must wait before rejoining (0 disables)
+			RejoinCooldown int `yaml:"rejoin_cooldown"`
+			// Stop mods from adding users on the !qban list too
+			BlacklistBlocksMods bool `yaml:"blacklist_blocks_mods"`
+			// Reply to a duplicate !join; {user} and {position} are filled in
+			AlreadyQueuedMessage string `yaml:"already_queued_message"`
+			// Replies to a blocked !join keyed by reason (disabled, paused,
+			// full, banned, cooldown); {user}, {wait} and, for paused, the
+			// !pausequeue {reason} are filled in
+			JoinBlockedMessages map[string]string `yaml:"join_blocked_messages"`
+			// Announcement when !pop serves users; {user} and {lobby_code}
+			// (set with !lobby) are filled in. Empty keeps "Popped: ..."
+			ServedMessage string `yaml:"served_message"`
+			// Whisper a short "you're #4" for a successful self-join and
+			// post nothing in chat. Blocked joins are still replied to.
+			QuietJoin bool `yaml:"quiet_join"`
+			// Points a viewer pays to !join (0 makes joining free)
+			JoinCost int64 `yaml:"join_cost"`
+			// Most names listed in a !pop response (defaults to 10)
+			MaxPopNames int `yaml:"max_pop_names"`
 		} `yaml:"queue"`
+		// Keywords that run a command without the prefix when a chat message
+		// is exactly one of them, keyed by command name
+		Triggers  map[string][]string `yaml:"triggers"`
 		Cooldowns struct {
 			Default   int `yaml:"default"`
 			Moderator int `yaml:"moderator"`
@@ -27,6 +107,28 @@ type Config struct {
 	} `yaml:"commands"`
 }
 
+// RedactedValue replaces sensitive values in exported configs
+const RedactedValue = "[REDACTED]"
+
+// Redacted returns a copy of the config with sensitive fields redacted.
+// Unset secrets stay empty, so they're still left out where omitted.
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	if redacted.OAuth != "" {
+		redacted.OAuth = RedactedValue
+	}
+	if redacted.ClientSecret != "" {
+		redacted.ClientSecret = RedactedValue
+	}
+	if redacted.Spotify.RefreshToken != "" {
+		redacted.Spotify.RefreshToken = RedactedValue
+	}
+	if redacted.LastFM.APIKey != "" {
+		redacted.LastFM.APIKey = RedactedValue
+	}
+	return &redacted
+}
+
 // Load loads the configuration from a YAML file
 func Load(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
@@ -67,6 +169,10 @@ func Load(path string) (*Config, error) {
 	if config.Commands.Queue.DefaultPopCount == 0 {
 		config.Commands.Queue.DefaultPopCount = 1
 	}
+	if config.Commands.Queue.UndoClearWindow == nil {
+		window := 60
+		config.Commands.Queue.UndoClearWindow = &window
+	}
 	if config.Commands.Cooldowns.Default == 0 {
 		config.Commands.Cooldowns.Default = 5
 	}
@@ -79,3 +185,60 @@ func Load(path string) (*Config, error) {
 
 	return &config, nil
 }
+
+// SetField updates a single value in the config file at path, leaving the
+// rest of the file (including comments) untouched. keys is the path to the
+// value, e.g. SetField(path, "America/Chicago", "timezone").
+func SetField(path string, value string, keys ...string) error {
+	if len(keys) == 0 {
+		return fmt.Errorf("no config key given")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("error parsing config file: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("config file is not a mapping")
+	}
+
+	node := doc.Content[0]
+	for i, key := range keys {
+		var child *yaml.Node
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value == key {
+				child = node.Content[j+1]
+				break
+			}
+		}
+
+		// Add missing keys, creating intermediate mappings as needed
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			if i == len(keys)-1 {
+				child = &yaml.Node{Kind: yaml.ScalarNode}
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, child)
+		}
+		node = child
+	}
+
+	node.Kind = yaml.ScalarNode
+	node.Tag = ""
+	node.Value = value
+	node.Content = nil
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return fmt.Errorf("error encoding config file: %w", err)
+	}
+	if err := os.WriteFile(path, out, 0644); err != nil {
+		return fmt.Errorf("error writing config file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package metrics
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the upper bounds of the latency histogram buckets.
+// Anything slower than the last bucket lands in an overflow bucket.
+var latencyBuckets = []time.Duration{
+	1 * time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	1 * time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// histogram is a fixed-bucket latency histogram
+type histogram struct {
+	counts []int // one per bucket, plus the overflow bucket
+	count  int
+	sum    time.Duration
+	max    time.Duration
+}
+
+func newHistogram() *histogram {
+	return &histogram{counts: make([]int, len(latencyBuckets)+1)}
+}
+
+// observe records one latency
+func (h *histogram) observe(d time.Duration) {
+	i := sort.Search(len(latencyBuckets), func(i int) bool { return d <= latencyBuckets[i] })
+	h.counts[i]++
+	h.count++
+	h.sum += d
+	if d > h.max {
+		h.max = d
+	}
+}
+
+// quantile returns the upper bound of the bucket holding the q-th quantile,
+// capped at the slowest latency seen
+func (h *histogram) quantile(q float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	rank := int(q*float64(h.count) + 0.999999)
+	seen := 0
+	for i, n := range h.counts {
+		seen += n
+		if seen >= rank {
+			if i < len(latencyBuckets) && latencyBuckets[i] < h.max {
+				return latencyBuckets[i]
+			}
+			return h.max
+		}
+	}
+	return h.max
+}
+
+// LatencyStats summarizes the recorded latencies of one command
+type LatencyStats struct {
+	Count   int
+	Average time.Duration
+	P95     time.Duration
+	Max     time.Duration
+}
+
+// MarshalJSON reports latencies in milliseconds
+func (s LatencyStats) MarshalJSON() ([]byte, error) {
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+	return json.Marshal(struct {
+		Count     int     `json:"count"`
+		AverageMs float64 `json:"average_ms"`
+		P95Ms     float64 `json:"p95_ms"`
+		MaxMs     float64 `json:"max_ms"`
+	}{s.Count, ms(s.Average), ms(s.P95), ms(s.Max)})
+}
+
+// CommandLatency tracks how long each command handler takes to run
+type CommandLatency struct {
+	mu         sync.Mutex
+	histograms map[string]*histogram
+}
+
+// NewCommandLatency creates an empty command latency tracker
+func NewCommandLatency() *CommandLatency {
+	return &CommandLatency{histograms: make(map[string]*histogram)}
+}
+
+// Observe records that command took d to handle
+func (c *CommandLatency) Observe(command string, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	h, exists := c.histograms[command]
+	if !exists {
+		h = newHistogram()
+		c.histograms[command] = h
+	}
+	h.observe(d)
+}
+
+// Stats returns the latency summary for one command
+func (c *CommandLatency) Stats(command string) (LatencyStats, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	h, exists := c.histograms[command]
+	if !exists {
+		return LatencyStats{}, false
+	}
+	return h.stats(), true
+}
+
+// Snapshot returns the latency summary of every command seen so far
+func (c *CommandLatency) Snapshot() map[string]LatencyStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snapshot := make(map[string]LatencyStats, len(c.histograms))
+	for command, h := range c.histograms {
+		snapshot[command] = h.stats()
+	}
+	return snapshot
+}
+
+// stats summarizes the histogram
+func (h *histogram) stats() LatencyStats {
+	stats := LatencyStats{
+		Count: h.count,
+		P95:   h.quantile(0.95),
+		Max:   h.max,
+	}
+	if h.count > 0 {
+		stats.Average = h.sum / time.Duration(h.count)
+	}
+	return stats
+}
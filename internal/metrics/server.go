@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Source provides one named section of the metrics report
+type Source func() interface{}
+
+// Handler serves the current value of each source as a JSON object
+func Handler(sources map[string]Source) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := make(map[string]interface{}, len(sources))
+		for name, source := range sources {
+			report[name] = source()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			log.Printf("[Metrics] Error encoding report: %v", err)
+		}
+	})
+}
+
+// Serve serves the metrics report at /metrics on addr until ctx is cancelled
+func Serve(ctx context.Context, addr string, sources map[string]Source) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler(sources))
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package music
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// lastFMURL is the Last.fm API endpoint
+var lastFMURL = "https://ws.audioscrobbler.com/2.0/"
+
+// LastFMProvider reports the track a Last.fm user is scrobbling
+type LastFMProvider struct {
+	apiKey     string
+	username   string
+	httpClient *http.Client
+}
+
+// NewLastFMProvider creates a provider for username's scrobbles
+func NewLastFMProvider(apiKey, username string) *LastFMProvider {
+	return &LastFMProvider{
+		apiKey:     apiKey,
+		username:   username,
+		httpClient: newHTTPClient(),
+	}
+}
+
+// lastFMText is a Last.fm field of the form {"#text": "..."}
+type lastFMText struct {
+	Text string `json:"#text"`
+}
+
+// CurrentTrack returns the user's most recent track if it is marked as
+// now playing
+func (p *LastFMProvider) CurrentTrack() (*Track, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	query := url.Values{
+		"method":  {"user.getrecenttracks"},
+		"user":    {p.username},
+		"api_key": {p.apiKey},
+		"format":  {"json"},
+		"limit":   {"1"},
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", lastFMURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+
+	// Last.fm reports errors in the body, sometimes with a 200 status
+	var apiErr struct {
+		Error   int    `json:"error"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != 0 {
+		return nil, &APIError{Provider: "lastfm", StatusCode: resp.StatusCode, Message: apiErr.Message}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &APIError{Provider: "lastfm", StatusCode: resp.StatusCode, Message: string(body)}
+	}
+
+	var result struct {
+		RecentTracks struct {
+			Track []struct {
+				Name   string     `json:"name"`
+				URL    string     `json:"url"`
+				Artist lastFMText `json:"artist"`
+				Album  lastFMText `json:"album"`
+				Attr   struct {
+					NowPlaying string `json:"nowplaying"`
+				} `json:"@attr"`
+			} `json:"track"`
+		} `json:"recenttracks"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	tracks := result.RecentTracks.Track
+	if len(tracks) == 0 || tracks[0].Attr.NowPlaying != "true" {
+		return nil, nil
+	}
+	return &Track{
+		Name:   tracks[0].Name,
+		Artist: tracks[0].Artist.Text,
+		Album:  tracks[0].Album.Text,
+		URL:    tracks[0].URL,
+	}, nil
+}
@@ -0,0 +1,70 @@
+package music
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestLastFMProvider points a Last.fm provider at server
+func newTestLastFMProvider(t *testing.T, server *httptest.Server) *LastFMProvider {
+	originalURL := lastFMURL
+	lastFMURL = server.URL
+	t.Cleanup(func() { lastFMURL = originalURL })
+
+	return NewLastFMProvider("test_api_key", "streamer")
+}
+
+func TestLastFMCurrentTrack(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("method") != "user.getrecenttracks" || query.Get("user") != "streamer" || query.Get("api_key") != "test_api_key" {
+			t.Errorf("Unexpected query: %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"recenttracks":{"track":[{
+			"name":"Around the World",
+			"url":"https://www.last.fm/music/Daft+Punk/_/Around+the+World",
+			"artist":{"#text":"Daft Punk"},
+			"album":{"#text":"Homework"},
+			"@attr":{"nowplaying":"true"}
+		}]}}`))
+	}))
+	defer server.Close()
+
+	track, err := newTestLastFMProvider(t, server).CurrentTrack()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "Around the World by Daft Punk (Homework) — www.last.fm/music/Daft+Punk/_/Around+the+World"
+	if track == nil || track.String() != expected {
+		t.Errorf("Expected %q, got %+v", expected, track)
+	}
+}
+
+func TestLastFMNothingPlaying(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The last scrobble isn't marked as now playing
+		w.Write([]byte(`{"recenttracks":{"track":[{"name":"Old Song","artist":{"#text":"Someone"},"album":{"#text":""}}]}}`))
+	}))
+	defer server.Close()
+
+	track, err := newTestLastFMProvider(t, server).CurrentTrack()
+	if err != nil || track != nil {
+		t.Errorf("Expected no track and no error, got %+v, %v", track, err)
+	}
+}
+
+func TestLastFMAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":10,"message":"Invalid API key - You must be granted a valid key by last.fm"}`))
+	}))
+	defer server.Close()
+
+	_, err := newTestLastFMProvider(t, server).CurrentTrack()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden || apiErr.Provider != "lastfm" {
+		t.Errorf("Expected a lastfm APIError with status 403, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package music
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/config"
+)
+
+// requestTimeout bounds every music API request so a slow API can't stall !song
+const requestTimeout = 10 * time.Second
+
+// DefaultCacheTTL is how long a now-playing lookup is reused
+const DefaultCacheTTL = 15 * time.Second
+
+// Track is a song reported by a music provider
+type Track struct {
+	Name   string
+	Artist string
+	Album  string
+	// Link to the track on the provider's site
+	URL string
+}
+
+// String formats the track as "Name by Artist (Album) — link"
+func (t *Track) String() string {
+	text := t.Name
+	if t.Artist != "" {
+		text += " by " + t.Artist
+	}
+	if t.Album != "" {
+		text += fmt.Sprintf(" (%s)", t.Album)
+	}
+	if link := strings.TrimPrefix(strings.TrimPrefix(t.URL, "https://"), "http://"); link != "" {
+		text += " — " + link
+	}
+	return text
+}
+
+// MusicProvider looks up the track currently playing on stream.
+// CurrentTrack returns a nil track and nil error when nothing is playing.
+type MusicProvider interface {
+	CurrentTrack() (*Track, error)
+}
+
+// APIError is returned when a music API responds with a non-success status
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Message    string
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s request failed with status %d: %s", e.Provider, e.StatusCode, e.Message)
+}
+
+// newHTTPClient returns the HTTP client used by the providers
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: requestTimeout}
+}
+
+// CachedProvider reuses another provider's answer for a short time so a
+// burst of !song commands only makes one API call
+type CachedProvider struct {
+	provider MusicProvider
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	track     *Track
+	fetchedAt time.Time
+}
+
+// NewCachedProvider wraps provider, caching results for ttl
+func NewCachedProvider(provider MusicProvider, ttl time.Duration) *CachedProvider {
+	return &CachedProvider{
+		provider: provider,
+		ttl:      ttl,
+		now:      time.Now,
+	}
+}
+
+// CurrentTrack returns the cached track, refreshing it once the TTL has
+// passed. Errors are not cached.
+func (c *CachedProvider) CurrentTrack() (*Track, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if !c.fetchedAt.IsZero() && now.Sub(c.fetchedAt) < c.ttl {
+		return c.track, nil
+	}
+
+	track, err := c.provider.CurrentTrack()
+	if err != nil {
+		return nil, err
+	}
+	c.track = track
+	c.fetchedAt = now
+	return track, nil
+}
+
+// NewProvider builds the provider selected by cfg.MusicProvider ("spotify"
+// or "lastfm"), wrapped in a cache. It returns nil when no provider is set.
+// configPath is the file cfg was loaded from, where a rotated Spotify
+// refresh token is saved.
+func NewProvider(cfg *config.Config, configPath string) (MusicProvider, error) {
+	var provider MusicProvider
+	switch strings.ToLower(cfg.MusicProvider) {
+	case "":
+		return nil, nil
+	case "spotify":
+		if cfg.Spotify.ClientID == "" || cfg.Spotify.RefreshToken == "" {
+			return nil, fmt.Errorf("spotify requires spotify.client_id and spotify.refresh_token")
+		}
+		provider = NewSpotifyProvider(cfg.Spotify.ClientID, cfg.Spotify.RefreshToken, configPath)
+	case "lastfm":
+		if cfg.LastFM.APIKey == "" || cfg.LastFM.Username == "" {
+			return nil, fmt.Errorf("lastfm requires lastfm.api_key and lastfm.username")
+		}
+		provider = NewLastFMProvider(cfg.LastFM.APIKey, cfg.LastFM.Username)
+	default:
+		return nil, fmt.Errorf("unknown music provider: %s", cfg.MusicProvider)
+	}
+	return NewCachedProvider(provider, DefaultCacheTTL), nil
+}
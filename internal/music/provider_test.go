@@ -0,0 +1,47 @@
+package music
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// countingProvider counts lookups and returns a fixed result
+type countingProvider struct {
+	calls int
+	track *Track
+	err   error
+}
+
+func (p *countingProvider) CurrentTrack() (*Track, error) {
+	p.calls++
+	return p.track, p.err
+}
+
+func TestCachedProvider(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	inner := &countingProvider{track: &Track{Name: "Song"}}
+	cached := NewCachedProvider(inner, DefaultCacheTTL)
+	cached.now = func() time.Time { return now }
+
+	cached.CurrentTrack()
+	now = now.Add(10 * time.Second)
+	if track, _ := cached.CurrentTrack(); track == nil || track.Name != "Song" || inner.calls != 1 {
+		t.Errorf("Expected a cached result within the TTL, got %+v after %d calls", track, inner.calls)
+	}
+
+	now = now.Add(5 * time.Second)
+	cached.CurrentTrack()
+	if inner.calls != 2 {
+		t.Errorf("Expected a fresh lookup after the TTL, got %d calls", inner.calls)
+	}
+
+	// Errors aren't cached
+	inner.err = errors.New("boom")
+	now = now.Add(DefaultCacheTTL)
+	cached.CurrentTrack()
+	cached.CurrentTrack()
+	if inner.calls != 4 {
+		t.Errorf("Expected errors not to be cached, got %d calls", inner.calls)
+	}
+}
@@ -0,0 +1,260 @@
+package music
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/config"
+)
+
+// Spotify endpoints, replaceable in tests
+var (
+	spotifyAuthorizeURL = "https://accounts.spotify.com/authorize"
+	spotifyTokenURL     = "https://accounts.spotify.com/api/token"
+	spotifyAPIURL       = "https://api.spotify.com/v1"
+)
+
+// SpotifyScope is the scope needed to read the currently playing track
+const SpotifyScope = "user-read-currently-playing"
+
+// SpotifyProvider reports the track playing on a Spotify account. It
+// authenticates with a refresh token obtained through the OAuth PKCE flow,
+// so no client secret is needed.
+type SpotifyProvider struct {
+	clientID   string
+	httpClient *http.Client
+	// Config file the refresh token is saved back to when Spotify rotates
+	// it ("" keeps it in memory only)
+	configPath string
+
+	mu           sync.Mutex
+	refreshToken string
+	accessToken  string
+	expiresAt    time.Time
+}
+
+// NewSpotifyProvider creates a provider for the account refreshToken belongs
+// to. Rotated refresh tokens are saved to spotify.refresh_token in the
+// config file at configPath, if one is given.
+func NewSpotifyProvider(clientID, refreshToken, configPath string) *SpotifyProvider {
+	return &SpotifyProvider{
+		clientID:     clientID,
+		refreshToken: refreshToken,
+		configPath:   configPath,
+		httpClient:   newHTTPClient(),
+	}
+}
+
+// NewPKCEVerifier returns a random PKCE code verifier and its S256 challenge
+func NewPKCEVerifier() (verifier, challenge string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("error generating code verifier: %w", err)
+	}
+	verifier = base64.RawURLEncoding.EncodeToString(buf)
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// SpotifyAuthURL returns the URL the streamer opens to authorize the bot
+func SpotifyAuthURL(clientID, redirectURI, challenge string) string {
+	query := url.Values{
+		"client_id":             {clientID},
+		"response_type":         {"code"},
+		"redirect_uri":          {redirectURI},
+		"code_challenge_method": {"S256"},
+		"code_challenge":        {challenge},
+		"scope":                 {SpotifyScope},
+	}
+	return spotifyAuthorizeURL + "?" + query.Encode()
+}
+
+// spotifyTokenResponse is the token endpoint's response
+type spotifyTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// ExchangeSpotifyCode trades the code from the authorize redirect for a
+// refresh token, completing the PKCE flow
+func ExchangeSpotifyCode(ctx context.Context, clientID, code, redirectURI, verifier string) (string, error) {
+	token, err := requestSpotifyToken(ctx, newHTTPClient(), url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {clientID},
+		"code_verifier": {verifier},
+	})
+	if err != nil {
+		return "", err
+	}
+	return token.RefreshToken, nil
+}
+
+// requestSpotifyToken posts form to the token endpoint
+func requestSpotifyToken(ctx context.Context, client *http.Client, form url.Values) (*spotifyTokenResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", spotifyTokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, spotifyError(resp)
+	}
+
+	var token spotifyTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("error decoding token response: %w", err)
+	}
+	return &token, nil
+}
+
+// getAccessToken returns a valid access token, refreshing it if needed.
+// Spotify may rotate the refresh token; the new one is saved to the config
+// file so the next run can still authenticate.
+func (p *SpotifyProvider) getAccessToken(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.accessToken != "" && time.Until(p.expiresAt) > time.Minute {
+		return p.accessToken, nil
+	}
+
+	token, err := requestSpotifyToken(ctx, p.httpClient, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {p.refreshToken},
+		"client_id":     {p.clientID},
+	})
+	if err != nil {
+		return "", fmt.Errorf("error refreshing spotify token: %w", err)
+	}
+
+	p.accessToken = token.AccessToken
+	p.expiresAt = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	if token.RefreshToken != "" && token.RefreshToken != p.refreshToken {
+		p.refreshToken = token.RefreshToken
+		if err := p.persistRefreshToken(); err != nil {
+			log.Printf("Error saving rotated Spotify refresh token: %v", err)
+		}
+	}
+	return p.accessToken, nil
+}
+
+// persistRefreshToken saves the refresh token to the config file. Callers
+// must hold p.mu.
+func (p *SpotifyProvider) persistRefreshToken() error {
+	if p.configPath == "" {
+		return nil
+	}
+	return config.SetField(p.configPath, p.refreshToken, "spotify", "refresh_token")
+}
+
+// CurrentTrack returns the track playing on the account, or nil when
+// playback is stopped or paused
+func (p *SpotifyProvider) CurrentTrack() (*Track, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	accessToken, err := p.getAccessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", spotifyAPIURL+"/me/player/currently-playing", nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 204 means nothing is playing
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, spotifyError(resp)
+	}
+
+	var playing struct {
+		IsPlaying bool `json:"is_playing"`
+		Item      *struct {
+			Name    string `json:"name"`
+			Artists []struct {
+				Name string `json:"name"`
+			} `json:"artists"`
+			Album struct {
+				Name string `json:"name"`
+			} `json:"album"`
+			ExternalURLs struct {
+				Spotify string `json:"spotify"`
+			} `json:"external_urls"`
+		} `json:"item"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&playing); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+	if !playing.IsPlaying || playing.Item == nil {
+		return nil, nil
+	}
+
+	artists := make([]string, len(playing.Item.Artists))
+	for i, artist := range playing.Item.Artists {
+		artists[i] = artist.Name
+	}
+	return &Track{
+		Name:   playing.Item.Name,
+		Artist: strings.Join(artists, ", "),
+		Album:  playing.Item.Album.Name,
+		URL:    playing.Item.ExternalURLs.Spotify,
+	}, nil
+}
+
+// spotifyError builds an APIError from a failed Spotify response
+func spotifyError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	message := string(body)
+
+	// The Web API nests the message; the accounts service uses OAuth fields
+	var apiErr struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	var oauthErr struct {
+		Error       string `json:"error"`
+		Description string `json:"error_description"`
+	}
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Error.Message != "" {
+		message = apiErr.Error.Message
+	} else if json.Unmarshal(body, &oauthErr) == nil && oauthErr.Error != "" {
+		message = oauthErr.Error
+		if oauthErr.Description != "" {
+			message += ": " + oauthErr.Description
+		}
+	}
+	return &APIError{Provider: "spotify", StatusCode: resp.StatusCode, Message: message}
+}
@@ -0,0 +1,126 @@
+package music
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pbuckles22/PBChatBot/internal/config"
+)
+
+// newTestSpotifyProvider points a Spotify provider at a server that issues
+// tokens and answers currently-playing requests with playing. Its refresh
+// token is saved in a temporary channel config.
+func newTestSpotifyProvider(t *testing.T, playing http.HandlerFunc) *SpotifyProvider {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("refresh_token") != "test_refresh_token" || r.Form.Get("client_id") != "test_client_id" {
+			t.Errorf("Unexpected token request: %v", r.Form)
+		}
+		w.Write([]byte(`{"access_token":"test_access_token","expires_in":3600,"refresh_token":"rotated_refresh_token"}`))
+	})
+	mux.HandleFunc("/v1/me/player/currently-playing", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test_access_token" {
+			t.Errorf("Unexpected Authorization header: %s", r.Header.Get("Authorization"))
+		}
+		playing(w, r)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	originalTokenURL, originalAPIURL := spotifyTokenURL, spotifyAPIURL
+	spotifyTokenURL = server.URL + "/api/token"
+	spotifyAPIURL = server.URL + "/v1"
+	t.Cleanup(func() { spotifyTokenURL, spotifyAPIURL = originalTokenURL, originalAPIURL })
+
+	configPath := filepath.Join(t.TempDir(), "testchannel_config_secrets.yaml")
+	configYAML := "bot_name: testbot\nchannel: testchannel\nspotify:\n  client_id: test_client_id\n  refresh_token: test_refresh_token\n"
+	if err := os.WriteFile(configPath, []byte(configYAML), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return NewSpotifyProvider("test_client_id", "test_refresh_token", configPath)
+}
+
+func TestSpotifyCurrentTrack(t *testing.T) {
+	provider := newTestSpotifyProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"is_playing":true,"item":{
+			"name":"One More Time",
+			"artists":[{"name":"Daft Punk"}],
+			"album":{"name":"Discovery"},
+			"external_urls":{"spotify":"https://open.spotify.com/track/0DiWol3AO6WpXZgp0goxAV"}
+		}}`))
+	})
+
+	track, err := provider.CurrentTrack()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "One More Time by Daft Punk (Discovery) — open.spotify.com/track/0DiWol3AO6WpXZgp0goxAV"
+	if track == nil || track.String() != expected {
+		t.Errorf("Expected %q, got %+v", expected, track)
+	}
+	if provider.refreshToken != "rotated_refresh_token" {
+		t.Errorf("Expected the rotated refresh token to be kept, got %s", provider.refreshToken)
+	}
+
+	// The rotated token is saved so the next run can still authenticate
+	cfg, err := config.Load(provider.configPath)
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if cfg.Spotify.RefreshToken != "rotated_refresh_token" || cfg.Spotify.ClientID != "test_client_id" {
+		t.Errorf("Expected the rotated refresh token in the config file, got %+v", cfg.Spotify)
+	}
+}
+
+func TestSpotifyNothingPlaying(t *testing.T) {
+	provider := newTestSpotifyProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	track, err := provider.CurrentTrack()
+	if err != nil || track != nil {
+		t.Errorf("Expected no track and no error, got %+v, %v", track, err)
+	}
+}
+
+func TestSpotifyAPIError(t *testing.T) {
+	provider := newTestSpotifyProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"status":429,"message":"API rate limit exceeded"}}`))
+	})
+
+	_, err := provider.CurrentTrack()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests || apiErr.Message != "API rate limit exceeded" {
+		t.Errorf("Expected a spotify APIError with status 429, got %v", err)
+	}
+}
+
+func TestSpotifyPKCE(t *testing.T) {
+	verifier, challenge, err := NewPKCEVerifier()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	if challenge != base64.RawURLEncoding.EncodeToString(sum[:]) {
+		t.Errorf("Challenge doesn't match the verifier")
+	}
+
+	authURL, err := url.Parse(SpotifyAuthURL("test_client_id", "http://localhost:8888/callback", challenge))
+	if err != nil || !strings.HasPrefix(authURL.String(), spotifyAuthorizeURL) {
+		t.Fatalf("Unexpected auth URL: %v, %v", authURL, err)
+	}
+	query := authURL.Query()
+	if query.Get("code_challenge") != challenge || query.Get("code_challenge_method") != "S256" || query.Get("scope") != SpotifyScope {
+		t.Errorf("Unexpected auth URL query: %s", authURL.RawQuery)
+	}
+}
@@ -0,0 +1,156 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pbuckles22/PBChatBot/internal/utils"
+)
+
+// ErrUserBanned is returned when a user on the !qban list tries to join
+var ErrUserBanned = errors.New("user is barred from joining the queue")
+
+// normalizeLogin turns a username as typed in chat ("@User") into a login
+func normalizeLogin(username string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(username), "@"))
+}
+
+// Ban bars a user from joining the queue or VIP line. The blacklist is kept
+// in its own file, so it survives clears, restarts, disabling the queue and
+// restoring older queue snapshots. Returns false if the user was already
+// banned.
+func (q *Queue) Ban(username string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	login := normalizeLogin(username)
+	if login == "" || q.banned[login] {
+		return false
+	}
+	q.banned[login] = true
+	if err := q.saveBlacklistLocked(); err != nil {
+		log.Printf("Error saving queue blacklist: %v", err)
+	}
+	return true
+}
+
+// Unban lets a banned user join again. Returns false if they weren't banned.
+func (q *Queue) Unban(username string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	login := normalizeLogin(username)
+	if !q.banned[login] {
+		return false
+	}
+	delete(q.banned, login)
+	if err := q.saveBlacklistLocked(); err != nil {
+		log.Printf("Error saving queue blacklist: %v", err)
+	}
+	return true
+}
+
+// IsBanned reports whether a user is on the blacklist
+func (q *Queue) IsBanned(username string) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.banned[normalizeLogin(username)]
+}
+
+// ListBanned returns the blacklisted logins in alphabetical order
+func (q *Queue) ListBanned() []string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	logins := make([]string, 0, len(q.banned))
+	for login := range q.banned {
+		logins = append(logins, login)
+	}
+	sort.Strings(logins)
+	return logins
+}
+
+// SetBlacklistBlocksMods sets whether the blacklist also stops moderators
+// from adding a banned user. By default mods can still add them.
+func (q *Queue) SetBlacklistBlocksMods(blocks bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.blacklistBlocksMods = blocks
+}
+
+// checkBlacklist returns an error if username is banned from joining.
+// Caller must hold the lock.
+func (q *Queue) checkBlacklist(username string, isMod bool) error {
+	if isMod && !q.blacklistBlocksMods {
+		return nil
+	}
+	if q.banned[normalizeLogin(username)] {
+		return ErrUserBanned
+	}
+	return nil
+}
+
+// blacklistFile returns the path of the channel's blacklist file
+func (q *Queue) blacklistFile() string {
+	return filepath.Join(q.dataPath, fmt.Sprintf("blacklist_%s.json", q.channel))
+}
+
+// saveBlacklistLocked writes the blacklist to its file. Caller must hold
+// the lock.
+func (q *Queue) saveBlacklistLocked() error {
+	logins := make([]string, 0, len(q.banned))
+	for login := range q.banned {
+		logins = append(logins, login)
+	}
+	sort.Strings(logins)
+
+	data, err := json.MarshalIndent(logins, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal queue blacklist: %w", err)
+	}
+	if err := os.MkdirAll(q.dataPath, 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	if err := utils.WriteFileAtomic(q.blacklistFile(), data); err != nil {
+		return fmt.Errorf("failed to write queue blacklist: %w", err)
+	}
+	return nil
+}
+
+// loadBlacklist loads the blacklist from its file. Without one, bans saved
+// in the auto-save by older versions are moved into a new file.
+func (q *Queue) loadBlacklist() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	data, err := os.ReadFile(q.blacklistFile())
+	if errors.Is(err, os.ErrNotExist) {
+		state, stateErr := q.readStateFile("queue_state")
+		if stateErr != nil || len(state.Blacklist) == 0 {
+			return nil
+		}
+		for _, login := range state.Blacklist {
+			q.banned[normalizeLogin(login)] = true
+		}
+		return q.saveBlacklistLocked()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read queue blacklist: %w", err)
+	}
+
+	var logins []string
+	if err := json.Unmarshal(data, &logins); err != nil {
+		return fmt.Errorf("failed to parse queue blacklist: %w", err)
+	}
+	q.banned = make(map[string]bool)
+	for _, login := range logins {
+		q.banned[normalizeLogin(login)] = true
+	}
+	return nil
+}
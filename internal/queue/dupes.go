@@ -0,0 +1,52 @@
+package queue
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DuplicateEntry is a queue entry reported by Duplicates, with its 1-based
+// position
+type DuplicateEntry struct {
+	Username string
+	Position int
+}
+
+// canonicalLogin reduces a username to the login it most likely stands for,
+// dropping whitespace and invisible formatting characters such as zero-width
+// spaces that slip past the case-insensitive duplicate check on join
+func canonicalLogin(username string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, username)
+	return normalizeLogin(cleaned)
+}
+
+// Duplicates returns groups of queue entries that normalize to the same
+// login, each group in queue order and the groups ordered by their first
+// entry
+func (q *Queue) Duplicates() [][]DuplicateEntry {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	groups := make(map[string][]DuplicateEntry)
+	for i, user := range q.users {
+		login := canonicalLogin(user)
+		groups[login] = append(groups[login], DuplicateEntry{Username: user, Position: i + 1})
+	}
+
+	var dupes [][]DuplicateEntry
+	for _, group := range groups {
+		if len(group) > 1 {
+			dupes = append(dupes, group)
+		}
+	}
+	sort.Slice(dupes, func(i, j int) bool {
+		return dupes[i][0].Position < dupes[j][0].Position
+	})
+	return dupes
+}
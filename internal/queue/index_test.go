@@ -0,0 +1,144 @@
+package queue
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// checkIndex fails the test if q.index doesn't match q.users
+func checkIndex(t *testing.T, q *Queue, step int, op string) {
+	t.Helper()
+	expected := make(map[string]int)
+	for i, user := range q.users {
+		key := strings.ToLower(user)
+		if _, ok := expected[key]; !ok {
+			expected[key] = i
+		}
+	}
+	if len(q.index) != len(expected) {
+		t.Fatalf("step %d (%s): index has %d entries, queue has %d users: %v vs %v", step, op, len(q.index), len(expected), q.index, q.users)
+	}
+	for key, i := range expected {
+		if got, ok := q.index[key]; !ok || got != i {
+			t.Fatalf("step %d (%s): index[%q] = %d, %v; want %d (queue %v)", step, op, key, got, ok, i, q.users)
+		}
+	}
+}
+
+func TestIndexMatchesQueueThroughRandomOperations(t *testing.T) {
+	q := NewQueue(t.TempDir(), "testchannel_index")
+	q.Enable()
+	q.SetWaitlistEnabled(true)
+	q.SetMaxSize(15)
+
+	rng := rand.New(rand.NewSource(1457))
+	name := func() string {
+		// Mixed case so lookups have to ignore it
+		user := fmt.Sprintf("user%d", rng.Intn(30))
+		if rng.Intn(2) == 0 {
+			user = strings.ToUpper(user)
+		}
+		return user
+	}
+	queued := func() string {
+		if len(q.users) == 0 {
+			return name()
+		}
+		return q.users[rng.Intn(len(q.users))]
+	}
+
+	for step := 0; step < 3000; step++ {
+		var op string
+		switch rng.Intn(16) {
+		case 0, 1, 2:
+			op = "Add"
+			q.Add(name(), rng.Intn(2) == 0)
+		case 3:
+			op = "AddAtPosition"
+			q.AddAtPosition(name(), rng.Intn(len(q.users)+2), true)
+		case 4:
+			op = "Remove"
+			q.Remove(name())
+		case 5:
+			op = "RemoveUser"
+			q.RemoveUser(queued())
+		case 6:
+			op = "Pop"
+			q.Pop()
+		case 7:
+			op = "PopN"
+			q.PopN(rng.Intn(4) + 1)
+		case 8:
+			op = "MoveUser"
+			q.MoveUser(queued(), rng.Intn(len(q.users)+1)+1)
+		case 9:
+			op = "MoveToEnd"
+			q.MoveToEnd(queued())
+		case 10:
+			op = "MoveToFront"
+			q.MoveToFront(queued())
+		case 11:
+			op = "MoveBatch"
+			q.MoveBatch([]string{queued(), queued()}, rng.Intn(len(q.users)+1)+1)
+		case 12:
+			op = "SkipTo"
+			q.SkipTo(queued())
+		case 13:
+			op = "Pin"
+			if rng.Intn(2) == 0 {
+				q.Pin(queued())
+			} else {
+				q.Unpin(queued())
+			}
+		case 14:
+			op = "AddVIP"
+			q.AddVIP(name())
+		case 15:
+			if rng.Intn(10) == 0 {
+				op = "Clear"
+				q.Clear()
+			} else {
+				op = "UndoClear"
+				q.UndoClear()
+			}
+		}
+
+		q.mu.RLock()
+		checkIndex(t, q, step, op)
+		for i, user := range q.users {
+			if got := q.indexOf(strings.ToUpper(user)); got != i {
+				t.Fatalf("step %d (%s): indexOf(%q) = %d, want %d", step, op, user, got, i)
+			}
+		}
+		q.mu.RUnlock()
+	}
+
+	if err := q.waitForSaves(); err != nil {
+		t.Fatalf("Auto-save failed: %v", err)
+	}
+}
+
+func TestIndexRebuiltOnLoad(t *testing.T) {
+	dir := t.TempDir()
+	q := NewQueue(dir, "testchannel_index_load")
+	q.Enable()
+	for _, user := range []string{"Alice", "bob", "Carol"} {
+		q.Add(user, false)
+	}
+	if err := q.SaveState(); err != nil {
+		t.Fatalf("Failed to save queue: %v", err)
+	}
+
+	loaded := NewQueue(dir, "testchannel_index_load")
+	if pos := loaded.Position("CAROL"); pos != 3 {
+		t.Errorf("Expected Carol at position 3 after loading, got %d", pos)
+	}
+	if user := loaded.Find("alice"); user != "Alice" {
+		t.Errorf("Expected Find to return the stored spelling Alice, got %q", user)
+	}
+	if user := loaded.Find("dave"); user != "" {
+		t.Errorf("Expected Find to return \"\" for a user not in the queue, got %q", user)
+	}
+}
@@ -0,0 +1,108 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/pbuckles22/PBChatBot/internal/utils"
+)
+
+// legacyStateFile is the auto-save filename used before queue state files
+// were named per channel
+const legacyStateFile = "queue_state.json"
+
+// MigrateState copies a legacy queue_state.json into this channel's
+// auto-save file. It does nothing if the channel already has an auto-save
+// or there is no legacy file. The legacy file is left in place.
+func (q *Queue) MigrateState() error {
+	filename := filepath.Join(q.dataPath, fmt.Sprintf("queue_state_%s.json", q.channel))
+	if _, err := os.Stat(filename); err == nil {
+		return nil
+	}
+
+	legacy := filepath.Join(q.dataPath, legacyStateFile)
+	data, err := os.ReadFile(legacy)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read legacy queue state: %w", err)
+	}
+
+	state, err := decodeState(data)
+	if err != nil {
+		return fmt.Errorf("legacy queue state: %w", err)
+	}
+	if state.Channel != q.channel {
+		return fmt.Errorf("legacy queue state channel mismatch: expected %s, got %s", q.channel, state.Channel)
+	}
+
+	if err := utils.WriteFileAtomic(filename, data); err != nil {
+		return fmt.Errorf("failed to write migrated queue state: %w", err)
+	}
+	log.Printf("Migrated queue state for %s from %s to %s", q.channel, legacy, filename)
+	return nil
+}
+
+// CurrentStateVersion is the queue state file format written by this build.
+// Version 0 files (no "version" field) store the queue as a list of
+// usernames; version 1 stores QueuedUser entries with join times.
+const CurrentStateVersion = 1
+
+// ErrUnsupportedStateVersion is returned when a state file was written by a
+// newer build than this one
+var ErrUnsupportedStateVersion = errors.New("unsupported queue state version")
+
+// storedState is a state file as read from disk, with the queue left
+// undecoded until its format version is known
+type storedState struct {
+	QueueState
+	Queue json.RawMessage `json:"queue"`
+}
+
+// decodeState parses a state file of any supported version, upgrading it to
+// the current in-memory model
+func decodeState(data []byte) (*QueueState, error) {
+	var stored storedState
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal queue state: %w", err)
+	}
+	state := stored.QueueState
+	if state.Version > CurrentStateVersion {
+		return nil, fmt.Errorf("%w: file is version %d, this build reads up to %d", ErrUnsupportedStateVersion, state.Version, CurrentStateVersion)
+	}
+
+	if len(stored.Queue) > 0 && string(stored.Queue) != "null" {
+		switch state.Version {
+		case 0:
+			// v0 -> v1: plain usernames become entries without join times
+			var users []string
+			if err := json.Unmarshal(stored.Queue, &users); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal v0 queue: %w", err)
+			}
+			state.Queue = make([]QueuedUser, len(users))
+			for i, user := range users {
+				state.Queue[i] = QueuedUser{Username: user}
+			}
+		default:
+			if err := json.Unmarshal(stored.Queue, &state.Queue); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal queue: %w", err)
+			}
+		}
+	}
+	state.Version = CurrentStateVersion
+	return &state, nil
+}
+
+// queuedUsernames returns the usernames of entries in order
+func queuedUsernames(entries []QueuedUser) []string {
+	users := make([]string, len(entries))
+	for i, entry := range entries {
+		users[i] = entry.Username
+	}
+	return users
+}
@@ -0,0 +1,23 @@
+package queue
+
+// NowServing returns the users popped since the now-serving list was last
+// cleared, in the order they were popped
+func (q *Queue) NowServing() []string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return append([]string(nil), q.nowServing...)
+}
+
+// ClearNowServing empties the now-serving list, e.g. when a batch of games
+// is finished. Returns the number of users removed from it.
+func (q *Queue) ClearNowServing() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	count := len(q.nowServing)
+	if count > 0 {
+		q.nowServing = nil
+		q.autoSave() // Auto-save after clearing now-serving
+	}
+	return count
+}
@@ -0,0 +1,114 @@
+package queue
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// ErrUserPinned is returned when moving a pinned user
+var ErrUserPinned = errors.New("user is pinned; unpin them first")
+
+// Pin keeps a queued user at their current position: moves of other users
+// and subscriber promotions leave them in place, and pops skip them until
+// they are unpinned or removed. Returns false if the user isn't queued.
+func (q *Queue) Pin(username string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.indexOf(username) == -1 {
+		return false
+	}
+	q.pinned[strings.ToLower(username)] = true
+	q.autoSave() // Auto-save after pinning user
+	return true
+}
+
+// Unpin releases a pinned user. Returns false if the user wasn't pinned.
+func (q *Queue) Unpin(username string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	key := strings.ToLower(username)
+	if !q.pinned[key] || q.indexOf(username) == -1 {
+		return false
+	}
+	delete(q.pinned, key)
+	q.autoSave() // Auto-save after unpinning user
+	return true
+}
+
+// IsPinned reports whether a queued user is pinned
+func (q *Queue) IsPinned(username string) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.pinned[strings.ToLower(username)] && q.indexOf(username) != -1
+}
+
+// isPinnedLocked reports whether user is pinned. Callers must hold q.mu.
+func (q *Queue) isPinnedLocked(user string) bool {
+	return q.pinned[strings.ToLower(user)]
+}
+
+// pinnedIndexes returns the queue index of every pinned user, to be passed
+// to restorePins after reordering. Callers must hold q.mu.
+func (q *Queue) pinnedIndexes() map[int]string {
+	pins := make(map[int]string)
+	for i, user := range q.users {
+		if q.isPinnedLocked(user) {
+			pins[i] = user
+		}
+	}
+	return pins
+}
+
+// restorePins puts pinned users back at the indexes recorded by
+// pinnedIndexes. Callers must hold q.mu.
+func (q *Queue) restorePins(pins map[int]string) {
+	if len(pins) == 0 {
+		return
+	}
+	users := make([]string, 0, len(q.users))
+	for _, user := range q.users {
+		if !q.isPinnedLocked(user) {
+			users = append(users, user)
+		}
+	}
+
+	indexes := make([]int, 0, len(pins))
+	for i := range pins {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	for _, i := range indexes {
+		at := i
+		if at > len(users) {
+			at = len(users)
+		}
+		users = append(users[:at], append([]string{pins[i]}, users[at:]...)...)
+	}
+	q.setUsers(users)
+}
+
+// nextUnpinned returns the index of the first user pops may take, or -1.
+// Callers must hold q.mu.
+func (q *Queue) nextUnpinned() int {
+	for i, user := range q.users {
+		if !q.isPinnedLocked(user) {
+			return i
+		}
+	}
+	return -1
+}
+
+// poppable returns how many users pops may take from both lines. Callers
+// must hold q.mu.
+func (q *Queue) poppable() int {
+	count := len(q.vipUsers)
+	for _, user := range q.users {
+		if !q.isPinnedLocked(user) {
+			count++
+		}
+	}
+	return count
+}
@@ -2,48 +2,169 @@ package queue
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/utils"
 )
 
-// QueuedUser represents a user in the queue
+// DefaultUndoClearWindow is how long a cleared queue can be restored by default
+const DefaultUndoClearWindow = 60 * time.Second
+
+// QueuedUser represents a user in the queue, as saved in the state file
 type QueuedUser struct {
-	Username string
-	JoinTime time.Time
-	IsMod    bool
+	Username string     `json:"username"`
+	JoinTime *time.Time `json:"join_time,omitempty"` // Nil when unknown, e.g. upgraded v0 files
+	IsMod    bool       `json:"is_mod,omitempty"`
+}
+
+// AlreadyQueuedError is returned when adding a user who is already in the
+// queue. Position is their current 1-based position.
+type AlreadyQueuedError struct {
+	Position int
+}
+
+// Error implements the error interface
+func (e *AlreadyQueuedError) Error() string {
+	return "user is already in queue"
+}
+
+// ErrQueueFull is returned by Add and AddAtPosition when the queue has
+// reached its max size
+var ErrQueueFull = errors.New("queue is full")
+
+// ErrQueueDisabled is returned when adding to a queue that is turned off
+var ErrQueueDisabled = errors.New("queue system is currently disabled")
+
+// ErrQueuePaused is returned when a viewer joins a paused queue
+var ErrQueuePaused = errors.New("queue system is currently paused")
+
+// RejoinCooldownError is returned by Add when a recently served user tries
+// to rejoin before rejoin_cooldown has passed
+type RejoinCooldownError struct {
+	// Time left until the user can rejoin
+	Wait time.Duration
+}
+
+// Error implements the error interface
+func (e *RejoinCooldownError) Error() string {
+	return fmt.Sprintf("you can rejoin in %s", e.Wait.Round(time.Second))
 }
 
 // QueueState represents the persistent state of the queue
 type QueueState struct {
-	Channel     string   `json:"channel"`      // Channel name this queue belongs to
-	Queue       []string `json:"queue"`        // List of usernames in queue
-	LastUpdated int64    `json:"last_updated"` // Unix timestamp of last update
+	Version     int          `json:"version"`               // Format version; see CurrentStateVersion
+	Channel     string       `json:"channel"`               // Channel name this queue belongs to
+	Queue       []QueuedUser `json:"queue"`                 // Users in queue order
+	LastUpdated int64        `json:"last_updated"`          // Unix timestamp of last update
+	Reserved    []string     `json:"reserved,omitempty"`    // Users holding a slot reserved by a mod
+	Pinned      []string     `json:"pinned,omitempty"`      // Users pinned in place with !pin
+	VIPQueue    []string     `json:"vip_queue,omitempty"`   // Users in the VIP fast-pass line
+	Blacklist   []string     `json:"blacklist,omitempty"`   // Legacy; bans now live in blacklist_<channel>.json
+	Subscribers []string     `json:"subscribers,omitempty"` // Queued users who joined as subscribers
+	Ended       bool         `json:"ended,omitempty"`       // Queue was ended; don't restore it on startup
+	NowServing  []string     `json:"now_serving,omitempty"` // Users popped since !clearserved
+	Waitlist    []string     `json:"waitlist,omitempty"`    // Users waiting for a main queue slot
 }
 
 // Queue represents a queue of users
 type Queue struct {
-	users    []string
+	users []string
+	// Position of each queued user in users (keyed by lowercase username).
+	// Only changed through setUsers, insertAt and removeAt.
+	index    map[string]int
 	mu       sync.RWMutex
 	dataPath string
 	channel  string
 	enabled  bool
 	paused   bool
+	// Why the queue was paused, shown to users who try to join ("" if none)
+	pauseReason string
+	// When each user was last popped from the queue (keyed by lowercase username)
+	served map[string]time.Time
+	// How long a served user must wait before rejoining (0 disables)
+	rejoinCooldown time.Duration
+	// Snapshot of the last cleared queue, VIP line and waitlist, restorable
+	// until the window expires
+	clearedUsers    []string
+	clearedVIPs     []string
+	clearedWaitlist []string
+	clearedAt       time.Time
+	undoClearWindow time.Duration
+	// Users whose slot was reserved by a mod (keyed by lowercase username).
+	// Entries only count while the user is still in the queue.
+	reserved map[string]bool
+	// Users pinned in place with !pin (keyed by lowercase username).
+	// Entries only count while the user is still in the queue.
+	pinned map[string]bool
+	// Queued users who were subscribed when they joined (keyed by lowercase username)
+	subscribers map[string]bool
+	// Users popped since the now-serving list was last cleared
+	nowServing []string
+	// Current queue session, started when the queue is enabled
+	sessionStart  time.Time
+	sessionServed []ServedUser
+	// When each queued user joined (keyed by lowercase username)
+	joinedAt map[string]time.Time
+	// VIP fast-pass line, served every vipInterleave-th pop
+	vipUsers      []string
+	vipInterleave int
+	popCount      int
+	// Logins barred from joining (lowercase), and whether that applies to
+	// users added by mods too
+	banned              map[string]bool
+	blacklistBlocksMods bool
+	// Most users the queue holds at once (0 means unlimited)
+	maxSize int
+	// Joins past maxSize wait here when waitlistEnabled, and are moved into
+	// the main queue as slots free up. onPromote is told who was moved.
+	waitlist        []string
+	waitlistEnabled bool
+	onPromote       func(users []string)
+	// Background auto-save state: the latest unwritten snapshot, whether the
+	// writer goroutine is running, and the result of its last write.
+	// Guarded by saveMu rather than mu; saveIdle is signalled when the
+	// writer stops.
+	saveMu      sync.Mutex
+	saveIdle    *sync.Cond
+	pendingSave []byte
+	saving      bool
+	saveErr     error
+	// Users kept in the auto-save by End(true), restorable with
+	// RestoreAutoSave until the queue is enabled again
+	endedUsers []string
 }
 
 // NewQueue creates a new queue manager
 func NewQueue(dataPath string, channel string) *Queue {
 	q := &Queue{
-		users:    make([]string, 0),
-		dataPath: dataPath,
-		channel:  channel,
-		enabled:  false,
-		paused:   false,
+		users:       make([]string, 0),
+		index:       make(map[string]int),
+		dataPath:    utils.EnsureWritableDataPath(dataPath),
+		channel:     channel,
+		enabled:     false,
+		paused:      false,
+		served:      make(map[string]time.Time),
+		reserved:    make(map[string]bool),
+		pinned:      make(map[string]bool),
+		subscribers: make(map[string]bool),
+		joinedAt:    make(map[string]time.Time),
+		banned:      make(map[string]bool),
+
+		undoClearWindow: DefaultUndoClearWindow,
+		vipInterleave:   DefaultVIPInterleave,
 	}
+	q.saveIdle = sync.NewCond(&q.saveMu)
 	q.LoadState()
+	if err := q.loadBlacklist(); err != nil {
+		log.Printf("Error loading queue blacklist: %v", err)
+	}
 	return q
 }
 
@@ -51,8 +172,14 @@ func NewQueue(dataPath string, channel string) *Queue {
 func (q *Queue) Enable() {
 	q.mu.Lock()
 	defer q.mu.Unlock()
+	if !q.enabled {
+		// Start a new session for SessionSummary
+		q.sessionStart = time.Now()
+		q.sessionServed = nil
+	}
 	q.enabled = true
 	q.paused = false
+	q.endedUsers = nil
 	// Don't clear the queue when enabling - let LoadState handle it
 	q.autoSave() // Auto-save after enabling
 }
@@ -61,19 +188,55 @@ func (q *Queue) Enable() {
 func (q *Queue) Disable() {
 	q.mu.Lock()
 	defer q.mu.Unlock()
+	q.disableLocked()
+	q.endedUsers = nil
+	q.autoSave() // Auto-save after disabling (saves empty queue)
+}
+
+// End stops the queue system and clears it like Disable, then saves the
+// auto-save file before returning. With preserve, the queued users stay in
+// the file marked as ended: they aren't restored on the next startup, but
+// RestoreAutoSave brings them back until the queue is enabled again.
+func (q *Queue) End(preserve bool) error {
+	q.mu.Lock()
+	var ended []string
+	if preserve {
+		ended = append([]string{}, q.users...)
+	}
+	q.disableLocked()
+	q.endedUsers = ended
+	q.mu.Unlock()
+
+	return q.SaveState()
+}
+
+// disableLocked stops the queue system and clears the queue. Caller must
+// hold the lock.
+func (q *Queue) disableLocked() {
 	q.enabled = false
 	q.paused = false
-	q.users = make([]string, 0)
-	q.autoSave() // Auto-save after disabling (saves empty queue)
+	q.pauseReason = ""
+	q.setUsers(make([]string, 0))
+	q.pinned = make(map[string]bool)
+	q.vipUsers = nil
+	q.waitlist = nil
+	q.popCount = 0
+	q.joinedAt = make(map[string]time.Time)
 }
 
 // Pause pauses the queue system (no new additions allowed)
 func (q *Queue) Pause() error {
+	return q.PauseWithReason("")
+}
+
+// PauseWithReason pauses the queue like Pause and records why, so blocked
+// joins can say so. The reason is cleared on unpause.
+func (q *Queue) PauseWithReason(reason string) error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
 	if !q.enabled {
-		return fmt.Errorf("queue system is currently disabled")
+		return ErrQueueDisabled
 	}
 
 	if q.paused {
@@ -81,6 +244,7 @@ func (q *Queue) Pause() error {
 	}
 
 	q.paused = true
+	q.pauseReason = reason
 	q.autoSave() // Auto-save after pausing
 	return nil
 }
@@ -91,7 +255,7 @@ func (q *Queue) Unpause() error {
 	defer q.mu.Unlock()
 
 	if !q.enabled {
-		return fmt.Errorf("queue system is currently disabled")
+		return ErrQueueDisabled
 	}
 
 	if !q.paused {
@@ -99,10 +263,19 @@ func (q *Queue) Unpause() error {
 	}
 
 	q.paused = false
+	q.pauseReason = ""
 	q.autoSave() // Auto-save after unpausing
 	return nil
 }
 
+// PauseReason returns why the queue was paused, or "" if it isn't paused or
+// no reason was given
+func (q *Queue) PauseReason() string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.pauseReason
+}
+
 // IsPaused returns whether the queue system is paused
 func (q *Queue) IsPaused() bool {
 	q.mu.RLock()
@@ -117,55 +290,180 @@ func (q *Queue) IsEnabled() bool {
 	return q.enabled
 }
 
-// Clear removes all users from the queue
+// Clear removes all users from the queue, the VIP line and the waitlist.
+// Returns the number of users removed from the queue and the VIP line.
 func (q *Queue) Clear() int {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	count := len(q.users)
-	q.users = make([]string, 0)
+	count := len(q.users) + len(q.vipUsers)
+	if count > 0 && q.undoClearWindow > 0 {
+		// Keep the cleared lists so they can be restored with UndoClear
+		q.clearedUsers = q.users
+		q.clearedVIPs = q.vipUsers
+		q.clearedWaitlist = q.waitlist
+		q.clearedAt = time.Now()
+	}
+	q.setUsers(make([]string, 0))
+	q.pinned = make(map[string]bool)
+	q.vipUsers = nil
+	q.waitlist = nil
 	q.autoSave() // Auto-save after clearing
 	return count
 }
 
+// SetUndoClearWindow sets how long a cleared queue can be restored
+func (q *Queue) SetUndoClearWindow(window time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.undoClearWindow = window
+}
+
+// GetUndoClearWindow returns how long a cleared queue can be restored
+func (q *Queue) GetUndoClearWindow() time.Duration {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.undoClearWindow
+}
+
+// UndoClear restores the users removed by the last Clear, if it happened
+// within the undo window. Restored users go back in front of anyone who
+// joined since the clear, and the cleared VIP line and waitlist go back in
+// front of the current ones. Returns the number of queue and VIP line users
+// restored.
+func (q *Queue) UndoClear() (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.enabled {
+		return 0, fmt.Errorf("queue system is currently disabled")
+	}
+
+	if len(q.clearedUsers)+len(q.clearedVIPs) == 0 || time.Since(q.clearedAt) > q.undoClearWindow {
+		q.clearedUsers = nil
+		q.clearedVIPs = nil
+		q.clearedWaitlist = nil
+		return 0, fmt.Errorf("nothing to restore")
+	}
+
+	restored := make([]string, 0, len(q.clearedUsers)+len(q.users))
+	restored = append(restored, q.clearedUsers...)
+	cleared := make(map[string]bool, len(q.clearedUsers))
+	for _, user := range q.clearedUsers {
+		cleared[strings.ToLower(user)] = true
+	}
+	for _, user := range q.users {
+		if !cleared[strings.ToLower(user)] {
+			restored = append(restored, user)
+		}
+	}
+
+	// Each user keeps only one spot: the main queue first, then the VIP
+	// line, then the waitlist
+	queued := make(map[string]bool, len(restored))
+	for _, user := range restored {
+		queued[strings.ToLower(user)] = true
+	}
+	vips := mergeCleared(q.clearedVIPs, q.vipUsers, queued)
+	waitlist := mergeCleared(q.clearedWaitlist, q.waitlist, queued)
+
+	count := len(q.clearedUsers) + len(q.clearedVIPs)
+	q.setUsers(restored)
+	q.vipUsers = vips
+	q.waitlist = waitlist
+	q.clearedUsers = nil
+	q.clearedVIPs = nil
+	q.clearedWaitlist = nil
+	q.autoSave() // Auto-save after restoring cleared users
+	return count, nil
+}
+
+// mergeCleared returns the cleared users followed by the current ones,
+// skipping anyone already in queued, and marks them queued
+func mergeCleared(cleared, current []string, queued map[string]bool) []string {
+	merged := make([]string, 0, len(cleared)+len(current))
+	for _, users := range [][]string{cleared, current} {
+		for _, user := range users {
+			if key := strings.ToLower(user); !queued[key] {
+				queued[key] = true
+				merged = append(merged, user)
+			}
+		}
+	}
+	return merged
+}
+
 // Add adds a user to the queue
 func (q *Queue) Add(username string, isMod bool) error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
 	if !q.enabled {
-		return fmt.Errorf("queue system is currently disabled")
+		return ErrQueueDisabled
 	}
 
 	if q.paused && !isMod {
-		return fmt.Errorf("queue system is currently paused")
+		return ErrQueuePaused
 	}
 
-	// Check if user is already in queue (case-insensitive check)
-	for _, user := range q.users {
-		if strings.EqualFold(user, username) {
-			return fmt.Errorf("user is already in queue")
+	if err := q.checkBlacklist(username, isMod); err != nil {
+		return err
+	}
+
+	if !isMod {
+		if err := q.checkRejoinCooldown(username); err != nil {
+			return err
 		}
 	}
 
+	// Check if user is already in queue (case-insensitive check)
+	if i := q.indexOf(username); i != -1 {
+		return &AlreadyQueuedError{Position: i + 1}
+	}
+	if q.vipIndexOf(username) != -1 {
+		return fmt.Errorf("user is already in the VIP line")
+	}
+	if q.waitlistIndexOf(username) != -1 {
+		return fmt.Errorf("user is already on the waitlist")
+	}
+
+	if q.maxSize > 0 && len(q.users) >= q.maxSize {
+		return ErrQueueFull
+	}
+
 	// Store the username with its exact capitalization
-	q.users = append(q.users, username)
+	q.insertAt(len(q.users), username)
+	delete(q.reserved, strings.ToLower(username))
+	delete(q.pinned, strings.ToLower(username))
+	q.joinedAt[strings.ToLower(username)] = time.Now()
 	q.autoSave() // Auto-save after adding user
 	return nil
 }
 
-// Remove removes a user from the queue
+// Remove removes a user from the queue, the VIP line or the waitlist
 func (q *Queue) Remove(username string) bool {
+	var promoted []string
+	defer func() { q.notifyPromoted(promoted) }()
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	for i, user := range q.users {
-		if strings.EqualFold(user, username) {
-			// Remove user by slicing
-			q.users = append(q.users[:i], q.users[i+1:]...)
-			q.autoSave() // Auto-save after removing user
-			return true
-		}
+	if i := q.indexOf(username); i != -1 {
+		q.removeAt(i)
+		delete(q.pinned, strings.ToLower(username))
+		promoted = q.promoteWaitlist()
+		q.autoSave() // Auto-save after removing user
+		return true
+	}
+	if i := q.vipIndexOf(username); i != -1 {
+		q.vipUsers = append(q.vipUsers[:i], q.vipUsers[i+1:]...)
+		q.autoSave() // Auto-save after removing user
+		return true
+	}
+	if i := q.waitlistIndexOf(username); i != -1 {
+		q.waitlist = append(q.waitlist[:i], q.waitlist[i+1:]...)
+		delete(q.joinedAt, strings.ToLower(username))
+		q.autoSave() // Auto-save after removing user
+		return true
 	}
 	return false
 }
@@ -193,32 +491,123 @@ func (q *Queue) Position(username string) int {
 	q.mu.RLock()
 	defer q.mu.RUnlock()
 
-	for i, user := range q.users {
-		if strings.EqualFold(user, username) {
-			return i + 1
-		}
+	if i := q.indexOf(username); i != -1 {
+		return i + 1
 	}
 	return -1
 }
 
-// AddAtPosition adds a user to the queue at the specified position (1-based)
+// Find returns the queued username matching username case-insensitively,
+// with the capitalization stored in the queue, or "" if it isn't queued
+func (q *Queue) Find(username string) string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if i := q.indexOf(username); i != -1 {
+		return q.users[i]
+	}
+	return ""
+}
+
+// LastServed returns when a user was last popped from the queue.
+// The boolean is false if the user hasn't been served.
+func (q *Queue) LastServed(username string) (time.Time, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	servedAt, ok := q.served[strings.ToLower(username)]
+	return servedAt, ok
+}
+
+// SetMaxSize sets the most users the queue holds at once. Values below 1
+// remove the limit. Users already queued beyond a lowered limit stay queued,
+// and waitlisted users fill any slots a raised limit opens up.
+func (q *Queue) SetMaxSize(size int) {
+	var promoted []string
+	defer func() { q.notifyPromoted(promoted) }()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if size < 0 {
+		size = 0
+	}
+	q.maxSize = size
+	if promoted = q.promoteWaitlist(); len(promoted) > 0 {
+		q.autoSave() // Auto-save after promoting users
+	}
+}
+
+// MaxSize returns the most users the queue holds at once (0 means unlimited)
+func (q *Queue) MaxSize() int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.maxSize
+}
+
+// SetRejoinCooldown sets how long a served user must wait before rejoining
+func (q *Queue) SetRejoinCooldown(cooldown time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.rejoinCooldown = cooldown
+}
+
+// checkRejoinCooldown returns an error if username was served too recently
+// to rejoin. Caller must hold the lock.
+func (q *Queue) checkRejoinCooldown(username string) error {
+	if q.rejoinCooldown <= 0 {
+		return nil
+	}
+	servedAt, ok := q.served[strings.ToLower(username)]
+	if !ok {
+		return nil
+	}
+	if wait := q.rejoinCooldown - time.Since(servedAt); wait > 0 {
+		return &RejoinCooldownError{Wait: wait}
+	}
+	return nil
+}
+
+// ResetLimits clears per-user rejoin cooldowns without touching the queue.
+// It returns the number of users whose cooldown was cleared.
+func (q *Queue) ResetLimits() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	count := len(q.served)
+	q.served = make(map[string]time.Time)
+	return count
+}
+
+// AddAtPosition adds a user to the queue at the specified position (1-based),
+// with the same capacity and duplicate checks as Add
 func (q *Queue) AddAtPosition(username string, position int, isMod bool) error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
 	if !q.enabled {
-		return fmt.Errorf("queue system is currently disabled")
+		return ErrQueueDisabled
 	}
 
 	if q.paused && !isMod {
-		return fmt.Errorf("queue system is currently paused")
+		return ErrQueuePaused
+	}
+
+	if err := q.checkBlacklist(username, isMod); err != nil {
+		return err
 	}
 
 	// Check if user is already in queue
-	for _, user := range q.users {
-		if strings.EqualFold(user, username) {
-			return fmt.Errorf("user is already in queue")
-		}
+	if i := q.indexOf(username); i != -1 {
+		return &AlreadyQueuedError{Position: i + 1}
+	}
+	if q.vipIndexOf(username) != -1 {
+		return fmt.Errorf("user is already in the VIP line")
+	}
+	if q.waitlistIndexOf(username) != -1 {
+		return fmt.Errorf("user is already on the waitlist")
+	}
+
+	if q.maxSize > 0 && len(q.users) >= q.maxSize {
+		return ErrQueueFull
 	}
 
 	// Validate position
@@ -233,20 +622,117 @@ func (q *Queue) AddAtPosition(username string, position int, isMod bool) error {
 	newUser := username
 
 	// Insert at position (converting from 1-based to 0-based index)
-	position--
-	if position == len(q.users) {
-		// Append to end
-		q.users = append(q.users, newUser)
-	} else {
-		// Insert at position
-		q.users = append(q.users[:position], append([]string{newUser}, q.users[position:]...)...)
-	}
+	q.insertAt(position-1, newUser)
+	delete(q.reserved, strings.ToLower(username))
+	delete(q.pinned, strings.ToLower(username))
+	q.joinedAt[strings.ToLower(username)] = time.Now()
 	q.autoSave() // Auto-save after adding user at position
 	return nil
 }
 
+// SetReserved marks or unmarks a queued user's slot as reserved.
+// Returns false if the user isn't in the queue.
+func (q *Queue) SetReserved(username string, reserved bool) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.indexOf(username) == -1 {
+		return false
+	}
+	if reserved {
+		q.reserved[strings.ToLower(username)] = true
+	} else {
+		delete(q.reserved, strings.ToLower(username))
+	}
+	q.autoSave() // Auto-save after changing reservation
+	return true
+}
+
+// IsReserved reports whether a queued user holds a reserved slot
+func (q *Queue) IsReserved(username string) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.reserved[strings.ToLower(username)] && q.indexOf(username) != -1
+}
+
+// indexOf returns the index of a user in the queue (case-insensitive), or -1.
+// Callers must hold q.mu.
+func (q *Queue) indexOf(username string) int {
+	if i, ok := q.index[strings.ToLower(username)]; ok {
+		return i
+	}
+	return -1
+}
+
+// setUsers replaces the queue and rebuilds the index. Callers must hold q.mu.
+func (q *Queue) setUsers(users []string) {
+	q.users = users
+	q.index = make(map[string]int, len(users))
+	q.reindexFrom(0)
+}
+
+// insertAt inserts user at index i (0 to len(q.users)) and updates the index.
+// Callers must hold q.mu.
+func (q *Queue) insertAt(i int, user string) {
+	q.users = append(q.users[:i:i], append([]string{user}, q.users[i:]...)...)
+	q.reindexFrom(i)
+}
+
+// removeAt removes the user at index i and updates the index. Callers must
+// hold q.mu.
+func (q *Queue) removeAt(i int) {
+	key := strings.ToLower(q.users[i])
+	q.users = append(q.users[:i:i], q.users[i+1:]...)
+	if q.index[key] == i {
+		delete(q.index, key)
+	}
+	q.reindexFrom(i)
+}
+
+// reindexFrom updates the index after the queue changed at or after index
+// from. Entries before from are still valid. Walking backwards lets the
+// first occurrence win if a name is somehow queued twice, matching a scan.
+// Callers must hold q.mu.
+func (q *Queue) reindexFrom(from int) {
+	for j := len(q.users) - 1; j >= from; j-- {
+		key := strings.ToLower(q.users[j])
+		if cur, ok := q.index[key]; !ok || cur >= from {
+			q.index[key] = j
+		}
+	}
+}
+
+// markServed records that a user was popped from the queue. Callers must hold q.mu.
+func (q *Queue) markServed(username string, at time.Time) {
+	key := strings.ToLower(username)
+	q.served[key] = at
+	q.sessionServed = append(q.sessionServed, ServedUser{
+		Username: username,
+		JoinedAt: q.joinedAt[key],
+		ServedAt: at,
+	})
+	q.nowServing = append(q.nowServing, username)
+	delete(q.joinedAt, key)
+}
+
+// markSkipped records that a user was passed over by SkipTo. Skipped users
+// appear in the session history but aren't subject to the rejoin cooldown.
+// Callers must hold q.mu.
+func (q *Queue) markSkipped(username string, at time.Time) {
+	key := strings.ToLower(username)
+	q.sessionServed = append(q.sessionServed, ServedUser{
+		Username: username,
+		JoinedAt: q.joinedAt[key],
+		ServedAt: at,
+		Skipped:  true,
+	})
+	delete(q.joinedAt, key)
+}
+
 // Pop removes and returns the first user from the queue
 func (q *Queue) Pop() (string, error) {
+	var promoted []string
+	defer func() { q.notifyPromoted(promoted) }()
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
@@ -254,15 +740,17 @@ func (q *Queue) Pop() (string, error) {
 		return "", fmt.Errorf("queue system is currently disabled")
 	}
 
-	if len(q.users) == 0 {
+	if len(q.users) == 0 && len(q.vipUsers) == 0 {
 		return "", fmt.Errorf("queue is empty")
 	}
+	if q.poppable() == 0 {
+		return "", fmt.Errorf("only pinned users are left in the queue")
+	}
 
-	// Get first user
-	user := q.users[0]
-
-	// Remove first user
-	q.users = q.users[1:]
+	// Take the next user from the main or VIP line
+	user := q.popNext()
+	q.markServed(user, time.Now())
+	promoted = q.promoteWaitlist()
 	q.autoSave() // Auto-save after popping user
 
 	return user, nil
@@ -270,6 +758,8 @@ func (q *Queue) Pop() (string, error) {
 
 // PopN removes and returns the first N users from the queue
 func (q *Queue) PopN(count int) ([]string, error) {
+	var promoted []string
+	defer func() { q.notifyPromoted(promoted) }()
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
@@ -277,28 +767,74 @@ func (q *Queue) PopN(count int) ([]string, error) {
 		return nil, fmt.Errorf("queue system is currently disabled")
 	}
 
-	if len(q.users) == 0 {
+	if len(q.users) == 0 && len(q.vipUsers) == 0 {
 		return nil, fmt.Errorf("queue is empty")
 	}
+	if q.poppable() == 0 {
+		return nil, fmt.Errorf("only pinned users are left in the queue")
+	}
 
-	// Ensure count doesn't exceed queue size
-	if count > len(q.users) {
-		count = len(q.users)
+	// Ensure count doesn't exceed the users pops may take from both lines
+	if total := q.poppable(); count > total {
+		count = total
 	}
 
-	// Get first N users
+	// Take the next N users, interleaving the VIP line
 	users := make([]string, count)
-	copy(users, q.users[:count])
-
-	// Remove first N users
-	q.users = q.users[count:]
+	now := time.Now()
+	for i := range users {
+		users[i] = q.popNext()
+		q.markServed(users[i], now)
+	}
+	promoted = q.promoteWaitlist()
 	q.autoSave() // Auto-save after popping users
 
 	return users, nil
 }
 
+// SkipTo removes everyone ahead of username in the queue, recording them as
+// skipped in the session history, so username is at position 1. It returns
+// the skipped users in queue order.
+func (q *Queue) SkipTo(username string) ([]string, error) {
+	var promoted []string
+	defer func() { q.notifyPromoted(promoted) }()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.enabled {
+		return nil, fmt.Errorf("queue system is currently disabled")
+	}
+
+	i := q.indexOf(username)
+	if i == -1 {
+		return nil, fmt.Errorf("user is not in queue")
+	}
+
+	// Pinned users ahead of username stay where they are
+	var skipped, kept []string
+	for _, user := range q.users[:i] {
+		if q.isPinnedLocked(user) {
+			kept = append(kept, user)
+		} else {
+			skipped = append(skipped, user)
+		}
+	}
+	q.setUsers(append(kept, q.users[i:]...))
+	now := time.Now()
+	for _, user := range skipped {
+		q.markSkipped(user, now)
+	}
+	if len(skipped) > 0 {
+		promoted = q.promoteWaitlist()
+		q.autoSave() // Auto-save after skipping users
+	}
+	return skipped, nil
+}
+
 // RemoveUser removes a specified user from the queue
 func (q *Queue) RemoveUser(username string) (bool, error) {
+	var promoted []string
+	defer func() { q.notifyPromoted(promoted) }()
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
@@ -306,13 +842,13 @@ func (q *Queue) RemoveUser(username string) (bool, error) {
 		return false, fmt.Errorf("queue system is currently disabled")
 	}
 
-	for i, user := range q.users {
-		if user == username {
-			// Remove the user from the queue
-			q.users = append(q.users[:i], q.users[i+1:]...)
-			q.autoSave() // Auto-save after removing user
-			return true, nil
-		}
+	if i := q.indexOf(username); i != -1 && q.users[i] == username {
+		// Remove the user from the queue
+		q.removeAt(i)
+		delete(q.pinned, strings.ToLower(username))
+		promoted = q.promoteWaitlist()
+		q.autoSave() // Auto-save after removing user
+		return true, nil
 	}
 
 	return false, nil
@@ -324,21 +860,22 @@ func (q *Queue) MoveUser(username string, position int) error {
 	defer q.mu.Unlock()
 
 	if !q.enabled {
-		return fmt.Errorf("queue system is currently disabled")
+		return ErrQueueDisabled
 	}
 
 	// Find user's current position
-	currentPos := -1
-	for i, user := range q.users {
-		if user == username {
-			currentPos = i
-			break
-		}
+	currentPos := q.indexOf(username)
+	if currentPos != -1 && q.users[currentPos] != username {
+		currentPos = -1
 	}
 
 	if currentPos == -1 {
 		return fmt.Errorf("user not found in queue")
 	}
+	if q.isPinnedLocked(username) {
+		return ErrUserPinned
+	}
+	pins := q.pinnedIndexes()
 
 	// Validate position
 	if position < 1 {
@@ -360,10 +897,11 @@ func (q *Queue) MoveUser(username string, position int) error {
 	user := q.users[currentPos]
 
 	// Remove from current position
-	q.users = append(q.users[:currentPos], q.users[currentPos+1:]...)
+	q.removeAt(currentPos)
 
 	// Insert at new position
-	q.users = append(q.users[:position], append([]string{user}, q.users[position:]...)...)
+	q.insertAt(position, user)
+	q.restorePins(pins)
 	q.autoSave() // Auto-save after moving user
 
 	return nil
@@ -375,21 +913,22 @@ func (q *Queue) MoveToEnd(username string) error {
 	defer q.mu.Unlock()
 
 	if !q.enabled {
-		return fmt.Errorf("queue system is currently disabled")
+		return ErrQueueDisabled
 	}
 
 	// Find user's current position
-	currentPos := -1
-	for i, user := range q.users {
-		if user == username {
-			currentPos = i
-			break
-		}
+	currentPos := q.indexOf(username)
+	if currentPos != -1 && q.users[currentPos] != username {
+		currentPos = -1
 	}
 
 	if currentPos == -1 {
 		return fmt.Errorf("user not found in queue")
 	}
+	if q.isPinnedLocked(username) {
+		return ErrUserPinned
+	}
+	pins := q.pinnedIndexes()
 
 	// If already at end, no need to move
 	if currentPos == len(q.users)-1 {
@@ -400,37 +939,207 @@ func (q *Queue) MoveToEnd(username string) error {
 	user := q.users[currentPos]
 
 	// Remove from current position
-	q.users = append(q.users[:currentPos], q.users[currentPos+1:]...)
+	q.removeAt(currentPos)
 
 	// Add to end
-	q.users = append(q.users, user)
+	q.insertAt(len(q.users), user)
+	q.restorePins(pins)
 	q.autoSave() // Auto-save after moving user to end
 
 	return nil
 }
 
-// autoSave automatically saves the queue state after modifications
-// This method should be called after any queue modification operation
+// MoveToFront moves a user to the front of the queue
+func (q *Queue) MoveToFront(username string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.enabled {
+		return ErrQueueDisabled
+	}
+
+	// Find user's current position
+	currentPos := q.indexOf(username)
+	if currentPos != -1 && q.users[currentPos] != username {
+		currentPos = -1
+	}
+
+	if currentPos == -1 {
+		return fmt.Errorf("user not found in queue")
+	}
+	if q.isPinnedLocked(username) {
+		return ErrUserPinned
+	}
+	pins := q.pinnedIndexes()
+
+	// If already at front, no need to move
+	if currentPos == 0 {
+		return nil
+	}
+
+	// Get user
+	user := q.users[currentPos]
+
+	// Remove from current position
+	q.removeAt(currentPos)
+
+	// Add to front
+	q.insertAt(0, user)
+	q.restorePins(pins)
+	q.autoSave() // Auto-save after moving user to front
+
+	return nil
+}
+
+// MoveBatch moves the listed users so they sit next to each other in the
+// given order, starting at position (1-based). Everyone else keeps their
+// relative order. The move fails without changing the queue if any listed
+// user isn't queued. It returns the users moved, with their queue spelling.
+func (q *Queue) MoveBatch(usernames []string, position int) ([]string, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.enabled {
+		return nil, fmt.Errorf("queue system is currently disabled")
+	}
+
+	// Resolve the batch against a snapshot, skipping repeated names
+	var batch []string
+	var missing []string
+	inBatch := make(map[string]bool)
+	for _, username := range usernames {
+		key := strings.ToLower(username)
+		if inBatch[key] {
+			continue
+		}
+		i := q.indexOf(username)
+		if i == -1 {
+			missing = append(missing, username)
+			continue
+		}
+		inBatch[key] = true
+		batch = append(batch, q.users[i])
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("not in queue: %s", strings.Join(missing, ", "))
+	}
+	for _, user := range batch {
+		if q.isPinnedLocked(user) {
+			return nil, fmt.Errorf("%s: %w", user, ErrUserPinned)
+		}
+	}
+	pins := q.pinnedIndexes()
+
+	rest := make([]string, 0, len(q.users)-len(batch))
+	for _, user := range q.users {
+		if !inBatch[strings.ToLower(user)] {
+			rest = append(rest, user)
+		}
+	}
+
+	// Validate position
+	if position < 1 {
+		position = 1
+	}
+	if position > len(rest)+1 {
+		position = len(rest) + 1
+	}
+
+	users := make([]string, 0, len(q.users))
+	users = append(users, rest[:position-1]...)
+	users = append(users, batch...)
+	users = append(users, rest[position-1:]...)
+	q.setUsers(users)
+	q.restorePins(pins)
+	q.autoSave() // Auto-save after moving users
+	return batch, nil
+}
+
+// autoSave saves the queue state in the background after a modification.
+// Callers must hold q.mu. The state is captured before returning, so the
+// background write never needs the lock; see queueSave.
 func (q *Queue) autoSave() {
-	// Use a goroutine to avoid blocking the main operation
-	go func() {
-		if err := q.SaveState(); err != nil {
+	q.queueSave()
+}
+
+// queueSave captures the current state for the auto-save file and hands it
+// to the background writer. Callers must hold q.mu (read or write).
+// Snapshots are written in order by a single goroutine, and a snapshot still
+// waiting when a newer one arrives is skipped, so older state never
+// overwrites newer state.
+func (q *Queue) queueSave() {
+	data, err := q.marshalStateLocked()
+
+	q.saveMu.Lock()
+	defer q.saveMu.Unlock()
+	if err != nil {
+		q.saveErr = err
+		return
+	}
+	q.pendingSave = data
+	if !q.saving {
+		q.saving = true
+		go q.saveLoop()
+	}
+}
+
+// saveLoop writes queued snapshots to the auto-save file until none are left
+func (q *Queue) saveLoop() {
+	for {
+		q.saveMu.Lock()
+		data := q.pendingSave
+		q.pendingSave = nil
+		if data == nil {
+			q.saving = false
+			q.saveIdle.Broadcast()
+			q.saveMu.Unlock()
+			return
+		}
+		q.saveMu.Unlock()
+
+		err := q.writeStateFile("queue_state", data)
+		if err != nil {
 			// Log error but don't fail the operation
 			fmt.Printf("Auto-save failed: %v\n", err)
 		}
-	}()
+		q.saveMu.Lock()
+		q.saveErr = err
+		q.saveMu.Unlock()
+	}
 }
 
-// SaveState saves the current queue state to a file
+// SaveState saves the current queue state to the auto-save file and waits
+// for it, and any earlier background saves, to be written
 func (q *Queue) SaveState() error {
-	return q.saveStateToFile("queue_state")
+	q.mu.RLock()
+	q.queueSave()
+	q.mu.RUnlock()
+	return q.waitForSaves()
+}
+
+// waitForSaves blocks until pending auto-saves are written and returns the
+// result of the last write
+func (q *Queue) waitForSaves() error {
+	q.saveMu.Lock()
+	defer q.saveMu.Unlock()
+	for q.saving {
+		q.saveIdle.Wait()
+	}
+	return q.saveErr
 }
 
 // SaveBackup saves the current queue state to a backup file
 func (q *Queue) SaveBackup() error {
+	q.mu.RLock()
+	count := len(q.users)
+	data, err := q.marshalStateLocked()
+	q.mu.RUnlock()
+
 	// Add debug logging
-	fmt.Printf("[DEBUG] Saving backup for channel: %s with %d users\n", q.channel, len(q.users))
-	err := q.saveStateToFile("queue_backup")
+	fmt.Printf("[DEBUG] Saving backup for channel: %s with %d users\n", q.channel, count)
+	if err == nil {
+		err = q.writeStateFile("queue_backup", data)
+	}
 	if err != nil {
 		fmt.Printf("[DEBUG] SaveBackup error: %v\n", err)
 	} else {
@@ -439,81 +1148,174 @@ func (q *Queue) SaveBackup() error {
 	return err
 }
 
-// saveStateToFile saves the current queue state to a specific file
-func (q *Queue) saveStateToFile(filePrefix string) error {
-	q.mu.RLock()
-	defer q.mu.RUnlock()
-
-	// Ensure the data directory exists
-	if err := os.MkdirAll(q.dataPath, 0755); err != nil {
-		return fmt.Errorf("failed to create data directory: %w", err)
-	}
-
+// marshalStateLocked encodes the current queue state. Callers must hold q.mu.
+func (q *Queue) marshalStateLocked() ([]byte, error) {
 	state := QueueState{
+		Version:     CurrentStateVersion,
 		Channel:     q.channel,
-		Queue:       q.users,
 		LastUpdated: time.Now().Unix(),
+		VIPQueue:    q.vipUsers,
+		NowServing:  q.nowServing,
+		Waitlist:    q.waitlist,
+	}
+	users := q.users
+	if !q.enabled && q.endedUsers != nil {
+		users = q.endedUsers
+		state.Ended = true
+	}
+	state.Queue = make([]QueuedUser, len(users))
+	for i, user := range users {
+		state.Queue[i] = QueuedUser{Username: user}
+		if joined, ok := q.joinedAt[strings.ToLower(user)]; ok {
+			state.Queue[i].JoinTime = &joined
+		}
+		if q.reserved[strings.ToLower(user)] {
+			state.Reserved = append(state.Reserved, user)
+		}
+		if q.pinned[strings.ToLower(user)] {
+			state.Pinned = append(state.Pinned, user)
+		}
+		if q.subscribers[strings.ToLower(user)] {
+			state.Subscribers = append(state.Subscribers, user)
+		}
 	}
 
 	data, err := json.MarshalIndent(state, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal queue state: %w", err)
+		return nil, fmt.Errorf("failed to marshal queue state: %w", err)
+	}
+	return data, nil
+}
+
+// writeStateFile writes encoded queue state to the channel's file with the
+// given prefix
+func (q *Queue) writeStateFile(filePrefix string, data []byte) error {
+	// Ensure the data directory exists
+	if err := os.MkdirAll(q.dataPath, 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
 	}
 
 	// Use channel-specific filename with prefix
 	filename := filepath.Join(q.dataPath, fmt.Sprintf("%s_%s.json", filePrefix, q.channel))
-	if err := os.WriteFile(filename, data, 0644); err != nil {
+	if err := utils.WriteFileAtomic(filename, data); err != nil {
 		return fmt.Errorf("failed to write queue state: %w", err)
 	}
 
 	return nil
 }
 
-// LoadState loads the queue state from a file
+// LoadState loads the queue state from a file, migrating a legacy
+// queue_state.json first if needed. A missing auto-save file leaves an
+// empty queue.
 func (q *Queue) LoadState() error {
-	return q.loadStateFromFile("queue_state")
+	if err := q.MigrateState(); err != nil {
+		log.Printf("Skipping queue state migration: %v", err)
+	}
+	err := q.loadStateFromFile("queue_state", false)
+	if errors.Is(err, os.ErrNotExist) {
+		q.mu.Lock()
+		q.setUsers(make([]string, 0))
+		q.vipUsers = nil
+		q.mu.Unlock()
+		return nil
+	}
+	return err
 }
 
-// LoadBackup loads the queue state from the backup file
+// LoadBackup loads the queue state from the backup written by SaveBackup.
+// If there is no backup the queue is left untouched and the returned error
+// matches os.ErrNotExist.
 func (q *Queue) LoadBackup() error {
 	// Add debug logging
 	fmt.Printf("[DEBUG] Loading backup for channel: %s\n", q.channel)
-	err := q.loadStateFromFile("queue_backup")
+	err := q.loadStateFromFile("queue_backup", true)
 	if err != nil {
 		fmt.Printf("[DEBUG] LoadBackup error: %v\n", err)
 	}
 	return err
 }
 
-// loadStateFromFile loads the queue state from a specific file
-func (q *Queue) loadStateFromFile(filePrefix string) error {
+// RestoreAutoSave loads the queue state from the auto-save file, including
+// users kept by End(true)
+func (q *Queue) RestoreAutoSave() error {
+	return q.loadStateFromFile("queue_state", true)
+}
+
+// loadStateFromFile loads the queue state from a specific file. The users of
+// an ended queue are only restored when restoreEnded is set; otherwise they
+// are kept aside so later saves don't drop them.
+func (q *Queue) loadStateFromFile(filePrefix string, restoreEnded bool) error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
+	state, err := q.readStateFile(filePrefix)
+	if err != nil {
+		return err
+	}
+
+	users := queuedUsernames(state.Queue)
+	q.setUsers(users)
+	q.endedUsers = nil
+	if state.Ended && !restoreEnded {
+		q.setUsers(make([]string, 0))
+		q.endedUsers = users
+	} else {
+		for _, entry := range state.Queue {
+			if entry.JoinTime != nil && !entry.JoinTime.IsZero() {
+				q.joinedAt[strings.ToLower(entry.Username)] = *entry.JoinTime
+			}
+		}
+	}
+	q.vipUsers = state.VIPQueue
+	q.nowServing = state.NowServing
+	q.waitlist = state.Waitlist
+	q.reserved = make(map[string]bool)
+	for _, user := range state.Reserved {
+		q.reserved[strings.ToLower(user)] = true
+	}
+	q.pinned = make(map[string]bool)
+	for _, user := range state.Pinned {
+		q.pinned[strings.ToLower(user)] = true
+	}
+	q.subscribers = make(map[string]bool)
+	for _, user := range state.Subscribers {
+		q.subscribers[strings.ToLower(user)] = true
+	}
+	return nil
+}
+
+// readStateFile reads the channel's state file with the given prefix and
+// checks it belongs to this channel
+func (q *Queue) readStateFile(filePrefix string) (*QueueState, error) {
 	// Use channel-specific filename with prefix
 	filename := filepath.Join(q.dataPath, fmt.Sprintf("%s_%s.json", filePrefix, q.channel))
 	data, err := os.ReadFile(filename)
 	if err != nil {
-		if os.IsNotExist(err) {
-			// If file doesn't exist, start with empty queue
-			q.users = make([]string, 0)
-			return nil
-		}
-		return fmt.Errorf("failed to read queue state: %w", err)
+		return nil, fmt.Errorf("failed to read queue state: %w", err)
 	}
 
-	var state QueueState
-	if err := json.Unmarshal(data, &state); err != nil {
-		return fmt.Errorf("failed to unmarshal queue state: %w", err)
+	state, err := decodeState(data)
+	if err != nil {
+		return nil, err
 	}
 
 	// Verify the channel matches
 	if state.Channel != q.channel {
-		return fmt.Errorf("queue state channel mismatch: expected %s, got %s", q.channel, state.Channel)
+		return nil, fmt.Errorf("queue state channel mismatch: expected %s, got %s", q.channel, state.Channel)
 	}
+	return state, nil
+}
 
-	q.users = state.Queue
-	return nil
+// PeekSavedState returns the users in the auto-save file without changing
+// the queue, writing any pending auto-save first so the file is current.
+// A missing file returns an error wrapping os.ErrNotExist.
+func (q *Queue) PeekSavedState() ([]string, error) {
+	q.waitForSaves()
+	state, err := q.readStateFile("queue_state")
+	if err != nil {
+		return nil, err
+	}
+	return queuedUsernames(state.Queue), nil
 }
 
 // GetDataPath returns the data path for this queue
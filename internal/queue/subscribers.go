@@ -0,0 +1,68 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNoSubscribers is returned by PromoteNextSubscriber when no queued user
+// is marked as a subscriber
+var ErrNoSubscribers = errors.New("no subscribers in queue")
+
+// SetSubscriber marks or unmarks a queued user as a subscriber.
+// Returns false if the user isn't in the queue.
+func (q *Queue) SetSubscriber(username string, subscribed bool) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.indexOf(username) == -1 {
+		return false
+	}
+	key := strings.ToLower(username)
+	if q.subscribers[key] == subscribed {
+		return true
+	}
+	if subscribed {
+		q.subscribers[key] = true
+	} else {
+		delete(q.subscribers, key)
+	}
+	q.autoSave() // Auto-save after changing subscriber status
+	return true
+}
+
+// IsSubscriber reports whether a queued user was marked as a subscriber
+func (q *Queue) IsSubscriber(username string) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.subscribers[strings.ToLower(username)] && q.indexOf(username) != -1
+}
+
+// PromoteNextSubscriber moves the highest-positioned unpinned subscriber to
+// the front of the queue, or as close to it as pins allow. It returns the
+// subscriber and the position they were at before the move (1 if they were
+// already at the front).
+func (q *Queue) PromoteNextSubscriber() (string, int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.enabled {
+		return "", 0, fmt.Errorf("queue system is currently disabled")
+	}
+
+	for i, user := range q.users {
+		if !q.subscribers[strings.ToLower(user)] || q.isPinnedLocked(user) {
+			continue
+		}
+		if i > 0 {
+			pins := q.pinnedIndexes()
+			q.removeAt(i)
+			q.insertAt(0, user)
+			q.restorePins(pins)
+			q.autoSave() // Auto-save after promoting user
+		}
+		return user, i + 1, nil
+	}
+	return "", 0, ErrNoSubscribers
+}
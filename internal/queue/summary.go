@@ -0,0 +1,58 @@
+package queue
+
+import (
+	"strings"
+	"time"
+)
+
+// ServedUser is a user popped from the queue during a session
+type ServedUser struct {
+	Username string    `json:"username"`
+	JoinedAt time.Time `json:"joined_at"` // Zero if the join predates this run of the bot
+	ServedAt time.Time `json:"served_at"`
+	Skipped  bool      `json:"skipped,omitempty"` // Passed over by !skipto rather than served
+}
+
+// WaitingUser is a user still in the queue when the summary was taken
+type WaitingUser struct {
+	Username string    `json:"username"`
+	Position int       `json:"position"`
+	JoinedAt time.Time `json:"joined_at"` // Zero if the join predates this run of the bot
+	Reserved bool      `json:"reserved,omitempty"`
+}
+
+// SessionSummary describes one queue session, from !startqueue until now
+type SessionSummary struct {
+	Channel   string        `json:"channel"`
+	StartedAt time.Time     `json:"started_at"`
+	EndedAt   time.Time     `json:"ended_at"`
+	Served    []ServedUser  `json:"served"`
+	Remaining []WaitingUser `json:"remaining"`
+}
+
+// SessionSummary returns who was served during the current session and who
+// is still waiting
+func (q *Queue) SessionSummary() SessionSummary {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	summary := SessionSummary{
+		Channel:   q.channel,
+		StartedAt: q.sessionStart,
+		EndedAt:   time.Now(),
+		Served:    make([]ServedUser, len(q.sessionServed)),
+		Remaining: make([]WaitingUser, 0, len(q.users)),
+	}
+	copy(summary.Served, q.sessionServed)
+
+	for i, user := range q.users {
+		key := strings.ToLower(user)
+		summary.Remaining = append(summary.Remaining, WaitingUser{
+			Username: user,
+			Position: i + 1,
+			JoinedAt: q.joinedAt[key],
+			Reserved: q.reserved[key],
+		})
+	}
+	return summary
+}
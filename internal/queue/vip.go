@@ -0,0 +1,113 @@
+package queue
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultVIPInterleave serves every 3rd pop from the VIP line by default
+const DefaultVIPInterleave = 3
+
+// AddVIP adds a user to the VIP fast-pass line
+func (q *Queue) AddVIP(username string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.enabled {
+		return ErrQueueDisabled
+	}
+
+	if q.paused {
+		return ErrQueuePaused
+	}
+
+	if err := q.checkBlacklist(username, false); err != nil {
+		return err
+	}
+
+	if q.vipIndexOf(username) != -1 {
+		return fmt.Errorf("user is already in the VIP line")
+	}
+	if i := q.indexOf(username); i != -1 {
+		return &AlreadyQueuedError{Position: i + 1}
+	}
+
+	q.vipUsers = append(q.vipUsers, username)
+	q.joinedAt[strings.ToLower(username)] = time.Now()
+	q.autoSave() // Auto-save after adding user
+	return nil
+}
+
+// ListVIP returns the current VIP line
+func (q *Queue) ListVIP() []string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	// Return a copy to prevent external modifications
+	users := make([]string, len(q.vipUsers))
+	copy(users, q.vipUsers)
+	return users
+}
+
+// VIPPosition returns a user's 1-based position in the VIP line, or -1
+func (q *Queue) VIPPosition(username string) int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if i := q.vipIndexOf(username); i != -1 {
+		return i + 1
+	}
+	return -1
+}
+
+// SetVIPInterleave sets how often the VIP line is served: every nth pop
+// comes from the VIP line while it has users. Values below 1 are treated as 1.
+// The pop count restarts so the new ratio applies from the next pop.
+func (q *Queue) SetVIPInterleave(n int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if n < 1 {
+		n = 1
+	}
+	q.vipInterleave = n
+	q.popCount = 0
+}
+
+// GetVIPInterleave returns how often the VIP line is served
+func (q *Queue) GetVIPInterleave() int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.vipInterleave
+}
+
+// popNext removes and returns the next user to serve. Every vipInterleave-th
+// pop is taken from the VIP line; either line is used if the other is empty.
+// Caller must hold the lock and ensure at least one line is non-empty.
+func (q *Queue) popNext() string {
+	q.popCount++
+	vipTurn := q.vipInterleave <= 1 || q.popCount%q.vipInterleave == 0
+
+	// Pinned users are never popped
+	next := q.nextUnpinned()
+	var user string
+	if len(q.vipUsers) > 0 && (vipTurn || next == -1) {
+		user = q.vipUsers[0]
+		q.vipUsers = q.vipUsers[1:]
+	} else {
+		user = q.users[next]
+		q.removeAt(next)
+	}
+	return user
+}
+
+// vipIndexOf returns the index of username in the VIP line, or -1.
+// Caller must hold the lock.
+func (q *Queue) vipIndexOf(username string) int {
+	for i, user := range q.vipUsers {
+		if strings.EqualFold(user, username) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,133 @@
+package queue
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SetWaitlistEnabled sets whether joins past the max size can go on the
+// waitlist instead of being turned away
+func (q *Queue) SetWaitlistEnabled(enabled bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.waitlistEnabled = enabled
+}
+
+// WaitlistEnabled reports whether the waitlist is turned on
+func (q *Queue) WaitlistEnabled() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.waitlistEnabled
+}
+
+// SetPromotionHandler sets the function told about users moved from the
+// waitlist into the main queue. It is called without the queue lock held.
+func (q *Queue) SetPromotionHandler(handler func(users []string)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.onPromote = handler
+}
+
+// AddWaitlist adds a user to the waitlist. The waitlist only takes users
+// while it's enabled and the main queue is full.
+func (q *Queue) AddWaitlist(username string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.enabled {
+		return ErrQueueDisabled
+	}
+
+	if q.paused {
+		return ErrQueuePaused
+	}
+
+	if !q.waitlistEnabled {
+		return fmt.Errorf("the waitlist is not enabled")
+	}
+
+	if err := q.checkBlacklist(username, false); err != nil {
+		return err
+	}
+
+	if i := q.indexOf(username); i != -1 {
+		return &AlreadyQueuedError{Position: i + 1}
+	}
+	if q.vipIndexOf(username) != -1 {
+		return fmt.Errorf("user is already in the VIP line")
+	}
+	if q.waitlistIndexOf(username) != -1 {
+		return fmt.Errorf("user is already on the waitlist")
+	}
+
+	if q.maxSize == 0 || len(q.users) < q.maxSize {
+		return fmt.Errorf("queue has room, join it directly")
+	}
+
+	q.waitlist = append(q.waitlist, username)
+	q.joinedAt[strings.ToLower(username)] = time.Now()
+	q.autoSave() // Auto-save after adding user
+	return nil
+}
+
+// ListWaitlist returns the current waitlist
+func (q *Queue) ListWaitlist() []string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	// Return a copy to prevent external modifications
+	users := make([]string, len(q.waitlist))
+	copy(users, q.waitlist)
+	return users
+}
+
+// WaitlistPosition returns a user's 1-based position on the waitlist, or -1
+func (q *Queue) WaitlistPosition(username string) int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if i := q.waitlistIndexOf(username); i != -1 {
+		return i + 1
+	}
+	return -1
+}
+
+// waitlistIndexOf returns the index of username on the waitlist, or -1.
+// Caller must hold the lock.
+func (q *Queue) waitlistIndexOf(username string) int {
+	for i, user := range q.waitlist {
+		if strings.EqualFold(user, username) {
+			return i
+		}
+	}
+	return -1
+}
+
+// promoteWaitlist moves users from the front of the waitlist into free
+// main queue slots and returns them. Caller must hold the lock.
+func (q *Queue) promoteWaitlist() []string {
+	var promoted []string
+	for len(q.waitlist) > 0 && (q.maxSize == 0 || len(q.users) < q.maxSize) {
+		user := q.waitlist[0]
+		q.waitlist = q.waitlist[1:]
+		delete(q.pinned, strings.ToLower(user))
+		q.insertAt(len(q.users), user)
+		promoted = append(promoted, user)
+	}
+	return promoted
+}
+
+// notifyPromoted passes promoted users to the promotion handler. Callers
+// must not hold the lock, so methods defer it before locking.
+func (q *Queue) notifyPromoted(promoted []string) {
+	if len(promoted) == 0 {
+		return
+	}
+	q.mu.RLock()
+	handler := q.onPromote
+	q.mu.RUnlock()
+	if handler != nil {
+		handler(promoted)
+	}
+}
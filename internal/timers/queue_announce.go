@@ -0,0 +1,30 @@
+package timers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/queue"
+)
+
+// NewQueueAnnounceTimer creates a timer that advertises the queue while it
+// is enabled and has people in it
+func NewQueueAnnounceTimer(q *queue.Queue, interval time.Duration) *Timer {
+	return &Timer{
+		Name:     "queue_announce",
+		Interval: interval,
+		Message: func() string {
+			if !q.IsEnabled() {
+				return ""
+			}
+			switch size := q.Size(); size {
+			case 0:
+				return ""
+			case 1:
+				return "1 person in queue — type !join to enter!"
+			default:
+				return fmt.Sprintf("%d people in queue — type !join to enter!", size)
+			}
+		},
+	}
+}
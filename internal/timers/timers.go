@@ -0,0 +1,112 @@
+package timers
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock provides the current time. It can be replaced in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock is the Clock backed by the system time
+type realClock struct{}
+
+// Now returns the current system time
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Timer is a message posted to chat on a fixed interval
+type Timer struct {
+	// Name identifying the timer
+	Name string
+	// How often the message should be posted
+	Interval time.Duration
+	// Builds the message to post; returning "" skips this run
+	Message func() string
+	// When the message was last posted (or the timer was added)
+	lastRun time.Time
+}
+
+// Manager runs timed messages, suppressing them while chat is quiet.
+// A timer only posts if there has been chat activity since its last post.
+type Manager struct {
+	mu           sync.Mutex
+	clock        Clock
+	send         func(string)
+	timers       []*Timer
+	lastActivity time.Time
+}
+
+// NewManager creates a timer manager that posts messages using send.
+// If clock is nil the system clock is used.
+func NewManager(clock Clock, send func(string)) *Manager {
+	if clock == nil {
+		clock = realClock{}
+	}
+	return &Manager{
+		clock: clock,
+		send:  send,
+	}
+}
+
+// Add registers a timer. Its first post is due one interval from now.
+func (m *Manager) Add(timer *Timer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	timer.lastRun = m.clock.Now()
+	m.timers = append(m.timers, timer)
+}
+
+// RecordActivity notes that a chat message was seen
+func (m *Manager) RecordActivity() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastActivity = m.clock.Now()
+}
+
+// Tick posts every timer that is due, has a message, and has seen chat
+// activity since its last post
+func (m *Manager) Tick() {
+	m.mu.Lock()
+	now := m.clock.Now()
+	var messages []string
+	for _, timer := range m.timers {
+		if now.Sub(timer.lastRun) < timer.Interval {
+			continue
+		}
+		// Don't talk to an empty room
+		if !m.lastActivity.After(timer.lastRun) {
+			continue
+		}
+		msg := timer.Message()
+		if msg == "" {
+			continue
+		}
+		timer.lastRun = now
+		messages = append(messages, msg)
+	}
+	m.mu.Unlock()
+
+	for _, msg := range messages {
+		m.send(msg)
+	}
+}
+
+// Run calls Tick every tickInterval until the context is cancelled
+func (m *Manager) Run(ctx context.Context, tickInterval time.Duration) {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Tick()
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Package trace tags the log entries for one incoming chat message with a
+// shared trace ID so they can be correlated.
+package trace
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+)
+
+// counter numbers trace IDs in the order messages arrive
+var counter atomic.Uint64
+
+// traceIDKey is the context key holding the trace ID
+type traceIDKey struct{}
+
+// NewID returns a trace ID unique within this process
+func NewID() string {
+	return strconv.FormatUint(counter.Add(1), 10)
+}
+
+// WithID returns a copy of ctx carrying the trace ID id
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, id)
+}
+
+// ID returns the trace ID carried by ctx, or "" if it has none
+func ID(ctx context.Context) string {
+	id, _ := ctx.Value(traceIDKey{}).(string)
+	return id
+}
+
+// Ensure returns ctx unchanged if it carries a trace ID, or a copy with a
+// new one otherwise
+func Ensure(ctx context.Context) context.Context {
+	if ID(ctx) != "" {
+		return ctx
+	}
+	return WithID(ctx, NewID())
+}
+
+// Logger returns the default logger with ctx's trace ID attached to every entry
+func Logger(ctx context.Context) *slog.Logger {
+	return slog.Default().With("trace_id", ID(ctx))
+}
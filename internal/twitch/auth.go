@@ -1,6 +1,7 @@
 package twitch
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -26,12 +27,14 @@ type TokenResponse struct {
 
 // AuthManager handles Twitch OAuth token management
 type AuthManager struct {
+	BotName           string
 	ClientID          string
 	ClientSecret      string
 	RefreshTokenValue string
 	AccessToken       string
 	ExpiresAt         time.Time
 	SecretsPath       string
+	HTTPTimeout       time.Duration // Per-request timeout for the token endpoint
 	lastRefreshTime   time.Time
 	etLocation        *time.Location
 }
@@ -39,6 +42,9 @@ type AuthManager struct {
 // tokenURL is the endpoint for token operations
 var tokenURL = "https://id.twitch.tv/oauth2/token"
 
+// DefaultHTTPTimeout is how long a token request may take before it's abandoned
+const DefaultHTTPTimeout = 10 * time.Second
+
 // NewAuthManager creates a new Twitch authentication manager
 func NewAuthManager(clientID, clientSecret, refreshToken, secretsPath string) *AuthManager {
 	loc := utils.GetLogLocation()
@@ -48,20 +54,66 @@ func NewAuthManager(clientID, clientSecret, refreshToken, secretsPath string) *A
 		ClientSecret:      clientSecret,
 		RefreshTokenValue: refreshToken,
 		SecretsPath:       secretsPath,
+		HTTPTimeout:       DefaultHTTPTimeout,
 		lastRefreshTime:   time.Now().In(loc),
 		etLocation:        loc,
 	}
 }
 
+// Environment variables read by LoadFromEnvironment
+const (
+	EnvClientID     = "TWITCH_CLIENT_ID"
+	EnvClientSecret = "TWITCH_CLIENT_SECRET"
+	EnvRefreshToken = "TWITCH_REFRESH_TOKEN"
+	EnvBotName      = "TWITCH_BOT_NAME"
+)
+
+// LoadFromEnvironment loads the bot's credentials from environment variables
+// instead of an auth secrets file, for deployments that inject secrets from
+// a secret manager. Refreshed tokens are kept in memory only.
+func (am *AuthManager) LoadFromEnvironment() error {
+	values := make(map[string]string)
+	var missing []string
+	for _, name := range []string{EnvClientID, EnvClientSecret, EnvRefreshToken, EnvBotName} {
+		value := strings.TrimSpace(os.Getenv(name))
+		if value == "" {
+			missing = append(missing, name)
+		}
+		values[name] = value
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required environment variables: %s", strings.Join(missing, ", "))
+	}
+
+	am.ClientID = values[EnvClientID]
+	am.ClientSecret = values[EnvClientSecret]
+	am.RefreshTokenValue = values[EnvRefreshToken]
+	am.BotName = values[EnvBotName]
+	am.SecretsPath = ""
+	return nil
+}
+
 // RefreshToken refreshes the OAuth token using the refresh token
 func (am *AuthManager) RefreshToken() error {
+	return am.RefreshTokenContext(context.Background())
+}
+
+// RefreshTokenContext refreshes the OAuth token, giving up when ctx is
+// cancelled or HTTPTimeout elapses
+func (am *AuthManager) RefreshTokenContext(ctx context.Context) error {
+	if am.HTTPTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, am.HTTPTimeout)
+		defer cancel()
+	}
+
 	data := url.Values{}
 	data.Set("grant_type", "refresh_token")
 	data.Set("refresh_token", am.RefreshTokenValue)
 	data.Set("client_id", am.ClientID)
 	data.Set("client_secret", am.ClientSecret)
 
-	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return fmt.Errorf("error creating request: %w", err)
 	}
@@ -101,6 +153,11 @@ func (am *AuthManager) RefreshToken() error {
 
 // persistRefreshToken saves the new refresh token to the secrets file
 func (am *AuthManager) persistRefreshToken() error {
+	// Credentials loaded from the environment have no file to update
+	if am.SecretsPath == "" {
+		return nil
+	}
+
 	// Read the current secrets file
 	data, err := os.ReadFile(am.SecretsPath)
 	if err != nil {
@@ -134,9 +191,15 @@ func (am *AuthManager) persistRefreshToken() error {
 
 // GetAccessToken returns the current access token, refreshing if necessary
 func (am *AuthManager) GetAccessToken() (string, error) {
+	return am.GetAccessTokenContext(context.Background())
+}
+
+// GetAccessTokenContext returns the current access token, refreshing it with
+// ctx if necessary
+func (am *AuthManager) GetAccessTokenContext(ctx context.Context) (string, error) {
 	if !am.IsTokenValid() {
 		log.Printf("[Auth] Refreshing token...")
-		if err := am.RefreshToken(); err != nil {
+		if err := am.RefreshTokenContext(ctx); err != nil {
 			return "", fmt.Errorf("failed to refresh token: %w", err)
 		}
 		am.lastRefreshTime = time.Now().In(am.etLocation)
@@ -161,3 +224,40 @@ func (am *AuthManager) GetLastRefreshTime() time.Time {
 func (am *AuthManager) GetExpiresAt() time.Time {
 	return am.ExpiresAt
 }
+
+// StatusSummary describes the token's state without any token material:
+// whether it's valid, how long until it expires, when it was last refreshed
+// (in the log timezone) and whether refreshed tokens are saved to a file
+func (am *AuthManager) StatusSummary() string {
+	status := "invalid"
+	if am.IsTokenValid() {
+		status = "valid"
+	}
+
+	expiry := "expiry unknown"
+	if !am.ExpiresAt.IsZero() {
+		if remaining := time.Until(am.ExpiresAt); remaining > 0 {
+			expiry = fmt.Sprintf("expires in %s (%s)", remaining.Round(time.Second), utils.FormatTimeForLogs(am.ExpiresAt))
+		} else {
+			expiry = fmt.Sprintf("expired %s ago (%s)", (-remaining).Round(time.Second), utils.FormatTimeForLogs(am.ExpiresAt))
+		}
+	}
+
+	lastRefresh := "never"
+	if !am.lastRefreshTime.IsZero() {
+		lastRefresh = utils.FormatTimeForLogs(am.lastRefreshTime)
+	}
+
+	persist := "not persisted"
+	if am.SecretsPath != "" {
+		persist = "persisted to secrets file"
+	}
+
+	return fmt.Sprintf("Token %s, %s | Last refresh: %s | Refresh token %s", status, expiry, lastRefresh, persist)
+}
+
+// String implements fmt.Stringer so printing an AuthManager shows its
+// status rather than its tokens
+func (am *AuthManager) String() string {
+	return am.StatusSummary()
+}
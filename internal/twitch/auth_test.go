@@ -1,9 +1,15 @@
 package twitch
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -36,11 +42,18 @@ func TestTokenRefresh(t *testing.T) {
 	}))
 	defer server.Close()
 
+	// Create a secrets file for the refreshed token to be persisted to
+	secretsPath := filepath.Join(t.TempDir(), "test_auth_secrets.yaml")
+	if err := os.WriteFile(secretsPath, []byte("bot_name: testbot\n"), 0644); err != nil {
+		t.Fatalf("Failed to write secrets file: %v", err)
+	}
+
 	// Create a new auth manager with test credentials
 	am := NewAuthManager(
 		"test_client_id",
 		"test_client_secret",
 		"test_refresh_token",
+		secretsPath,
 	)
 
 	// Override the token endpoint URL for testing
@@ -90,8 +103,148 @@ func TestTokenRefresh(t *testing.T) {
 	}
 
 	// Test token near expiration
-	am.ExpiresAt = time.Now().Add(4 * time.Minute) // Set expiration to 4 minutes from now
+	am.ExpiresAt = time.Now().Add(30 * time.Second) // Set expiration to 30 seconds from now
 	if am.IsTokenValid() {
-		t.Error("Token should be considered invalid when within 5 minutes of expiration")
+		t.Error("Token should be considered invalid when within 1 minute of expiration")
+	}
+}
+
+func TestTokenRefreshTimeout(t *testing.T) {
+	// A token endpoint that hangs until the test finishes
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	originalTokenURL := tokenURL
+	tokenURL = server.URL
+	defer func() { tokenURL = originalTokenURL }()
+
+	am := NewAuthManager("test_client_id", "test_client_secret", "test_refresh_token", "")
+
+	t.Run("context_deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := am.RefreshTokenContext(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected deadline exceeded error, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Expected refresh to give up promptly, took %v", elapsed)
+		}
+	})
+
+	t.Run("http_timeout", func(t *testing.T) {
+		am.HTTPTimeout = 50 * time.Millisecond
+
+		start := time.Now()
+		if err := am.RefreshToken(); err == nil {
+			t.Error("Expected refresh to time out")
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Expected refresh to give up promptly, took %v", elapsed)
+		}
+	})
+
+	if am.AccessToken != "" {
+		t.Error("Access token should not change after a failed refresh")
+	}
+}
+
+func TestLoadFromEnvironment(t *testing.T) {
+	envVars := map[string]string{
+		EnvClientID:     "env_client_id",
+		EnvClientSecret: "env_client_secret",
+		EnvRefreshToken: "env_refresh_token",
+		EnvBotName:      "envbot",
+	}
+
+	t.Run("all_set", func(t *testing.T) {
+		for name, value := range envVars {
+			t.Setenv(name, value)
+		}
+
+		am := NewAuthManager("", "", "", "configs/bots/old_auth_secrets.yaml")
+		if err := am.LoadFromEnvironment(); err != nil {
+			t.Fatalf("Expected credentials to load, got %v", err)
+		}
+		if am.ClientID != "env_client_id" || am.ClientSecret != "env_client_secret" ||
+			am.RefreshTokenValue != "env_refresh_token" || am.BotName != "envbot" {
+			t.Errorf("Unexpected credentials: %+v", am)
+		}
+		if am.SecretsPath != "" {
+			t.Errorf("Expected no secrets file when loading from environment, got %s", am.SecretsPath)
+		}
+	})
+
+	for missing := range envVars {
+		t.Run("missing_"+missing, func(t *testing.T) {
+			for name, value := range envVars {
+				if name == missing {
+					value = ""
+				}
+				t.Setenv(name, value)
+			}
+
+			am := NewAuthManager("", "", "", "")
+			err := am.LoadFromEnvironment()
+			expected := "missing required environment variables: " + missing
+			if err == nil || err.Error() != expected {
+				t.Errorf("Expected error '%s', got %v", expected, err)
+			}
+		})
+	}
+
+	t.Run("none_set", func(t *testing.T) {
+		for name := range envVars {
+			t.Setenv(name, "")
+		}
+
+		err := NewAuthManager("", "", "", "").LoadFromEnvironment()
+		expected := "missing required environment variables: TWITCH_CLIENT_ID, TWITCH_CLIENT_SECRET, TWITCH_REFRESH_TOKEN, TWITCH_BOT_NAME"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error '%s', got %v", expected, err)
+		}
+	})
+}
+
+func TestStatusSummary(t *testing.T) {
+	am := NewAuthManager("client_id", "client_secret_value", "refresh_token_value", "configs/bots/test_auth_secrets.yaml")
+	am.AccessToken = "access_token_value"
+	am.ExpiresAt = time.Now().Add(2*time.Hour + 30*time.Second)
+
+	summary := am.StatusSummary()
+	for _, want := range []string{"Token valid", "expires in 2h0m30s", "Last refresh:", "persisted to secrets file"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Expected summary to contain %q, got %q", want, summary)
+		}
+	}
+
+	// No token material, however the manager is printed
+	for _, printed := range []string{summary, am.String(), fmt.Sprintf("%v", am), fmt.Sprintf("%+v", am)} {
+		for _, secret := range []string{"access_token_value", "refresh_token_value", "client_secret_value"} {
+			if strings.Contains(printed, secret) {
+				t.Errorf("Summary leaked %q: %q", secret, printed)
+			}
+		}
+	}
+
+	// Expired tokens without a secrets file
+	am.ExpiresAt = time.Now().Add(-90 * time.Second)
+	am.SecretsPath = ""
+	summary = am.StatusSummary()
+	for _, want := range []string{"Token invalid", "expired 1m30s ago", "not persisted"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Expected summary to contain %q, got %q", want, summary)
+		}
+	}
+
+	am.ExpiresAt = time.Time{}
+	if summary := am.StatusSummary(); !strings.Contains(summary, "expiry unknown") {
+		t.Errorf("Expected unknown expiry, got %q", summary)
 	}
 }
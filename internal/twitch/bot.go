@@ -2,14 +2,19 @@ package twitch
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"runtime/debug"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gempir/go-twitch-irc/v4"
 	channelstats "github.com/pbuckles22/PBChatBot/internal/channel"
 	"github.com/pbuckles22/PBChatBot/internal/config"
+	"github.com/pbuckles22/PBChatBot/internal/trace"
 	"github.com/pbuckles22/PBChatBot/internal/utils"
 )
 
@@ -34,14 +39,140 @@ type Bot struct {
 	channel         string
 	authManager     *AuthManager
 	client          *twitch.Client
-	commandHandlers []func(twitch.PrivateMessage) string
+	commandHandlers []func(context.Context, twitch.PrivateMessage) string
 	secretsPath     string
 	botUsername     string
 	startTime       time.Time
 	cfg             *config.Config
 	channelStats    *channelstats.ChannelStats
+	api             *TwitchAPIClient
+
+	// Helix user IDs for announcements, looked up when connecting (or on
+	// first use if that failed)
+	broadcasterID string
+	botUserID     string
+	userIDMu      sync.Mutex
+
+	// Chat messages waiting for the command handlers, which run on their
+	// own goroutine so slow Helix calls never hold up the IRC reader. Nil
+	// until Connect, in which case handlers run inline.
+	commandQueue chan chatCommand
+
+	// Minimum time between two consecutive bot responses (0 disables)
+	responseThrottle time.Duration
+	lastResponseTime time.Time
+	throttleMu       sync.Mutex
+	// Last message sent to each channel (keyed by lowercase channel), so
+	// repeats can be made unique before Twitch drops them
+	lastResponses map[string]sentResponse
+	dedupeMu      sync.Mutex
+	// Context passed to Connect, used as the parent for Helix requests
+	ctx context.Context
+
+	// Extra channels joined to relay messages into
+	joined   map[string]bool
+	joinedMu sync.Mutex
+
+	// Connection state reported by !connstatus
+	connected         atomic.Bool
+	reconnectAttempts atomic.Int32
+	// Set after the first successful connect; later connects are reconnects
+	connectedOnce atomic.Bool
+	// Closed on the first successful connect
+	firstConnect chan struct{}
+	// Reconnects since startup and when the last one happened (unix nanos),
+	// never reset so flapping connections show up in metrics
+	reconnects    atomic.Int64
+	lastReconnect atomic.Int64
+	// When a chat message was last seen, keyed by lowercase channel
+	lastMessageTime map[string]time.Time
+	lastMessageMu   sync.Mutex
+	// Latest ROOMSTATE values (e.g. "slow": 30), keyed by lowercase channel
+	roomState   map[string]map[string]int
+	roomStateMu sync.Mutex
+
+	// Set once Twitch reports a login other than botUsername; the bot stops
+	// handling chat from then on
+	identityRejected   atomic.Bool
+	onIdentityMismatch func(error)
+
+	// Set when Twitch rejects the login; the token is refreshed before the
+	// next reconnect instead of retrying with the same one
+	authFailed    atomic.Bool
+	onAuthFailure func(error)
+	// Token refreshes since the last successful connect
+	authRefreshes atomic.Int32
+
+	// How often the channel's live status is checked to start and end chat
+	// sessions (0 disables), whether it was live at the last check, and
+	// whether it has been checked at all
+	streamPollInterval time.Duration
+	streamLive         atomic.Bool
+	streamChecked      atomic.Bool
+	// Called when the channel is seen going live
+	onStreamStart func()
+
+	// How long to wait before reconnecting after the IRC connection fails,
+	// how long the connection can sit idle before the bot sends a PING, and
+	// how long it waits for the PONG before reconnecting
+	reconnectDelay   time.Duration
+	idlePingInterval time.Duration
+	pongTimeout      time.Duration
+	// When the outstanding keepalive PING was sent (unix nanos, 0 when none
+	// is) and the round trip of the last one answered, in nanoseconds
+	pingSentAt atomic.Int64
+	latency    atomic.Int64
+	// Plain-text IRC server to use instead of Twitch's, if set
+	ircAddress string
+	// Goroutines started by Connect; they stop once its context is done and
+	// the IRC client is disconnected
+	loops sync.WaitGroup
 }
 
+// chatCommand is a chat message queued for the command handlers, with the
+// context carrying its trace ID
+type chatCommand struct {
+	ctx     context.Context
+	message twitch.PrivateMessage
+}
+
+// commandQueueSize is how many chat messages can wait for the command
+// handlers before new ones are dropped
+const commandQueueSize = 100
+
+// sentResponse is the last message the bot sent to a channel
+type sentResponse struct {
+	text   string
+	count  int
+	sentAt time.Time
+}
+
+// duplicateWindow is how long Twitch treats a repeated message as a
+// duplicate. Tests shorten it.
+var duplicateWindow = 60 * time.Second
+
+// ErrBotIdentityMismatch is returned when the token belongs to a different
+// account than the configured bot username
+var ErrBotIdentityMismatch = errors.New("connected account does not match the configured bot username")
+
+// Default connection timing for NewBot's bots
+const (
+	defaultReconnectDelay   = 30 * time.Second
+	defaultIdlePingInterval = 4 * time.Minute
+	defaultPongTimeout      = 10 * time.Second
+)
+
+// ErrAuthenticationFailed is returned when Twitch rejects the login and the
+// token can't be refreshed, or keeps rejecting refreshed tokens
+var ErrAuthenticationFailed = errors.New("twitch login authentication failed")
+
+// maxAuthRefreshes is how many times in a row the token is refreshed after
+// Twitch rejects the login before the bot gives up
+const maxAuthRefreshes = 3
+
+// ErrClientPanic wraps a panic recovered from the IRC client's Connect
+var ErrClientPanic = errors.New("twitch IRC client panicked")
+
 // NewBot creates a new Twitch bot instance
 func NewBot(channel string, authManager *AuthManager, secretsPath string, botUsername string) *Bot {
 	// Load the channel's config
@@ -57,6 +188,7 @@ func NewBot(channel string, authManager *AuthManager, secretsPath string, botUse
 
 	// Initialize channel stats using the same data path as the queue
 	channelStats := channelstats.NewChannelStats(cfg.DataPath)
+	channelStats.SetRetention(cfg.StatsRetention.MaxSessions, cfg.StatsRetention.MaxAgeDays)
 
 	return &Bot{
 		channel:      channel,
@@ -66,13 +198,21 @@ func NewBot(channel string, authManager *AuthManager, secretsPath string, botUse
 		startTime:    time.Now(),
 		cfg:          cfg,
 		channelStats: channelStats,
+		api:          NewTwitchAPIClient(authManager),
+
+		streamPollInterval: defaultStreamPollInterval,
+		reconnectDelay:     defaultReconnectDelay,
+		idlePingInterval:   defaultIdlePingInterval,
+		pongTimeout:        defaultPongTimeout,
 	}
 }
 
 // Connect establishes a connection to Twitch IRC
 func (b *Bot) Connect(ctx context.Context) error {
+	b.ctx = ctx
+
 	// Get initial access token, refreshing only if needed
-	token, err := b.authManager.GetAccessToken()
+	token, err := b.authManager.GetAccessTokenContext(ctx)
 	if err != nil {
 		return fmt.Errorf("error getting initial access token: %w", err)
 	}
@@ -88,70 +228,295 @@ func (b *Bot) Connect(ctx context.Context) error {
 
 	// Create Twitch client with bot username and new token
 	b.client = twitch.NewClient(b.botUsername, "oauth:"+token)
+	b.commandQueue = make(chan chatCommand, commandQueueSize)
+	firstConnect := make(chan struct{})
+	b.firstConnect = firstConnect
+	if b.ircAddress != "" {
+		b.client.IrcAddress = b.ircAddress
+		b.client.TLS = false
+	}
+
+	// Keep idle connections alive; a missing PONG makes the client reconnect
+	b.client.SendPings = true
+	b.client.IdlePingInterval = b.idlePingInterval
+	b.client.PongTimeout = b.pongTimeout
+	b.client.OnPingSent(b.recordPingSent)
+	b.client.OnPongMessage(b.handlePong)
 
 	// Set up connection handler
 	b.client.OnConnect(func() {
+		b.connected.Store(true)
+		b.reconnectAttempts.Store(0)
+		b.authRefreshes.Store(0)
+		if b.connectedOnce.Swap(true) {
+			b.recordReconnect()
+		} else {
+			close(firstConnect)
+		}
 		log.Printf("Successfully connected to Twitch IRC")
 		log.Printf("Joining channel: %s", b.channel)
 		b.client.Join(b.channel)
 	})
 
+	// Make sure the token belongs to the account we think we're running as
+	b.client.OnGlobalUserStateMessage(func(message twitch.GlobalUserStateMessage) {
+		b.verifyIdentity(message.User.Name)
+	})
+	b.client.OnUserStateMessage(func(message twitch.UserStateMessage) {
+		b.verifyIdentity(message.User.Name)
+	})
+
+	// Watch for Twitch rejecting the token
+	b.client.OnNoticeMessage(b.handleNotice)
+
+	// Track the channel's chat restrictions for !roommode
+	b.client.OnRoomStateMessage(b.handleRoomState)
+
 	// Set up message handler
-	b.client.OnPrivateMessage(func(message twitch.PrivateMessage) {
-		// Record chatter stats
-		b.channelStats.RecordChatMessage(message.User.Name)
-		// Check if token needs refresh
-		if !b.authManager.IsTokenValid() {
-			newToken, err := b.authManager.GetAccessToken()
+	b.client.OnPrivateMessage(b.handlePrivateMessage)
+
+	// Start connection in a goroutine with reconnection logic
+	b.goLoop(func() { b.connectLoop(ctx, b.client.Connect) })
+
+	// Start token refresh goroutine
+	b.goLoop(func() { b.refreshTokenLoop(ctx) })
+
+	// Run command handlers off the IRC reader
+	queue := b.commandQueue
+	b.goLoop(func() { b.runCommands(ctx, queue) })
+
+	// Resolve the user IDs Helix calls need before chat asks for them
+	if b.api != nil {
+		b.goLoop(func() { b.resolveUserIDs(ctx) })
+	}
+
+	// Start and end chat sessions with the stream
+	if b.api != nil && b.streamPollInterval > 0 {
+		b.goLoop(func() { b.watchStream(ctx, b.streamPollInterval) })
+	}
+
+	return nil
+}
+
+// goLoop runs f in a goroutine tracked by b.loops
+func (b *Bot) goLoop(f func()) {
+	b.loops.Add(1)
+	go func() {
+		defer b.loops.Done()
+		f()
+	}()
+}
+
+// connectLoop runs connect until it returns cleanly, reconnecting after
+// failures until ctx is done or the bot's identity is rejected
+func (b *Bot) connectLoop(ctx context.Context, connect func() error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			err := connectRecovered(connect)
+			b.connected.Store(false)
 			if err != nil {
-				log.Printf("Error refreshing token: %v", err)
-				return
+				if b.identityRejected.Load() {
+					return
+				}
+				b.reconnectAttempts.Add(1)
+				log.Printf("Error connecting to Twitch IRC: %v", err)
+				if b.authFailed.Swap(false) || errors.Is(err, twitch.ErrLoginAuthenticationFailed) {
+					// Reconnect with a fresh token, or stop
+					if err := b.refreshAfterAuthFailure(ctx); err != nil {
+						return
+					}
+				}
+				log.Printf("Attempting to reconnect in %s...", b.reconnectDelay)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(b.reconnectDelay):
+				}
+				continue
 			}
-			b.client.SetIRCToken("oauth:" + newToken)
+			return
 		}
+	}
+}
 
-		// Handle commands
-		for _, handler := range b.commandHandlers {
-			if response := handler(message); response != "" {
+// connectRecovered calls connect, turning a panic in the IRC client (such as
+// a repeated read on a failed connection) into an error so the bot
+// reconnects instead of crashing
+func connectRecovered(connect func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Recovered from panic in Twitch IRC client: %v\n%s", r, debug.Stack())
+			err = fmt.Errorf("%w: %v", ErrClientPanic, r)
+		}
+	}()
+	return connect()
+}
+
+// handlePrivateMessage records chat stats and runs the command handlers
+func (b *Bot) handlePrivateMessage(message twitch.PrivateMessage) {
+	if b.identityRejected.Load() {
+		return
+	}
+
+	b.recordMessageTime(message.Channel, time.Now())
+	// Channels joined only to relay into aren't ours: their chat isn't
+	// counted, relayed back or run as commands
+	if !strings.EqualFold(message.Channel, b.channel) {
+		return
+	}
+
+	// Record chatter stats
+	b.channelStats.RecordChatMessage(message.User.Name)
+	// Check if token needs refresh
+	if !b.authManager.IsTokenValid() {
+		newToken, err := b.authManager.GetAccessToken()
+		if err != nil {
+			log.Printf("Error refreshing token: %v", err)
+			return
+		}
+		b.client.SetIRCToken("oauth:" + newToken)
+	}
+
+	// Handle commands, tagging their log entries with one trace ID
+	ctx := trace.WithID(context.Background(), trace.NewID())
+	if b.commandQueue == nil {
+		b.runCommandHandlers(ctx, message)
+		return
+	}
+	select {
+	case b.commandQueue <- chatCommand{ctx: ctx, message: message}:
+	default:
+		log.Printf("Command handlers are behind, dropping message from %s", message.User.Name)
+	}
+}
+
+// runCommands runs the command handlers for queued chat messages, in order,
+// until ctx is done
+func (b *Bot) runCommands(ctx context.Context, queue <-chan chatCommand) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case command := <-queue:
+			b.runCommandHandlers(command.ctx, command.message)
+		}
+	}
+}
+
+// runCommandHandlers sends the reply of the first command handler that has one
+func (b *Bot) runCommandHandlers(ctx context.Context, message twitch.PrivateMessage) {
+	for _, handler := range b.commandHandlers {
+		if response := handler(ctx, message); response != "" {
+			trace.Logger(ctx).Debug("sending response", "channel", message.Channel, "length", len(response))
+			channel := message.Channel
+			b.paced(func() {
 				// Check if response is a whisper command
 				if strings.HasPrefix(response, "/w ") {
 					// Extract the whisper command parts
 					parts := strings.SplitN(response, " ", 3)
 					if len(parts) == 3 {
-						b.client.Say(message.Channel, fmt.Sprintf("/w %s %s", parts[1], parts[2]))
+						b.client.Say(channel, fmt.Sprintf("/w %s %s", parts[1], parts[2]))
 					}
 				} else {
-					b.client.Say(message.Channel, response)
+					b.say(channel, response)
 				}
-				break
-			}
+			})
+			break
 		}
-	})
+	}
+}
 
-	// Start connection in a goroutine with reconnection logic
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				if err := b.client.Connect(); err != nil {
-					log.Printf("Error connecting to Twitch IRC: %v", err)
-					log.Printf("Attempting to reconnect in 30 seconds...")
-					time.Sleep(30 * time.Second)
-					continue
-				}
-				return
-			}
-		}
-	}()
+// isAuthFailureNotice reports whether a NOTICE is Twitch rejecting the login
+func isAuthFailureNotice(message twitch.NoticeMessage) bool {
+	switch message.Message {
+	case "Login authentication failed", "Improperly formatted auth":
+		return true
+	}
+	return false
+}
 
-	// Start token refresh goroutine
-	go b.refreshTokenLoop(ctx)
+// handleNotice flags a login rejection so the reconnect loop refreshes the
+// token instead of retrying with the one Twitch just refused
+func (b *Bot) handleNotice(message twitch.NoticeMessage) {
+	if isAuthFailureNotice(message) {
+		log.Printf("[Auth] Twitch rejected the login: %s", message.Message)
+		b.authFailed.Store(true)
+	}
+}
 
+// refreshAfterAuthFailure refreshes the token after Twitch rejected the
+// login and hands it to the IRC client. If the refresh fails, or Twitch has
+// already rejected maxAuthRefreshes refreshed tokens in a row (e.g. the token
+// is for the wrong account or lacks a scope), the bot can't log in at all,
+// so the auth failure hook is called with the error.
+func (b *Bot) refreshAfterAuthFailure(ctx context.Context) error {
+	if b.authRefreshes.Add(1) > maxAuthRefreshes {
+		return b.stopForAuthFailure(fmt.Errorf("%w after %d token refreshes in a row", ErrAuthenticationFailed, maxAuthRefreshes))
+	}
+	log.Printf("[Auth] Refreshing token before reconnecting...")
+	if err := b.authManager.RefreshTokenContext(ctx); err != nil {
+		return b.stopForAuthFailure(fmt.Errorf("%w and the token could not be refreshed: %v", ErrAuthenticationFailed, err))
+	}
+	if b.client != nil {
+		b.client.SetIRCToken("oauth:" + b.authManager.AccessToken)
+	}
+	log.Printf("[Auth] Token refreshed")
 	return nil
 }
 
+// stopForAuthFailure reports an unrecoverable login failure to the auth
+// failure hook and returns it
+func (b *Bot) stopForAuthFailure(err error) error {
+	log.Printf("!!! %v. Stopping. !!!", err)
+	if b.onAuthFailure != nil {
+		b.onAuthFailure(err)
+	}
+	return err
+}
+
+// SetOnAuthFailure sets a function called if Twitch rejects the login and
+// the token can't be refreshed
+func (b *Bot) SetOnAuthFailure(f func(error)) {
+	b.onAuthFailure = f
+}
+
+// verifyIdentity compares the login Twitch reports for the connection with
+// the configured bot username. On a mismatch it disconnects so the bot never
+// acts as the wrong account, and reports the error to the mismatch hook.
+func (b *Bot) verifyIdentity(login string) error {
+	if login == "" || strings.EqualFold(login, b.botUsername) {
+		return nil
+	}
+
+	err := fmt.Errorf("%w: token is for %q, config says %q", ErrBotIdentityMismatch, login, b.botUsername)
+	if b.identityRejected.Swap(true) {
+		return err
+	}
+	log.Printf("!!! SECURITY: %v. Disconnecting. !!!", err)
+	if b.client != nil {
+		b.client.Disconnect()
+	}
+	if b.onIdentityMismatch != nil {
+		b.onIdentityMismatch(err)
+	}
+	return err
+}
+
+// SetOnIdentityMismatch sets a function called once if the connected account
+// doesn't match the configured bot username
+func (b *Bot) SetOnIdentityMismatch(f func(error)) {
+	b.onIdentityMismatch = f
+}
+
+// IdentityRejected reports whether the bot refused to run because the
+// connected account didn't match the configured bot username
+func (b *Bot) IdentityRejected() bool {
+	return b.identityRejected.Load()
+}
+
 // refreshTokenLoop periodically checks and refreshes the token
 func (b *Bot) refreshTokenLoop(ctx context.Context) {
 	// Calculate initial check interval based on time until expiry
@@ -191,7 +556,7 @@ func (b *Bot) refreshTokenLoop(ctx context.Context) {
 				// Store the old expiry time for comparison
 				oldExpiry := b.authManager.ExpiresAt
 
-				newToken, err := b.authManager.GetAccessToken()
+				newToken, err := b.authManager.GetAccessTokenContext(ctx)
 				if err != nil {
 					log.Printf("Error refreshing token: %v", err)
 					continue
@@ -265,8 +630,296 @@ func calculateCheckInterval(timeUntilExpiry time.Duration) time.Duration {
 	return interval
 }
 
-// RegisterCommandHandler adds a new command handler
-func (b *Bot) RegisterCommandHandler(handler func(twitch.PrivateMessage) string) {
+// SetResponseThrottle sets the minimum time between consecutive bot responses.
+// A zero duration disables throttling.
+func (b *Bot) SetResponseThrottle(d time.Duration) {
+	b.throttleMu.Lock()
+	defer b.throttleMu.Unlock()
+	b.responseThrottle = d
+}
+
+// GetResponseThrottle returns the minimum time between consecutive bot responses
+func (b *Bot) GetResponseThrottle() time.Duration {
+	b.throttleMu.Lock()
+	defer b.throttleMu.Unlock()
+	return b.responseThrottle
+}
+
+// reserveResponseSlot claims the next send time the response throttle
+// allows and returns how long until it. Responses are paced, never dropped.
+func (b *Bot) reserveResponseSlot() time.Duration {
+	b.throttleMu.Lock()
+	defer b.throttleMu.Unlock()
+
+	now := time.Now()
+	slot := now
+	if b.responseThrottle > 0 && !b.lastResponseTime.IsZero() {
+		if next := b.lastResponseTime.Add(b.responseThrottle); next.After(now) {
+			slot = next
+		}
+	}
+	b.lastResponseTime = slot
+	return slot.Sub(now)
+}
+
+// waitForResponseSlot blocks until the response throttle allows another message
+func (b *Bot) waitForResponseSlot() {
+	time.Sleep(b.reserveResponseSlot())
+}
+
+// paced runs send once the response throttle allows another message. If it
+// has to wait, send runs later on its own goroutine so the caller (usually
+// the IRC reader) isn't held up; reserved slots keep messages in order.
+func (b *Bot) paced(send func()) {
+	if wait := b.reserveResponseSlot(); wait > 0 {
+		time.AfterFunc(wait, send)
+		return
+	}
+	send()
+}
+
+// Say sends a message to the bot's channel, respecting the response throttle
+func (b *Bot) Say(message string) {
+	if b.client == nil || b.identityRejected.Load() {
+		return
+	}
+	b.paced(func() { b.say(b.channel, message) })
+}
+
+// SayTo sends a message to another channel, joining it first if needed.
+// It's used for relaying chat and isn't subject to the response throttle.
+func (b *Bot) SayTo(channel, message string) {
+	if b.client == nil || b.identityRejected.Load() {
+		return
+	}
+	channel = strings.ToLower(channel)
+
+	b.joinedMu.Lock()
+	if b.joined == nil {
+		b.joined = make(map[string]bool)
+	}
+	if !b.joined[channel] && channel != strings.ToLower(b.channel) {
+		b.client.Join(channel)
+		b.joined[channel] = true
+	}
+	b.joinedMu.Unlock()
+
+	b.say(channel, message)
+}
+
+// Depart leaves a channel joined by SayTo. The bot's own channel is never left.
+func (b *Bot) Depart(channel string) {
+	channel = strings.ToLower(channel)
+	if channel == strings.ToLower(b.channel) {
+		return
+	}
+
+	b.joinedMu.Lock()
+	joined := b.joined[channel]
+	delete(b.joined, channel)
+	b.joinedMu.Unlock()
+
+	if joined && b.client != nil {
+		b.client.Depart(channel)
+	}
+}
+
+// dedupeResponse returns msg with a counter suffix, e.g. "... (2)", when it
+// repeats the last message sent to channel within duplicateWindow, since
+// Twitch silently drops identical consecutive messages.
+func (b *Bot) dedupeResponse(channel, msg string) string {
+	b.dedupeMu.Lock()
+	defer b.dedupeMu.Unlock()
+
+	if b.lastResponses == nil {
+		b.lastResponses = make(map[string]sentResponse)
+	}
+	key := strings.ToLower(channel)
+	now := time.Now()
+	last, ok := b.lastResponses[key]
+	if !ok || last.text != msg || now.Sub(last.sentAt) >= duplicateWindow {
+		b.lastResponses[key] = sentResponse{text: msg, count: 1, sentAt: now}
+		return msg
+	}
+
+	last.count++
+	last.sentAt = now
+	b.lastResponses[key] = last
+	return fmt.Sprintf("%s (%d)", msg, last.count)
+}
+
+// Announce posts a highlighted chat announcement through the Helix API,
+// falling back to a regular chat message if the API call fails.
+func (b *Bot) Announce(message string) {
+	ctx, cancel := b.requestContext()
+	defer cancel()
+
+	if err := b.sendAnnouncement(ctx, message); err != nil {
+		log.Printf("[Announce] Falling back to chat message: %v", err)
+		b.Say(message)
+	}
+}
+
+// sendAnnouncement resolves the channel and bot user IDs and sends the announcement
+func (b *Bot) sendAnnouncement(ctx context.Context, message string) error {
+	if b.api == nil {
+		return fmt.Errorf("no API client configured")
+	}
+	broadcasterID, err := b.getBroadcasterID(ctx)
+	if err != nil {
+		return err
+	}
+	botUserID, err := b.getBotUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if prefix := b.responsePrefix(); prefix != "" {
+		message = prefix + " " + message
+	}
+	b.waitForResponseSlot()
+	return b.api.SendAnnouncement(ctx, broadcasterID, botUserID, message, "primary")
+}
+
+// resolveUserIDs looks up the broadcaster and bot user IDs ahead of the
+// first announcement or follow check. Failures are retried on first use.
+func (b *Bot) resolveUserIDs(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, helixTimeout)
+	defer cancel()
+
+	if _, err := b.getBroadcasterID(ctx); err != nil {
+		log.Printf("[Helix] %v", err)
+	}
+	if _, err := b.getBotUserID(ctx); err != nil {
+		log.Printf("[Helix] %v", err)
+	}
+}
+
+// getBotUserID returns the bot account's user ID, looking it up once
+func (b *Bot) getBotUserID(ctx context.Context) (string, error) {
+	b.userIDMu.Lock()
+	defer b.userIDMu.Unlock()
+	if b.botUserID == "" {
+		id, err := b.api.GetUserID(ctx, b.botUsername)
+		if err != nil {
+			return "", fmt.Errorf("error looking up bot user ID: %w", err)
+		}
+		b.botUserID = id
+	}
+	return b.botUserID, nil
+}
+
+// Whisper sends a Twitch whisper through the Helix API. Users who have
+// disabled whispers and tokens missing the whisper scope are logged as
+// warnings; the error is still returned so callers can fall back to chat.
+func (b *Bot) Whisper(fromUserID, toUserID, message string) error {
+	if b.api == nil {
+		return fmt.Errorf("no API client configured")
+	}
+
+	ctx, cancel := b.requestContext()
+	defer cancel()
+
+	err := b.api.SendWhisper(ctx, fromUserID, toUserID, message)
+	switch {
+	case errors.Is(err, ErrWhisperDisabled):
+		log.Printf("[Whisper] Warning: user %s has disabled whispers: %v", toUserID, err)
+	case errors.Is(err, ErrMissingScopes):
+		log.Printf("[Whisper] Warning: bot token is missing the user:manage:whispers scope: %v", err)
+	case err != nil:
+		log.Printf("[Whisper] Error sending whisper to %s: %v", toUserID, err)
+	}
+	return err
+}
+
+// WhisperUser whispers username from the bot account
+func (b *Bot) WhisperUser(username, message string) error {
+	if b.api == nil {
+		return fmt.Errorf("no API client configured")
+	}
+
+	ctx, cancel := b.requestContext()
+	defer cancel()
+
+	fromUserID, err := b.getBotUserID(ctx)
+	if err != nil {
+		return err
+	}
+	toUserID, err := b.api.GetUserID(ctx, username)
+	if err != nil {
+		return fmt.Errorf("error looking up user ID for %s: %w", username, err)
+	}
+	return b.Whisper(fromUserID, toUserID, message)
+}
+
+// getBroadcasterID returns the channel owner's user ID, looking it up once
+func (b *Bot) getBroadcasterID(ctx context.Context) (string, error) {
+	b.userIDMu.Lock()
+	defer b.userIDMu.Unlock()
+	if b.broadcasterID == "" {
+		id, err := b.api.GetUserID(ctx, b.channel)
+		if err != nil {
+			return "", fmt.Errorf("error looking up broadcaster ID: %w", err)
+		}
+		b.broadcasterID = id
+	}
+	return b.broadcasterID, nil
+}
+
+// FollowAge reports how long username has followed the channel. The bool is
+// false when the user doesn't follow at all.
+func (b *Bot) FollowAge(username string) (time.Duration, bool, error) {
+	if b.api == nil {
+		return 0, false, fmt.Errorf("no API client configured")
+	}
+
+	ctx, cancel := b.requestContext()
+	defer cancel()
+
+	broadcasterID, err := b.getBroadcasterID(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	userID, err := b.api.GetUserID(ctx, username)
+	if err != nil {
+		return 0, false, fmt.Errorf("error looking up user ID for %s: %w", username, err)
+	}
+	followedAt, following, err := b.api.GetFollowedAt(ctx, broadcasterID, userID)
+	if err != nil || !following {
+		return 0, following, err
+	}
+	return time.Since(followedAt), true, nil
+}
+
+// AccountCreatedAt reports when username's Twitch account was created
+func (b *Bot) AccountCreatedAt(username string) (time.Time, error) {
+	if b.api == nil {
+		return time.Time{}, fmt.Errorf("no API client configured")
+	}
+
+	ctx, cancel := b.requestContext()
+	defer cancel()
+	return b.api.GetUserCreatedAt(ctx, username)
+}
+
+// requestContext returns a context for one Helix call, bounded by the Helix
+// timeout and cancelled when the bot shuts down
+func (b *Bot) requestContext() (context.Context, context.CancelFunc) {
+	parent := b.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	return context.WithTimeout(parent, helixTimeout)
+}
+
+// GetChannelStats returns the channel stats tracker for this bot
+func (b *Bot) GetChannelStats() *channelstats.ChannelStats {
+	return b.channelStats
+}
+
+// RegisterCommandHandler adds a new command handler. The context carries the
+// message's trace ID.
+func (b *Bot) RegisterCommandHandler(handler func(context.Context, twitch.PrivateMessage) string) {
 	b.commandHandlers = append(b.commandHandlers, handler)
 }
 
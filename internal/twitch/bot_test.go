@@ -0,0 +1,842 @@
+package twitch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gempir/go-twitch-irc/v4"
+	channelstats "github.com/pbuckles22/PBChatBot/internal/channel"
+	"github.com/pbuckles22/PBChatBot/internal/config"
+)
+
+func TestResponseThrottle(t *testing.T) {
+	b := &Bot{}
+	b.SetResponseThrottle(100 * time.Millisecond)
+
+	// First response goes out immediately
+	start := time.Now()
+	b.waitForResponseSlot()
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("Expected first response to be immediate, waited %v", elapsed)
+	}
+
+	// Second response 10ms later should be held for the remaining ~90ms
+	time.Sleep(10 * time.Millisecond)
+	start = time.Now()
+	b.waitForResponseSlot()
+	elapsed := time.Since(start)
+	if elapsed < 80*time.Millisecond || elapsed > 150*time.Millisecond {
+		t.Errorf("Expected second response to be delayed ~90ms, waited %v", elapsed)
+	}
+
+	// Disabling the throttle lets responses through immediately
+	b.SetResponseThrottle(0)
+	start = time.Now()
+	b.waitForResponseSlot()
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("Expected no delay with throttle off, waited %v", elapsed)
+	}
+}
+
+func TestPacedResponsesDontBlock(t *testing.T) {
+	b := &Bot{}
+	b.SetResponseThrottle(100 * time.Millisecond)
+
+	// Throttled sends are scheduled without blocking the caller
+	sent := make(chan int, 3)
+	start := time.Now()
+	for i := 1; i <= 3; i++ {
+		i := i
+		b.paced(func() { sent <- i })
+	}
+	b.GetResponseThrottle()
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("Expected scheduling to return immediately, took %v", elapsed)
+	}
+
+	// They still go out in order, one throttle interval apart
+	for want := 1; want <= 3; want++ {
+		select {
+		case got := <-sent:
+			if got != want {
+				t.Errorf("Expected send %d next, got %d", want, got)
+			}
+			if elapsed, earliest := time.Since(start), time.Duration(want-1)*90*time.Millisecond; elapsed < earliest {
+				t.Errorf("Expected send %d after at least %v, sent after %v", want, earliest, elapsed)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for send %d", want)
+		}
+	}
+}
+
+func TestDedupeResponse(t *testing.T) {
+	b := &Bot{}
+
+	// Different messages go out unchanged
+	if msg := b.dedupeResponse("testchannel", "Queue is empty."); msg != "Queue is empty." {
+		t.Errorf("Expected the first message unchanged, got %q", msg)
+	}
+	if msg := b.dedupeResponse("testchannel", "Queue: user1 (1 total)"); msg != "Queue: user1 (1 total)" {
+		t.Errorf("Expected a different message unchanged, got %q", msg)
+	}
+
+	// Repeats get a counter that keeps incrementing
+	if msg := b.dedupeResponse("testchannel", "Queue: user1 (1 total)"); msg != "Queue: user1 (1 total) (2)" {
+		t.Errorf("Expected a (2) suffix on the repeat, got %q", msg)
+	}
+	if msg := b.dedupeResponse("TestChannel", "Queue: user1 (1 total)"); msg != "Queue: user1 (1 total) (3)" {
+		t.Errorf("Expected a (3) suffix on the third send, got %q", msg)
+	}
+
+	// Other channels are tracked separately
+	if msg := b.dedupeResponse("relaychannel", "Queue: user1 (1 total)"); msg != "Queue: user1 (1 total)" {
+		t.Errorf("Expected no suffix in another channel, got %q", msg)
+	}
+}
+
+func TestDedupeResponseWindow(t *testing.T) {
+	originalWindow := duplicateWindow
+	duplicateWindow = 50 * time.Millisecond
+	defer func() { duplicateWindow = originalWindow }()
+
+	b := &Bot{}
+	b.dedupeResponse("testchannel", "pong")
+	if msg := b.dedupeResponse("testchannel", "pong"); msg != "pong (2)" {
+		t.Errorf("Expected a suffix inside the window, got %q", msg)
+	}
+
+	// Once the window passes the message is no longer a duplicate
+	time.Sleep(60 * time.Millisecond)
+	if msg := b.dedupeResponse("testchannel", "pong"); msg != "pong" {
+		t.Errorf("Expected no suffix after the window, got %q", msg)
+	}
+}
+
+func TestOutgoingMessagesPrefix(t *testing.T) {
+	// Without a prefix responses go out unchanged
+	b := &Bot{cfg: &config.Config{}}
+	if parts := b.outgoingMessages("testchannel", "user1 joined queue at position 1 (1 total)"); len(parts) != 1 || parts[0] != "user1 joined queue at position 1 (1 total)" {
+		t.Errorf("Expected the response unchanged, got %q", parts)
+	}
+
+	b = &Bot{cfg: &config.Config{ResponsePrefix: "[PerfTilt]"}}
+	if parts := b.outgoingMessages("testchannel", "Queue is empty."); len(parts) != 1 || parts[0] != "[PerfTilt] Queue is empty." {
+		t.Errorf("Expected the prefixed response, got %q", parts)
+	}
+
+	// The prefix counts toward the length limit, so a response that fits on
+	// its own is split once the prefix is added
+	long := strings.Repeat("word ", 99) + "end"
+	if len(long) != 498 {
+		t.Fatalf("Test message should be 498 characters, got %d", len(long))
+	}
+	parts := b.outgoingMessages("testchannel", long)
+	if len(parts) != 2 {
+		t.Fatalf("Expected the response to be split in two, got %d parts", len(parts))
+	}
+	for _, part := range parts {
+		if !strings.HasPrefix(part, "[PerfTilt] ") || len(part) > maxChatMessageLength {
+			t.Errorf("Expected a prefixed part within the limit, got %d chars: %q", len(part), part)
+		}
+	}
+	if joined := strings.TrimPrefix(parts[0], "[PerfTilt] ") + " " + strings.TrimPrefix(parts[1], "[PerfTilt] "); joined != long {
+		t.Error("Expected the parts to rejoin into the original response at a word break")
+	}
+}
+
+func TestVerifyIdentityMismatch(t *testing.T) {
+	b := &Bot{botUsername: "PerfTiltBot"}
+	var reported error
+	b.SetOnIdentityMismatch(func(err error) { reported = err })
+
+	handled := false
+	b.RegisterCommandHandler(func(ctx context.Context, message twitch.PrivateMessage) string {
+		handled = true
+		return ""
+	})
+
+	// A userstate for a different account is rejected
+	err := b.verifyIdentity("someoneelse")
+	if !errors.Is(err, ErrBotIdentityMismatch) {
+		t.Fatalf("Expected ErrBotIdentityMismatch, got %v", err)
+	}
+	if !errors.Is(reported, ErrBotIdentityMismatch) {
+		t.Errorf("Expected the mismatch hook to be called, got %v", reported)
+	}
+	if !b.IdentityRejected() {
+		t.Error("Expected the bot to be marked as rejected")
+	}
+
+	// The bot refuses to handle chat afterwards
+	b.handlePrivateMessage(twitch.PrivateMessage{Message: "!ping"})
+	if handled {
+		t.Error("Expected no command handling after an identity mismatch")
+	}
+}
+
+func TestSlowCommandHandlersDontBlockChat(t *testing.T) {
+	am := NewAuthManager("client_id", "client_secret", "refresh_token", "")
+	am.AccessToken = "token"
+	am.ExpiresAt = time.Now().Add(time.Hour)
+	b := &Bot{
+		channel:      "testchannel",
+		authManager:  am,
+		channelStats: channelstats.NewChannelStats(t.TempDir()),
+		commandQueue: make(chan chatCommand, commandQueueSize),
+	}
+	release := make(chan struct{})
+	handled := make(chan string, 2)
+	b.RegisterCommandHandler(func(ctx context.Context, message twitch.PrivateMessage) string {
+		<-release // e.g. a Helix lookup that takes a while
+		handled <- message.Message
+		return ""
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.runCommands(ctx, b.commandQueue)
+
+	// The IRC reader hands messages off without waiting for the handlers
+	start := time.Now()
+	b.handlePrivateMessage(twitch.PrivateMessage{Channel: "testchannel", Message: "!followage"})
+	b.handlePrivateMessage(twitch.PrivateMessage{Channel: "testchannel", Message: "!join"})
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Expected chat handling not to wait for command handlers, took %v", elapsed)
+	}
+
+	// The handlers still see the messages in order
+	close(release)
+	for _, expected := range []string{"!followage", "!join"} {
+		select {
+		case got := <-handled:
+			if got != expected {
+				t.Errorf("Expected %s to be handled next, got %s", expected, got)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timed out waiting for %s to be handled", expected)
+		}
+	}
+}
+
+func TestVerifyIdentityMatch(t *testing.T) {
+	b := &Bot{botUsername: "PerfTiltBot"}
+	b.SetOnIdentityMismatch(func(err error) {
+		t.Errorf("Unexpected mismatch: %v", err)
+	})
+
+	if err := b.verifyIdentity("perftiltbot"); err != nil {
+		t.Errorf("Expected a case-insensitive match, got %v", err)
+	}
+	if b.IdentityRejected() {
+		t.Error("Expected the bot not to be rejected")
+	}
+}
+
+func TestConnStatus(t *testing.T) {
+	b := &Bot{channel: "HomeChannel", joined: map[string]bool{"relayb": true, "relaya": true}}
+
+	// Before connecting every channel is down with no messages
+	for _, status := range b.ConnStatus() {
+		if status.Connected || status.Reconnecting() || !status.LastMessage.IsZero() {
+			t.Errorf("Expected %s to be disconnected with no messages, got %+v", status.Channel, status)
+		}
+	}
+
+	b.reconnectAttempts.Store(2)
+	if status := b.ConnStatus()[0]; !status.Reconnecting() || status.ReconnectAttempts != 2 {
+		t.Errorf("Expected reconnecting on attempt 2, got %+v", status)
+	}
+
+	seen := time.Now()
+	b.connected.Store(true)
+	b.reconnectAttempts.Store(0)
+	b.recordMessageTime("HomeChannel", seen)
+
+	statuses := b.ConnStatus()
+	var channels []string
+	for _, status := range statuses {
+		channels = append(channels, status.Channel)
+		if !status.Connected {
+			t.Errorf("Expected %s to be connected", status.Channel)
+		}
+	}
+	if len(channels) != 3 || channels[0] != "homechannel" || channels[1] != "relaya" || channels[2] != "relayb" {
+		t.Errorf("Expected own channel first, then relays alphabetically, got %v", channels)
+	}
+	if !statuses[0].LastMessage.Equal(seen) || !statuses[1].LastMessage.IsZero() {
+		t.Errorf("Expected only the home channel to have a last message, got %+v", statuses)
+	}
+}
+
+func TestRelayTargetChatIgnored(t *testing.T) {
+	am := NewAuthManager("client_id", "client_secret", "refresh_token", "")
+	am.AccessToken = "token"
+	am.ExpiresAt = time.Now().Add(time.Hour)
+	b := &Bot{
+		channel:      "HomeChannel",
+		authManager:  am,
+		cfg:          &config.Config{},
+		channelStats: channelstats.NewChannelStats(t.TempDir()),
+		joined:       map[string]bool{"relaychannel": true},
+	}
+	b.channelStats.StartSession("", "", 0)
+	var handled []string
+	b.RegisterCommandHandler(func(ctx context.Context, message twitch.PrivateMessage) string {
+		handled = append(handled, message.Channel)
+		return ""
+	})
+
+	b.handlePrivateMessage(twitch.PrivateMessage{Channel: "relaychannel", User: twitch.User{Name: "stranger"}, Message: "!join"})
+	b.handlePrivateMessage(twitch.PrivateMessage{Channel: "homechannel", User: twitch.User{Name: "viewer"}, Message: "hi"})
+
+	if len(handled) != 1 || handled[0] != "homechannel" {
+		t.Errorf("Expected only the home channel's message to be handled, got %v", handled)
+	}
+	if messages, chatters, _ := b.channelStats.GetCurrentSessionChatStats(); messages != 1 || chatters != 1 {
+		t.Errorf("Expected only the home channel's message in stats, got %d messages from %d chatters", messages, chatters)
+	}
+	// The relay channel still shows as alive in !connstatus
+	if statuses := b.ConnStatus(); statuses[1].LastMessage.IsZero() {
+		t.Error("Expected the relay channel's last message time to be recorded")
+	}
+
+	b.Depart("RelayChannel")
+	if len(b.ConnStatus()) != 1 {
+		t.Errorf("Expected the relay channel to be dropped after leaving, got %+v", b.ConnStatus())
+	}
+}
+
+func TestRoomModes(t *testing.T) {
+	b := &Bot{channel: "HomeChannel"}
+
+	if _, ok := b.RoomModes(); ok {
+		t.Fatal("Expected no room state before Twitch sends one")
+	}
+
+	// Full state on join
+	b.handleRoomState(twitch.RoomStateMessage{
+		Channel: "homechannel",
+		State:   map[string]int{"emote-only": 0, "followers-only": 10, "r9k": 0, "slow": 30, "subs-only": 0},
+	})
+	modes, ok := b.RoomModes()
+	if !ok {
+		t.Fatal("Expected room state after the join update")
+	}
+	expected := RoomModes{FollowersOnly: 10, Slow: 30}
+	if modes != expected {
+		t.Errorf("Expected %+v, got %+v", expected, modes)
+	}
+
+	// Partial update only changes the modes it carries
+	b.handleRoomState(twitch.RoomStateMessage{Channel: "homechannel", State: map[string]int{"subs-only": 1, "followers-only": -1}})
+	modes, _ = b.RoomModes()
+	expected = RoomModes{FollowersOnly: -1, Slow: 30, SubsOnly: true}
+	if modes != expected {
+		t.Errorf("Expected %+v, got %+v", expected, modes)
+	}
+
+	// Other channels' room state is kept separately
+	b.handleRoomState(twitch.RoomStateMessage{Channel: "relaychannel", State: map[string]int{"emote-only": 1}})
+	if modes, _ = b.RoomModes(); modes.EmoteOnly {
+		t.Error("Expected another channel's emote-only mode not to apply to the bot's channel")
+	}
+}
+
+// startAuthIRCServer starts an IRC server that rejects every login except
+// those using goodToken. It reports the PASS token of each connection.
+func startAuthIRCServer(t *testing.T, goodToken string) (string, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start IRC server: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	logins := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				var token string
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					line = strings.TrimSpace(line)
+					switch {
+					case strings.HasPrefix(line, "PASS "):
+						token = strings.TrimPrefix(line, "PASS oauth:")
+					case strings.HasPrefix(line, "NICK "):
+						logins <- token
+						if token != goodToken {
+							fmt.Fprint(conn, ":tmi.twitch.tv NOTICE * :Login authentication failed\r\n")
+							return
+						}
+						fmt.Fprint(conn, ":tmi.twitch.tv 001 testbot :Welcome, GLHF!\r\n")
+					}
+				}
+			}(conn)
+		}
+	}()
+	return listener.Addr().String(), logins
+}
+
+// newAuthTestBot returns a bot connecting to the IRC server at address with
+// a valid-looking token that Twitch will reject, whose refreshes are
+// answered by tokenServer
+func newAuthTestBot(t *testing.T, address string, tokenServer *httptest.Server) *Bot {
+	originalTokenURL := tokenURL
+	tokenURL = tokenServer.URL
+	t.Cleanup(func() { tokenURL = originalTokenURL })
+
+	am := NewAuthManager("client_id", "client_secret", "refresh_token", "")
+	am.AccessToken = "revoked_token"
+	am.ExpiresAt = time.Now().Add(time.Hour)
+	return &Bot{
+		channel:          "testchannel",
+		authManager:      am,
+		botUsername:      "testbot",
+		cfg:              &config.Config{},
+		channelStats:     channelstats.NewChannelStats(t.TempDir()),
+		reconnectDelay:   10 * time.Millisecond,
+		idlePingInterval: defaultIdlePingInterval,
+		pongTimeout:      defaultPongTimeout,
+		ircAddress:       address,
+	}
+}
+
+// stopBot cancels the bot's context and disconnects it, waiting for the
+// goroutines Connect started so none outlive the test. Disconnect is retried
+// because it does nothing while the client is between connections.
+func stopBot(t *testing.T, b *Bot, cancel context.CancelFunc) {
+	cancel()
+	stopped := make(chan struct{})
+	go func() {
+		b.loops.Wait()
+		close(stopped)
+	}()
+
+	deadline := time.After(3 * time.Second)
+	for {
+		b.client.Disconnect()
+		select {
+		case <-stopped:
+			return
+		case <-deadline:
+			t.Fatal("Timed out waiting for the bot to stop")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// nextLogin returns the token used by the next login attempt
+func nextLogin(t *testing.T, logins chan string) string {
+	select {
+	case token := <-logins:
+		return token
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timed out waiting for a login attempt")
+		return ""
+	}
+}
+
+func TestAuthFailureRefreshesToken(t *testing.T) {
+	address, logins := startAuthIRCServer(t, "fresh_token")
+
+	refreshes := make(chan struct{}, 10)
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshes <- struct{}{}
+		json.NewEncoder(w).Encode(TokenResponse{AccessToken: "fresh_token", RefreshToken: "new_refresh", ExpiresIn: 3600})
+	}))
+	defer tokenServer.Close()
+
+	b := newAuthTestBot(t, address, tokenServer)
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := b.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer stopBot(t, b, cancel)
+
+	// The rejected login triggers a refresh and a retry with the new token
+	if token := nextLogin(t, logins); token != "revoked_token" {
+		t.Errorf("Expected first login with the revoked token, got %q", token)
+	}
+	select {
+	case <-refreshes:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Expected a token refresh after the auth failure notice")
+	}
+	if token := nextLogin(t, logins); token != "fresh_token" {
+		t.Errorf("Expected reconnect with the refreshed token, got %q", token)
+	}
+}
+
+func TestAuthFailureStopsWhenRefreshFails(t *testing.T) {
+	address, logins := startAuthIRCServer(t, "fresh_token")
+
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Invalid refresh token"}`, http.StatusBadRequest)
+	}))
+	defer tokenServer.Close()
+
+	b := newAuthTestBot(t, address, tokenServer)
+	failures := make(chan error, 1)
+	b.SetOnAuthFailure(func(err error) { failures <- err })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := b.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer stopBot(t, b, cancel)
+
+	nextLogin(t, logins)
+	select {
+	case err := <-failures:
+		if !errors.Is(err, ErrAuthenticationFailed) || !strings.Contains(err.Error(), "400") {
+			t.Errorf("Expected an auth failure error with the refresh status, got %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Expected the auth failure hook to be called")
+	}
+
+	// No retry with the rejected token
+	select {
+	case token := <-logins:
+		t.Errorf("Expected the bot to stop, but it logged in again with %q", token)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestAuthFailureGivesUpAfterRepeatedRefreshes(t *testing.T) {
+	// Every token is rejected, as when it's for the wrong account
+	address, logins := startAuthIRCServer(t, "never_accepted")
+
+	refreshes := make(chan struct{}, 10)
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshes <- struct{}{}
+		json.NewEncoder(w).Encode(TokenResponse{AccessToken: "fresh_token", RefreshToken: "new_refresh", ExpiresIn: 3600})
+	}))
+	defer tokenServer.Close()
+
+	b := newAuthTestBot(t, address, tokenServer)
+	b.reconnectDelay = 50 * time.Millisecond
+	failures := make(chan error, 1)
+	b.SetOnAuthFailure(func(err error) { failures <- err })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	start := time.Now()
+	if err := b.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer stopBot(t, b, cancel)
+
+	select {
+	case err := <-failures:
+		if !errors.Is(err, ErrAuthenticationFailed) {
+			t.Errorf("Expected ErrAuthenticationFailed, got %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Expected the bot to give up after repeated rejections")
+	}
+
+	// Each retry waited out the reconnect delay, and the bot stopped
+	// refreshing once the limit was reached
+	if elapsed := time.Since(start); elapsed < maxAuthRefreshes*b.reconnectDelay {
+		t.Errorf("Expected a delay before each retry, gave up after %v", elapsed)
+	}
+	if len(refreshes) != maxAuthRefreshes || len(logins) != maxAuthRefreshes+1 {
+		t.Errorf("Expected %d refreshes and %d logins, got %d and %d", maxAuthRefreshes, maxAuthRefreshes+1, len(refreshes), len(logins))
+	}
+}
+
+func TestHandleNoticeFlagsAuthFailure(t *testing.T) {
+	b := &Bot{}
+	b.handleNotice(twitch.NoticeMessage{Channel: "testchannel", Message: "This room is in slow mode."})
+	if b.authFailed.Load() {
+		t.Error("Expected ordinary notices to be ignored")
+	}
+	b.handleNotice(twitch.NoticeMessage{Channel: "*", Message: "Login authentication failed"})
+	if !b.authFailed.Load() {
+		t.Error("Expected the auth failure notice to be flagged")
+	}
+}
+
+// startFlappingIRCServer starts an IRC server that welcomes every login and
+// then drops the connection. It reports each login.
+func startFlappingIRCServer(t *testing.T) (string, chan struct{}) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start IRC server: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	logins := make(chan struct{}, 100)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if strings.HasPrefix(line, "NICK ") {
+						fmt.Fprint(conn, ":tmi.twitch.tv 001 testbot :Welcome, GLHF!\r\n")
+						logins <- struct{}{}
+						time.Sleep(10 * time.Millisecond)
+						return
+					}
+				}
+			}(conn)
+		}
+	}()
+	return listener.Addr().String(), logins
+}
+
+func TestReconnectCounter(t *testing.T) {
+	address, logins := startFlappingIRCServer(t)
+
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(TokenResponse{AccessToken: "token", RefreshToken: "refresh", ExpiresIn: 3600})
+	}))
+	defer tokenServer.Close()
+
+	b := newAuthTestBot(t, address, tokenServer)
+	if stats := b.ReconnectStats()["testchannel"]; stats.Reconnects != 0 || !stats.LastReconnect.IsZero() {
+		t.Fatalf("Expected no reconnects before connecting, got %+v", stats)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	start := time.Now()
+	if err := b.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer stopBot(t, b, cancel)
+
+	// Every connection after the first is counted as a reconnect
+	for i := 0; i < 5; i++ {
+		select {
+		case <-logins:
+		case <-time.After(3 * time.Second):
+			t.Fatalf("Timed out waiting for login %d", i+1)
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	stats := b.ReconnectStats()["testchannel"]
+	if stats.Reconnects < 3 {
+		t.Errorf("Expected at least 3 reconnects, got %d", stats.Reconnects)
+	}
+	if stats.LastReconnect.Before(start) {
+		t.Errorf("Expected the last reconnect time to be set, got %v", stats.LastReconnect)
+	}
+
+	// Unlike the attempt count, the counter isn't reset by a successful connect
+	if b.reconnectAttempts.Load() != 0 || stats.Reconnects == 0 {
+		t.Errorf("Expected attempts reset but reconnects kept, got %d attempts and %d reconnects", b.reconnectAttempts.Load(), stats.Reconnects)
+	}
+}
+
+// startSilentIRCServer starts an IRC server that welcomes every login and
+// then never answers. It reports each login and each PING it receives.
+func startSilentIRCServer(t *testing.T) (string, chan struct{}, chan string) {
+	return startPingIRCServer(t, 0, false)
+}
+
+// startPingIRCServer is startSilentIRCServer, except that PINGs are answered
+// with a PONG after pongDelay when answer is set
+func startPingIRCServer(t *testing.T, pongDelay time.Duration, answer bool) (string, chan struct{}, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start IRC server: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	logins := make(chan struct{}, 100)
+	pings := make(chan string, 100)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					line = strings.TrimSpace(line)
+					switch {
+					case strings.HasPrefix(line, "NICK "):
+						fmt.Fprint(conn, ":tmi.twitch.tv 001 testbot :Welcome, GLHF!\r\n")
+						logins <- struct{}{}
+					case strings.HasPrefix(line, "PING "):
+						pings <- line
+						if answer {
+							time.Sleep(pongDelay)
+							fmt.Fprintf(conn, ":tmi.twitch.tv PONG tmi.twitch.tv %s\r\n", strings.TrimPrefix(line, "PING "))
+						}
+					}
+				}
+			}(conn)
+		}
+	}()
+	return listener.Addr().String(), logins, pings
+}
+
+func TestIdlePingReconnectsWithoutPong(t *testing.T) {
+	address, logins, pings := startSilentIRCServer(t)
+
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(TokenResponse{AccessToken: "token", RefreshToken: "refresh", ExpiresIn: 3600})
+	}))
+	defer tokenServer.Close()
+
+	b := newAuthTestBot(t, address, tokenServer)
+	b.idlePingInterval, b.pongTimeout = 100*time.Millisecond, 50*time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := b.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer stopBot(t, b, cancel)
+
+	select {
+	case <-logins:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timed out waiting for the first login")
+	}
+	connected := time.Now()
+
+	// The idle connection gets a PING once the interval passes
+	select {
+	case <-pings:
+		if idle := time.Since(connected); idle < 80*time.Millisecond {
+			t.Errorf("Expected the PING after the idle interval, sent after %v", idle)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timed out waiting for a PING")
+	}
+
+	// With no PONG the client reconnects
+	select {
+	case <-logins:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Expected a reconnect after the PONG timeout")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if reconnects := b.ReconnectStats()["testchannel"].Reconnects; reconnects < 1 {
+		t.Errorf("Expected the reconnect to be counted, got %d", reconnects)
+	}
+}
+
+func TestLatencyMeasuredFromPong(t *testing.T) {
+	address, logins, pings := startPingIRCServer(t, 30*time.Millisecond, true)
+
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(TokenResponse{AccessToken: "token", RefreshToken: "refresh", ExpiresIn: 3600})
+	}))
+	defer tokenServer.Close()
+
+	b := newAuthTestBot(t, address, tokenServer)
+	b.idlePingInterval, b.pongTimeout = 100*time.Millisecond, time.Second
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := b.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer stopBot(t, b, cancel)
+
+	select {
+	case <-logins:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timed out waiting for the login")
+	}
+	if latency := b.Latency(); latency != 0 {
+		t.Errorf("Expected no latency before the first PONG, got %v", latency)
+	}
+	select {
+	case <-pings:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timed out waiting for a PING")
+	}
+
+	// The PONG's delay shows up as the round-trip time
+	deadline := time.Now().Add(2 * time.Second)
+	for b.Latency() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if latency := b.Latency(); latency < 30*time.Millisecond || latency > time.Second {
+		t.Errorf("Expected an RTT of at least the 30ms PONG delay, got %v", latency)
+	}
+
+	// An answered PING doesn't make the client reconnect
+	select {
+	case <-logins:
+		t.Error("Expected the connection to stay up after the PONG")
+	case <-time.After(150 * time.Millisecond):
+	}
+}
+
+func TestConnectLoopRecoversFromPanic(t *testing.T) {
+	// The first connection panics like a repeated read on a failed
+	// websocket; the second succeeds
+	calls := 0
+	connect := func() error {
+		calls++
+		if calls == 1 {
+			panic("repeated read on failed websocket connection")
+		}
+		return nil
+	}
+
+	b := &Bot{reconnectDelay: 10 * time.Millisecond}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.connectLoop(context.Background(), connect)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the connect loop to reconnect")
+	}
+	if calls != 2 {
+		t.Errorf("Expected a reconnect after the panic, got %d connection attempts", calls)
+	}
+	if attempts := b.reconnectAttempts.Load(); attempts != 1 {
+		t.Errorf("Expected the panic to count as 1 failed attempt, got %d", attempts)
+	}
+}
+
+func TestConnectRecoveredReturnsPanicAsError(t *testing.T) {
+	err := connectRecovered(func() error { panic("boom") })
+	if !errors.Is(err, ErrClientPanic) || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Expected the panic to be returned as ErrClientPanic, got %v", err)
+	}
+
+	want := errors.New("connection refused")
+	if err := connectRecovered(func() error { return want }); err != want {
+		t.Errorf("Expected errors to pass through unchanged, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package twitch
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ChannelStatus describes the bot's connection to one channel
+type ChannelStatus struct {
+	Channel   string
+	Connected bool
+	// Failed connection attempts since the last successful connect
+	ReconnectAttempts int
+	// When the last chat message was seen (zero if none has been)
+	LastMessage time.Time
+}
+
+// Reconnecting reports whether the bot is retrying a failed connection
+func (s ChannelStatus) Reconnecting() bool {
+	return !s.Connected && s.ReconnectAttempts > 0
+}
+
+// ReconnectStats counts the bot's reconnects to Twitch IRC since startup.
+// Reconnects made by the IRC client after a dropped connection and by the
+// bot's retry loop after a failed one are both counted.
+type ReconnectStats struct {
+	Reconnects int64 `json:"reconnects"`
+	// When the bot last reconnected (zero if it never has)
+	LastReconnect time.Time `json:"last_reconnect"`
+}
+
+// recordReconnect counts a connection re-established after the first
+func (b *Bot) recordReconnect() {
+	count := b.reconnects.Add(1)
+	b.lastReconnect.Store(time.Now().UnixNano())
+	log.Printf("[Connection] Reconnected to %s (reconnect #%d)", b.channel, count)
+}
+
+// ReconnectStats returns the reconnect counters keyed by the bot's channel,
+// for the metrics endpoint
+func (b *Bot) ReconnectStats() map[string]ReconnectStats {
+	stats := ReconnectStats{Reconnects: b.reconnects.Load()}
+	if last := b.lastReconnect.Load(); last != 0 {
+		stats.LastReconnect = time.Unix(0, last)
+	}
+	return map[string]ReconnectStats{strings.ToLower(b.channel): stats}
+}
+
+// recordMessageTime notes that a chat message was seen in channel
+func (b *Bot) recordMessageTime(channel string, at time.Time) {
+	b.lastMessageMu.Lock()
+	defer b.lastMessageMu.Unlock()
+	if b.lastMessageTime == nil {
+		b.lastMessageTime = make(map[string]time.Time)
+	}
+	b.lastMessageTime[strings.ToLower(channel)] = at
+}
+
+// ConnStatus returns the connection state of the bot's own channel followed
+// by any channels joined for relaying, in alphabetical order. All channels
+// share one IRC connection, so they report the same connected state and
+// reconnect attempts.
+func (b *Bot) ConnStatus() []ChannelStatus {
+	channels := []string{strings.ToLower(b.channel)}
+	b.joinedMu.Lock()
+	var joined []string
+	for channel := range b.joined {
+		joined = append(joined, channel)
+	}
+	b.joinedMu.Unlock()
+	sort.Strings(joined)
+	channels = append(channels, joined...)
+
+	b.lastMessageMu.Lock()
+	defer b.lastMessageMu.Unlock()
+	statuses := make([]ChannelStatus, len(channels))
+	for i, channel := range channels {
+		statuses[i] = ChannelStatus{
+			Channel:           channel,
+			Connected:         b.connected.Load(),
+			ReconnectAttempts: int(b.reconnectAttempts.Load()),
+			LastMessage:       b.lastMessageTime[channel],
+		}
+	}
+	return statuses
+}
@@ -0,0 +1,223 @@
+package twitch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// helixURL is the base endpoint for Twitch Helix API calls
+var helixURL = "https://api.twitch.tv/helix"
+
+// helixTimeout bounds every Helix request so a slow API can't stall command handling
+const helixTimeout = 10 * time.Second
+
+// TwitchAPIClient makes authenticated calls to the Twitch Helix API
+type TwitchAPIClient struct {
+	clientID    string
+	authManager *AuthManager
+	httpClient  *http.Client
+}
+
+// HelixError is returned when a Helix call responds with a non-success status
+type HelixError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error implements the error interface
+func (e *HelixError) Error() string {
+	return fmt.Sprintf("helix request failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+// Whisper failures callers may want to handle specially
+var (
+	// ErrWhisperDisabled means the recipient doesn't accept whispers from the bot
+	ErrWhisperDisabled = errors.New("whisper_disabled_by_user")
+	// ErrMissingScopes means the token lacks the user:manage:whispers scope
+	ErrMissingScopes = errors.New("missing_scopes")
+)
+
+// NewTwitchAPIClient creates a new Helix API client using the auth manager's tokens
+func NewTwitchAPIClient(authManager *AuthManager) *TwitchAPIClient {
+	return &TwitchAPIClient{
+		clientID:    authManager.ClientID,
+		authManager: authManager,
+		httpClient:  &http.Client{Timeout: helixTimeout},
+	}
+}
+
+// doHelixRequest sends a Helix request and decodes a JSON response into out
+// (if non-nil). Every Helix call goes through here so they all honor ctx
+// and the client timeout.
+func (c *TwitchAPIClient) doHelixRequest(ctx context.Context, method, path string, query url.Values, body interface{}, out interface{}) error {
+	token, err := c.authManager.GetAccessTokenContext(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting access token: %w", err)
+	}
+
+	endpoint := helixURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("error encoding request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Client-Id", c.clientID)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(resp.Body)
+		var helixErr struct {
+			Message string `json:"message"`
+		}
+		message := string(respBody)
+		if json.Unmarshal(respBody, &helixErr) == nil && helixErr.Message != "" {
+			message = helixErr.Message
+		}
+		return &HelixError{StatusCode: resp.StatusCode, Message: message}
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("error decoding response: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetUserID looks up a user's ID by login name
+func (c *TwitchAPIClient) GetUserID(ctx context.Context, login string) (string, error) {
+	var resp struct {
+		Data []struct {
+			ID    string `json:"id"`
+			Login string `json:"login"`
+		} `json:"data"`
+	}
+	if err := c.doHelixRequest(ctx, "GET", "/users", url.Values{"login": {login}}, nil, &resp); err != nil {
+		return "", err
+	}
+	if len(resp.Data) == 0 {
+		return "", fmt.Errorf("user %s not found", login)
+	}
+	return resp.Data[0].ID, nil
+}
+
+// GetUserCreatedAt looks up when a user's account was created
+func (c *TwitchAPIClient) GetUserCreatedAt(ctx context.Context, login string) (time.Time, error) {
+	var resp struct {
+		Data []struct {
+			Login     string    `json:"login"`
+			CreatedAt time.Time `json:"created_at"`
+		} `json:"data"`
+	}
+	if err := c.doHelixRequest(ctx, "GET", "/users", url.Values{"login": {login}}, nil, &resp); err != nil {
+		return time.Time{}, err
+	}
+	if len(resp.Data) == 0 {
+		return time.Time{}, fmt.Errorf("user %s not found", login)
+	}
+	return resp.Data[0].CreatedAt, nil
+}
+
+// SendAnnouncement posts a highlighted announcement to the broadcaster's chat.
+// color is one of "blue", "green", "orange", "purple" or "primary" ("" uses primary).
+func (c *TwitchAPIClient) SendAnnouncement(ctx context.Context, broadcasterID, moderatorID, message, color string) error {
+	query := url.Values{
+		"broadcaster_id": {broadcasterID},
+		"moderator_id":   {moderatorID},
+	}
+	body := map[string]string{"message": message}
+	if color != "" {
+		body["color"] = color
+	}
+	return c.doHelixRequest(ctx, "POST", "/chat/announcements", query, body, nil)
+}
+
+// GetFollowedAt returns when userID followed broadcasterID. The bool is false
+// when the user doesn't follow the channel. Requires moderator:read:followers.
+func (c *TwitchAPIClient) GetFollowedAt(ctx context.Context, broadcasterID, userID string) (time.Time, bool, error) {
+	var resp struct {
+		Data []struct {
+			UserID     string    `json:"user_id"`
+			FollowedAt time.Time `json:"followed_at"`
+		} `json:"data"`
+	}
+	query := url.Values{"broadcaster_id": {broadcasterID}, "user_id": {userID}}
+	if err := c.doHelixRequest(ctx, "GET", "/channels/followers", query, nil, &resp); err != nil {
+		return time.Time{}, false, err
+	}
+	if len(resp.Data) == 0 {
+		return time.Time{}, false, nil
+	}
+	return resp.Data[0].FollowedAt, true, nil
+}
+
+// SendWhisper sends a whisper from one user to another. The sender must be
+// the token's user and needs the user:manage:whispers scope.
+func (c *TwitchAPIClient) SendWhisper(ctx context.Context, fromUserID, toUserID, message string) error {
+	query := url.Values{"from_user_id": {fromUserID}, "to_user_id": {toUserID}}
+	body := map[string]string{"message": message}
+
+	err := c.doHelixRequest(ctx, "POST", "/whispers", query, body, nil)
+	var helixErr *HelixError
+	if errors.As(err, &helixErr) {
+		switch {
+		case helixErr.StatusCode == http.StatusUnauthorized && strings.Contains(strings.ToLower(helixErr.Message), "scope"):
+			return fmt.Errorf("%w: %s", ErrMissingScopes, helixErr.Message)
+		case helixErr.StatusCode == http.StatusForbidden:
+			return fmt.Errorf("%w: %s", ErrWhisperDisabled, helixErr.Message)
+		}
+	}
+	return err
+}
+
+// StreamInfo describes a channel's live stream
+type StreamInfo struct {
+	GameName    string    `json:"game_name"`
+	Title       string    `json:"title"`
+	ViewerCount int       `json:"viewer_count"`
+	StartedAt   time.Time `json:"started_at"`
+}
+
+// GetStream looks up a channel's live stream by login name. The bool is
+// false when the channel is offline.
+func (c *TwitchAPIClient) GetStream(ctx context.Context, login string) (StreamInfo, bool, error) {
+	var resp struct {
+		Data []StreamInfo `json:"data"`
+	}
+	if err := c.doHelixRequest(ctx, "GET", "/streams", url.Values{"user_login": {login}}, nil, &resp); err != nil {
+		return StreamInfo{}, false, err
+	}
+	if len(resp.Data) == 0 {
+		return StreamInfo{}, false, nil
+	}
+	return resp.Data[0], true, nil
+}
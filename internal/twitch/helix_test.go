@@ -0,0 +1,269 @@
+package twitch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestAPIClient creates an API client with a valid token pointed at server
+func newTestAPIClient(t *testing.T, server *httptest.Server) *TwitchAPIClient {
+	am := NewAuthManager("test_client_id", "test_client_secret", "test_refresh_token", "")
+	am.AccessToken = "test_access_token"
+	am.ExpiresAt = time.Now().Add(time.Hour)
+
+	originalHelixURL := helixURL
+	helixURL = server.URL
+	t.Cleanup(func() { helixURL = originalHelixURL })
+
+	return NewTwitchAPIClient(am)
+}
+
+func TestSendAnnouncement(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/chat/announcements" {
+			t.Errorf("Expected POST /chat/announcements, got %s %s", r.Method, r.URL.Path)
+		}
+		if r.URL.Query().Get("broadcaster_id") != "123" || r.URL.Query().Get("moderator_id") != "456" {
+			t.Errorf("Unexpected query: %s", r.URL.RawQuery)
+		}
+		if r.Header.Get("Authorization") != "Bearer test_access_token" {
+			t.Errorf("Unexpected Authorization header: %s", r.Header.Get("Authorization"))
+		}
+		if r.Header.Get("Client-Id") != "test_client_id" {
+			t.Errorf("Unexpected Client-Id header: %s", r.Header.Get("Client-Id"))
+		}
+
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode request body: %v", err)
+		}
+		if body["message"] != "The queue is full!" || body["color"] != "purple" {
+			t.Errorf("Unexpected body: %v", body)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := newTestAPIClient(t, server)
+	if err := client.SendAnnouncement(context.Background(), "123", "456", "The queue is full!", "purple"); err != nil {
+		t.Errorf("Expected announcement to succeed, got %v", err)
+	}
+}
+
+func TestSendAnnouncementRateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":"Too Many Requests","status":429,"message":"rate limit exceeded"}`))
+	}))
+	defer server.Close()
+
+	client := newTestAPIClient(t, server)
+	err := client.SendAnnouncement(context.Background(), "123", "456", "The queue is full!", "")
+	if err == nil {
+		t.Fatal("Expected rate-limit error")
+	}
+
+	var helixErr *HelixError
+	if !errors.As(err, &helixErr) {
+		t.Fatalf("Expected HelixError, got %T: %v", err, err)
+	}
+	if helixErr.StatusCode != http.StatusTooManyRequests || helixErr.Message != "rate limit exceeded" {
+		t.Errorf("Unexpected error details: %+v", helixErr)
+	}
+}
+
+func TestAnnounceFallsBackOnAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	b := &Bot{
+		channel:       "testchannel",
+		api:           newTestAPIClient(t, server),
+		broadcasterID: "123",
+		botUserID:     "456",
+	}
+
+	// With no chat client the fallback is a no-op; the API error is surfaced
+	if err := b.sendAnnouncement(context.Background(), "Milestone!"); err == nil {
+		t.Error("Expected sendAnnouncement to return the API error")
+	}
+	b.Announce("Milestone!") // must not panic when falling back
+}
+
+func TestUserIDsResolvedOnce(t *testing.T) {
+	var lookups atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups.Add(1)
+		ids := map[string]string{"testchannel": "123", "testbot": "456"}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": []map[string]string{{"id": ids[r.URL.Query().Get("login")]}},
+		})
+	}))
+	defer server.Close()
+
+	b := &Bot{channel: "testchannel", botUsername: "testbot", api: newTestAPIClient(t, server)}
+	b.resolveUserIDs(context.Background())
+	if lookups.Load() != 2 {
+		t.Fatalf("Expected both IDs to be looked up when connecting, got %d lookups", lookups.Load())
+	}
+
+	// Concurrent uses share the cached IDs
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			broadcasterID, err := b.getBroadcasterID(context.Background())
+			if err != nil || broadcasterID != "123" {
+				t.Errorf("Expected broadcaster ID 123, got %q (%v)", broadcasterID, err)
+			}
+			botUserID, err := b.getBotUserID(context.Background())
+			if err != nil || botUserID != "456" {
+				t.Errorf("Expected bot user ID 456, got %q (%v)", botUserID, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if lookups.Load() != 2 {
+		t.Errorf("Expected no further lookups, got %d in total", lookups.Load())
+	}
+}
+
+func TestGetFollowedAt(t *testing.T) {
+	followedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/channels/followers" {
+			t.Errorf("Expected /channels/followers, got %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("user_id") == "789" {
+			w.Write([]byte(`{"total":1,"data":[{"user_id":"789","followed_at":"2024-01-02T03:04:05Z"}]}`))
+			return
+		}
+		w.Write([]byte(`{"total":0,"data":[]}`))
+	}))
+	defer server.Close()
+
+	client := newTestAPIClient(t, server)
+
+	got, following, err := client.GetFollowedAt(context.Background(), "123", "789")
+	if err != nil || !following || !got.Equal(followedAt) {
+		t.Errorf("Expected follow at %v, got %v (following=%v, err=%v)", followedAt, got, following, err)
+	}
+
+	_, following, err = client.GetFollowedAt(context.Background(), "123", "000")
+	if err != nil || following {
+		t.Errorf("Expected non-follower, got following=%v err=%v", following, err)
+	}
+}
+
+func TestGetUserCreatedAt(t *testing.T) {
+	createdAt := time.Date(2019, 5, 6, 7, 8, 9, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users" {
+			t.Errorf("Expected /users, got %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("login") == "someuser" {
+			w.Write([]byte(`{"data":[{"id":"789","login":"someuser","created_at":"2019-05-06T07:08:09Z"}]}`))
+			return
+		}
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	client := newTestAPIClient(t, server)
+
+	got, err := client.GetUserCreatedAt(context.Background(), "someuser")
+	if err != nil || !got.Equal(createdAt) {
+		t.Errorf("Expected created at %v, got %v (err=%v)", createdAt, got, err)
+	}
+
+	if _, err := client.GetUserCreatedAt(context.Background(), "nobody"); err == nil {
+		t.Error("Expected an error for an unknown user")
+	}
+}
+
+func TestWhisper(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected error
+	}{
+		{"success", http.StatusNoContent, "", nil},
+		{"user_disabled", http.StatusForbidden, `{"error":"Forbidden","status":403,"message":"The recipient's settings prevent this sender from whispering them."}`, ErrWhisperDisabled},
+		{"missing_scope", http.StatusUnauthorized, `{"error":"Unauthorized","status":401,"message":"Missing scope: user:manage:whispers"}`, ErrMissingScopes},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "POST" || r.URL.Path != "/whispers" {
+					t.Errorf("Expected POST /whispers, got %s %s", r.Method, r.URL.Path)
+				}
+				if r.URL.Query().Get("from_user_id") != "456" || r.URL.Query().Get("to_user_id") != "789" {
+					t.Errorf("Unexpected query: %s", r.URL.RawQuery)
+				}
+				var body map[string]string
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["message"] != "You are at position 3" {
+					t.Errorf("Unexpected body: %v (err=%v)", body, err)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			b := &Bot{api: newTestAPIClient(t, server)}
+			err := b.Whisper("456", "789", "You are at position 3")
+			if tt.expected == nil {
+				if err != nil {
+					t.Errorf("Expected whisper to succeed, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestHelixRequestRespectsContextDeadline(t *testing.T) {
+	// A Helix endpoint that hangs until the test finishes
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := newTestAPIClient(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetUserID(ctx, "someuser")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected request to give up promptly, took %v", elapsed)
+	}
+
+	if client.httpClient.Timeout != helixTimeout {
+		t.Errorf("Expected client timeout %v, got %v", helixTimeout, client.httpClient.Timeout)
+	}
+}
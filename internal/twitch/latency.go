@@ -0,0 +1,28 @@
+package twitch
+
+import (
+	"time"
+
+	"github.com/gempir/go-twitch-irc/v4"
+)
+
+// recordPingSent notes when the client sent its keepalive PING
+func (b *Bot) recordPingSent() {
+	b.pingSentAt.Store(time.Now().UnixNano())
+}
+
+// handlePong measures the round trip of the keepalive PING it answers.
+// PONGs with no PING outstanding are ignored.
+func (b *Bot) handlePong(message twitch.PongMessage) {
+	sent := b.pingSentAt.Swap(0)
+	if sent == 0 {
+		return
+	}
+	b.latency.Store(time.Now().UnixNano() - sent)
+}
+
+// Latency returns the round-trip time of the last answered IRC PING, or 0 if
+// none has been measured yet
+func (b *Bot) Latency() time.Duration {
+	return time.Duration(b.latency.Load())
+}
@@ -0,0 +1,104 @@
+package twitch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// defaultMaxConcurrentConnections is how many channels MultiChannelBot
+// connects at once unless WithMaxConcurrentConnections says otherwise
+const defaultMaxConcurrentConnections = 10
+
+// defaultChannelConnectTimeout is how long a channel can hold a connection
+// slot waiting for its first login before the next channel is let through.
+// The slow channel keeps retrying in the background.
+const defaultChannelConnectTimeout = 30 * time.Second
+
+// MultiChannelBot runs one Bot per channel and connects them together,
+// a few at a time so startup doesn't hammer Twitch's IRC servers
+type MultiChannelBot struct {
+	bots                     []*Bot
+	maxConcurrentConnections int
+	connectTimeout           time.Duration
+}
+
+// NewMultiChannelBot creates a MultiChannelBot for the given channel bots
+func NewMultiChannelBot(bots ...*Bot) *MultiChannelBot {
+	return &MultiChannelBot{
+		bots:                     bots,
+		maxConcurrentConnections: defaultMaxConcurrentConnections,
+		connectTimeout:           defaultChannelConnectTimeout,
+	}
+}
+
+// WithMaxConcurrentConnections sets how many channels connect at once.
+// Values below 1 keep the default.
+func (m *MultiChannelBot) WithMaxConcurrentConnections(n int) *MultiChannelBot {
+	if n < 1 {
+		n = defaultMaxConcurrentConnections
+	}
+	m.maxConcurrentConnections = n
+	return m
+}
+
+// Bots returns the channel bots, in the order they were added
+func (m *MultiChannelBot) Bots() []*Bot {
+	return m.bots
+}
+
+// ConnectToAllChannels connects every channel's bot, with at most
+// maxConcurrentConnections logging in at the same time. A channel gives up
+// its slot once it's connected, its Connect fails or connectTimeout passes.
+// It returns once every channel has been started, joining the errors of
+// those whose Connect failed.
+func (m *MultiChannelBot) ConnectToAllChannels(ctx context.Context) error {
+	slots := make(chan struct{}, m.maxConcurrentConnections)
+	errs := make([]error, len(m.bots))
+
+	var wg sync.WaitGroup
+	for i, b := range m.bots {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		}
+
+		wg.Add(1)
+		go func(i int, b *Bot) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			if err := b.Connect(ctx); err != nil {
+				errs[i] = fmt.Errorf("error connecting to %s: %w", b.channel, err)
+				return
+			}
+			if !b.waitForFirstConnect(ctx, m.connectTimeout) {
+				log.Printf("Still connecting to %s after %s; moving on to the next channel", b.channel, m.connectTimeout)
+			}
+		}(i, b)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// waitForFirstConnect waits for the IRC connection started by Connect to log
+// in, reporting whether it did before timeout or ctx ended
+func (b *Bot) waitForFirstConnect(ctx context.Context, timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-b.firstConnect:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
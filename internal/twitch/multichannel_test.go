@@ -0,0 +1,111 @@
+package twitch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// startSlowIRCServer is an IRC server that takes loginDelay to welcome each
+// connection, recording the most connections it had logging in at once
+func startSlowIRCServer(t *testing.T, loginDelay time.Duration) (string, *atomic.Int32, *atomic.Int32) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start IRC server: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	var inFlight, peak, welcomed atomic.Int32
+	var peakMu sync.Mutex
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				connecting := inFlight.Add(1)
+				peakMu.Lock()
+				if connecting > peak.Load() {
+					peak.Store(connecting)
+				}
+				peakMu.Unlock()
+
+				reader := bufio.NewReader(conn)
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if strings.HasPrefix(line, "NICK ") {
+						time.Sleep(loginDelay)
+						inFlight.Add(-1)
+						welcomed.Add(1)
+						fmt.Fprint(conn, ":tmi.twitch.tv 001 testbot :Welcome, GLHF!\r\n")
+					}
+				}
+			}(conn)
+		}
+	}()
+	return listener.Addr().String(), &peak, &welcomed
+}
+
+func TestConnectToAllChannelsLimitsConcurrentConnections(t *testing.T) {
+	address, peak, welcomed := startSlowIRCServer(t, 50*time.Millisecond)
+
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(TokenResponse{AccessToken: "token", RefreshToken: "refresh", ExpiresIn: 3600})
+	}))
+	defer tokenServer.Close()
+
+	var bots []*Bot
+	for i := 0; i < 20; i++ {
+		b := newAuthTestBot(t, address, tokenServer)
+		b.channel = fmt.Sprintf("channel%d", i)
+		bots = append(bots, b)
+	}
+	multi := NewMultiChannelBot(bots...).WithMaxConcurrentConnections(5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		for _, b := range bots {
+			stopBot(t, b, cancel)
+		}
+	}()
+	if err := multi.ConnectToAllChannels(ctx); err != nil {
+		t.Fatalf("ConnectToAllChannels failed: %v", err)
+	}
+
+	if got := welcomed.Load(); got != 20 {
+		t.Errorf("Expected all 20 channels to log in, got %d", got)
+	}
+	if got := peak.Load(); got > 5 {
+		t.Errorf("Expected at most 5 connections at once, got %d", got)
+	}
+	if got := peak.Load(); got < 2 {
+		t.Errorf("Expected channels to connect in parallel, peak was %d", got)
+	}
+}
+
+func TestWithMaxConcurrentConnectionsDefault(t *testing.T) {
+	multi := NewMultiChannelBot()
+	if multi.maxConcurrentConnections != defaultMaxConcurrentConnections {
+		t.Errorf("Expected default limit %d, got %d", defaultMaxConcurrentConnections, multi.maxConcurrentConnections)
+	}
+	if multi.WithMaxConcurrentConnections(0).maxConcurrentConnections != defaultMaxConcurrentConnections {
+		t.Error("Expected a limit below 1 to keep the default")
+	}
+	if multi.WithMaxConcurrentConnections(3).maxConcurrentConnections != 3 {
+		t.Error("Expected the limit to be set to 3")
+	}
+}
@@ -0,0 +1,59 @@
+package twitch
+
+import (
+	"strings"
+
+	"github.com/gempir/go-twitch-irc/v4"
+)
+
+// RoomModes are the chat restrictions Twitch reports for a channel
+type RoomModes struct {
+	EmoteOnly bool
+	// Minutes a viewer must have followed to chat; -1 when followers-only is off
+	FollowersOnly int
+	SubsOnly      bool
+	// Seconds between a viewer's messages; 0 when slow mode is off
+	Slow int
+	// Unique chat (r9k): repeated messages are rejected
+	UniqueChat bool
+}
+
+// handleRoomState records a channel's chat restrictions. Twitch sends every
+// mode on join and only the changed ones afterwards, so updates are merged
+// into what's already known.
+func (b *Bot) handleRoomState(message twitch.RoomStateMessage) {
+	channel := strings.ToLower(message.Channel)
+
+	b.roomStateMu.Lock()
+	defer b.roomStateMu.Unlock()
+	if b.roomState == nil {
+		b.roomState = make(map[string]map[string]int)
+	}
+	state, ok := b.roomState[channel]
+	if !ok {
+		state = map[string]int{"followers-only": -1}
+		b.roomState[channel] = state
+	}
+	for mode, value := range message.State {
+		state[mode] = value
+	}
+}
+
+// RoomModes returns the bot's own channel's chat restrictions. The boolean
+// is false until Twitch has sent the channel's room state.
+func (b *Bot) RoomModes() (RoomModes, bool) {
+	b.roomStateMu.Lock()
+	defer b.roomStateMu.Unlock()
+
+	state, ok := b.roomState[strings.ToLower(b.channel)]
+	if !ok {
+		return RoomModes{}, false
+	}
+	return RoomModes{
+		EmoteOnly:     state["emote-only"] > 0,
+		FollowersOnly: state["followers-only"],
+		SubsOnly:      state["subs-only"] > 0,
+		Slow:          state["slow"],
+		UniqueChat:    state["r9k"] > 0,
+	}, true
+}
@@ -0,0 +1,67 @@
+package twitch
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// maxChatMessageLength is the longest chat message Twitch accepts, in characters
+const maxChatMessageLength = 500
+
+// say sends msg to channel through the shared send path: repeats are made
+// unique, the configured response prefix is added and long messages are
+// split to fit Twitch's length limit
+func (b *Bot) say(channel, msg string) {
+	for _, part := range b.outgoingMessages(channel, msg) {
+		b.client.Say(channel, part)
+	}
+}
+
+// outgoingMessages returns the chat messages that send msg to channel. The
+// response prefix counts toward the length limit of every part.
+func (b *Bot) outgoingMessages(channel, msg string) []string {
+	msg = b.dedupeResponse(channel, msg)
+	prefix := b.responsePrefix()
+	limit := maxChatMessageLength
+	if prefix != "" {
+		limit -= utf8.RuneCountInString(prefix) + 1
+	}
+
+	parts := splitMessage(msg, limit)
+	if prefix != "" {
+		for i, part := range parts {
+			parts[i] = prefix + " " + part
+		}
+	}
+	return parts
+}
+
+// responsePrefix returns the branding added to every bot message, or ""
+func (b *Bot) responsePrefix() string {
+	if b.cfg == nil {
+		return ""
+	}
+	return strings.TrimSpace(b.cfg.ResponsePrefix)
+}
+
+// splitMessage splits msg into parts of at most limit characters, breaking
+// at the last space that fits where possible
+func splitMessage(msg string, limit int) []string {
+	if limit < 1 {
+		limit = 1
+	}
+	var parts []string
+	runes := []rune(msg)
+	for len(runes) > limit {
+		cut := limit
+		for i := limit; i > 0; i-- {
+			if runes[i] == ' ' {
+				cut = i
+				break
+			}
+		}
+		parts = append(parts, strings.TrimSpace(string(runes[:cut])))
+		runes = []rune(strings.TrimLeft(string(runes[cut:]), " "))
+	}
+	return append(parts, string(runes))
+}
@@ -0,0 +1,73 @@
+package twitch
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// defaultStreamPollInterval is how often NewBot's bots check whether the
+// channel is live
+const defaultStreamPollInterval = 2 * time.Minute
+
+// watchStream checks the channel's live status every interval until ctx is done
+func (b *Bot) watchStream(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		b.checkStream(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkStream keeps the chat session in step with the stream: a session is
+// started (or updated with the game, title and viewers) while the channel is
+// live, and ended and saved when it goes offline
+func (b *Bot) checkStream(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, helixTimeout)
+	defer cancel()
+
+	stream, live, err := b.api.GetStream(ctx, b.channel)
+	if err != nil {
+		log.Printf("[Stream] Error checking whether %s is live: %v", b.channel, err)
+		return
+	}
+	wasLive := b.streamLive.Swap(live)
+	firstCheck := !b.streamChecked.Swap(true)
+
+	// A stream already live at startup may be the one the bot was last
+	// running for, so only a transition counts as a new stream
+	if live && !wasLive && !firstCheck && b.onStreamStart != nil {
+		b.onStreamStart()
+	}
+
+	switch {
+	case live:
+		if _, _, active := b.channelStats.GetCurrentSessionChatStats(); !active {
+			b.channelStats.StartSession(stream.GameName, stream.Title, stream.ViewerCount)
+			return
+		}
+		b.channelStats.UpdateStreamInfo(stream.GameName, stream.Title, stream.ViewerCount)
+	case wasLive:
+		log.Printf("[Stream] %s went offline, ending the chat session", b.channel)
+		b.channelStats.EndSession()
+	}
+}
+
+// SetOnStreamStart sets a function called each time the channel is seen
+// going live after being offline
+func (b *Bot) SetOnStreamStart(f func()) {
+	b.onStreamStart = f
+}
+
+// EndStatsSession ends the in-progress chat session and saves the channel
+// stats, so the session is kept for !laststats across restarts
+func (b *Bot) EndStatsSession() error {
+	b.channelStats.EndSession()
+	return b.channelStats.Save()
+}
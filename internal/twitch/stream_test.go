@@ -0,0 +1,160 @@
+package twitch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	channelstats "github.com/pbuckles22/PBChatBot/internal/channel"
+	"github.com/pbuckles22/PBChatBot/internal/config"
+)
+
+func TestStreamSessionLifecycle(t *testing.T) {
+	var live atomic.Bool
+	var title atomic.Value
+	title.Store("Going for the record")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/streams" || r.URL.Query().Get("user_login") != "testchannel" {
+			t.Errorf("Unexpected request: %s %s", r.URL.Path, r.URL.RawQuery)
+		}
+		var data []StreamInfo
+		if live.Load() {
+			data = append(data, StreamInfo{GameName: "Tetris", Title: title.Load().(string), ViewerCount: 42})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	defer server.Close()
+
+	dataPath := t.TempDir()
+	stats := channelstats.NewChannelStats(dataPath)
+	stats.SetRetention(1, 0)
+	b := &Bot{
+		channel:      "testchannel",
+		cfg:          &config.Config{},
+		channelStats: stats,
+		api:          newTestAPIClient(t, server),
+	}
+	starts := 0
+	b.SetOnStreamStart(func() { starts++ })
+	ctx := context.Background()
+
+	// Offline before the stream starts: nothing to track
+	b.checkStream(ctx)
+	if _, _, active := stats.GetCurrentSessionChatStats(); active {
+		t.Fatal("Expected no session while offline")
+	}
+
+	// Going live starts a session with the stream's details
+	live.Store(true)
+	b.checkStream(ctx)
+	stats.RecordChatMessage("viewer1")
+	stats.RecordChatMessage("viewer2")
+	b.checkStream(ctx)
+	if messages, chatters, active := stats.GetCurrentSessionChatStats(); !active || messages != 2 || chatters != 2 {
+		t.Fatalf("Expected a live session with 2 messages from 2 chatters, got %d/%d (active %v)", messages, chatters, active)
+	}
+	if starts != 1 {
+		t.Errorf("Expected going live to be reported once, got %d", starts)
+	}
+
+	// Going offline ends the session and saves it
+	live.Store(false)
+	b.checkStream(ctx)
+	if _, _, active := stats.GetCurrentSessionChatStats(); active {
+		t.Error("Expected the session to end when the stream went offline")
+	}
+	saved, ok := channelstats.NewChannelStats(dataPath).LastSession()
+	if !ok || saved.Game != "Tetris" || saved.PeakViewers != 42 || saved.ChatMessages != 2 {
+		t.Errorf("Expected the ended session on disk, got %+v (found %v)", saved, ok)
+	}
+
+	// Shutting down mid-stream also keeps the session, and saving applies the
+	// retention policy. A new title starts a new session rather than resuming.
+	title.Store("Second attempt")
+	live.Store(true)
+	b.checkStream(ctx)
+	if starts != 2 {
+		t.Errorf("Expected the second stream to be reported, got %d starts", starts)
+	}
+	stats.RecordChatMessage("viewer3")
+	if err := b.EndStatsSession(); err != nil {
+		t.Fatalf("EndStatsSession failed: %v", err)
+	}
+	reloaded := channelstats.NewChannelStats(dataPath)
+	if len(reloaded.Sessions) != 1 || reloaded.Sessions[0].ChatMessages != 1 || reloaded.TotalSessions != 2 {
+		t.Errorf("Expected only the latest of 2 sessions to be kept, got %d sessions (%d total)", len(reloaded.Sessions), reloaded.TotalSessions)
+	}
+}
+
+func TestStreamLiveAtStartupNotReportedAsStart(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": []StreamInfo{{GameName: "Tetris", Title: "Still going"}}})
+	}))
+	defer server.Close()
+
+	b := &Bot{
+		channel:      "testchannel",
+		cfg:          &config.Config{},
+		channelStats: channelstats.NewChannelStats(t.TempDir()),
+		api:          newTestAPIClient(t, server),
+	}
+	starts := 0
+	b.SetOnStreamStart(func() { starts++ })
+
+	// The stream may have started before the bot did, so the first check
+	// finding it live isn't a new stream
+	b.checkStream(context.Background())
+	b.checkStream(context.Background())
+	if starts != 0 {
+		t.Errorf("Expected no stream start for a stream already live at startup, got %d", starts)
+	}
+}
+
+func TestConnectWhileOfflineStartsNoSession(t *testing.T) {
+	address, logins, _ := startSilentIRCServer(t)
+
+	var streamChecks atomic.Int32
+	helixServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/streams" {
+			streamChecks.Add(1)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": []interface{}{}})
+	}))
+	defer helixServer.Close()
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(TokenResponse{AccessToken: "token", RefreshToken: "refresh", ExpiresIn: 3600})
+	}))
+	defer tokenServer.Close()
+
+	b := newAuthTestBot(t, address, tokenServer)
+	b.api = newTestAPIClient(t, helixServer)
+	b.streamPollInterval = 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := b.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer stopBot(t, b, cancel)
+
+	select {
+	case <-logins:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timed out waiting for the login")
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for streamChecks.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if streamChecks.Load() < 2 {
+		t.Fatal("Timed out waiting for the stream checks")
+	}
+
+	// Only the stream going live starts a session, so a connect while the
+	// channel is offline leaves nothing to save as a fake session
+	if _, _, active := b.channelStats.GetCurrentSessionChatStats(); active {
+		t.Error("Expected no chat session after connecting while offline")
+	}
+}
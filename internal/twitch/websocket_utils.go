@@ -0,0 +1,39 @@
+package twitch
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrWebSocketFailed is returned by SafeReadMessage when the connection has
+// already failed and can't be read from again
+var ErrWebSocketFailed = errors.New("websocket connection failed")
+
+// failedReadPanic is the panic message gorilla/websocket uses when a
+// connection is read again after a previous read failed
+const failedReadPanic = "repeated read on failed websocket connection"
+
+// MessageReader is the part of *websocket.Conn that SafeReadMessage needs
+type MessageReader interface {
+	ReadMessage() (messageType int, p []byte, err error)
+}
+
+// SafeReadMessage reads the next message from conn. gorilla/websocket panics
+// instead of returning an error when a failed connection is read again;
+// that panic is turned into ErrWebSocketFailed so callers can reconnect.
+// Any other panic is re-raised.
+func SafeReadMessage(conn MessageReader) (message []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if strings.Contains(fmt.Sprint(r), failedReadPanic) {
+				message, err = nil, ErrWebSocketFailed
+				return
+			}
+			panic(r)
+		}
+	}()
+
+	_, message, err = conn.ReadMessage()
+	return message, err
+}
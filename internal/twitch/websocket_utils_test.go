@@ -0,0 +1,53 @@
+package twitch
+
+import (
+	"errors"
+	"testing"
+)
+
+// mockReader is a MessageReader that returns a message, an error, or panics
+type mockReader struct {
+	message  []byte
+	err      error
+	panicMsg interface{}
+}
+
+func (m *mockReader) ReadMessage() (int, []byte, error) {
+	if m.panicMsg != nil {
+		panic(m.panicMsg)
+	}
+	return 1, m.message, m.err
+}
+
+func TestSafeReadMessage(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		message, err := SafeReadMessage(&mockReader{message: []byte("PING :tmi.twitch.tv")})
+		if err != nil || string(message) != "PING :tmi.twitch.tv" {
+			t.Errorf("Expected message, got %q (err=%v)", message, err)
+		}
+	})
+
+	t.Run("read_error", func(t *testing.T) {
+		readErr := errors.New("i/o timeout")
+		if _, err := SafeReadMessage(&mockReader{err: readErr}); err != readErr {
+			t.Errorf("Expected read error to pass through, got %v", err)
+		}
+	})
+
+	t.Run("failed_connection_panic", func(t *testing.T) {
+		_, err := SafeReadMessage(&mockReader{panicMsg: "repeated read on failed websocket connection"})
+		if !errors.Is(err, ErrWebSocketFailed) {
+			t.Errorf("Expected ErrWebSocketFailed, got %v", err)
+		}
+	})
+
+	t.Run("other_panic", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r != "something else broke" {
+				t.Errorf("Expected original panic to be re-raised, got %v", r)
+			}
+		}()
+		SafeReadMessage(&mockReader{panicMsg: "something else broke"})
+		t.Error("Expected SafeReadMessage to panic")
+	})
+}
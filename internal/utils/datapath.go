@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// FallbackDataRoot is the directory (under the system temp dir) used when the
+// configured data path can't be written to
+const FallbackDataRoot = "pbchatbot-data"
+
+// EnsureWritableDataPath returns dataPath if it can be created and written to.
+// Otherwise it logs a single warning and returns a writable fallback under the
+// system temp directory, keeping the last path element so channels stay separate.
+func EnsureWritableDataPath(dataPath string) string {
+	if isWritableDir(dataPath) {
+		return dataPath
+	}
+
+	fallback := filepath.Join(os.TempDir(), FallbackDataRoot, filepath.Base(dataPath))
+	if !isWritableDir(fallback) {
+		log.Printf("Warning: data path %s is not writable and fallback %s is unavailable; state will not be persisted", dataPath, fallback)
+		return dataPath
+	}
+
+	log.Printf("Warning: data path %s is not writable, persisting state to %s instead", dataPath, fallback)
+	return fallback
+}
+
+// isWritableDir creates dir if needed and checks that a file can be written in it
+func isWritableDir(dir string) bool {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return false
+	}
+	f, err := os.CreateTemp(dir, ".write_test_*")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
+}
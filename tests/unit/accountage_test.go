@@ -0,0 +1,84 @@
+package unit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+// fakeAccountChecker returns canned account creation times and counts lookups
+type fakeAccountChecker struct {
+	created map[string]time.Time
+	lookups int
+}
+
+func (f *fakeAccountChecker) AccountCreatedAt(username string) (time.Time, error) {
+	f.lookups++
+	createdAt, ok := f.created[username]
+	if !ok {
+		return time.Time{}, errors.New("user not found")
+	}
+	return createdAt, nil
+}
+
+func TestHandleJoinMinAccountDays(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_accountage")
+	commands.SetCommandManager(cm)
+	cm.GetQueue().Enable()
+	cm.GetConfig().Commands.Queue.MinAccountDays = 30
+
+	day := 24 * time.Hour
+	now := time.Now()
+	checker := &fakeAccountChecker{created: map[string]time.Time{
+		"veteran":   now.Add(-(30*day + time.Hour)),
+		"newbie":    now.Add(-(30*day - time.Hour)),
+		"brandnew":  now.Add(-time.Hour),
+		"modnewbie": now.Add(-time.Hour),
+	}}
+	cm.SetAccountChecker(checker)
+
+	tests := []struct {
+		name     string
+		user     string
+		isMod    bool
+		expected string
+	}{
+		{"just_inside_threshold", "veteran", false, "veteran joined queue at position 1"},
+		{"just_outside_threshold", "newbie", false, "newbie, your account must be at least 30 days old to join the queue (it is 29 days old)."},
+		{"brand_new_account", "brandnew", false, "brandnew, your account must be at least 30 days old to join the queue (it is 0 days old)."},
+		{"mod_bypass", "modnewbie", true, "modnewbie joined queue at position 2"},
+		{"lookup_failure_allows_join", "unknown", false, "unknown joined queue at position 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := createMockMessage(tt.user, "!join", tt.isMod, false, false)
+			response := commands.HandleJoin(msg, []string{})
+			if !strings.HasPrefix(response, tt.expected) {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, response)
+			}
+		})
+	}
+
+	// Account ages are cached, so repeat attempts don't look the user up again
+	lookups := checker.lookups
+	commands.HandleJoin(createMockMessage("newbie", "!join", false, false, false), []string{})
+	if checker.lookups != lookups {
+		t.Errorf("Expected the cached account age to be reused, got %d lookups (was %d)", checker.lookups, lookups)
+	}
+
+	// The gate is off by default
+	cm.GetConfig().Commands.Queue.MinAccountDays = 0
+	if response := commands.HandleJoin(createMockMessage("brandnew", "!join", false, false, false), []string{}); !strings.HasPrefix(response, "brandnew joined queue") {
+		t.Errorf("Expected new accounts to join with the gate off, got '%s'", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
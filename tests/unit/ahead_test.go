@@ -0,0 +1,51 @@
+package unit
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+func TestAheadCommand(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_ahead")
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+	cm.GetQueue().Enable()
+	for i := 1; i <= 9; i++ {
+		cm.GetQueue().Add(fmt.Sprintf("user%d", i), false)
+	}
+
+	ahead := func(user string) string {
+		t.Helper()
+		response, _ := cm.HandleMessage(createMockMessage(user, "!ahead", false, false, false))
+		return response
+	}
+
+	// A few users ahead are all listed
+	if response := ahead("user4"); response != "Ahead of you: user1, user2, user3" {
+		t.Errorf("Expected the three users ahead, got %q", response)
+	}
+
+	// Long lists are capped
+	if response := ahead("USER8"); response != "Ahead of you: user1, user2, user3, user4, user5 (+2 more)" {
+		t.Errorf("Expected a capped list, got %q", response)
+	}
+
+	// At the front
+	if response := ahead("user1"); response != "@user1, nobody is ahead of you. You're next!" {
+		t.Errorf("Expected the front-of-queue reply, got %q", response)
+	}
+
+	// Not in the queue
+	if response := ahead("stranger"); response != "@stranger, you are not in the queue!" {
+		t.Errorf("Expected the not-in-queue reply, got %q", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
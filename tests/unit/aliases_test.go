@@ -0,0 +1,309 @@
+package unit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+func TestAliasCreateAndExecute(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_alias")
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+	cm.GetQueue().Enable()
+
+	msg := createMockMessage("testuser", "!alias !j2 !join", false, false, false)
+	response := commands.HandleAlias(msg, []string{"!j2", "!join"})
+
+	if !strings.Contains(response, "!j2 is now an alias for !join") {
+		t.Errorf("Expected alias confirmation, got '%s'", response)
+	}
+
+	// Executing the alias should invoke the join handler
+	response, _ = cm.HandleMessage(createMockMessage("testuser", "!j2", false, false, false))
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+
+	if !strings.Contains(response, "testuser joined queue at position 1") {
+		t.Errorf("Expected 'testuser joined queue at position 1', got '%s'", response)
+	}
+
+	// Aliasing an alias resolves to the underlying command
+	response = commands.HandleAlias(msg, []string{"!j3", "!j2"})
+	if !strings.Contains(response, "!j3 is now an alias for !j2") {
+		t.Errorf("Expected alias confirmation, got '%s'", response)
+	}
+	if target := cm.GetAliases()["j3"]; target != "join" {
+		t.Errorf("Expected j3 to resolve to join, got '%s'", target)
+	}
+
+	// Listing aliases
+	response = commands.HandleAliases(msg, []string{})
+	if !strings.Contains(response, "!j2 -> !join") || !strings.Contains(response, "!j3 -> !join") {
+		t.Errorf("Expected aliases to be listed, got '%s'", response)
+	}
+
+	// Existing commands cannot be overwritten
+	response = commands.HandleAlias(msg, []string{"!ping", "!join"})
+	if !strings.Contains(response, "already a command") {
+		t.Errorf("Expected 'already a command', got '%s'", response)
+	}
+
+	// Unknown targets are rejected
+	response = commands.HandleAlias(msg, []string{"!x", "!nonexistent"})
+	if !strings.Contains(response, "not a command") {
+		t.Errorf("Expected 'not a command', got '%s'", response)
+	}
+
+	// Removing an alias
+	response = commands.HandleUnalias(msg, []string{"!j2"})
+	if !strings.Contains(response, "Alias !j2 removed") {
+		t.Errorf("Expected 'Alias !j2 removed', got '%s'", response)
+	}
+	if response, _ := cm.HandleMessage(createMockMessage("testuser", "!j2", false, false, false)); response != "" {
+		t.Errorf("Expected no response for removed alias, got '%s'", response)
+	}
+
+	// Built-in commands cannot be unaliased
+	response = commands.HandleUnalias(msg, []string{"!join"})
+	if !strings.Contains(response, "not an alias") {
+		t.Errorf("Expected 'not an alias', got '%s'", response)
+	}
+}
+
+func TestAliasCircular(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_alias_circular")
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+
+	msg := createMockMessage("testuser", "!alias !ping !ping", false, false, false)
+	response := commands.HandleAlias(msg, []string{"!ping", "!ping"})
+
+	if !strings.Contains(response, "circular") {
+		t.Errorf("Expected 'circular', got '%s'", response)
+	}
+
+	response = commands.HandleAlias(msg, []string{"!new", "!new"})
+	if !strings.Contains(response, "circular") {
+		t.Errorf("Expected 'circular', got '%s'", response)
+	}
+}
+
+func TestAliasPermissions(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_alias_perms")
+	commands.SetCommandManager(cm)
+	cm.RegisterCommand(&commands.Command{
+		Name:        "modcmd",
+		Description: "Moderator command",
+		Handler:     commands.HandlePing,
+		ModOnly:     true,
+	})
+
+	// Regular users cannot alias mod-only commands
+	regularMsg := createMockMessage("testuser", "!alias !mc !modcmd", false, false, false)
+	response := commands.HandleAlias(regularMsg, []string{"!mc", "!modcmd"})
+
+	if !strings.Contains(response, "Only moderators") {
+		t.Errorf("Expected 'Only moderators', got '%s'", response)
+	}
+
+	// Moderators can, and the alias stays mod-only
+	modMsg := createMockMessage("moduser", "!alias !mc !modcmd", true, false, false)
+	response = commands.HandleAlias(modMsg, []string{"!mc", "!modcmd"})
+
+	if !strings.Contains(response, "!mc is now an alias for !modcmd") {
+		t.Errorf("Expected alias confirmation, got '%s'", response)
+	}
+
+	response, _ = cm.HandleMessage(createMockMessage("testuser", "!mc", false, false, false))
+	if !strings.Contains(response, "only be used by moderators") {
+		t.Errorf("Expected alias to be mod-only, got '%s'", response)
+	}
+}
+
+func TestAliasPersistence(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_alias_persist")
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+
+	if err := cm.AddAlias("pong", "ping"); err != nil {
+		t.Fatalf("Failed to add alias: %v", err)
+	}
+
+	aliasFile := filepath.Join(tempDir, "aliases_testchannel_alias_persist.json")
+	if _, err := os.Stat(aliasFile); os.IsNotExist(err) {
+		t.Fatal("Aliases file should be created")
+	}
+
+	// Create new manager instance (simulating restart)
+	cm2 := commands.NewCommandManager("!", tempDir, "testchannel_alias_persist")
+	commands.SetCommandManager(cm2)
+	commands.RegisterBasicCommands(cm2)
+	if err := cm2.LoadAliases(); err != nil {
+		t.Fatalf("Failed to load aliases: %v", err)
+	}
+
+	response, _ := cm2.HandleMessage(createMockMessage("testuser", "!pong", false, false, false))
+	if !strings.HasPrefix(response, "Pong! 🏓") {
+		t.Errorf("Expected a 'Pong! 🏓' reply from restored alias, got '%s'", response)
+	}
+}
+
+func TestListAliases(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_listaliases")
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+
+	msg := createMockMessage("testuser", "!listaliases", false, false, false)
+
+	// A command with several aliases, looked up by name or alias
+	if response := commands.HandleListAliases(msg, []string{"move"}); response != "Aliases for !move: !m, !mv." {
+		t.Errorf("Expected the aliases of !move, got %q", response)
+	}
+	if response := commands.HandleListAliases(msg, []string{"!j"}); response != "Aliases for !join: !j." {
+		t.Errorf("Expected an alias to resolve to its command, got %q", response)
+	}
+
+	// Runtime aliases are listed alongside built-in ones
+	mod := createMockMessage("moduser", "!alias", true, false, false)
+	commands.HandleAlias(mod, []string{"!hop", "!join"})
+	if response := commands.HandleListAliases(msg, []string{"join"}); response != "Aliases for !join: !hop, !j." {
+		t.Errorf("Expected the runtime alias to be included, got %q", response)
+	}
+
+	// A command without aliases and an unknown command
+	if response := commands.HandleListAliases(msg, []string{"ping"}); response != "!ping has no aliases." {
+		t.Errorf("Expected a no-aliases reply, got %q", response)
+	}
+	if response := commands.HandleListAliases(msg, []string{"nosuchcommand"}); response != "Unknown command: !nosuchcommand" {
+		t.Errorf("Expected an unknown command reply, got %q", response)
+	}
+
+	// Without an argument every command with an alias is listed and counted
+	expected := 0
+	for _, cmd := range cm.GetCommandList() {
+		if len(cmd.Aliases) > 0 {
+			expected++
+		}
+	}
+	response := commands.HandleListAliases(msg, nil)
+	if !strings.HasPrefix(response, fmt.Sprintf("Commands with aliases (%d): ", expected)) {
+		t.Errorf("Expected %d commands with aliases, got %q", expected, response)
+	}
+	for _, entry := range []string{"!join(!hop, !j)", "!move(!m, !mv)", "!queue(!q)"} {
+		if !strings.Contains(response, entry) {
+			t.Errorf("Expected %q in the listing, got %q", entry, response)
+		}
+	}
+	if strings.Contains(response, "!ping(") {
+		t.Errorf("Expected commands without aliases to be left out, got %q", response)
+	}
+}
+
+func TestAliasLimits(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_alias_limits")
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+	msg := createMockMessage("testuser", "!alias", false, false, false)
+
+	if response := commands.HandleAlias(msg, []string{"!" + strings.Repeat("a", 26), "!ping"}); !strings.Contains(response, "at most 25 characters") {
+		t.Errorf("Expected a long alias name to be rejected, got '%s'", response)
+	}
+	if response := commands.HandleAlias(msg, []string{"!pi-ng", "!ping"}); !strings.Contains(response, "letters, numbers and underscores") {
+		t.Errorf("Expected an alias name with punctuation to be rejected, got '%s'", response)
+	}
+
+	for i := 0; i < 50; i++ {
+		if err := cm.AddAlias(fmt.Sprintf("ping%d", i), "ping"); err != nil {
+			t.Fatalf("Failed to add alias %d: %v", i, err)
+		}
+	}
+	if response := commands.HandleAlias(msg, []string{"!oneTooMany", "!ping"}); !strings.Contains(response, "maximum of 50 aliases") {
+		t.Errorf("Expected aliases past the cap to be rejected, got '%s'", response)
+	}
+}
+
+func TestAliasModHandlerCommands(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_alias_modhandler")
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+
+	// !pop has no ModOnly flag, but it's still a moderator command
+	viewer := createMockMessage("testuser", "!alias !next !pop", false, false, false)
+	if response := commands.HandleAlias(viewer, []string{"!next", "!pop"}); !strings.Contains(response, "Only moderators") {
+		t.Errorf("Expected viewers to be kept from aliasing !pop, got '%s'", response)
+	}
+	mod := createMockMessage("moduser", "!alias !next !pop", true, false, false)
+	if response := commands.HandleAlias(mod, []string{"!next", "!pop"}); !strings.Contains(response, "!next is now an alias for !pop") {
+		t.Errorf("Expected a moderator to alias !pop, got '%s'", response)
+	}
+	if response := commands.HandleUnalias(viewer, []string{"!next"}); !strings.Contains(response, "Only moderators") {
+		t.Errorf("Expected viewers to be kept from removing the !pop alias, got '%s'", response)
+	}
+}
+
+func TestAliasCopiesSilent(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_alias_silent")
+	commands.SetCommandManager(cm)
+	cm.RegisterCommand(&commands.Command{
+		Name:        "quiet",
+		Description: "Silent command",
+		Handler:     commands.HandlePing,
+		Silent:      true,
+	})
+
+	if err := cm.AddAlias("hush", "quiet"); err != nil {
+		t.Fatalf("Failed to add alias: %v", err)
+	}
+	if response, isCommand := cm.HandleMessage(createMockMessage("testuser", "!hush", false, false, false)); !isCommand || response != "" {
+		t.Errorf("Expected the alias of a silent command to run silently, got '%s' (command %v)", response, isCommand)
+	}
+}
+
+func TestLoadAliasesSkipsBrokenEntries(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	saved := `{"pong": "ping", "gone": "removedcommand", "j2": "join"}`
+	if err := os.WriteFile(filepath.Join(tempDir, "aliases_testchannel_alias_broken.json"), []byte(saved), 0644); err != nil {
+		t.Fatalf("Failed to write aliases: %v", err)
+	}
+
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_alias_broken")
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+	if err := cm.LoadAliases(); err != nil {
+		t.Fatalf("Expected broken aliases to be skipped, got %v", err)
+	}
+	aliases := cm.GetAliases()
+	if aliases["pong"] != "ping" || aliases["j2"] != "join" || len(aliases) != 2 {
+		t.Errorf("Expected the valid aliases to be restored, got %v", aliases)
+	}
+}
@@ -0,0 +1,62 @@
+package unit
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+// fakeAnnouncer records announcements instead of sending them
+type fakeAnnouncer struct {
+	messages []string
+}
+
+func (a *fakeAnnouncer) Announce(message string) {
+	a.messages = append(a.messages, message)
+}
+
+func TestQueueAnnouncements(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_announcements")
+	commands.SetCommandManager(cm)
+	cm.GetQueue().Enable()
+
+	cfg := cm.GetConfig()
+	cfg.Commands.Queue.MaxSize = 3
+	cfg.Commands.Queue.AnnounceQueueFull = true
+	cfg.Commands.Queue.AnnounceMilestoneEvery = 2
+
+	announcer := &fakeAnnouncer{}
+	cm.SetAnnouncer(announcer)
+
+	for i := 1; i <= 3; i++ {
+		msg := createMockMessage(fmt.Sprintf("user%d", i), "!join", false, false, false)
+		commands.HandleJoin(msg, []string{})
+	}
+
+	expected := []string{
+		"Milestone: 2 queue joins this session!",
+		"The queue is full! (3/3)",
+	}
+	if len(announcer.messages) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, announcer.messages)
+	}
+	for i := range expected {
+		if announcer.messages[i] != expected[i] {
+			t.Errorf("Expected '%s', got '%s'", expected[i], announcer.messages[i])
+		}
+	}
+
+	// Failed joins don't count toward milestones
+	commands.HandleJoin(createMockMessage("user1", "!join", false, false, false), []string{})
+	if len(announcer.messages) != len(expected) {
+		t.Errorf("Expected no new announcements after a failed join, got %v", announcer.messages)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
@@ -0,0 +1,33 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pbuckles22/PBChatBot/internal/utils"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "channel_settings_test.json")
+
+	for _, content := range []string{`{"lobby_code":"ABCD"}`, `{}`} {
+		if err := utils.WriteFileAtomic(filename, []byte(content)); err != nil {
+			t.Fatalf("WriteFileAtomic failed: %v", err)
+		}
+		data, err := os.ReadFile(filename)
+		if err != nil || string(data) != content {
+			t.Errorf("Expected %q on disk, got %q (%v)", content, data, err)
+		}
+	}
+
+	// Only the target file is left behind, readable by other processes
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Expected no temporary files to be left, got %v (%v)", entries, err)
+	}
+	if info, err := os.Stat(filename); err != nil || info.Mode().Perm() != 0644 {
+		t.Errorf("Expected mode 0644, got %v (%v)", info, err)
+	}
+}
@@ -0,0 +1,152 @@
+package unit
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+	"github.com/pbuckles22/PBChatBot/internal/queue"
+)
+
+func TestAutoAdvancerPopsOnSchedule(t *testing.T) {
+	q := queue.NewQueue(t.TempDir(), "testchannel")
+	q.Enable()
+	q.Add("user1", false)
+	q.Add("user2", false)
+	q.Add("user3", false)
+
+	clock := &fakeCountdownClock{}
+	recorder := &countdownRecorder{}
+	autoAdvance := commands.NewAutoAdvancer(clock, recorder.send)
+
+	if err := autoAdvance.Start(q, 90*time.Second); err != nil {
+		t.Fatalf("Failed to start auto-advance: %v", err)
+	}
+	if err := autoAdvance.Start(q, time.Minute); err != commands.ErrAutoAdvanceRunning {
+		t.Errorf("Expected ErrAutoAdvanceRunning, got %v", err)
+	}
+
+	steps := []struct {
+		advance  time.Duration
+		expected []string
+	}{
+		{89 * time.Second, nil},
+		{time.Second, []string{"@user1, you're up! (2 left in queue, next in 1m30s)"}},
+		{90 * time.Second, []string{
+			"@user1, you're up! (2 left in queue, next in 1m30s)",
+			"@user2, you're up! (1 left in queue, next in 1m30s)",
+		}},
+		{90 * time.Second, []string{
+			"@user1, you're up! (2 left in queue, next in 1m30s)",
+			"@user2, you're up! (1 left in queue, next in 1m30s)",
+			"@user3, you're up! The queue is now empty, so auto-advance has stopped.",
+		}},
+		{10 * time.Minute, []string{
+			"@user1, you're up! (2 left in queue, next in 1m30s)",
+			"@user2, you're up! (1 left in queue, next in 1m30s)",
+			"@user3, you're up! The queue is now empty, so auto-advance has stopped.",
+		}},
+	}
+	for i, step := range steps {
+		clock.Advance(step.advance)
+		if got := recorder.messages(); !reflect.DeepEqual(got, step.expected) {
+			t.Fatalf("Step %d: expected %v, got %v", i, step.expected, got)
+		}
+	}
+
+	if autoAdvance.IsActive() {
+		t.Error("Auto-advance should stop once the queue is empty")
+	}
+	if q.Size() != 0 {
+		t.Errorf("Expected an empty queue, got %v", q.List())
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestAutoAdvancerStop(t *testing.T) {
+	q := queue.NewQueue(t.TempDir(), "testchannel")
+	q.Enable()
+	q.Add("user1", false)
+	q.Add("user2", false)
+
+	clock := &fakeCountdownClock{}
+	recorder := &countdownRecorder{}
+	autoAdvance := commands.NewAutoAdvancer(clock, recorder.send)
+
+	if err := autoAdvance.Start(q, time.Minute); err != nil {
+		t.Fatalf("Failed to start auto-advance: %v", err)
+	}
+	clock.Advance(30 * time.Second)
+	if !autoAdvance.Stop() {
+		t.Fatal("Expected stop to end the running auto-advance")
+	}
+	if autoAdvance.Stop() {
+		t.Error("Expected a second stop to report nothing running")
+	}
+
+	clock.Advance(5 * time.Minute)
+	if got := recorder.messages(); len(got) != 0 {
+		t.Errorf("Expected no pops after stopping, got %v", got)
+	}
+	if q.Size() != 2 {
+		t.Errorf("Expected the queue untouched, got %v", q.List())
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestHandleAutoAdvance(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_autoadvance")
+	commands.SetCommandManager(cm)
+	clock := &fakeCountdownClock{}
+	recorder := &countdownRecorder{}
+	cm.SetAutoAdvancer(commands.NewAutoAdvancer(clock, recorder.send))
+	msg := createMockMessage("moduser", "!autoadvance", true, false, false)
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{nil, "Usage: !autoadvance <interval> or !autoadvance off"},
+		{[]string{"soon"}, "Invalid interval: soon (use e.g. 90s or 5m)"},
+		{[]string{"5s"}, "The auto-advance interval must be at least 10s."},
+		{[]string{"90s"}, "Queue system is currently disabled."},
+		{[]string{"off"}, "Auto-advance is not running."},
+	}
+	for _, tt := range tests {
+		if got := commands.HandleAutoAdvance(msg, tt.args); got != tt.expected {
+			t.Errorf("Args %v: expected '%s', got '%s'", tt.args, tt.expected, got)
+		}
+	}
+
+	cm.GetQueue().Enable()
+	if got := commands.HandleAutoAdvance(msg, []string{"90s"}); got != "Queue is empty." {
+		t.Errorf("Expected 'Queue is empty.', got '%s'", got)
+	}
+
+	cm.GetQueue().Add("user1", false)
+	cm.GetQueue().Add("user2", false)
+	if got := commands.HandleAutoAdvance(msg, []string{"90s"}); got != "Auto-advance started: popping the next user every 1m30s." {
+		t.Errorf("Unexpected start response '%s'", got)
+	}
+	if got := commands.HandleAutoAdvance(msg, []string{"2m"}); got != "Auto-advance is already running. Use !autoadvance off first." {
+		t.Errorf("Expected already running response, got '%s'", got)
+	}
+
+	clock.Advance(90 * time.Second)
+	if got := commands.HandleAutoAdvance(msg, []string{"OFF"}); got != "Auto-advance stopped." {
+		t.Errorf("Expected 'Auto-advance stopped.', got '%s'", got)
+	}
+	if users := cm.GetQueue().List(); len(users) != 1 || users[0] != "user2" {
+		t.Errorf("Expected only user2 left after one pop, got %v", users)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
@@ -0,0 +1,145 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+	"github.com/pbuckles22/PBChatBot/internal/queue"
+)
+
+func TestQueueBlacklist(t *testing.T) {
+	dir := t.TempDir()
+	q := queue.NewQueue(dir, "testchannel")
+	q.Enable()
+
+	if !q.Ban("@Troll") {
+		t.Fatal("Expected ban to succeed")
+	}
+	if q.Ban("troll") {
+		t.Error("Expected a second ban of the same login to report false")
+	}
+
+	if err := q.Add("TROLL", false); err == nil {
+		t.Error("Expected banned user to be rejected")
+	}
+	if err := q.AddVIP("troll"); err == nil {
+		t.Error("Expected banned user to be kept out of the VIP line")
+	}
+
+	// Mods can still add a banned user unless the blacklist blocks them
+	q.SetBlacklistBlocksMods(true)
+	if err := q.Add("troll", true); err == nil {
+		t.Error("Expected mod-added banned user to be rejected")
+	}
+	q.SetBlacklistBlocksMods(false)
+	if err := q.AddAtPosition("troll", 1, true); err != nil {
+		t.Errorf("Expected mod to be able to add banned user, got %v", err)
+	}
+	q.Remove("troll")
+
+	// The blacklist survives a restart
+	if err := q.SaveState(); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	reloaded := queue.NewQueue(dir, "testchannel")
+	if !reloaded.IsBanned("Troll") {
+		t.Errorf("Expected blacklist to persist, got %v", reloaded.ListBanned())
+	}
+
+	if !q.Unban("troll") {
+		t.Fatal("Expected unban to succeed")
+	}
+	if err := q.Add("troll", false); err != nil {
+		t.Errorf("Expected unbanned user to join, got %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueRestoreKeepsBlacklist(t *testing.T) {
+	dir := t.TempDir()
+	q := queue.NewQueue(dir, "testchannel")
+	q.Enable()
+	q.Ban("oldtroll")
+	q.Add("viewer1", false)
+	if err := q.SaveBackup(); err != nil {
+		t.Fatalf("Failed to save backup: %v", err)
+	}
+
+	// Bans changed after the backup aren't undone by restoring it
+	q.Ban("newtroll")
+	q.Unban("oldtroll")
+	if err := q.LoadBackup(); err != nil {
+		t.Fatalf("Failed to load backup: %v", err)
+	}
+	if !q.IsBanned("newtroll") || q.IsBanned("oldtroll") {
+		t.Errorf("Expected restoring the backup to keep the current bans, got %v", q.ListBanned())
+	}
+	if err := q.SaveState(); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	if err := q.RestoreAutoSave(); err != nil {
+		t.Fatalf("Failed to restore auto-save: %v", err)
+	}
+	if !q.IsBanned("newtroll") || q.IsBanned("oldtroll") {
+		t.Errorf("Expected restoring the auto-save to keep the current bans, got %v", q.ListBanned())
+	}
+
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueBlacklistMigratedFromAutoSave(t *testing.T) {
+	dir := t.TempDir()
+	legacy := `{"version": 1, "channel": "testchannel", "queue": [], "last_updated": 0, "blacklist": ["troll"]}`
+	if err := os.WriteFile(filepath.Join(dir, "queue_state_testchannel.json"), []byte(legacy), 0644); err != nil {
+		t.Fatalf("Failed to write legacy state: %v", err)
+	}
+
+	q := queue.NewQueue(dir, "testchannel")
+	if !q.IsBanned("troll") {
+		t.Fatalf("Expected bans from the auto-save to be kept, got %v", q.ListBanned())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "blacklist_testchannel.json")); err != nil {
+		t.Errorf("Expected the bans to be moved into the blacklist file: %v", err)
+	}
+}
+
+func TestHandleQueueBan(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_qban")
+	commands.SetCommandManager(cm)
+	cm.GetQueue().Enable()
+
+	mod := createMockMessage("moduser", "!qban troll", true, false, false)
+	if response := commands.HandleQueueBan(mod, []string{"troll"}); response != "troll is now barred from joining the queue." {
+		t.Errorf("Unexpected ban response: '%s'", response)
+	}
+	commands.HandleQueueBan(mod, []string{"@Spammer"})
+
+	join := createMockMessage("troll", "!join", false, false, false)
+	if response := commands.HandleJoin(join, nil); response != "@troll you're barred from joining the queue." {
+		t.Errorf("Expected banned join to be rejected, got '%s'", response)
+	}
+
+	list := createMockMessage("moduser", "!qbanlist", true, false, false)
+	if response := commands.HandleQueueBanList(list, nil); response != "Barred from the queue: spammer, troll (2 total)" {
+		t.Errorf("Unexpected ban list: '%s'", response)
+	}
+
+	if response := commands.HandleQueueUnban(mod, []string{"troll"}); response != "troll can join the queue again." {
+		t.Errorf("Unexpected unban response: '%s'", response)
+	}
+	if response := commands.HandleQueueUnban(mod, []string{"troll"}); response != "troll isn't barred from the queue." {
+		t.Errorf("Unexpected second unban response: '%s'", response)
+	}
+	if response := commands.HandleJoin(join, nil); response != "troll joined queue at position 1 (1 total)" {
+		t.Errorf("Expected unbanned user to join, got '%s'", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
@@ -0,0 +1,40 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	channelstats "github.com/pbuckles22/PBChatBot/internal/channel"
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+func TestChatStatsCommand(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_chatstats")
+	commands.SetCommandManager(cm)
+
+	stats := channelstats.NewChannelStats(tempDir)
+	commands.RegisterChatStatsCommand(cm, stats)
+
+	msg := createMockMessage("testuser", "!chatstats", false, false, false)
+
+	// Test offline case (no session)
+	response, _ := cm.HandleMessage(msg)
+	if !strings.Contains(response, "No session") {
+		t.Errorf("Expected 'No session', got '%s'", response)
+	}
+
+	// Start a session and record some chat
+	stats.StartSession("", "", 0)
+	stats.RecordChatMessage("user1")
+	stats.RecordChatMessage("user1")
+	stats.RecordChatMessage("user2")
+
+	// Another viewer asks, since testuser's !chatstats is now on cooldown
+	response, _ = cm.HandleMessage(createMockMessage("otheruser", "!chatstats", false, false, false))
+	if !strings.Contains(response, "3 chat messages from 2 unique chatters") {
+		t.Errorf("Expected '3 chat messages from 2 unique chatters', got '%s'", response)
+	}
+}
@@ -0,0 +1,186 @@
+package unit
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+// fakeTimerClock adds a current time to fakeCountdownClock
+type fakeTimerClock struct {
+	*fakeCountdownClock
+	base time.Time
+}
+
+func newFakeTimerClock() *fakeTimerClock {
+	return &fakeTimerClock{
+		fakeCountdownClock: &fakeCountdownClock{},
+		base:               time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func (c *fakeTimerClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.base.Add(c.now)
+}
+
+func TestTimerManagerSetAndExpire(t *testing.T) {
+	clock := newFakeTimerClock()
+	recorder := &countdownRecorder{}
+	tm := commands.NewTimerManager(clock, recorder.send, 10*time.Minute)
+
+	if err := tm.Set("grind", 30*time.Minute, "Taking a break!"); err != nil {
+		t.Fatalf("Failed to set timer: %v", err)
+	}
+	if err := tm.Set("GRIND", time.Minute, ""); !errors.Is(err, commands.ErrTimerExists) {
+		t.Errorf("Expected ErrTimerExists, got %v", err)
+	}
+
+	clock.Advance(10*time.Minute - time.Second)
+	if got := recorder.messages(); len(got) != 0 {
+		t.Fatalf("Expected no announcements yet, got %v", got)
+	}
+
+	clock.Advance(time.Second)
+	clock.Advance(10 * time.Minute)
+	clock.Advance(10 * time.Minute)
+	expected := []string{
+		"⏰ grind timer: 20 minutes remaining.",
+		"⏰ grind timer: 10 minutes remaining.",
+		"⏰ grind: Time's up! Taking a break!",
+	}
+	if got := recorder.messages(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if active := tm.List(); len(active) != 0 {
+		t.Errorf("Expected no timers after expiry, got %v", active)
+	}
+}
+
+func TestTimerManagerCancel(t *testing.T) {
+	clock := newFakeTimerClock()
+	recorder := &countdownRecorder{}
+	tm := commands.NewTimerManager(clock, recorder.send, 10*time.Minute)
+
+	tm.Set("break", 15*time.Minute, "")
+	clock.Advance(10 * time.Minute)
+	if err := tm.Cancel("Break"); err != nil {
+		t.Fatalf("Failed to cancel timer: %v", err)
+	}
+	clock.Advance(time.Hour)
+
+	expected := []string{"⏰ break timer: 5 minutes remaining."}
+	if got := recorder.messages(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected no announcements after cancel, got %v", got)
+	}
+	if err := tm.Cancel("break"); !errors.Is(err, commands.ErrTimerNotFound) {
+		t.Errorf("Expected ErrTimerNotFound, got %v", err)
+	}
+}
+
+func TestTimerManagerConcurrentTimers(t *testing.T) {
+	clock := newFakeTimerClock()
+	recorder := &countdownRecorder{}
+	tm := commands.NewTimerManager(clock, recorder.send, time.Minute)
+
+	tm.Set("long", 3*time.Minute, "")
+	tm.Set("short", 90*time.Second, "Go!")
+
+	clock.Advance(time.Minute)
+	active := tm.List()
+	if len(active) != 2 || active[0].Name != "short" || active[0].Remaining != 30*time.Second ||
+		active[1].Name != "long" || active[1].Remaining != 2*time.Minute {
+		t.Errorf("Unexpected active timers: %+v", active)
+	}
+
+	clock.Advance(2 * time.Minute)
+	expected := []string{
+		"⏰ long timer: 2 minutes remaining.",
+		"⏰ short timer: 30 seconds remaining.",
+		"⏰ short: Time's up! Go!",
+		"⏰ long timer: 1 minute remaining.",
+		"⏰ long: Time's up!",
+	}
+	if got := recorder.messages(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestTimerManagerSchedulesLazily(t *testing.T) {
+	clock := newFakeTimerClock()
+	recorder := &countdownRecorder{}
+	tm := commands.NewTimerManager(clock, recorder.send, time.Minute)
+
+	// A long timer only ever has its next post scheduled
+	if err := tm.Set("marathon", 24*time.Hour, ""); err != nil {
+		t.Fatalf("Failed to set timer: %v", err)
+	}
+	if pending := clock.scheduled(); pending != 1 {
+		t.Errorf("Expected 1 scheduled post, got %d", pending)
+	}
+
+	clock.Advance(3 * time.Minute)
+	expected := []string{
+		"⏰ marathon timer: 1439 minutes remaining.",
+		"⏰ marathon timer: 1438 minutes remaining.",
+		"⏰ marathon timer: 1437 minutes remaining.",
+	}
+	if got := recorder.messages(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if pending := clock.scheduled(); pending != 1 {
+		t.Errorf("Expected 1 scheduled post after announcing, got %d", pending)
+	}
+
+	tm.Cancel("marathon")
+	if pending := clock.scheduled(); pending != 0 {
+		t.Errorf("Expected nothing scheduled after cancel, got %d", pending)
+	}
+}
+
+func TestHandleTimer(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_timer")
+	commands.SetCommandManager(cm)
+
+	clock := newFakeTimerClock()
+	recorder := &countdownRecorder{}
+	cm.SetTimerManager(commands.NewTimerManager(clock, recorder.send, 0))
+
+	mod := createMockMessage("moduser", `!timer set grind 30m "Taking a break!"`, true, false, false)
+	if response := commands.HandleTimer(mod, []string{"set", "grind", "30m", `"Taking`, `break!"`}); response != "⏰ grind timer set for 30 minutes." {
+		t.Errorf("Unexpected set response: '%s'", response)
+	}
+
+	viewer := createMockMessage("viewer", "!timer set x 1m", false, false, false)
+	if response := commands.HandleTimer(viewer, []string{"set", "x", "1m"}); response != "Only moderators can set timers." {
+		t.Errorf("Expected moderator-only response, got '%s'", response)
+	}
+
+	tooLong := createMockMessage("moduser", "!timer set x 100000h", true, false, false)
+	if response := commands.HandleTimer(tooLong, []string{"set", "x", "100000h"}); response != "Timers can be at most 24 hours." {
+		t.Errorf("Expected the duration cap, got '%s'", response)
+	}
+
+	clock.Advance(5 * time.Minute)
+	list := createMockMessage("viewer", "!timer list", false, false, false)
+	if response := commands.HandleTimer(list, []string{"list"}); response != "Active timers: grind (25m remaining)" {
+		t.Errorf("Unexpected list response: '%s'", response)
+	}
+
+	cancel := createMockMessage("moduser", "!timer cancel grind", true, false, false)
+	if response := commands.HandleTimer(cancel, []string{"cancel", "grind"}); response != "⏰ grind timer cancelled." {
+		t.Errorf("Unexpected cancel response: '%s'", response)
+	}
+	if response := commands.HandleTimer(list, []string{"list"}); response != "No timers are running." {
+		t.Errorf("Expected no timers, got '%s'", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
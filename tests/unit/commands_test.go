@@ -1,12 +1,20 @@
 package unit
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 	"time"
 
 	twitchirc "github.com/gempir/go-twitch-irc/v4"
 	"github.com/pbuckles22/PBChatBot/internal/commands"
+	"github.com/pbuckles22/PBChatBot/internal/config"
+	"github.com/pbuckles22/PBChatBot/internal/queue"
 )
 
 // Mock message for testing
@@ -37,8 +45,8 @@ func TestHandlePing(t *testing.T) {
 
 	response := commands.HandlePing(msg, []string{})
 
-	if response != "Pong! 🏓" {
-		t.Errorf("Expected 'Pong! 🏓', got '%s'", response)
+	if !strings.HasPrefix(response, "Pong! 🏓") {
+		t.Errorf("Expected a 'Pong! 🏓' reply, got '%s'", response)
 	}
 }
 
@@ -70,6 +78,60 @@ func TestHandleStartQueue(t *testing.T) {
 	}
 }
 
+func TestHandleStartQueueMaxSize(t *testing.T) {
+	msg := createMockMessage("testbroadcaster", "!startqueue", false, false, true)
+
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManagerWithOptions(
+		commands.WithDataPath(t.TempDir()),
+		commands.WithChannel("testchannel_start_max"),
+		commands.WithMaxQueueSize(50),
+	)
+	commands.SetCommandManager(cm)
+
+	// No argument uses the configured max size
+	response := commands.HandleStartQueue(msg, []string{})
+	if response != "@testbroadcaster has started the queue system (max 50 users)!" {
+		t.Errorf("Expected the configured max in the response, got '%s'", response)
+	}
+	if cm.GetQueue().MaxSize() != 50 {
+		t.Errorf("Expected max size 50, got %d", cm.GetQueue().MaxSize())
+	}
+	cm.GetQueue().Disable()
+
+	// A valid argument overrides it
+	response = commands.HandleStartQueue(msg, []string{"20"})
+	if response != "@testbroadcaster has started the queue system (max 20 users)!" {
+		t.Errorf("Expected the override in the response, got '%s'", response)
+	}
+	if cm.GetQueue().MaxSize() != 20 {
+		t.Errorf("Expected max size 20, got %d", cm.GetQueue().MaxSize())
+	}
+	cm.GetQueue().Disable()
+
+	// Invalid and negative sizes are rejected without starting the queue
+	for _, arg := range []string{"lots", "-5", "0"} {
+		response = commands.HandleStartQueue(msg, []string{arg})
+		expected := "Invalid max queue size. Please specify a positive number. Usage: !startqueue [max size]"
+		if response != expected {
+			t.Errorf("Arg %q: expected '%s', got '%s'", arg, expected, response)
+		}
+		if cm.GetQueue().IsEnabled() {
+			t.Errorf("Arg %q: queue should not start", arg)
+		}
+	}
+
+	// Restarting without an argument goes back to the configured size
+	commands.HandleStartQueue(msg, []string{})
+	if cm.GetQueue().MaxSize() != 50 {
+		t.Errorf("Expected max size back at 50, got %d", cm.GetQueue().MaxSize())
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
 func TestHandleEndQueue(t *testing.T) {
 	msg := createMockMessage("testuser", "!endqueue", false, false, false)
 
@@ -79,6 +141,7 @@ func TestHandleEndQueue(t *testing.T) {
 	cm := commands.NewCommandManager("!", tempDir, "testchannel_end")
 	commands.SetCommandManager(cm)
 	cm.GetQueue().Enable()
+	cm.GetQueue().Add("user1", false)
 
 	response := commands.HandleEndQueue(msg, []string{})
 
@@ -90,6 +153,12 @@ func TestHandleEndQueue(t *testing.T) {
 		t.Error("Queue should be disabled after end")
 	}
 
+	// A restart doesn't bring back the ended queue's users
+	restarted := queue.NewQueue(tempDir, "testchannel_end")
+	if restarted.Size() != 0 {
+		t.Errorf("Expected an empty auto-save after end, got %v", restarted.List())
+	}
+
 	// Test ending queue when already disabled
 	response = commands.HandleEndQueue(msg, []string{})
 
@@ -98,6 +167,108 @@ func TestHandleEndQueue(t *testing.T) {
 	}
 }
 
+func TestHandleEndQueueSaveVariants(t *testing.T) {
+	msg := createMockMessage("testbroadcaster", "!endqueue", false, false, true)
+
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_end_save")
+	commands.SetCommandManager(cm)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+		restored int
+	}{
+		{"default", nil, "@testbroadcaster has ended the queue system! 2 user(s) saved; use !restoreauto to bring them back.", 2},
+		{"save", []string{"save"}, "@testbroadcaster has ended the queue system! 2 user(s) saved; use !restoreauto to bring them back.", 2},
+		{"nosave", []string{"NOSAVE"}, "@testbroadcaster has ended the queue system!", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm.GetQueue().Enable()
+			cm.GetQueue().Add("user1", false)
+			cm.GetQueue().Add("user2", false)
+
+			if response := commands.HandleEndQueue(msg, tt.args); response != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, response)
+			}
+			if cm.GetQueue().IsEnabled() || cm.GetQueue().Size() != 0 {
+				t.Errorf("Expected an ended, empty queue, got %v", cm.GetQueue().List())
+			}
+
+			// Startup never restores an ended queue
+			if restarted := queue.NewQueue(tempDir, "testchannel_end_save"); restarted.Size() != 0 {
+				t.Errorf("Expected no users restored on startup, got %v", restarted.List())
+			}
+
+			// !restoreauto brings back a saved queue
+			restoreMsg := createMockMessage("testbroadcaster", "!restoreauto", false, false, true)
+			commands.HandleRestoreAuto(restoreMsg, nil)
+			if cm.GetQueue().Size() != tt.restored {
+				t.Errorf("Expected %d users after !restoreauto, got %v", tt.restored, cm.GetQueue().List())
+			}
+			cm.GetQueue().Disable()
+		})
+	}
+
+	cm.GetQueue().Enable()
+	if response := commands.HandleEndQueue(msg, []string{"later"}); response != "Usage: !endqueue [save|nosave]" {
+		t.Errorf("Expected usage for an unknown argument, got '%s'", response)
+	}
+	if !cm.GetQueue().IsEnabled() {
+		t.Error("Queue should stay running after a bad argument")
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestHandleEndQueueSessionSummary(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_end_summary")
+	commands.SetCommandManager(cm)
+	cm.GetConfig().Commands.Queue.SessionSummary = true
+	cm.GetQueue().Enable()
+
+	cm.GetQueue().Add("user1", false)
+	cm.GetQueue().Add("user2", false)
+	cm.GetQueue().Pop()
+
+	msg := createMockMessage("moduser", "!endqueue", true, false, false)
+	response := commands.HandleEndQueue(msg, []string{})
+	if !strings.Contains(response, "Session summary saved to queue_summary_testchannel_end_summary_") {
+		t.Fatalf("Expected summary file in response, got '%s'", response)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(tempDir, "queue_summary_testchannel_end_summary_*.json"))
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 summary file, got %v", files)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("Failed to read summary: %v", err)
+	}
+
+	var summary queue.SessionSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatalf("Failed to parse summary: %v", err)
+	}
+	if len(summary.Served) != 1 || summary.Served[0].Username != "user1" {
+		t.Errorf("Expected user1 served, got %+v", summary.Served)
+	}
+	if len(summary.Remaining) != 1 || summary.Remaining[0].Username != "user2" {
+		t.Errorf("Expected user2 remaining, got %+v", summary.Remaining)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
 func TestHandleJoin(t *testing.T) {
 	// Reset command manager for test
 	commands.SetCommandManager(nil)
@@ -144,6 +315,127 @@ func TestHandleJoin(t *testing.T) {
 	}
 }
 
+func TestHandleJoinAlreadyQueued(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_join_dup")
+	commands.SetCommandManager(cm)
+	cm.GetQueue().Enable()
+
+	for _, user := range []string{"user1", "user2", "user3", "alice"} {
+		cm.GetQueue().Add(user, false)
+	}
+
+	msg := createMockMessage("Alice", "!join", false, false, false)
+	if response := commands.HandleJoin(msg, nil); response != "@Alice you're already in the queue at position 4" {
+		t.Errorf("Expected duplicate join to show the existing position, got '%s'", response)
+	}
+
+	// The reply can be customized per channel
+	cm.GetConfig().Commands.Queue.AlreadyQueuedMessage = "{user}, hang tight — you're #{position}"
+	if response := commands.HandleJoin(msg, nil); response != "Alice, hang tight — you're #4" {
+		t.Errorf("Expected custom duplicate join reply, got '%s'", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestHandleJoinBulk(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_join_bulk")
+	commands.SetCommandManager(cm)
+	cm.GetQueue().Enable()
+	cm.GetQueue().Add("early1", false)
+	cm.GetQueue().Add("early2", false)
+
+	msg := createMockMessage("moduser", "!join", true, false, false)
+	var args []string
+	for i := 1; i <= 8; i++ {
+		args = append(args, fmt.Sprintf("user%d", i))
+	}
+	args = append(args, "early1", "EARLY2")
+
+	expected := "Added 8 users (positions 3-10). Skipped 2 (already queued)."
+	if response := commands.HandleJoin(msg, args); response != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, response)
+	}
+	if cm.GetQueue().Size() != 10 {
+		t.Errorf("Expected 10 users in queue, got %d", cm.GetQueue().Size())
+	}
+
+	// Skips are counted per reason
+	cm.GetQueue().SetMaxSize(12)
+	expected = "Added 2 users (positions 11-12). Skipped 3 (already queued: 1, queue full: 2)."
+	if response := commands.HandleJoin(msg, []string{"user1", "late1", "late2", "late3", "late4"}); response != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, response)
+	}
+
+	expected = "Added 0 users. Skipped 2 (queue full)."
+	if response := commands.HandleJoin(msg, []string{"late3", "late4"}); response != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestHandleClearServed(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_clearserved")
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+	cm.GetQueue().Enable()
+	for _, user := range []string{"user1", "user2", "user3"} {
+		cm.GetQueue().Add(user, false)
+	}
+
+	viewer := createMockMessage("viewer", "!nowserving", false, false, false)
+	if response := commands.HandleNowServing(viewer, nil); response != "Nobody is being served right now." {
+		t.Errorf("Expected nobody served before popping, got '%s'", response)
+	}
+
+	// Popping fills now-serving
+	mod := createMockMessage("moduser", "!pop", true, false, false)
+	commands.HandlePop(mod, []string{"2"})
+	if response := commands.HandleNowServing(viewer, nil); response != "Now serving: user1, user2" {
+		t.Errorf("Expected 'Now serving: user1, user2', got '%s'", response)
+	}
+
+	// Now-serving is persisted with the queue
+	if err := cm.GetQueue().SaveState(); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if served := queue.NewQueue(tempDir, "testchannel_clearserved").NowServing(); len(served) != 2 {
+		t.Errorf("Expected now-serving to survive a restart, got %v", served)
+	}
+
+	// !clearserved empties it and persists the change
+	if response, _ := cm.HandleMessage(createMockMessage("viewer", "!clearserved", false, false, false)); response != "This command can only be used by moderators and VIPs." {
+		t.Errorf("Expected privileged-only reply, got '%s'", response)
+	}
+	if response := commands.HandleClearServed(mod, nil); response != "Cleared 2 user(s) from now serving." {
+		t.Errorf("Expected 'Cleared 2 user(s) from now serving.', got '%s'", response)
+	}
+	if response := commands.HandleNowServing(viewer, nil); response != "Nobody is being served right now." {
+		t.Errorf("Expected nobody served after clearing, got '%s'", response)
+	}
+	if response := commands.HandleClearServed(mod, nil); response != "Nobody is being served right now." {
+		t.Errorf("Expected nothing to clear, got '%s'", response)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if served := queue.NewQueue(tempDir, "testchannel_clearserved").NowServing(); len(served) != 0 {
+		t.Errorf("Expected the cleared list to be persisted, got %v", served)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
 func TestHandleLeave(t *testing.T) {
 	// Reset command manager for test
 	commands.SetCommandManager(nil)
@@ -186,6 +478,39 @@ func TestHandleLeave(t *testing.T) {
 	}
 }
 
+func TestHandleUnqueue(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_unqueue")
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+	cm.GetQueue().Enable()
+	msg := createMockMessage("testuser", "!unqueue", false, false, false)
+
+	// Within 30 days of the alias being added, users are pointed at !leave
+	cm.SetClock(func() time.Time { return time.Date(2026, time.November, 1, 12, 0, 0, 0, time.UTC) })
+	cm.GetQueue().Add("testuser", false)
+	response := commands.HandleUnqueue(msg, []string{})
+	if response != "(Note: use !leave going forward) testuser left queue" {
+		t.Errorf("Expected the deprecation note, got '%s'", response)
+	}
+	if cm.GetQueue().Position("testuser") != -1 {
+		t.Error("Expected testuser to have left the queue")
+	}
+
+	// Afterwards it behaves exactly like !leave
+	cm.SetClock(func() time.Time { return time.Date(2026, time.November, 20, 0, 0, 0, 0, time.UTC) })
+	cm.GetQueue().Add("testuser", false)
+	response = commands.HandleUnqueue(msg, []string{})
+	if response != "testuser left queue" {
+		t.Errorf("Expected the plain !leave response, got '%s'", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
 func TestHandleQueue(t *testing.T) {
 	// Reset command manager for test
 	commands.SetCommandManager(nil)
@@ -221,6 +546,41 @@ func TestHandleQueue(t *testing.T) {
 	}
 }
 
+func TestHandleQueueShowsSavedState(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_queue_saved")
+	commands.SetCommandManager(cm)
+	q := cm.GetQueue()
+	msg := createMockMessage("testuser", "!queue", false, false, false)
+
+	// Disabled with no saved file
+	if _, err := q.PeekSavedState(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected a not-exist error with no saved file, got %v", err)
+	}
+	if response := commands.HandleQueue(msg, []string{}); response != "Queue system is currently disabled." {
+		t.Errorf("Expected the disabled message with no saved file, got %q", response)
+	}
+
+	// Disabled with users kept in the auto-save
+	q.Enable()
+	q.Add("user1", false)
+	q.Add("user2", false)
+	if err := q.End(true); err != nil {
+		t.Fatalf("Failed to end queue: %v", err)
+	}
+	response := commands.HandleQueue(msg, []string{})
+	if response != "⏸ Queue is paused. Last state: user1, user2 (2 users)." {
+		t.Errorf("Expected the last saved state, got %q", response)
+	}
+
+	// Peeking leaves the live queue empty and disabled
+	if q.IsEnabled() || q.Size() != 0 {
+		t.Errorf("Expected the live queue to stay disabled and empty, got enabled=%v size=%d", q.IsEnabled(), q.Size())
+	}
+}
+
 func TestHandlePosition(t *testing.T) {
 	// Reset command manager for test
 	commands.SetCommandManager(nil)
@@ -329,6 +689,46 @@ func TestHandlePop(t *testing.T) {
 	}
 }
 
+func TestHandlePopNameCap(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_pop_cap")
+	commands.SetCommandManager(cm)
+	cm.GetConfig().Commands.Queue.MaxPopNames = 3
+	cm.GetQueue().Enable()
+
+	for i := 1; i <= 8; i++ {
+		cm.GetQueue().Add(fmt.Sprintf("user%d", i), false)
+	}
+	msg := createMockMessage("moduser", "!pop", true, false, false)
+
+	// Under the cap every name is listed
+	if response := commands.HandlePop(msg, []string{"3"}); response != "Popped: user1, user2, user3" {
+		t.Errorf("Expected all three names, got '%s'", response)
+	}
+
+	// Over the cap the rest are counted, but everyone is still popped
+	expected := "Popped: user4, user5, user6 ...and 2 more"
+	if response := commands.HandlePop(msg, []string{"5"}); response != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, response)
+	}
+	if cm.GetQueue().Size() != 0 {
+		t.Errorf("Expected all requested users popped, %d left", cm.GetQueue().Size())
+	}
+
+	// Without a setting the default cap applies
+	cm.GetConfig().Commands.Queue.MaxPopNames = 0
+	for i := 1; i <= commands.DefaultMaxPopNames+5; i++ {
+		cm.GetQueue().Add(fmt.Sprintf("viewer%d", i), false)
+	}
+	if response := commands.HandlePop(msg, []string{"50"}); !strings.HasSuffix(response, ", viewer10 ...and 5 more") {
+		t.Errorf("Expected default cap of %d names, got '%s'", commands.DefaultMaxPopNames, response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
 func TestHandleRemove(t *testing.T) {
 	// Reset command manager for test
 	commands.SetCommandManager(nil)
@@ -537,16 +937,19 @@ func TestHandleHelp(t *testing.T) {
 	cm.RegisterCommand(&commands.Command{
 		Name:        "help",
 		Description: "Show help",
+		Category:    commands.CategoryGeneral,
 		Handler:     commands.HandleHelp,
 	})
 	cm.RegisterCommand(&commands.Command{
 		Name:        "ping",
 		Description: "Ping the bot",
+		Category:    commands.CategoryGeneral,
 		Handler:     commands.HandlePing,
 	})
 	cm.RegisterCommand(&commands.Command{
 		Name:        "join",
 		Description: "Join queue",
+		Category:    commands.CategoryQueue,
 		Handler:     commands.HandleJoin,
 	})
 
@@ -557,8 +960,8 @@ func TestHandleHelp(t *testing.T) {
 		t.Errorf("Expected 'Available commands:', got '%s'", response)
 	}
 
-	if !strings.Contains(response, "Base Commands:") {
-		t.Errorf("Expected 'Base Commands:', got '%s'", response)
+	if !strings.Contains(response, "General Commands:") {
+		t.Errorf("Expected 'General Commands:', got '%s'", response)
 	}
 
 	if !strings.Contains(response, "Queue Commands:") {
@@ -577,3 +980,526 @@ func TestHandleHelp(t *testing.T) {
 		t.Errorf("Expected 'join' in response, got '%s'", response)
 	}
 }
+
+func TestHandleHelpCategories(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_help_categories")
+	commands.SetCommandManager(cm)
+
+	cm.RegisterCommand(&commands.Command{
+		Name:        "dance",
+		Description: "Dance",
+		Category:    "Party",
+		Handler:     commands.HandlePing,
+	})
+	cm.RegisterCommand(&commands.Command{
+		Name:        "join",
+		Description: "Join queue",
+		Category:    commands.CategoryQueue,
+		Handler:     commands.HandleJoin,
+	})
+	cm.RegisterCommand(&commands.Command{
+		Name:        "mystery",
+		Description: "No category",
+		Handler:     commands.HandlePing,
+	})
+
+	msg := createMockMessage("testuser", "!help", false, false, false)
+	response := commands.HandleHelp(msg, []string{})
+
+	// Custom categories come after the default order, uncategorized last
+	expected := "Available commands:\n" +
+		"Queue Commands:\n• !join: Join queue\n\n" +
+		"Party Commands:\n• !dance: Dance\n\n" +
+		"Other Commands:\n• !mystery: No category\n"
+	if response != expected {
+		t.Errorf("Expected %q, got %q", expected, response)
+	}
+
+	// help_category_order puts the listed categories first
+	cm.SetConfig(&config.Config{HelpCategoryOrder: []string{"Party"}})
+	response = commands.HandleHelp(msg, []string{})
+	party := strings.Index(response, "Party Commands:")
+	queue := strings.Index(response, "Queue Commands:")
+	if party < 0 || queue < 0 || party > queue {
+		t.Errorf("Expected Party before Queue, got '%s'", response)
+	}
+}
+
+func TestHandleHelpDeterministic(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_help_order")
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+
+	list := cm.GetCommandList()
+	sorted := sort.SliceIsSorted(list, func(i, j int) bool {
+		if list[i].Category != list[j].Category {
+			return list[i].Category < list[j].Category
+		}
+		return list[i].Name < list[j].Name
+	})
+	if !sorted {
+		t.Error("Expected GetCommandList to be sorted by category then name")
+	}
+
+	msg := createMockMessage("testuser", "!help", true, false, false)
+	first := commands.HandleHelp(msg, []string{})
+	second := commands.HandleHelp(msg, []string{})
+	if first != second {
+		t.Errorf("Expected identical !help output, got:\n%s\nthen:\n%s", first, second)
+	}
+
+	// Within each heading the commands are listed alphabetically
+	var names []string
+	for _, line := range strings.Split(first, "\n") {
+		if strings.HasPrefix(line, "• ") {
+			names = append(names, strings.Fields(line)[1])
+			continue
+		}
+		if !sort.StringsAreSorted(names) {
+			t.Errorf("Expected sorted commands under a heading, got %v", names)
+		}
+		names = nil
+	}
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestHandleHelpCommand(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_help_cmd")
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+
+	msg := createMockMessage("testuser", "!help join", false, false, false)
+	expected := "!join (!j): Join the queue | Permission: Everyone | Cooldown: 30s (viewers), 15s (VIPs), 5s (mods), 0s (broadcaster)"
+	if response := commands.HandleHelp(msg, []string{"join"}); response != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, response)
+	}
+
+	// Aliases resolve to the command they belong to
+	if response := commands.HandleHelp(msg, []string{"!J"}); response != expected {
+		t.Errorf("Expected alias to resolve to !join, got '%s'", response)
+	}
+
+	if response := commands.HandleHelp(msg, []string{"reserve"}); !strings.Contains(response, "| Permission: Moderators only |") {
+		t.Errorf("Expected mod-only permission, got '%s'", response)
+	}
+
+	if response := commands.HandleHelp(msg, []string{"nope"}); response != "No such command: !nope" {
+		t.Errorf("Expected unknown command response, got '%s'", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestHandleSkipTo(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_skipto")
+	commands.SetCommandManager(cm)
+	cm.GetQueue().Enable()
+
+	for _, user := range []string{"user1", "user2", "user3", "user4"} {
+		cm.GetQueue().Add(user, false)
+	}
+
+	msg := createMockMessage("moduser", "!skipto user3", true, false, false)
+	expected := "Skipped 2 users. user3 is now at the front of the queue."
+	if response := commands.HandleSkipTo(msg, []string{"USER3"}); response != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, response)
+	}
+	if position := cm.GetQueue().Position("user3"); position != 1 {
+		t.Errorf("Expected user3 at position 1, got %d", position)
+	}
+
+	// Positions work too
+	expected = "Skipped 1 user. user4 is now at the front of the queue."
+	if response := commands.HandleSkipTo(msg, []string{"2"}); response != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, response)
+	}
+
+	var skipped []string
+	for _, user := range cm.GetQueue().SessionSummary().Served {
+		if user.Skipped {
+			skipped = append(skipped, user.Username)
+		}
+	}
+	if strings.Join(skipped, ",") != "user1,user2,user3" {
+		t.Errorf("Expected user1, user2 and user3 recorded as skipped, got %v", skipped)
+	}
+
+	if response := commands.HandleSkipTo(msg, []string{"nobody"}); response != "nobody is not in the queue!" {
+		t.Errorf("Expected not in queue response, got '%s'", response)
+	}
+	if response := commands.HandleSkipTo(msg, []string{}); !strings.Contains(response, "!skipto <username>") {
+		t.Errorf("Expected usage, got '%s'", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestHandlePromoteSub(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_promotesub")
+	commands.SetCommandManager(cm)
+	cm.GetQueue().Enable()
+
+	modMsg := createMockMessage("moduser", "!promotesub", true, false, false)
+
+	// Users join with or without a sub badge
+	for _, viewer := range []struct {
+		name string
+		sub  bool
+	}{{"regular1", false}, {"regular2", false}, {"sub1", true}, {"regular3", false}, {"sub2", true}} {
+		msg := createMockMessage(viewer.name, "!join", false, false, false)
+		if viewer.sub {
+			msg.User.Badges["subscriber"] = 12
+		}
+		commands.HandleJoin(msg, []string{})
+	}
+
+	expected := "Promoted subscriber sub1 from position 3 to the front of the queue!"
+	if response := commands.HandlePromoteSub(modMsg, []string{}); response != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, response)
+	}
+	if position := cm.GetQueue().Position("sub1"); position != 1 {
+		t.Errorf("Expected sub1 at position 1, got %d", position)
+	}
+
+	// The front user is already the highest-positioned sub
+	if response := commands.HandlePromoteSub(modMsg, []string{}); response != "sub1 is already at the front of the queue." {
+		t.Errorf("Expected already-at-front response, got '%s'", response)
+	}
+
+	// Once sub1 is served, sub2 is next
+	cm.GetQueue().Pop()
+	expected = "Promoted subscriber sub2 from position 4 to the front of the queue!"
+	if response := commands.HandlePromoteSub(modMsg, []string{}); response != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, response)
+	}
+
+	cm.GetQueue().Pop()
+	if response := commands.HandlePromoteSub(modMsg, []string{}); response != "There are no subscribers in the queue." {
+		t.Errorf("Expected no subscribers response, got '%s'", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestCommandCount(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_command_count")
+	commands.SetCommandManager(cm)
+
+	cm.RegisterCommand(&commands.Command{Name: "ping", Handler: commands.HandlePing})
+	cm.RegisterCommand(&commands.Command{Name: "join", Aliases: []string{"j"}, Handler: commands.HandleJoin})
+	cm.RegisterCommand(&commands.Command{Name: "queue", Aliases: []string{"q", "list"}, Handler: commands.HandleQueue})
+
+	unique, withAliases := cm.CommandCount()
+	if unique != 3 || withAliases != 6 {
+		t.Errorf("Expected 3 commands (6 with aliases), got %d (%d)", unique, withAliases)
+	}
+
+	// The handler reports the counts as of the call, before it's registered itself
+	msg := createMockMessage("moduser", "!commandcount", true, false, false)
+	expected := "PBChatBot has 3 registered commands (6 including aliases)."
+	if response := commands.HandleCommandCount(msg, []string{}); response != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, response)
+	}
+
+	cm.RegisterCommand(&commands.Command{Name: "commandcount", Handler: commands.HandleCommandCount})
+	expected = "PBChatBot has 4 registered commands (7 including aliases)."
+	if response := commands.HandleCommandCount(msg, []string{}); response != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestHandleResetBot(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_reset")
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+	initialCount := len(cm.GetCommandList())
+
+	// Non-broadcasters cannot reset the bot
+	modMsg := createMockMessage("moduser", "!resetbot confirm", true, false, false)
+	response := commands.HandleResetBot(modMsg, []string{"confirm"})
+
+	if !strings.Contains(response, "only be used by the broadcaster") {
+		t.Errorf("Expected broadcaster-only message, got '%s'", response)
+	}
+
+	// Reset requires confirmation
+	msg := createMockMessage("testchannel_reset", "!resetbot", false, false, true)
+	response = commands.HandleResetBot(msg, []string{})
+
+	if !strings.Contains(response, "!resetbot confirm") {
+		t.Errorf("Expected confirmation prompt, got '%s'", response)
+	}
+
+	// Dirty the in-memory state: an extra command and a stale cooldown
+	cm.RegisterCommand(&commands.Command{
+		Name:        "extra",
+		Description: "Extra command",
+		Handler:     commands.HandlePing,
+	})
+	userMsg := createMockMessage("testuser", "!ping", false, false, false)
+	cm.GetCooldownManager().UpdateLastUsage("ping", userMsg)
+
+	if cm.GetCooldownManager().CheckCooldown("ping", userMsg) == 0 {
+		t.Fatal("Expected ping to be on cooldown before reset")
+	}
+
+	response = commands.HandleResetBot(msg, []string{"confirm"})
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+
+	expected := fmt.Sprintf("Bot state reset. Re-loaded %d commands, queue state loaded from disk.", initialCount)
+	if response != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, response)
+	}
+
+	if len(cm.GetCommandList()) != initialCount {
+		t.Errorf("Expected %d commands after reset, got %d", initialCount, len(cm.GetCommandList()))
+	}
+
+	if _, isCommand := cm.HandleMessage(createMockMessage("testuser", "!extra", false, false, false)); !isCommand {
+		t.Error("Expected !extra to still be treated as a command attempt")
+	}
+	if response, _ := cm.HandleMessage(createMockMessage("testuser", "!extra", false, false, false)); response != "" {
+		t.Errorf("Expected no response for removed command, got '%s'", response)
+	}
+
+	if cm.GetCooldownManager().CheckCooldown("ping", userMsg) != 0 {
+		t.Error("Expected ping cooldown to be cleared after reset")
+	}
+
+	// A reset leaves the queue enabled or disabled as it was
+	if cm.GetQueue().IsEnabled() {
+		t.Error("Expected a disabled queue to stay disabled after reset")
+	}
+	cm.GetQueue().Enable()
+	cm.GetQueue().Pause()
+	commands.HandleResetBot(msg, []string{"confirm"})
+	if !cm.GetQueue().IsEnabled() || !cm.GetQueue().IsPaused() {
+		t.Error("Expected an enabled, paused queue to stay that way after reset")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestHandleMoveBatch(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_move_batch")
+	commands.SetCommandManager(cm)
+	cm.GetQueue().Enable()
+
+	for _, user := range []string{"user1", "user2", "user3", "user4", "user5"} {
+		cm.GetQueue().Add(user, false)
+	}
+
+	msg := createMockMessage("moduser", "!movebatch", true, false, false)
+
+	response := commands.HandleMoveBatch(msg, []string{"1", "user4", "user2"})
+	if response != "Moved user4, user2 to positions 1-2" {
+		t.Errorf("Expected 'Moved user4, user2 to positions 1-2', got '%s'", response)
+	}
+	if users := cm.GetQueue().List(); strings.Join(users, ",") != "user4,user2,user1,user3,user5" {
+		t.Errorf("Expected [user4 user2 user1 user3 user5], got %v", users)
+	}
+
+	// The reported position reflects clamping to the end of the queue
+	response = commands.HandleMoveBatch(msg, []string{"9", "user4"})
+	if response != "user4 moved to position 5" {
+		t.Errorf("Expected 'user4 moved to position 5', got '%s'", response)
+	}
+
+	response = commands.HandleMoveBatch(msg, []string{"2", "user1", "ghost"})
+	if response != "Error moving users: not in queue: ghost" {
+		t.Errorf("Expected a missing user error, got '%s'", response)
+	}
+	response = commands.HandleMoveBatch(msg, []string{"user1", "user2"})
+	if !strings.Contains(response, "Invalid target position") {
+		t.Errorf("Expected an invalid position error, got '%s'", response)
+	}
+	response = commands.HandleMoveBatch(msg, []string{"2"})
+	if response != "Usage: !movebatch <position> <user1> [user2 ...]" {
+		t.Errorf("Expected usage, got '%s'", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestHandleMoveKeywords(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_move_keywords")
+	commands.SetCommandManager(cm)
+	cm.GetQueue().Enable()
+
+	// Add users
+	cm.GetQueue().Add("user1", false)
+	cm.GetQueue().Add("user2", false)
+	cm.GetQueue().Add("user3", false)
+
+	msg := createMockMessage("moduser", "!move", true, false, false)
+
+	// Test moving to the front
+	response := commands.HandleMove(msg, []string{"user3", "front"})
+	if !strings.Contains(response, "user3 moved to position 1") {
+		t.Errorf("Expected 'user3 moved to position 1', got '%s'", response)
+	}
+	if users := cm.GetQueue().List(); strings.Join(users, ",") != "user3,user1,user2" {
+		t.Errorf("Expected [user3 user1 user2], got %v", users)
+	}
+
+	// Test moving to the end
+	response = commands.HandleMove(msg, []string{"user3", "end"})
+	if !strings.Contains(response, "user3 moved to position 3") {
+		t.Errorf("Expected 'user3 moved to position 3', got '%s'", response)
+	}
+	if users := cm.GetQueue().List(); strings.Join(users, ",") != "user1,user2,user3" {
+		t.Errorf("Expected [user1 user2 user3], got %v", users)
+	}
+
+	// Test "back" as a synonym for end (case-insensitive)
+	response = commands.HandleMove(msg, []string{"user1", "BACK"})
+	if !strings.Contains(response, "user1 moved to position 3") {
+		t.Errorf("Expected 'user1 moved to position 3', got '%s'", response)
+	}
+	if users := cm.GetQueue().List(); strings.Join(users, ",") != "user2,user3,user1" {
+		t.Errorf("Expected [user2 user3 user1], got %v", users)
+	}
+
+	// Numeric targets still work
+	response = commands.HandleMove(msg, []string{"user1", "2"})
+	if !strings.Contains(response, "user1 moved to position 2") {
+		t.Errorf("Expected 'user1 moved to position 2', got '%s'", response)
+	}
+	if users := cm.GetQueue().List(); strings.Join(users, ",") != "user2,user1,user3" {
+		t.Errorf("Expected [user2 user1 user3], got %v", users)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestHandleJoinAfterServed(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_rejoin")
+	commands.SetCommandManager(cm)
+	cm.GetQueue().Enable()
+
+	// Fresh join produces the normal message
+	msg := createMockMessage("alice", "!join", false, false, false)
+	response := commands.HandleJoin(msg, []string{})
+
+	if response != "alice joined queue at position 1 (1 total)" {
+		t.Errorf("Expected 'alice joined queue at position 1 (1 total)', got '%s'", response)
+	}
+
+	// Serve alice, then have her rejoin behind another user
+	modMsg := createMockMessage("moduser", "!pop", true, false, false)
+	commands.HandlePop(modMsg, []string{})
+	cm.GetQueue().Add("bob", false)
+
+	response = commands.HandleJoin(msg, []string{})
+
+	if response != "Welcome back alice, joined at position 2 (you were served 0s ago)" {
+		t.Errorf("Expected annotated rejoin message, got '%s'", response)
+	}
+
+	// Users who were never served still get the normal message
+	response = commands.HandleJoin(createMockMessage("carol", "!join", false, false, false), []string{})
+
+	if response != "carol joined queue at position 3 (3 total)" {
+		t.Errorf("Expected 'carol joined queue at position 3 (3 total)', got '%s'", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestHandleUndoClear(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_undoclear")
+	commands.SetCommandManager(cm)
+	cm.GetQueue().Enable()
+
+	cm.GetQueue().Add("user1", false)
+	cm.GetQueue().Add("user2", false)
+
+	msg := createMockMessage("moduser", "!clearqueue", true, false, false)
+	response := commands.HandleClearQueue(msg, []string{})
+
+	if !strings.Contains(response, "!undoclear") {
+		t.Errorf("Expected undo hint, got '%s'", response)
+	}
+
+	cm.GetQueue().Add("user3", false)
+	response = commands.HandleUndoClear(msg, []string{})
+
+	if response != "Restored 2 user(s) from the last clear (3 total)" {
+		t.Errorf("Expected 'Restored 2 user(s) from the last clear (3 total)', got '%s'", response)
+	}
+
+	// Nothing left to restore
+	response = commands.HandleUndoClear(msg, []string{})
+
+	if !strings.Contains(response, "Nothing to restore") {
+		t.Errorf("Expected 'Nothing to restore', got '%s'", response)
+	}
+
+	// Expired window
+	cm.GetQueue().SetUndoClearWindow(50 * time.Millisecond)
+	commands.HandleClear(msg, []string{})
+	time.Sleep(100 * time.Millisecond)
+	response = commands.HandleUndoClear(msg, []string{})
+
+	if !strings.Contains(response, "Nothing to restore") {
+		t.Errorf("Expected 'Nothing to restore' after window, got '%s'", response)
+	}
+
+	// A window of 0 turns undo off
+	cm.GetQueue().SetUndoClearWindow(0)
+	cm.GetQueue().Add("user4", false)
+	if response := commands.HandleClear(msg, []string{}); strings.Contains(response, "!undoclear") {
+		t.Errorf("Expected no undo hint with undo disabled, got '%s'", response)
+	}
+	if response := commands.HandleUndoClear(msg, []string{}); response != "Undoing a clear is disabled for this channel." {
+		t.Errorf("Expected undo to be disabled, got '%s'", response)
+	}
+
+	// Only moderators can undo a clear
+	commands.RegisterBasicCommands(cm)
+	vip := createMockMessage("vipuser", "!undoclear", false, true, false)
+	if response, _ := cm.HandleMessage(vip); response != "This command can only be used by moderators." {
+		t.Errorf("Expected !undoclear to be refused for a VIP, got '%s'", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
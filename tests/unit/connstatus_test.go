@@ -0,0 +1,84 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+	twitchbot "github.com/pbuckles22/PBChatBot/internal/twitch"
+)
+
+// fakeConnStatus reports fixed channel states
+type fakeConnStatus []twitchbot.ChannelStatus
+
+func (f fakeConnStatus) ConnStatus() []twitchbot.ChannelStatus {
+	return f
+}
+
+func TestFormatConnStatus(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		statuses []twitchbot.ChannelStatus
+		expected string
+	}{
+		{
+			"mixed",
+			[]twitchbot.ChannelStatus{
+				{Channel: "ch1", Connected: true, LastMessage: now.Add(-5 * time.Second)},
+				{Channel: "ch2", ReconnectAttempts: 3},
+				{Channel: "ch3", Connected: true, LastMessage: now.Add(-2*time.Minute - 10*time.Second)},
+			},
+			"[ch1: ✓ 5s ago] [ch2: ✗ reconnecting (attempt 3)] [ch3: ✓ 2m ago]",
+		},
+		{
+			"quiet_channel",
+			[]twitchbot.ChannelStatus{{Channel: "ch1", Connected: true}},
+			"[ch1: ✓ no messages yet]",
+		},
+		{
+			"disconnected",
+			[]twitchbot.ChannelStatus{{Channel: "ch1", LastMessage: now.Add(-time.Hour)}},
+			"[ch1: ✗ disconnected]",
+		},
+		{
+			"no_channels",
+			nil,
+			"Not connected to any channels.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commands.FormatConnStatus(tt.statuses, now); got != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestHandleConnStatus(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_connstatus")
+	commands.SetCommandManager(cm)
+	commands.RegisterConnStatusCommand(cm, fakeConnStatus{
+		{Channel: "testchannel_connstatus", Connected: true},
+		{Channel: "relaytarget", ReconnectAttempts: 1},
+	})
+
+	response, _ := cm.HandleMessage(createMockMessage("moduser", "!connstatus", true, false, false))
+	if response != "This command can only be used by the broadcaster." {
+		t.Errorf("Expected broadcaster-only message, got '%s'", response)
+	}
+
+	response, _ = cm.HandleMessage(createMockMessage("testchannel_connstatus", "!connstatus", false, false, true))
+	expected := "[testchannel_connstatus: ✓ no messages yet] [relaytarget: ✗ reconnecting (attempt 1)]"
+	if response != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
@@ -0,0 +1,161 @@
+package unit
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	twitchirc "github.com/gempir/go-twitch-irc/v4"
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+func TestCooldownManagerConcurrency(t *testing.T) {
+	cooldowns := commands.NewCooldownManager()
+	cooldowns.SetCooldown("join", commands.CooldownConfig{
+		Regular: time.Hour,
+	})
+
+	const workers = 100
+	deadline := time.Now().Add(2 * time.Second)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			msg := createMockMessage(fmt.Sprintf("user%d", id), "!join", false, false, false)
+
+			// First use: no cooldown yet for this user
+			if remaining := cooldowns.CheckCooldown("join", msg); remaining != 0 {
+				errs <- fmt.Errorf("user%d: expected no cooldown before first use, got %v", id, remaining)
+				return
+			}
+			cooldowns.UpdateLastUsage("join", msg)
+
+			// Hammer the manager until the deadline; the user stays on cooldown
+			for time.Now().Before(deadline) {
+				if remaining := cooldowns.CheckCooldown("join", msg); remaining <= 0 {
+					errs <- fmt.Errorf("user%d: expected to be on cooldown after use", id)
+					return
+				}
+				cooldowns.UpdateLastUsage("join", msg)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// Each user's cooldown is tracked independently
+	unused := createMockMessage("neverused", "!join", false, false, false)
+	if remaining := cooldowns.CheckCooldown("join", unused); remaining != 0 {
+		t.Errorf("Expected no cooldown for a user who never ran the command, got %v", remaining)
+	}
+}
+
+func TestCooldownManagerConcurrentSetCooldown(t *testing.T) {
+	cooldowns := commands.NewCooldownManager()
+
+	const workers = 10
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			cooldowns.SetCooldown("pop", commands.CooldownConfig{
+				Regular: time.Duration(id+1) * time.Second,
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	// The config map holds exactly one of the written values
+	msg := createMockMessage("testuser", "!pop", false, false, false)
+	cooldowns.UpdateLastUsage("pop", msg)
+	remaining := cooldowns.CheckCooldown("pop", msg)
+	if remaining <= 0 || remaining > time.Duration(workers)*time.Second {
+		t.Errorf("Expected remaining cooldown between 0 and %ds, got %v", workers, remaining)
+	}
+}
+
+func TestCooldownManagerResetCommand(t *testing.T) {
+	cooldowns := commands.NewCooldownManager()
+	cooldowns.SetCooldown("join", commands.CooldownConfig{Regular: time.Hour})
+	alice := createMockMessage("alice", "!join", false, false, false)
+	bob := createMockMessage("bob", "!join", false, false, false)
+	cooldowns.UpdateLastUsage("join", alice)
+	cooldowns.UpdateLastUsage("join", bob)
+
+	if !cooldowns.ResetCommand("join", "ALICE") {
+		t.Fatal("Expected alice's cooldown to be cleared")
+	}
+	if remaining := cooldowns.CheckCooldown("join", alice); remaining != 0 {
+		t.Errorf("Expected no cooldown for alice after reset, got %v", remaining)
+	}
+	if remaining := cooldowns.CheckCooldown("join", bob); remaining == 0 {
+		t.Error("Expected bob to stay on cooldown")
+	}
+	if cooldowns.ResetCommand("join", "alice") {
+		t.Error("Expected a second reset to find nothing to clear")
+	}
+}
+
+func TestHandleResetCooldown(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_resetcooldown")
+	commands.SetCommandManager(cm)
+	cm.RegisterCommand(&commands.Command{
+		Name:        "test",
+		Aliases:     []string{"t"},
+		Description: "A command with a long cooldown",
+		Handler: func(message twitchirc.PrivateMessage, args []string) string {
+			return "ran"
+		},
+		Cooldown: commands.CooldownConfig{Regular: time.Hour, Mod: time.Hour},
+	})
+
+	// Running the command puts both users on cooldown
+	viewer := createMockMessage("viewer", "!test", false, false, false)
+	mod := createMockMessage("moduser", "!test", true, false, false)
+	for _, msg := range []twitchirc.PrivateMessage{viewer, mod} {
+		if response, _ := cm.HandleMessage(msg); response != "ran" {
+			t.Fatalf("Expected %s's first use to run, got %q", msg.User.Name, response)
+		}
+	}
+	if response, _ := cm.HandleMessage(mod); response == "ran" {
+		t.Fatal("Expected the mod to be on cooldown")
+	}
+
+	// Resetting defaults to the caller, and aliases resolve to the command
+	response := commands.HandleResetCooldown(mod, []string{"!t"})
+	if response != "Reset the !test cooldown for moduser." {
+		t.Errorf("Unexpected response: %q", response)
+	}
+	if response, _ := cm.HandleMessage(mod); response != "ran" {
+		t.Errorf("Expected the mod's next use not to be throttled, got %q", response)
+	}
+	if response, _ := cm.HandleMessage(viewer); response == "ran" {
+		t.Error("Expected the viewer to stay on cooldown")
+	}
+
+	response = commands.HandleResetCooldown(mod, []string{"test", "@viewer"})
+	if response != "Reset the !test cooldown for viewer." {
+		t.Errorf("Unexpected response: %q", response)
+	}
+	if response, _ := cm.HandleMessage(viewer); response != "ran" {
+		t.Errorf("Expected the viewer's next use not to be throttled, got %q", response)
+	}
+
+	if response := commands.HandleResetCooldown(mod, []string{"nosuchcommand"}); response != "Unknown command: !nosuchcommand" {
+		t.Errorf("Unexpected response for an unknown command: %q", response)
+	}
+	if response := commands.HandleResetCooldown(mod, []string{"test", "nobody"}); response != "nobody has no !test cooldown to reset." {
+		t.Errorf("Unexpected response for a user without a cooldown: %q", response)
+	}
+}
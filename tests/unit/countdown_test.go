@@ -0,0 +1,209 @@
+package unit
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+// fakeCountdownClock runs scheduled callbacks when advanced
+type fakeCountdownClock struct {
+	mu      sync.Mutex
+	now     time.Duration
+	pending []*fakeCountdownTimer
+}
+
+type fakeCountdownTimer struct {
+	at      time.Duration
+	f       func()
+	stopped bool
+	fired   bool
+}
+
+func (t *fakeCountdownTimer) Stop() bool {
+	wasPending := !t.stopped && !t.fired
+	t.stopped = true
+	return wasPending
+}
+
+func (c *fakeCountdownClock) AfterFunc(d time.Duration, f func()) commands.CountdownStopper {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeCountdownTimer{at: c.now + d, f: f}
+	c.pending = append(c.pending, t)
+	return t
+}
+
+// Advance moves the clock forward, running due callbacks in time order.
+// The clock reads each callback's due time while it runs, so callbacks
+// that schedule further ones (and those ones, if due) run too.
+func (c *fakeCountdownClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now + d
+	for {
+		var next *fakeCountdownTimer
+		for _, t := range c.pending {
+			if !t.stopped && !t.fired && t.at <= end && (next == nil || t.at < next.at) {
+				next = t
+			}
+		}
+		if next == nil {
+			break
+		}
+		next.fired = true
+		c.now = next.at
+		c.mu.Unlock()
+		next.f()
+		c.mu.Lock()
+	}
+	c.now = end
+	c.mu.Unlock()
+}
+
+// scheduled returns how many callbacks are waiting to run
+func (c *fakeCountdownClock) scheduled() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	count := 0
+	for _, t := range c.pending {
+		if !t.stopped && !t.fired {
+			count++
+		}
+	}
+	return count
+}
+
+// countdownRecorder records sent messages
+type countdownRecorder struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+func (r *countdownRecorder) send(message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, message)
+}
+
+func (r *countdownRecorder) messages() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.sent...)
+}
+
+func TestCountdownTimerAnnouncements(t *testing.T) {
+	clock := &fakeCountdownClock{}
+	recorder := &countdownRecorder{}
+	countdown := commands.NewCountdownTimer(clock, recorder.send)
+
+	if err := countdown.Start(5*time.Minute, "Game starting!"); err != nil {
+		t.Fatalf("Failed to start countdown: %v", err)
+	}
+
+	steps := []struct {
+		advance  time.Duration
+		expected []string
+	}{
+		{2*time.Minute - time.Second, nil},
+		{time.Second, []string{"3m remaining!"}},
+		{2 * time.Minute, []string{"3m remaining!", "1m remaining!"}},
+		{30 * time.Second, []string{"3m remaining!", "1m remaining!", "30s remaining!"}},
+		{20 * time.Second, []string{"3m remaining!", "1m remaining!", "30s remaining!", "10s remaining!"}},
+		{10 * time.Second, []string{"3m remaining!", "1m remaining!", "30s remaining!", "10s remaining!", "Game starting!"}},
+	}
+	for i, step := range steps {
+		clock.Advance(step.advance)
+		if got := recorder.messages(); !reflect.DeepEqual(got, step.expected) {
+			t.Fatalf("Step %d: expected %v, got %v", i, step.expected, got)
+		}
+	}
+
+	if countdown.IsActive() {
+		t.Error("Countdown should be finished after the final announcement")
+	}
+}
+
+func TestCountdownTimerShortDuration(t *testing.T) {
+	clock := &fakeCountdownClock{}
+	recorder := &countdownRecorder{}
+	countdown := commands.NewCountdownTimer(clock, recorder.send)
+
+	if err := countdown.Start(45*time.Second, ""); err != nil {
+		t.Fatalf("Failed to start countdown: %v", err)
+	}
+	clock.Advance(45 * time.Second)
+
+	expected := []string{"30s remaining!", "10s remaining!", "Time's up!"}
+	if got := recorder.messages(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestCountdownTimerCancel(t *testing.T) {
+	clock := &fakeCountdownClock{}
+	recorder := &countdownRecorder{}
+	countdown := commands.NewCountdownTimer(clock, recorder.send)
+
+	if err := countdown.Start(time.Minute, "Go!"); err != nil {
+		t.Fatalf("Failed to start countdown: %v", err)
+	}
+	if err := countdown.Start(time.Minute, "Again"); err != commands.ErrCountdownRunning {
+		t.Errorf("Expected ErrCountdownRunning, got %v", err)
+	}
+
+	clock.Advance(30 * time.Second)
+	if !countdown.Cancel() {
+		t.Fatal("Expected cancel to stop the running countdown")
+	}
+	clock.Advance(time.Minute)
+
+	expected := []string{"30s remaining!"}
+	if got := recorder.messages(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected no announcements after cancel, got %v", got)
+	}
+	if countdown.Cancel() {
+		t.Error("Cancel should report false when nothing is running")
+	}
+
+	// A new countdown can start once the previous one is cancelled
+	if err := countdown.Start(10*time.Second, "Go!"); err != nil {
+		t.Errorf("Expected new countdown to start, got %v", err)
+	}
+}
+
+func TestHandleCountdown(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_countdown")
+	commands.SetCommandManager(cm)
+
+	clock := &fakeCountdownClock{}
+	recorder := &countdownRecorder{}
+	cm.SetCountdownTimer(commands.NewCountdownTimer(clock, recorder.send))
+
+	msg := createMockMessage("moduser", "!countdown 5m Game starting!", true, false, false)
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{}, "Usage: !countdown <duration> [message] or !countdown cancel"},
+		{[]string{"soon"}, "Invalid duration: soon (use e.g. 90s or 5m)"},
+		{[]string{"2h"}, "Countdowns can be at most 60m."},
+		{[]string{"cancel"}, "No countdown is running."},
+		{[]string{"5m", "Game", "starting!"}, "Countdown started: 5m until Game starting!"},
+		{[]string{"1m"}, "A countdown is already running. Use !countdown cancel first."},
+		{[]string{"cancel"}, "Countdown cancelled."},
+		{[]string{"90s"}, "Countdown started: 1m30s!"},
+	}
+	for _, tt := range tests {
+		if response := commands.HandleCountdown(msg, tt.args); response != tt.expected {
+			t.Errorf("Args %v: expected '%s', got '%s'", tt.args, tt.expected, response)
+		}
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
@@ -0,0 +1,67 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	channelstats "github.com/pbuckles22/PBChatBot/internal/channel"
+	"github.com/pbuckles22/PBChatBot/internal/queue"
+	"github.com/pbuckles22/PBChatBot/internal/utils"
+)
+
+// unwritableDataPath returns a data path nested under a regular file, so it
+// can't be created even when tests run as root
+func unwritableDataPath(t *testing.T, channel string) string {
+	blocker := filepath.Join(t.TempDir(), "not_a_dir")
+	if err := os.WriteFile(blocker, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create blocker file: %v", err)
+	}
+	return filepath.Join(blocker, channel)
+}
+
+func TestQueueDataPathFallback(t *testing.T) {
+	channel := "testchannel_fallback_queue"
+	dataPath := unwritableDataPath(t, channel)
+	expected := filepath.Join(os.TempDir(), utils.FallbackDataRoot, channel)
+	defer os.RemoveAll(expected)
+
+	q := queue.NewQueue(dataPath, channel)
+	if q.GetDataPath() != expected {
+		t.Fatalf("Expected fallback data path %s, got %s", expected, q.GetDataPath())
+	}
+
+	q.Enable()
+	q.Add("user1", false)
+	time.Sleep(100 * time.Millisecond)
+	if err := q.SaveState(); err != nil {
+		t.Fatalf("Expected state to save to fallback path, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(expected, "queue_state_"+channel+".json")); err != nil {
+		t.Errorf("Expected queue state file in fallback path: %v", err)
+	}
+}
+
+func TestChannelStatsDataPathFallback(t *testing.T) {
+	channel := "testchannel_fallback_stats"
+	dataPath := unwritableDataPath(t, channel)
+	expected := filepath.Join(os.TempDir(), utils.FallbackDataRoot, channel)
+	defer os.RemoveAll(expected)
+
+	stats := channelstats.NewChannelStats(dataPath)
+	if !strings.HasPrefix(stats.GetStatsPath(), expected) {
+		t.Fatalf("Expected stats path under %s, got %s", expected, stats.GetStatsPath())
+	}
+	if err := stats.Save(); err != nil {
+		t.Fatalf("Expected stats to save to fallback path, got %v", err)
+	}
+}
+
+func TestWritableDataPathUnchanged(t *testing.T) {
+	dataPath := t.TempDir()
+	if got := utils.EnsureWritableDataPath(dataPath); got != dataPath {
+		t.Errorf("Expected writable data path to be kept, got %s", got)
+	}
+}
@@ -0,0 +1,45 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+func TestHandleEcho(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_echo")
+	commands.SetCommandManager(cm)
+	cm.GetConfig().BotName = "PerfTiltBot"
+
+	tests := []struct {
+		name          string
+		message       string
+		isBroadcaster bool
+		expected      string
+	}{
+		{"normal_echo", "!echo Hello chat Kappa", true, "Hello chat Kappa"},
+		{"verbatim_spacing", "!echo   spaced    out   text", true, "spaced    out   text"},
+		{"mention_bot", "!echo @perftiltbot, are you there?", true, "@perftiltbot, are you there?"},
+		{"mention_other_user", "!echo @someviewer you won!", true, "Echo text can't start with @ followed by a username other than the bot."},
+		{"mention_bot_prefix", "!echo @PerfTiltBotFake hi", true, "Echo text can't start with @ followed by a username other than the bot."},
+		{"mention_later_allowed", "!echo GG @someviewer", true, "GG @someviewer"},
+		{"at_limit", "!echo " + strings.Repeat("a", 450), true, strings.Repeat("a", 450)},
+		{"too_long", "!echo " + strings.Repeat("a", 451), true, "Echo text is too long (451/450 characters)."},
+		{"no_text", "!echo", true, "Usage: !echo <text>"},
+		{"not_broadcaster", "!echo Hello", false, "This command can only be used by the broadcaster."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := createMockMessage("testuser", tt.message, false, false, tt.isBroadcaster)
+			response := commands.HandleEcho(msg, strings.Fields(tt.message)[1:])
+			if response != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, response)
+			}
+		})
+	}
+}
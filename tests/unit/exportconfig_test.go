@@ -0,0 +1,81 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+	"github.com/pbuckles22/PBChatBot/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+func TestHandleExportConfig(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_export")
+	commands.SetCommandManager(cm)
+
+	cfg := &config.Config{
+		BotName:      "testbot",
+		Channel:      "testchannel_export",
+		DataPath:     tempDir,
+		Timezone:     "America/New_York",
+		OAuth:        "oauth:supersecret",
+		ClientSecret: "clientsecret123",
+	}
+	cfg.Commands.Queue.MaxSize = 42
+	cm.SetConfig(cfg)
+
+	// Non-broadcasters cannot export
+	modMsg := createMockMessage("moduser", "!exportconfig", true, false, false)
+	response := commands.HandleExportConfig(modMsg, []string{})
+
+	if !strings.Contains(response, "only be used by the broadcaster") {
+		t.Errorf("Expected broadcaster-only message, got '%s'", response)
+	}
+
+	msg := createMockMessage("testchannel_export", "!exportconfig", false, false, true)
+	response = commands.HandleExportConfig(msg, []string{})
+
+	if !strings.HasPrefix(response, "Config exported to config_export_testchannel_export_") {
+		t.Fatalf("Expected export confirmation, got '%s'", response)
+	}
+
+	// Verify the file was written
+	matches, err := filepath.Glob(filepath.Join(tempDir, "config_export_testchannel_export_*.yaml"))
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("Expected one export file, got %v (err: %v)", matches, err)
+	}
+
+	data, err := os.ReadFile(matches[0])
+	if err != nil {
+		t.Fatalf("Failed to read export file: %v", err)
+	}
+
+	// Verify sensitive fields are redacted
+	if strings.Contains(string(data), "supersecret") || strings.Contains(string(data), "clientsecret123") {
+		t.Errorf("Export should not contain secrets, got:\n%s", data)
+	}
+
+	// Verify the export is valid YAML
+	var exported config.Config
+	if err := yaml.Unmarshal(data, &exported); err != nil {
+		t.Fatalf("Export should be valid YAML: %v", err)
+	}
+
+	if exported.OAuth != config.RedactedValue || exported.ClientSecret != config.RedactedValue {
+		t.Errorf("Expected secrets to be '%s', got oauth='%s' client_secret='%s'",
+			config.RedactedValue, exported.OAuth, exported.ClientSecret)
+	}
+	if exported.Channel != "testchannel_export" || exported.Commands.Queue.MaxSize != 42 {
+		t.Errorf("Expected non-sensitive values to be exported, got %+v", exported)
+	}
+
+	// The in-memory config keeps its secrets
+	if cm.GetConfig().OAuth != "oauth:supersecret" {
+		t.Error("Export should not modify the in-memory config")
+	}
+}
@@ -0,0 +1,70 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+// fakeFollowChecker returns canned follow ages and counts lookups
+type fakeFollowChecker struct {
+	ages    map[string]time.Duration
+	lookups int
+}
+
+func (f *fakeFollowChecker) FollowAge(username string) (time.Duration, bool, error) {
+	f.lookups++
+	age, ok := f.ages[username]
+	return age, ok, nil
+}
+
+func TestHandleJoinMinFollowDays(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_followage")
+	commands.SetCommandManager(cm)
+	cm.GetQueue().Enable()
+	cm.GetConfig().Commands.Queue.MinFollowDays = 7
+
+	day := 24 * time.Hour
+	checker := &fakeFollowChecker{ages: map[string]time.Duration{
+		"veteran": 7*day + time.Hour,
+		"newbie":  7*day - time.Hour,
+	}}
+	cm.SetFollowChecker(checker)
+
+	tests := []struct {
+		name     string
+		user     string
+		isMod    bool
+		expected string
+	}{
+		{"just_inside_threshold", "veteran", false, "veteran joined queue at position 1"},
+		{"just_outside_threshold", "newbie", false, "newbie, you must follow for at least 7 days to join the queue (following for 6 days)."},
+		{"not_following", "stranger", false, "stranger, you must follow the channel for at least 7 days to join the queue."},
+		{"mod_bypass", "modnotfollowing", true, "modnotfollowing joined queue at position 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := createMockMessage(tt.user, "!join", tt.isMod, false, false)
+			response := commands.HandleJoin(msg, []string{})
+			if !strings.HasPrefix(response, tt.expected) {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, response)
+			}
+		})
+	}
+
+	// Repeat attempts within the cache window reuse the earlier lookup
+	lookups := checker.lookups
+	commands.HandleJoin(createMockMessage("newbie", "!join", false, false, false), []string{})
+	if checker.lookups != lookups {
+		t.Errorf("Expected cached follow age to be reused, got %d lookups (was %d)", checker.lookups, lookups)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
@@ -0,0 +1,160 @@
+package unit
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+// fakeGiveawayClock is a manually advanced clock
+type fakeGiveawayClock struct {
+	now time.Time
+}
+
+func (c *fakeGiveawayClock) Now() time.Time {
+	return c.now
+}
+
+func TestGiveawayEntries(t *testing.T) {
+	clock := &fakeGiveawayClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	g := commands.NewGiveawayManager(clock, rand.New(rand.NewSource(1)))
+
+	if err := g.Enter("user1", false); !errors.Is(err, commands.ErrNoGiveaway) {
+		t.Errorf("Expected ErrNoGiveaway before starting, got %v", err)
+	}
+
+	if err := g.Start(false); err != nil {
+		t.Fatalf("Failed to start giveaway: %v", err)
+	}
+	if err := g.Start(false); !errors.Is(err, commands.ErrGiveawayRunning) {
+		t.Errorf("Expected ErrGiveawayRunning, got %v", err)
+	}
+
+	if err := g.Enter("user1", false); err != nil {
+		t.Errorf("Unexpected entry error: %v", err)
+	}
+	clock.now = clock.now.Add(time.Minute)
+	if err := g.Enter("USER1", false); !errors.Is(err, commands.ErrAlreadyEntered) {
+		t.Errorf("Expected duplicate entry to be rejected, got %v", err)
+	}
+	if err := g.Enter("user2", false); err != nil {
+		t.Errorf("Unexpected entry error: %v", err)
+	}
+
+	entries := g.Entries()
+	if len(entries) != 2 || entries[0].Username != "user1" || entries[1].Username != "user2" {
+		t.Fatalf("Expected entries user1, user2, got %+v", entries)
+	}
+	if !entries[1].EnteredAt.Equal(entries[0].EnteredAt.Add(time.Minute)) {
+		t.Errorf("Expected entry times to be tracked, got %+v", entries)
+	}
+
+	// Entries close when the window ends
+	clock.now = clock.now.Add(commands.DefaultGiveawayWindow)
+	if err := g.Enter("late", false); !errors.Is(err, commands.ErrGiveawayClosed) {
+		t.Errorf("Expected ErrGiveawayClosed after the window, got %v", err)
+	}
+}
+
+func TestGiveawaySubOnly(t *testing.T) {
+	g := commands.NewGiveawayManager(nil, nil)
+	if err := g.Start(true); err != nil {
+		t.Fatalf("Failed to start giveaway: %v", err)
+	}
+
+	if err := g.Enter("viewer", false); !errors.Is(err, commands.ErrGiveawaySubOnly) {
+		t.Errorf("Expected non-subscriber to be rejected, got %v", err)
+	}
+	if err := g.Enter("subscriber", true); err != nil {
+		t.Errorf("Unexpected entry error: %v", err)
+	}
+
+	winner, entries, err := g.End()
+	if err != nil || winner != "subscriber" || entries != 1 {
+		t.Errorf("Expected subscriber to win the only entry, got %s (%d entries, %v)", winner, entries, err)
+	}
+}
+
+func TestGiveawayPickAndRepick(t *testing.T) {
+	g := commands.NewGiveawayManager(nil, rand.New(rand.NewSource(42)))
+	if _, err := g.Pick(); !errors.Is(err, commands.ErrNoGiveaway) {
+		t.Errorf("Expected ErrNoGiveaway before starting, got %v", err)
+	}
+
+	g.Start(false)
+	users := []string{"alice", "bob", "carol"}
+	for _, user := range users {
+		g.Enter(user, false)
+	}
+
+	first, entries, err := g.End()
+	if err != nil || entries != 3 {
+		t.Fatalf("Unexpected end result: %s (%d entries, %v)", first, entries, err)
+	}
+	if err := g.Enter("late", false); !errors.Is(err, commands.ErrGiveawayClosed) {
+		t.Errorf("Expected entries to close when the giveaway ends, got %v", err)
+	}
+
+	// Re-picks never repeat a winner
+	drawn := map[string]bool{first: true}
+	for i := 0; i < 2; i++ {
+		winner, err := g.Pick()
+		if err != nil {
+			t.Fatalf("Unexpected pick error: %v", err)
+		}
+		if drawn[winner] {
+			t.Errorf("Re-pick drew %s again", winner)
+		}
+		drawn[winner] = true
+	}
+	for _, user := range users {
+		if !drawn[user] {
+			t.Errorf("Expected %s to have been drawn, got %v", user, drawn)
+		}
+	}
+	if _, err := g.Pick(); !errors.Is(err, commands.ErrNoEntries) {
+		t.Errorf("Expected ErrNoEntries once everyone was drawn, got %v", err)
+	}
+}
+
+func TestHandleGiveaway(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_giveaway")
+	commands.SetCommandManager(cm)
+
+	mod := createMockMessage("moduser", "!giveaway", true, false, false)
+	if response := commands.HandleGiveaway(mod, []string{"start", "sub-only"}); response != "Sub-only giveaway started! Subscribers, type !enter in the next 5m to enter." {
+		t.Errorf("Unexpected start response: '%s'", response)
+	}
+
+	viewer := createMockMessage("viewer", "!enter", false, false, false)
+	if response := commands.HandleEnter(viewer, nil); response != "@viewer, the giveaway is for subscribers only." {
+		t.Errorf("Expected sub-only rejection, got '%s'", response)
+	}
+	sub := createMockMessage("subuser", "!enter", false, false, false)
+	sub.User.Badges["subscriber"] = 1
+	if response := commands.HandleEnter(sub, nil); response != "" {
+		t.Errorf("Expected a quiet entry, got '%s'", response)
+	}
+	if response := commands.HandleEnter(sub, nil); response != "@subuser, you're already entered." {
+		t.Errorf("Expected duplicate rejection, got '%s'", response)
+	}
+
+	if response := commands.HandleGiveaway(mod, []string{"end"}); response != "Giveaway closed (1 entered). The winner is @subuser!" {
+		t.Errorf("Unexpected end response: '%s'", response)
+	}
+	if response := commands.HandleGiveaway(mod, []string{"pick"}); response != "No eligible entries left to pick from." {
+		t.Errorf("Unexpected pick response: '%s'", response)
+	}
+	if response := commands.HandleGiveaway(mod, []string{"bogus"}); !strings.HasPrefix(response, "Usage:") {
+		t.Errorf("Expected usage response, got '%s'", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
@@ -0,0 +1,112 @@
+package unit
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+// fakeHypeSleeper records the requested delays instead of sleeping
+type fakeHypeSleeper struct {
+	mu     sync.Mutex
+	delays []time.Duration
+}
+
+func (s *fakeHypeSleeper) sleep(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delays = append(s.delays, d)
+}
+
+func TestHypeTrainSequence(t *testing.T) {
+	sleeper := &fakeHypeSleeper{}
+	recorder := &countdownRecorder{}
+	hype := commands.NewHypeTrain(sleeper.sleep, recorder.send)
+
+	messages := []string{"PogChamp PogChamp PogChamp", "GET HYPED!", "Let's GO! 🚀"}
+	if err := hype.Start(messages, 2*time.Second); err != nil {
+		t.Fatalf("Failed to start hype train: %v", err)
+	}
+	hype.Wait()
+
+	if got := recorder.messages(); !reflect.DeepEqual(got, messages) {
+		t.Errorf("Expected %v, got %v", messages, got)
+	}
+	if expected := []time.Duration{2 * time.Second, 2 * time.Second}; !reflect.DeepEqual(sleeper.delays, expected) {
+		t.Errorf("Expected delays %v between messages, got %v", expected, sleeper.delays)
+	}
+	if hype.IsRunning() {
+		t.Error("Expected hype train to finish")
+	}
+
+	if err := hype.Start(nil, time.Second); !errors.Is(err, commands.ErrNoHypeMessages) {
+		t.Errorf("Expected ErrNoHypeMessages, got %v", err)
+	}
+}
+
+func TestHypeTrainCancel(t *testing.T) {
+	// Block in the first delay until the test has cancelled
+	slept := make(chan time.Duration)
+	release := make(chan struct{})
+	recorder := &countdownRecorder{}
+	hype := commands.NewHypeTrain(func(d time.Duration) {
+		slept <- d
+		<-release
+	}, recorder.send)
+
+	hype.Start([]string{"first", "second", "third"}, time.Second)
+	<-slept
+
+	if err := hype.Start([]string{"other"}, time.Second); !errors.Is(err, commands.ErrHypeRunning) {
+		t.Errorf("Expected ErrHypeRunning, got %v", err)
+	}
+	if !hype.Cancel() {
+		t.Fatal("Expected cancel to stop the running hype train")
+	}
+	close(release)
+	hype.Wait()
+
+	if got := recorder.messages(); !reflect.DeepEqual(got, []string{"first"}) {
+		t.Errorf("Expected only the first message before cancel, got %v", got)
+	}
+	if hype.Cancel() {
+		t.Error("Expected nothing to cancel")
+	}
+}
+
+func TestHandleHype(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_hype")
+	commands.SetCommandManager(cm)
+
+	sleeper := &fakeHypeSleeper{}
+	recorder := &countdownRecorder{}
+	hype := commands.NewHypeTrain(sleeper.sleep, recorder.send)
+	cm.SetHypeTrain(hype)
+	cm.GetConfig().HypeMessages = []string{"Hype!", "More hype!"}
+	cm.GetConfig().HypeDelay = 5
+
+	mod := createMockMessage("moduser", "!hype", true, false, false)
+	if response := commands.HandleHype(mod, nil); response != "" {
+		t.Errorf("Expected no direct response, got '%s'", response)
+	}
+	hype.Wait()
+	if got := recorder.messages(); !reflect.DeepEqual(got, []string{"Hype!", "More hype!"}) {
+		t.Errorf("Unexpected hype messages: %v", got)
+	}
+	if !reflect.DeepEqual(sleeper.delays, []time.Duration{5 * time.Second}) {
+		t.Errorf("Expected a 5s delay, got %v", sleeper.delays)
+	}
+
+	if response := commands.HandleHype(mod, []string{"cancel"}); response != "No hype train is running." {
+		t.Errorf("Unexpected cancel response: '%s'", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
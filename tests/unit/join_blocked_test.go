@@ -0,0 +1,125 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+func TestJoinBlockedMessages(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_join_blocked")
+	commands.SetCommandManager(cm)
+	q := cm.GetQueue()
+	join := createMockMessage("viewer", "!join", false, false, false)
+
+	seen := make(map[string]string)
+	check := func(reason, expected string) {
+		t.Helper()
+		response := commands.HandleJoin(join, nil)
+		if response != expected {
+			t.Errorf("Expected the %s message %q, got %q", reason, expected, response)
+		}
+		if other, ok := seen[response]; ok {
+			t.Errorf("The %s and %s blocks share the message %q", reason, other, response)
+		}
+		seen[response] = reason
+	}
+
+	check("disabled", "@viewer the queue is disabled right now, so there is nothing to join.")
+
+	q.Enable()
+	q.Pause()
+	check("paused", "@viewer the queue is paused, so nobody can join until it resumes.")
+	q.Unpause()
+
+	q.SetMaxSize(1)
+	q.Add("user1", false)
+	check("full", "@viewer the queue is full. Try again when a spot opens.")
+	q.SetMaxSize(0)
+
+	q.Ban("viewer")
+	check("banned", "@viewer you're barred from joining the queue.")
+	q.Unban("viewer")
+
+	q.SetRejoinCooldown(time.Minute)
+	q.Add("viewer", false)
+	q.Remove("user1")
+	q.Pop()
+	check("cooldown", "@viewer you were served recently and can rejoin in 1m0s.")
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestJoinBlockedMessagesConfigured(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_join_blocked_config")
+	commands.SetCommandManager(cm)
+	cm.GetConfig().Commands.Queue.JoinBlockedMessages = map[string]string{
+		commands.JoinBlockedPaused: "{user}, hold on! Joins reopen after this round.",
+	}
+	cm.GetQueue().Enable()
+	cm.GetQueue().Pause()
+
+	join := createMockMessage("viewer", "!join", false, false, false)
+	if response := commands.HandleJoin(join, nil); response != "viewer, hold on! Joins reopen after this round." {
+		t.Errorf("Expected the configured paused message, got %q", response)
+	}
+
+	// Reasons without a configured message fall back to the default
+	cm.GetQueue().Disable()
+	if response := commands.HandleJoin(join, nil); response != "@viewer the queue is disabled right now, so there is nothing to join." {
+		t.Errorf("Expected the default disabled message, got %q", response)
+	}
+}
+
+func TestPauseReason(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_pause_reason")
+	commands.SetCommandManager(cm)
+	cm.GetQueue().Enable()
+
+	mod := createMockMessage("moduser", "!pausequeue", true, false, false)
+	join := createMockMessage("viewer", "!join", false, false, false)
+
+	// The reason is announced when pausing
+	response := commands.HandlePause(mod, []string{"taking", "a", "short", "break"})
+	if response != "Queue is now paused: taking a short break. No new entries can be added until the queue is unpaused." {
+		t.Errorf("Expected the reason in the pause reply, got %q", response)
+	}
+
+	// and shown to anyone who tries to join
+	if response := commands.HandleJoin(join, nil); response != "@viewer the queue is paused, so nobody can join until it resumes. Reason: taking a short break" {
+		t.Errorf("Expected the reason in the blocked join reply, got %q", response)
+	}
+
+	// A configured message can place the reason itself
+	cm.GetConfig().Commands.Queue.JoinBlockedMessages = map[string]string{
+		commands.JoinBlockedPaused: "{user}, joins are closed ({reason}).",
+	}
+	if response := commands.HandleJoin(join, nil); response != "viewer, joins are closed (taking a short break)." {
+		t.Errorf("Expected the reason in the configured message, got %q", response)
+	}
+	cm.GetConfig().Commands.Queue.JoinBlockedMessages = nil
+
+	// Unpausing clears the reason
+	commands.HandleUnpause(mod, nil)
+	if reason := cm.GetQueue().PauseReason(); reason != "" {
+		t.Errorf("Expected the reason to be cleared on unpause, got %q", reason)
+	}
+	cm.GetQueue().Pause()
+	if response := commands.HandleJoin(join, nil); response != "@viewer the queue is paused, so nobody can join until it resumes." {
+		t.Errorf("Expected no reason after a plain pause, got %q", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
@@ -0,0 +1,44 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	channelstats "github.com/pbuckles22/PBChatBot/internal/channel"
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+func TestLastStatsCommand(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_laststats")
+	commands.SetCommandManager(cm)
+
+	stats := channelstats.NewChannelStats(tempDir)
+	commands.RegisterLastStatsCommand(cm, stats)
+
+	msg := createMockMessage("testuser", "!laststats", false, false, false)
+
+	// No history yet
+	if response, _ := cm.HandleMessage(msg); response != "No completed sessions yet." {
+		t.Errorf("Expected the no-history reply, got %q", response)
+	}
+
+	// Seed a completed session
+	start := time.Date(2026, time.October, 15, 18, 0, 0, 0, time.Local)
+	stats.Sessions = append(stats.Sessions, channelstats.StreamSession{
+		StartTime:      start,
+		EndTime:        start.Add(3*time.Hour + 12*time.Minute),
+		Duration:       3*time.Hour + 12*time.Minute,
+		PeakViewers:    42,
+		ChatMessages:   1234,
+		UniqueChatters: 87,
+	})
+
+	expected := "Last stream (Oct 15): 3h12m long, peak 42 viewers, 1234 chat messages from 87 unique chatters."
+	// Another viewer asks, since testuser's !laststats is now on cooldown
+	if response, _ := cm.HandleMessage(createMockMessage("otheruser", "!laststats", false, false, false)); response != expected {
+		t.Errorf("Expected %q, got %q", expected, response)
+	}
+}
@@ -0,0 +1,108 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+func TestServedAnnouncement(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_lobby")
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+	cm.GetQueue().Enable()
+	for _, user := range []string{"alice", "bob", "carol"} {
+		cm.GetQueue().Add(user, false)
+	}
+
+	mod := createMockMessage("moduser", "!pop", true, false, false)
+
+	// Without a template the plain reply is kept
+	if response := commands.HandlePop(mod, nil); response != "Popped: alice" {
+		t.Errorf("Expected the default pop reply, got %q", response)
+	}
+
+	// Template without a lobby code
+	cm.GetConfig().Commands.Queue.ServedMessage = "{user}, you're up! Join the lobby: {lobby_code}"
+	if response := commands.HandlePop(mod, nil); response != "bob, you're up! Join the lobby: (not set)" {
+		t.Errorf("Expected the announcement without a code, got %q", response)
+	}
+
+	// Template with a lobby code set through !lobby
+	if response := commands.HandleLobby(mod, []string{"ABC123"}); response != "Lobby code set to ABC123" {
+		t.Errorf("Expected the lobby code to be set, got %q", response)
+	}
+	if response := commands.HandlePop(mod, nil); response != "carol, you're up! Join the lobby: ABC123" {
+		t.Errorf("Expected the announcement with the code, got %q", response)
+	}
+
+	// Several users served at once share one announcement
+	cm.GetQueue().Add("dave", false)
+	cm.GetQueue().Add("erin", false)
+	if response := commands.HandlePop(mod, []string{"2"}); response != "dave, erin, you're up! Join the lobby: ABC123" {
+		t.Errorf("Expected one announcement for both users, got %q", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestLobbyCommand(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_lobby_cmd")
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+
+	mod := createMockMessage("moduser", "!lobby", true, false, false)
+
+	if response := commands.HandleLobby(mod, nil); response != "No lobby code is set." {
+		t.Errorf("Expected no code to be set, got %q", response)
+	}
+	// The code is trimmed and length-checked
+	if response := commands.HandleLobby(mod, []string{"  XYZ-9 "}); response != "Lobby code set to XYZ-9" {
+		t.Errorf("Expected the trimmed code to be set, got %q", response)
+	}
+	if response := commands.HandleLobby(mod, []string{"ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"}); response != "Lobby code is too long. Use at most 32 characters." {
+		t.Errorf("Expected an over-long code to be rejected, got %q", response)
+	}
+
+	// The code is saved in the channel settings
+	saved := commands.NewSettings(cm.GetSettings().Path())
+	if err := saved.Load(); err != nil {
+		t.Fatalf("Failed to load settings: %v", err)
+	}
+	var code string
+	if !saved.Get(commands.SettingLobbyCode, &code) || code != "XYZ-9" {
+		t.Errorf("Expected the saved code XYZ-9, got %q", code)
+	}
+	// Everyone can see the code
+	viewer := createMockMessage("viewer", "!lobby", false, false, false)
+	if response, _ := cm.HandleMessage(viewer); response != "Lobby code: XYZ-9" {
+		t.Errorf("Expected viewers to see the current code, got %q", response)
+	}
+
+	// Viewers can't change it, but VIPs can
+	if response := commands.HandleLobby(viewer, []string{"hacked"}); response != "Only moderators and VIPs can set the lobby code." {
+		t.Errorf("Expected viewers to be unable to set the code, got %q", response)
+	}
+	vip := createMockMessage("vipuser", "!lobby", false, true, false)
+	if response := commands.HandleLobby(vip, []string{"VIP-1"}); response != "Lobby code set to VIP-1" {
+		t.Errorf("Expected VIPs to set the code, got %q", response)
+	}
+
+	if response := commands.HandleLobby(mod, []string{"clear"}); response != "Lobby code cleared." {
+		t.Errorf("Expected the code to be cleared, got %q", response)
+	}
+	if code := cm.LobbyCode(); code != "" {
+		t.Errorf("Expected no code after clearing, got %q", code)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
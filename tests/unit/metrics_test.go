@@ -0,0 +1,106 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	twitchirc "github.com/gempir/go-twitch-irc/v4"
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+	"github.com/pbuckles22/PBChatBot/internal/metrics"
+)
+
+func TestCommandLatencyRecorded(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_latency")
+	commands.SetCommandManager(cm)
+
+	const delay = 30 * time.Millisecond
+	cm.RegisterCommand(&commands.Command{
+		Name:        "slow",
+		Description: "A command with an injected delay",
+		Handler: func(message twitchirc.PrivateMessage, args []string) string {
+			time.Sleep(delay)
+			return "done"
+		},
+	})
+
+	for i := 0; i < 3; i++ {
+		msg := createMockMessage("testchannel_latency", "!slow", false, false, true)
+		if response, _ := cm.HandleMessage(msg); response != "done" {
+			t.Fatalf("Expected 'done', got '%s'", response)
+		}
+	}
+
+	stats, ok := cm.GetCommandLatency().Stats("slow")
+	if !ok {
+		t.Fatal("Expected latency to be recorded for !slow")
+	}
+	if stats.Count != 3 {
+		t.Errorf("Expected 3 observations, got %d", stats.Count)
+	}
+	if stats.Average < delay || stats.Average > delay+100*time.Millisecond {
+		t.Errorf("Expected average latency around %v, got %v", delay, stats.Average)
+	}
+	if stats.P95 < delay || stats.P95 > stats.Max {
+		t.Errorf("Expected p95 between %v and max %v, got %v", delay, stats.Max, stats.P95)
+	}
+
+	if _, ok := cm.GetCommandLatency().Stats("ping"); ok {
+		t.Error("Commands that never ran should have no latency stats")
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestCommandLatencyP95(t *testing.T) {
+	latency := metrics.NewCommandLatency()
+	for i := 0; i < 95; i++ {
+		latency.Observe("cmd", time.Millisecond)
+	}
+	for i := 0; i < 5; i++ {
+		latency.Observe("cmd", 400*time.Millisecond)
+	}
+
+	stats, _ := latency.Stats("cmd")
+	if stats.P95 != time.Millisecond {
+		t.Errorf("Expected p95 in the 1ms bucket, got %v", stats.P95)
+	}
+	if stats.Max != 400*time.Millisecond {
+		t.Errorf("Expected max 400ms, got %v", stats.Max)
+	}
+
+	latency.Observe("cmd", 400*time.Millisecond)
+	if stats, _ := latency.Stats("cmd"); stats.P95 != 400*time.Millisecond {
+		t.Errorf("Expected p95 capped at the slowest observation, got %v", stats.P95)
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	latency := metrics.NewCommandLatency()
+	latency.Observe("join", 4*time.Millisecond)
+
+	handler := metrics.Handler(map[string]metrics.Source{
+		"command_latency": func() interface{} { return latency.Snapshot() },
+	})
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	var report struct {
+		CommandLatency map[string]struct {
+			Count     int     `json:"count"`
+			AverageMs float64 `json:"average_ms"`
+			P95Ms     float64 `json:"p95_ms"`
+		} `json:"command_latency"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to parse metrics: %v", err)
+	}
+	join := report.CommandLatency["join"]
+	if join.Count != 1 || join.AverageMs != 4 || join.P95Ms != 4 {
+		t.Errorf("Unexpected join latency: %+v", join)
+	}
+}
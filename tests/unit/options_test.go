@@ -0,0 +1,219 @@
+package unit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+	"github.com/pbuckles22/PBChatBot/internal/config"
+	"github.com/pbuckles22/PBChatBot/internal/queue"
+)
+
+func TestCommandManagerOptionDefaults(t *testing.T) {
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManagerWithOptions(commands.WithDataPath(t.TempDir()))
+
+	if cm.GetPrefix() != commands.DefaultPrefix {
+		t.Errorf("Expected default prefix %q, got %q", commands.DefaultPrefix, cm.GetPrefix())
+	}
+	if cm.GetChannel() != "" {
+		t.Errorf("Expected no channel, got %q", cm.GetChannel())
+	}
+
+	cm.RegisterCommand(&commands.Command{Name: "ping", Handler: commands.HandlePing})
+	if cooldown := cm.GetCommandList()[0].Cooldown; cooldown != commands.DefaultCooldownConfig() {
+		t.Errorf("Expected default cooldown, got %+v", cooldown)
+	}
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestWithPrefix(t *testing.T) {
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManagerWithOptions(
+		commands.WithPrefix("?"),
+		commands.WithDataPath(t.TempDir()),
+	)
+	cm.RegisterCommand(&commands.Command{Name: "ping", Handler: commands.HandlePing})
+
+	if _, isCommand := cm.HandleMessage(createMockMessage("testuser", "!ping", false, false, false)); isCommand {
+		t.Error("Expected !ping to be ignored with a ? prefix")
+	}
+	if response, isCommand := cm.HandleMessage(createMockMessage("testuser", "?ping", false, false, false)); !isCommand || response == "" {
+		t.Errorf("Expected ?ping to run, got %q (isCommand=%v)", response, isCommand)
+	}
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestWithDataPath(t *testing.T) {
+	commands.SetCommandManager(nil)
+	dataPath := t.TempDir()
+	cm := commands.NewCommandManagerWithOptions(commands.WithDataPath(dataPath))
+
+	if cm.GetQueue().GetDataPath() != dataPath {
+		t.Errorf("Expected data path %s, got %s", dataPath, cm.GetQueue().GetDataPath())
+	}
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestWithChannel(t *testing.T) {
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManagerWithOptions(
+		commands.WithChannel("testchannel_options"),
+		commands.WithDataPath(t.TempDir()),
+	)
+
+	if cm.GetChannel() != "testchannel_options" {
+		t.Errorf("Expected channel testchannel_options, got %q", cm.GetChannel())
+	}
+	expected := "configs/channels/testchannel_options_config_secrets.yaml"
+	if cm.GetConfigPath() != expected {
+		t.Errorf("Expected config path %s, got %s", expected, cm.GetConfigPath())
+	}
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestWithMaxQueueSize(t *testing.T) {
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManagerWithOptions(
+		commands.WithMaxQueueSize(25),
+		commands.WithDataPath(t.TempDir()),
+	)
+
+	if size := cm.GetConfig().Commands.Queue.MaxSize; size != 25 {
+		t.Errorf("Expected max queue size 25, got %d", size)
+	}
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueMaxSizeFromConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "testchannel_maxsize_config_secrets.yaml")
+	configYAML := "bot_name: testbot\nchannel: testchannel_maxsize\ncommands:\n  queue:\n    max_size: 3\n"
+	if err := os.WriteFile(configPath, []byte(configYAML), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManagerWithOptions(
+		commands.WithDataPath(dir),
+		commands.WithChannel("testchannel_maxsize"),
+		commands.WithMaxQueueSize(cfg.Commands.Queue.MaxSize),
+	)
+	commands.RegisterBasicCommands(cm)
+	cm.GetQueue().Enable()
+
+	for _, user := range []string{"user1", "user2", "user3"} {
+		response, _ := cm.HandleMessage(createMockMessage(user, "!join", false, false, false))
+		if strings.Contains(response, "Error") {
+			t.Fatalf("Expected %s to join, got '%s'", user, response)
+		}
+	}
+	response, _ := cm.HandleMessage(createMockMessage("user4", "!join", false, false, false))
+	if response != "@user4 the queue is full. Try again when a spot opens." {
+		t.Errorf("Expected the fourth join to be rejected as full, got '%s'", response)
+	}
+	if err := cm.GetQueue().Add("user4", false); !errors.Is(err, queue.ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull, got: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestWithCooldownConfig(t *testing.T) {
+	commands.SetCommandManager(nil)
+	cooldown := commands.CooldownConfig{Regular: time.Minute, VIP: 30 * time.Second, Mod: time.Second}
+	cm := commands.NewCommandManagerWithOptions(
+		commands.WithCooldownConfig(cooldown),
+		commands.WithDataPath(t.TempDir()),
+	)
+
+	// Commands without a cooldown get the configured one; explicit ones are kept
+	custom := commands.CooldownConfig{Regular: 5 * time.Second}
+	cm.RegisterCommand(&commands.Command{Name: "ping", Handler: commands.HandlePing})
+	cm.RegisterCommand(&commands.Command{Name: "help", Handler: commands.HandleHelp, Cooldown: custom})
+
+	for _, cmd := range cm.GetCommandList() {
+		want := cooldown
+		if cmd.Name == "help" {
+			want = custom
+		}
+		if cmd.Cooldown != want {
+			t.Errorf("Expected !%s cooldown %+v, got %+v", cmd.Name, want, cmd.Cooldown)
+		}
+	}
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestCommandManagerOptionsCombined(t *testing.T) {
+	commands.SetCommandManager(nil)
+	dataPath := t.TempDir()
+	cooldown := commands.CooldownConfig{Regular: 10 * time.Second}
+	cm := commands.NewCommandManagerWithOptions(
+		commands.WithPrefix("#"),
+		commands.WithDataPath(dataPath),
+		commands.WithChannel("testchannel_options_combined"),
+		commands.WithMaxQueueSize(5),
+		commands.WithCooldownConfig(cooldown),
+	)
+
+	if cm.GetPrefix() != "#" {
+		t.Errorf("Expected prefix #, got %q", cm.GetPrefix())
+	}
+	if cm.GetQueue().GetDataPath() != dataPath {
+		t.Errorf("Expected data path %s, got %s", dataPath, cm.GetQueue().GetDataPath())
+	}
+	if cm.GetChannel() != "testchannel_options_combined" {
+		t.Errorf("Expected channel testchannel_options_combined, got %q", cm.GetChannel())
+	}
+	if size := cm.GetConfig().Commands.Queue.MaxSize; size != 5 {
+		t.Errorf("Expected max queue size 5, got %d", size)
+	}
+	cm.RegisterCommand(&commands.Command{Name: "ping", Handler: commands.HandlePing})
+	if got := cm.GetCommandList()[0].Cooldown; got != cooldown {
+		t.Errorf("Expected cooldown %+v, got %+v", cooldown, got)
+	}
+
+	// Later options win
+	cm = commands.NewCommandManagerWithOptions(
+		commands.WithPrefix("#"),
+		commands.WithPrefix("$"),
+		commands.WithDataPath(dataPath),
+	)
+	if cm.GetPrefix() != "$" {
+		t.Errorf("Expected the last prefix to win, got %q", cm.GetPrefix())
+	}
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestUndoClearWindowFromConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		queueYAML string
+		expected  int
+	}{
+		{"    max_size: 3\n", 60},
+		{"    undo_clear_window: 0\n", 0},
+		{"    undo_clear_window: 90\n", 90},
+	} {
+		configPath := filepath.Join(dir, "testchannel_undowindow_config_secrets.yaml")
+		configYAML := "bot_name: testbot\nchannel: testchannel_undowindow\ncommands:\n  queue:\n" + tc.queueYAML
+		if err := os.WriteFile(configPath, []byte(configYAML), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			t.Fatalf("Failed to load config: %v", err)
+		}
+		// An explicit 0 disables undo instead of falling back to the default
+		if window := cfg.Commands.Queue.UndoClearWindow; window == nil || *window != tc.expected {
+			t.Errorf("Config %q: expected an undo window of %d, got %v", tc.queueYAML, tc.expected, window)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package unit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+	"github.com/pbuckles22/PBChatBot/internal/queue"
+)
+
+func TestPinnedUserStaysInPlaceThroughMoves(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel")
+	q.Enable()
+	for _, user := range []string{"user1", "cohost", "user2", "user3", "user4"} {
+		q.Add(user, false)
+	}
+	if !q.Pin("CoHost") {
+		t.Fatal("Expected to pin a queued user")
+	}
+
+	// Reordering everyone else leaves the pinned user at position 2
+	q.MoveToFront("user4")
+	q.MoveToEnd("user1")
+	q.MoveUser("user3", 2)
+	if _, err := q.MoveBatch([]string{"user1", "user2"}, 1); err != nil {
+		t.Fatalf("MoveBatch failed: %v", err)
+	}
+	if pos := q.Position("cohost"); pos != 2 {
+		t.Errorf("Expected cohost to stay at position 2, got %d (queue %v)", pos, q.List())
+	}
+	if got := strings.Join(q.List(), ","); got != "user1,cohost,user2,user4,user3" {
+		t.Errorf("Unexpected order after moves: %s", got)
+	}
+
+	// The pinned user can't be moved until unpinned
+	if err := q.MoveToFront("cohost"); !errors.Is(err, queue.ErrUserPinned) {
+		t.Errorf("Expected ErrUserPinned, got %v", err)
+	}
+	if _, err := q.MoveBatch([]string{"cohost"}, 5); !errors.Is(err, queue.ErrUserPinned) {
+		t.Errorf("Expected ErrUserPinned from MoveBatch, got %v", err)
+	}
+	q.Unpin("cohost")
+	if err := q.MoveToFront("cohost"); err != nil || q.Position("cohost") != 1 {
+		t.Errorf("Expected an unpinned user to move, got %v", err)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestPinnedUserSkippedByPop(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel")
+	q.Enable()
+	for _, user := range []string{"cohost", "user1", "user2", "user3"} {
+		q.Add(user, false)
+	}
+	q.Pin("cohost")
+
+	if user, err := q.Pop(); err != nil || user != "user1" {
+		t.Errorf("Expected pop to skip the pinned user and serve user1, got %q (%v)", user, err)
+	}
+	users, err := q.PopN(5)
+	if err != nil || strings.Join(users, ",") != "user2,user3" {
+		t.Errorf("Expected PopN to serve only unpinned users, got %v (%v)", users, err)
+	}
+	if _, err := q.Pop(); err == nil || !strings.Contains(err.Error(), "pinned") {
+		t.Errorf("Expected an error when only pinned users are left, got %v", err)
+	}
+	if q.Position("cohost") != 1 {
+		t.Error("Expected the pinned user to remain queued")
+	}
+
+	// Removing the pinned user serves them explicitly
+	if !q.Remove("cohost") || q.Size() != 0 {
+		t.Error("Expected the pinned user to be removable")
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestPinnedUserKeptBySkipTo(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel")
+	q.Enable()
+	for _, user := range []string{"user1", "cohost", "user2", "user3"} {
+		q.Add(user, false)
+	}
+	q.Pin("cohost")
+
+	skipped, err := q.SkipTo("user3")
+	if err != nil || strings.Join(skipped, ",") != "user1,user2" {
+		t.Errorf("Expected user1 and user2 to be skipped, got %v (%v)", skipped, err)
+	}
+	if got := strings.Join(q.List(), ","); got != "cohost,user3" {
+		t.Errorf("Expected the pinned user to stay queued, got %s", got)
+	}
+
+	// Pins are saved with the queue
+	if err := q.SaveState(); err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+	restored := queue.NewQueue(tempDir, "testchannel")
+	if err := restored.LoadState(); err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	if !restored.IsPinned("cohost") || restored.IsPinned("user3") {
+		t.Error("Expected only cohost to be pinned after reloading")
+	}
+}
+
+func TestPinCommands(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_pin")
+	commands.SetCommandManager(cm)
+	cm.GetQueue().Enable()
+	cm.GetQueue().Add("user1", false)
+	cm.GetQueue().Add("CoHost", false)
+
+	mod := createMockMessage("moduser", "!pin", true, false, false)
+	if response := commands.HandlePin(mod, []string{"@cohost"}); response != "Pinned CoHost at position 2" {
+		t.Errorf("Unexpected pin response: %q", response)
+	}
+	if response := commands.HandlePin(mod, []string{"nobody"}); response != "nobody is not in the queue." {
+		t.Errorf("Unexpected response for a missing user: %q", response)
+	}
+	if response := commands.HandleQueue(mod, nil); response != "Queue: user1, CoHost (pinned) (2 total)" {
+		t.Errorf("Expected the pinned user to be flagged, got %q", response)
+	}
+
+	if response := commands.HandleUnpin(mod, []string{"cohost"}); response != "Unpinned CoHost" {
+		t.Errorf("Unexpected unpin response: %q", response)
+	}
+	if response := commands.HandleUnpin(mod, []string{"cohost"}); response != "cohost isn't pinned." {
+		t.Errorf("Unexpected response for an unpinned user: %q", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestPinnedSubscriberNotPromoted(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel")
+	q.Enable()
+	for _, user := range []string{"user1", "cohost", "user2", "sub1"} {
+		q.Add(user, false)
+	}
+	q.SetSubscriber("cohost", true)
+	q.SetSubscriber("sub1", true)
+	q.Pin("cohost")
+
+	// The pinned subscriber is passed over for the next one, who moves to
+	// the front around the pin
+	user, position, err := q.PromoteNextSubscriber()
+	if err != nil || user != "sub1" || position != 4 {
+		t.Errorf("Expected sub1 promoted from position 4, got %q at %d (%v)", user, position, err)
+	}
+	if got := strings.Join(q.List(), ","); got != "sub1,cohost,user1,user2" {
+		t.Errorf("Expected sub1 at the front with cohost still 2nd, got %s", got)
+	}
+
+	// With only the pinned subscriber left there is no one to promote
+	q.Remove("sub1")
+	if _, _, err := q.PromoteNextSubscriber(); !errors.Is(err, queue.ErrNoSubscribers) {
+		t.Errorf("Expected ErrNoSubscribers with only a pinned subscriber, got %v", err)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestPinDroppedOnRemoval(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel")
+	q.Enable()
+	q.SetMaxSize(2)
+	q.SetWaitlistEnabled(true)
+	q.Add("cohost", false)
+	q.Add("user1", false)
+	q.AddWaitlist("user2")
+	q.Pin("cohost")
+
+	// A removed user's pin doesn't come back when they rejoin through the
+	// waitlist
+	if !q.Remove("cohost") {
+		t.Fatal("Expected cohost to be removed")
+	}
+	q.AddWaitlist("cohost")
+	q.Remove("user1")
+	if got := strings.Join(q.List(), ","); got != "user2,cohost" {
+		t.Fatalf("Expected the waitlist to be promoted, got %s", got)
+	}
+	if q.IsPinned("cohost") {
+		t.Error("Expected the pin to be dropped when cohost was removed")
+	}
+
+	// Clearing drops pins too
+	q.Pin("user2")
+	q.Clear()
+	q.Add("user3", false)
+	q.Add("user4", false)
+	q.AddWaitlist("user2")
+	q.Remove("user3")
+	if q.Position("user2") != 2 || q.IsPinned("user2") {
+		t.Error("Expected clear to drop pins")
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
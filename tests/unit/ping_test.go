@@ -0,0 +1,40 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+// fakeLatency reports a fixed round-trip time
+type fakeLatency time.Duration
+
+func (f fakeLatency) Latency() time.Duration { return time.Duration(f) }
+
+func TestPingReportsLatency(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_ping")
+	commands.SetCommandManager(cm)
+	msg := createMockMessage("viewer1", "!ping", false, false, false)
+
+	// Without a latency source, or before a round trip has been measured,
+	// the reply is a plain pong
+	if response := commands.HandlePing(msg, []string{}); response != "Pong! 🏓" {
+		t.Errorf("Expected a plain pong, got '%s'", response)
+	}
+	cm.SetLatencyReporter(fakeLatency(0))
+	if response := commands.HandlePing(msg, []string{}); response != "Pong! 🏓" {
+		t.Errorf("Expected a plain pong before any RTT, got '%s'", response)
+	}
+
+	// Once measured, the RTT is included
+	cm.SetLatencyReporter(fakeLatency(42 * time.Millisecond))
+	if response := commands.HandlePing(msg, []string{}); response != "Pong! 🏓 (RTT: 42ms)" {
+		t.Errorf("Expected the RTT in the pong, got '%s'", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
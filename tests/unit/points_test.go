@@ -0,0 +1,135 @@
+package unit
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+func TestPointsEarnedByChatting(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_points")
+	commands.SetCommandManager(cm)
+
+	for i := 0; i < 3; i++ {
+		cm.HandleMessage(createMockMessage("Chatter", "hello chat", false, false, false))
+	}
+	// Commands don't earn points
+	cm.HandleMessage(createMockMessage("Chatter", "!points", false, false, false))
+
+	msg := createMockMessage("Chatter", "!points", false, false, false)
+	if response := commands.HandlePoints(msg, nil); response != "@Chatter, you have 3 points." {
+		t.Errorf("Unexpected balance response: '%s'", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestHandleAddRemovePoints(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	dir := t.TempDir()
+	cm := commands.NewCommandManager("!", dir, "testchannel_points_mod")
+	commands.SetCommandManager(cm)
+
+	mod := createMockMessage("moduser", "!addpoints", true, false, false)
+	if response := commands.HandleAddPoints(mod, []string{"@alice", "150"}); response != "Gave alice 150 points. They now have 150." {
+		t.Errorf("Unexpected add response: '%s'", response)
+	}
+	if response := commands.HandleAddPoints(mod, []string{"alice", "-5"}); response != "Invalid amount. Please specify a positive number." {
+		t.Errorf("Expected invalid amount response, got '%s'", response)
+	}
+	if response := commands.HandleRemovePoints(mod, []string{"Alice", "50"}); response != "Took 50 points from Alice. They now have 100." {
+		t.Errorf("Unexpected remove response: '%s'", response)
+	}
+	if response := commands.HandleRemovePoints(mod, []string{"alice", "500"}); response != "Took 500 points from alice. They now have 0." {
+		t.Errorf("Expected balance to stop at zero, got '%s'", response)
+	}
+
+	commands.HandleAddPoints(mod, []string{"bob", "30"})
+	commands.HandleAddPoints(mod, []string{"carol", "70"})
+	commands.HandleAddPoints(mod, []string{"alice", "30"})
+	if response := commands.HandlePointsLeaderboard(mod, nil); response != "Top points: 1. carol (70), 2. alice (30), 3. bob (30)" {
+		t.Errorf("Unexpected leaderboard: '%s'", response)
+	}
+
+	// Balances survive a restart
+	reloaded := commands.NewPointsManager(filepath.Join(cm.GetQueue().GetDataPath(), "points_testchannel_points_mod.json"))
+	if balance := reloaded.Balance("carol"); balance != 70 {
+		t.Errorf("Expected saved balance of 70, got %d", balance)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestJoinCostDeduction(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_points_join")
+	commands.SetCommandManager(cm)
+	cm.GetQueue().Enable()
+	cm.GetConfig().Commands.Queue.JoinCost = 100
+
+	join := createMockMessage("viewer", "!join", false, false, false)
+	cm.GetPoints().Add("viewer", 60)
+	if response := commands.HandleJoin(join, nil); response != "@viewer, joining the queue costs 100 points. You have 60." {
+		t.Errorf("Expected join to be refused, got '%s'", response)
+	}
+	if cm.GetQueue().Size() != 0 {
+		t.Error("Expected viewer not to be queued")
+	}
+
+	cm.GetPoints().Add("viewer", 90)
+	if response := commands.HandleJoin(join, nil); response != "viewer joined queue at position 1 (1 total)" {
+		t.Errorf("Unexpected join response: '%s'", response)
+	}
+	if balance := cm.GetPoints().Balance("viewer"); balance != 50 {
+		t.Errorf("Expected 100 points to be deducted, got balance %d", balance)
+	}
+
+	// A failed join is refunded
+	cm.GetPoints().Add("viewer", 50)
+	commands.HandleJoin(join, nil)
+	if balance := cm.GetPoints().Balance("viewer"); balance != 100 {
+		t.Errorf("Expected duplicate join to be refunded, got balance %d", balance)
+	}
+
+	// Moderators join for free
+	mod := createMockMessage("moduser", "!join", true, false, false)
+	if response := commands.HandleJoin(mod, nil); response != "moduser joined queue at position 2 (2 total)" {
+		t.Errorf("Unexpected mod join response: '%s'", response)
+	}
+
+	// Naming someone, even yourself, costs the viewer the same
+	named := createMockMessage("viewer2", "!join viewer2", false, false, false)
+	if response := commands.HandleJoin(named, []string{"viewer2"}); response != "@viewer2, joining the queue costs 100 points. You have 0." {
+		t.Errorf("Expected a named join to be charged, got '%s'", response)
+	}
+	cm.GetPoints().Add("viewer2", 100)
+	if response := commands.HandleJoin(named, []string{"viewer2"}); response != "viewer2 joined queue at position 3 (3 total)" {
+		t.Errorf("Unexpected named join response: '%s'", response)
+	}
+	if balance := cm.GetPoints().Balance("viewer2"); balance != 0 {
+		t.Errorf("Expected the named join to cost 100 points, got balance %d", balance)
+	}
+
+	// A waitlist spot costs the same as a queue spot
+	cm.GetQueue().SetMaxSize(3)
+	cm.GetQueue().SetWaitlistEnabled(true)
+	cm.GetPoints().Add("waiter", 100)
+	if response := commands.HandleJoin(createMockMessage("waiter", "!join", false, false, false), nil); !strings.Contains(response, "on the waitlist") {
+		t.Errorf("Expected waiter to be waitlisted, got '%s'", response)
+	}
+	if balance := cm.GetPoints().Balance("waiter"); balance != 0 {
+		t.Errorf("Expected the waitlisted join to cost 100 points, got balance %d", balance)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
@@ -0,0 +1,157 @@
+package unit
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+func TestFormatPollResults(t *testing.T) {
+	options := []string{"opt1", "opt2", "opt3"}
+
+	votes := make(map[string]int)
+	counts := []int{34, 28, 10}
+	voter := 0
+	for option, count := range counts {
+		for i := 0; i < count; i++ {
+			votes[fmt.Sprintf("voter%d", voter)] = option + 1
+			voter++
+		}
+	}
+
+	expected := "Poll results — opt1: 34 (47%), opt2: 28 (39%), opt3: 10 (14%) — Winner: opt1!"
+	if got := commands.FormatPollResults(options, votes); got != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, got)
+	}
+
+	tie := map[string]int{"a": 1, "b": 2}
+	expected = "Poll results — opt1: 1 (50%), opt2: 1 (50%), opt3: 0 (0%) — Tie: opt1, opt2!"
+	if got := commands.FormatPollResults(options, tie); got != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, got)
+	}
+
+	if got := commands.FormatPollResults(options, nil); got != "Poll results — no votes were cast." {
+		t.Errorf("Unexpected empty poll results: '%s'", got)
+	}
+}
+
+func TestPollManagerVoting(t *testing.T) {
+	pm := commands.NewPollManager(func(string) {})
+
+	if err := pm.Vote("user1", 1); !errors.Is(err, commands.ErrNoPoll) {
+		t.Errorf("Expected ErrNoPoll before a poll opens, got %v", err)
+	}
+
+	if err := pm.Open("Best map?", []string{"dust", "inferno"}); err != nil {
+		t.Fatalf("Failed to open poll: %v", err)
+	}
+	if err := pm.Open("Another?", []string{"a", "b"}); !errors.Is(err, commands.ErrPollRunning) {
+		t.Errorf("Expected ErrPollRunning, got %v", err)
+	}
+
+	if err := pm.Vote("user1", 1); err != nil {
+		t.Errorf("Unexpected vote error: %v", err)
+	}
+	if err := pm.Vote("USER1", 2); !errors.Is(err, commands.ErrAlreadyVoted) {
+		t.Errorf("Expected double vote to be rejected, got %v", err)
+	}
+	if err := pm.Vote("user2", 3); err == nil {
+		t.Error("Expected out-of-range option to be rejected")
+	}
+	if err := pm.Vote("user2", 1); err != nil {
+		t.Errorf("Unexpected vote error: %v", err)
+	}
+	if err := pm.Vote("user3", 2); err != nil {
+		t.Errorf("Unexpected vote error: %v", err)
+	}
+
+	results, err := pm.Close()
+	if err != nil {
+		t.Fatalf("Failed to close poll: %v", err)
+	}
+	expected := "Poll results — dust: 2 (67%), inferno: 1 (33%) — Winner: dust!"
+	if results != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, results)
+	}
+
+	if _, err := pm.Close(); !errors.Is(err, commands.ErrNoPoll) {
+		t.Errorf("Expected ErrNoPoll after closing, got %v", err)
+	}
+}
+
+func TestPollManagerAutoClose(t *testing.T) {
+	announced := make(chan string, 1)
+	pm := commands.NewPollManager(func(message string) { announced <- message })
+	pm.SetDuration(50 * time.Millisecond)
+
+	if err := pm.Open("Best map?", []string{"dust", "inferno"}); err != nil {
+		t.Fatalf("Failed to open poll: %v", err)
+	}
+	pm.Vote("user1", 2)
+
+	select {
+	case results := <-announced:
+		expected := "Poll results — dust: 0 (0%), inferno: 1 (100%) — Winner: inferno!"
+		if results != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, results)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected results to be announced when the poll closed")
+	}
+
+	// Closing early stops the automatic announcement
+	pm.Open("Again?", []string{"yes", "no"})
+	if _, err := pm.Close(); err != nil {
+		t.Fatalf("Failed to close poll early: %v", err)
+	}
+	select {
+	case results := <-announced:
+		t.Errorf("Expected no announcement after an early close, got '%s'", results)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestHandleStrawPoll(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_poll")
+	commands.SetCommandManager(cm)
+	cm.SetPollManager(commands.NewPollManager(func(string) {}))
+
+	response := commands.HandleStrawPoll(createMockMessage("moduser", "!strawpoll question", true, false, false), []string{"question"})
+	if response != `Usage: !strawpoll "<question>" <option1> <option2> [...] (2-5 options)` {
+		t.Errorf("Expected usage response, got '%s'", response)
+	}
+
+	response = commands.HandleStrawPoll(createMockMessage("moduser", `!strawpoll "Best map?" dust "de inferno"`, true, false, false), nil)
+	expected := "Poll: Best map? — vote with !vote 1 (dust), !vote 2 (de inferno) — closes in 1m"
+	if response != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, response)
+	}
+
+	viewer := createMockMessage("viewer", "!vote 2", false, false, false)
+	if response := commands.HandleVote(viewer, []string{"2"}); response != "" {
+		t.Errorf("Expected a quiet vote, got '%s'", response)
+	}
+	if response := commands.HandleVote(viewer, []string{"1"}); response != "@viewer, you've already voted in this poll." {
+		t.Errorf("Expected double vote rejection, got '%s'", response)
+	}
+	if response := commands.HandleVote(createMockMessage("other", "!vote 9", false, false, false), []string{"9"}); response != "@other, option must be between 1 and 2." {
+		t.Errorf("Expected range error, got '%s'", response)
+	}
+
+	response = commands.HandleEndPoll(createMockMessage("moduser", "!endpoll", true, false, false), nil)
+	expected = "Poll results — dust: 0 (0%), de inferno: 1 (100%) — Winner: de inferno!"
+	if response != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, response)
+	}
+	if response := commands.HandleEndPoll(createMockMessage("moduser", "!endpoll", true, false, false), nil); response != "There is no poll running." {
+		t.Errorf("Expected no-poll response, got '%s'", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
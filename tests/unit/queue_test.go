@@ -1,12 +1,17 @@
 package unit
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/pbuckles22/PBChatBot/internal/commands"
 	"github.com/pbuckles22/PBChatBot/internal/queue"
 )
 
@@ -86,6 +91,10 @@ func TestQueueAdd(t *testing.T) {
 	if !strings.Contains(err.Error(), "already in queue") {
 		t.Errorf("Expected 'already in queue' error, got: %v", err)
 	}
+	var dup *queue.AlreadyQueuedError
+	if !errors.As(err, &dup) || dup.Position != 1 {
+		t.Errorf("Expected AlreadyQueuedError at position 1, got: %v", err)
+	}
 
 	// Test adding user when disabled
 	q.Disable()
@@ -98,6 +107,83 @@ func TestQueueAdd(t *testing.T) {
 	}
 }
 
+func TestQueueMaxSize(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel")
+	q.Enable()
+	q.SetMaxSize(2)
+
+	for _, user := range []string{"user1", "user2"} {
+		if err := q.Add(user, false); err != nil {
+			t.Fatalf("Failed to add %s: %v", user, err)
+		}
+	}
+	if err := q.Add("user3", false); !errors.Is(err, queue.ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull, got: %v", err)
+	}
+	if err := q.Add("moduser", true); !errors.Is(err, queue.ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull for a mod add, got: %v", err)
+	}
+
+	// A free slot lets the next user in
+	q.Remove("user1")
+	if err := q.Add("user3", false); err != nil {
+		t.Errorf("Failed to add user3 after a slot opened: %v", err)
+	}
+
+	// Zero removes the limit
+	q.SetMaxSize(0)
+	if err := q.Add("user4", false); err != nil {
+		t.Errorf("Failed to add user4 with no limit: %v", err)
+	}
+}
+
+// TestQueueConcurrentAdds hammers the queue so auto-saves overlap with
+// mutations. Run with -race -count=5 to check for races and deadlocks.
+func TestQueueConcurrentAdds(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel")
+	q.Enable()
+
+	const workers, perWorker = 10, 100
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				if err := q.Add(fmt.Sprintf("user%d_%d", w, i), false); err != nil {
+					t.Errorf("Failed to add user: %v", err)
+				}
+				q.Size()
+			}
+		}(w)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Concurrent adds did not finish; possible deadlock")
+	}
+
+	if q.Size() != workers*perWorker {
+		t.Fatalf("Expected %d users, got %d", workers*perWorker, q.Size())
+	}
+
+	// SaveState waits for background saves, so the file holds the final state
+	if err := q.SaveState(); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	if reloaded := queue.NewQueue(tempDir, "testchannel"); reloaded.Size() != workers*perWorker {
+		t.Errorf("Expected %d users after reload, got %d", workers*perWorker, reloaded.Size())
+	}
+}
+
 func TestQueueRemove(t *testing.T) {
 	tempDir := t.TempDir()
 	q := queue.NewQueue(tempDir, "testchannel")
@@ -382,3 +468,888 @@ func TestQueueStatePersistence(t *testing.T) {
 		t.Errorf("Expected %v after restart, got %v", expected, users)
 	}
 }
+
+func TestQueueBackupRoundTrip(t *testing.T) {
+	tempDir := t.TempDir()
+	channel := "testchannel"
+
+	q := queue.NewQueue(tempDir, channel)
+	q.Enable()
+	q.Add("user1", false)
+	q.Add("user2", false)
+	if err := q.SaveBackup(); err != nil {
+		t.Fatalf("Failed to save backup: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "queue_backup_"+channel+".json")); err != nil {
+		t.Errorf("Expected backup file to exist: %v", err)
+	}
+
+	// Changes after the backup are discarded by LoadBackup
+	q.Add("user3", false)
+	q.Remove("user1")
+	if err := q.LoadBackup(); err != nil {
+		t.Fatalf("Failed to load backup: %v", err)
+	}
+	users := q.List()
+	if len(users) != 2 || users[0] != "user1" || users[1] != "user2" {
+		t.Errorf("Expected [user1 user2] after loading backup, got %v", users)
+	}
+
+	// Wait a moment for auto-save goroutine to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueLoadBackupMissing(t *testing.T) {
+	q := queue.NewQueue(t.TempDir(), "testchannel")
+	q.Enable()
+	q.Add("user1", false)
+
+	err := q.LoadBackup()
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected a not-exist error with no backup, got: %v", err)
+	}
+	if q.Size() != 1 {
+		t.Errorf("Expected the queue to be left alone, got %v", q.List())
+	}
+
+	// Wait a moment for auto-save goroutine to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueLoadBackupChannelMismatch(t *testing.T) {
+	tempDir := t.TempDir()
+
+	other := queue.NewQueue(tempDir, "otherchannel")
+	other.Enable()
+	other.Add("user1", false)
+	if err := other.SaveBackup(); err != nil {
+		t.Fatalf("Failed to save backup: %v", err)
+	}
+
+	// Put the other channel's backup where this channel's would be
+	data, err := os.ReadFile(filepath.Join(tempDir, "queue_backup_otherchannel.json"))
+	if err != nil {
+		t.Fatalf("Failed to read backup: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "queue_backup_testchannel.json"), data, 0644); err != nil {
+		t.Fatalf("Failed to write backup: %v", err)
+	}
+
+	q := queue.NewQueue(tempDir, "testchannel")
+	q.Enable()
+	err = q.LoadBackup()
+	if err == nil || !strings.Contains(err.Error(), "channel mismatch") {
+		t.Errorf("Expected a channel mismatch error, got: %v", err)
+	}
+	if q.Size() != 0 {
+		t.Errorf("Expected the queue to stay empty, got %v", q.List())
+	}
+
+	// Wait a moment for auto-save goroutine to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueMigrateLegacyState(t *testing.T) {
+	tempDir := t.TempDir()
+	legacy := `{"channel": "testchannel", "queue": ["user1", "user2"], "last_updated": 1700000000}`
+	if err := os.WriteFile(filepath.Join(tempDir, "queue_state.json"), []byte(legacy), 0644); err != nil {
+		t.Fatalf("Failed to write legacy state: %v", err)
+	}
+
+	q := queue.NewQueue(tempDir, "testchannel")
+	users := q.List()
+	if len(users) != 2 || users[0] != "user1" || users[1] != "user2" {
+		t.Errorf("Expected [user1 user2] from the legacy file, got %v", users)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "queue_state_testchannel.json")); err != nil {
+		t.Errorf("Expected the channel-specific state file to be created: %v", err)
+	}
+
+	// Another channel's queue doesn't pick up the legacy file
+	other := queue.NewQueue(tempDir, "otherchannel")
+	if other.Size() != 0 {
+		t.Errorf("Expected no users for another channel, got %v", other.List())
+	}
+	if err := other.MigrateState(); err == nil || !strings.Contains(err.Error(), "channel mismatch") {
+		t.Errorf("Expected a channel mismatch error, got: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "queue_state_otherchannel.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected no state file for another channel, got: %v", err)
+	}
+}
+
+func TestQueueStateVersionUpgrade(t *testing.T) {
+	tempDir := t.TempDir()
+	stateFile := filepath.Join(tempDir, "queue_state_testchannel.json")
+
+	// A v0 file has no version and stores plain usernames
+	v0 := `{"channel": "testchannel", "queue": ["Alice", "bob"], "last_updated": 1700000000, "reserved": ["bob"]}`
+	if err := os.WriteFile(stateFile, []byte(v0), 0644); err != nil {
+		t.Fatalf("Failed to write v0 state: %v", err)
+	}
+
+	q := queue.NewQueue(tempDir, "testchannel")
+	users := q.List()
+	if len(users) != 2 || users[0] != "Alice" || users[1] != "bob" {
+		t.Fatalf("Expected [Alice bob] from the v0 file, got %v", users)
+	}
+	if !q.IsReserved("bob") {
+		t.Error("Expected bob's reservation to survive the upgrade")
+	}
+
+	// The next save writes the current version with queue entries
+	q.Enable()
+	q.Add("carol", false)
+	if err := q.SaveState(); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	data, err := os.ReadFile(stateFile)
+	if err != nil {
+		t.Fatalf("Failed to read state: %v", err)
+	}
+	var saved queue.QueueState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("Expected the saved state to be the current format: %v", err)
+	}
+	if saved.Version != queue.CurrentStateVersion {
+		t.Errorf("Expected version %d, got %d", queue.CurrentStateVersion, saved.Version)
+	}
+	if len(saved.Queue) != 3 || saved.Queue[0].Username != "Alice" || saved.Queue[2].Username != "carol" {
+		t.Fatalf("Expected Alice, bob, carol in the saved queue, got %+v", saved.Queue)
+	}
+	if saved.Queue[0].JoinTime != nil || saved.Queue[2].JoinTime == nil {
+		t.Fatalf("Expected only carol to have a join time, got %+v", saved.Queue)
+	}
+	if strings.Count(string(data), "join_time") != 1 {
+		t.Errorf("Expected unknown join times to be left out of the file, got %s", data)
+	}
+
+	// Join times survive a reload
+	reloaded := queue.NewQueue(tempDir, "testchannel")
+	reloaded.Enable()
+	summary := reloaded.SessionSummary()
+	if len(summary.Remaining) != 3 || !summary.Remaining[2].JoinedAt.Equal(*saved.Queue[2].JoinTime) {
+		t.Errorf("Expected carol's join time to be restored, got %+v", summary.Remaining)
+	}
+
+	// Files from a newer build are refused rather than misread. They get
+	// their own directory so a background save above can't overwrite them.
+	futureDir := t.TempDir()
+	future := `{"version": 99, "channel": "testchannel", "queue": [{"username": "dave"}]}`
+	if err := os.WriteFile(filepath.Join(futureDir, "queue_state_testchannel.json"), []byte(future), 0644); err != nil {
+		t.Fatalf("Failed to write future state: %v", err)
+	}
+	if err := queue.NewQueue(futureDir, "testchannel").RestoreAutoSave(); !errors.Is(err, queue.ErrUnsupportedStateVersion) {
+		t.Errorf("Expected ErrUnsupportedStateVersion, got %v", err)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueEndPreserve(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel")
+	q.Enable()
+	q.Add("user1", false)
+	q.Add("user2", false)
+
+	if err := q.End(true); err != nil {
+		t.Fatalf("Failed to end queue: %v", err)
+	}
+	if q.IsEnabled() || q.Size() != 0 {
+		t.Errorf("Expected an ended, empty queue, got %v", q.List())
+	}
+	// Wait for q's auto-save goroutines so they don't overwrite the file below
+	time.Sleep(100 * time.Millisecond)
+
+	// The ended queue isn't restored on startup, and saves made while it's
+	// ended keep the preserved users
+	restarted := queue.NewQueue(tempDir, "testchannel")
+	if restarted.Size() != 0 {
+		t.Errorf("Expected no users restored on startup, got %v", restarted.List())
+	}
+	if err := restarted.SaveState(); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	if err := restarted.RestoreAutoSave(); err != nil {
+		t.Fatalf("Failed to restore auto-save: %v", err)
+	}
+	users := restarted.List()
+	if len(users) != 2 || users[0] != "user1" || users[1] != "user2" {
+		t.Errorf("Expected [user1 user2] from the ended queue, got %v", users)
+	}
+
+	// Enabling starts fresh; the restored queue is no longer marked ended
+	restarted.Enable()
+	if err := restarted.SaveState(); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	if again := queue.NewQueue(tempDir, "testchannel"); again.Size() != 2 {
+		t.Errorf("Expected the running queue to be restored on startup, got %v", again.List())
+	}
+
+	// Wait a moment for auto-save goroutine to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueEndWithoutPreserve(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel")
+	q.Enable()
+	q.Add("user1", false)
+
+	if err := q.End(false); err != nil {
+		t.Fatalf("Failed to end queue: %v", err)
+	}
+	if err := q.RestoreAutoSave(); err != nil {
+		t.Fatalf("Failed to restore auto-save: %v", err)
+	}
+	if q.Size() != 0 {
+		t.Errorf("Expected nothing to restore, got %v", q.List())
+	}
+
+	// Wait a moment for auto-save goroutine to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueMoveToFront(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel")
+	q.Enable()
+
+	// Add users
+	q.Add("user1", false)
+	q.Add("user2", false)
+	q.Add("user3", false)
+
+	// Test moving user to front
+	if err := q.MoveToFront("user3"); err != nil {
+		t.Errorf("Failed to move user to front: %v", err)
+	}
+
+	users := q.List()
+	expected := []string{"user3", "user1", "user2"}
+	for i := range expected {
+		if users[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, users)
+			break
+		}
+	}
+
+	// Test moving user already at front (should be no-op)
+	if err := q.MoveToFront("user3"); err != nil {
+		t.Errorf("Moving user already at front should not error: %v", err)
+	}
+
+	// Test moving non-existent user
+	err := q.MoveToFront("nonexistent")
+	if err == nil {
+		t.Error("Should not be able to move non-existent user")
+	}
+	if !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected 'not found' error, got: %v", err)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueMoveBatch(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel")
+	q.Enable()
+
+	for _, user := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		q.Add(user, false)
+	}
+
+	// The batch lands contiguously in the given order; everyone else keeps
+	// their relative order
+	moved, err := q.MoveBatch([]string{"F", "b", "g", "f"}, 2)
+	if err != nil {
+		t.Fatalf("Failed to move batch: %v", err)
+	}
+	if strings.Join(moved, ",") != "f,b,g" {
+		t.Errorf("Expected the batch [f b g] with repeats dropped, got %v", moved)
+	}
+	if users := q.List(); strings.Join(users, ",") != "a,f,b,g,c,d,e" {
+		t.Errorf("Expected [a f b g c d e], got %v", users)
+	}
+
+	// A position past the end appends the batch
+	if _, err := q.MoveBatch([]string{"a", "c"}, 50); err != nil {
+		t.Fatalf("Failed to move batch to the end: %v", err)
+	}
+	if users := q.List(); strings.Join(users, ",") != "f,b,g,d,e,a,c" {
+		t.Errorf("Expected [f b g d e a c], got %v", users)
+	}
+
+	// Any missing user aborts the whole move
+	_, err = q.MoveBatch([]string{"d", "nobody"}, 1)
+	if err == nil || !strings.Contains(err.Error(), "nobody") {
+		t.Errorf("Expected an error naming the missing user, got %v", err)
+	}
+	if users := q.List(); strings.Join(users, ",") != "f,b,g,d,e,a,c" {
+		t.Errorf("Expected the queue unchanged after a failed move, got %v", users)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueLastServed(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel")
+	q.Enable()
+
+	q.Add("User1", false)
+	q.Add("user2", false)
+
+	if _, ok := q.LastServed("user1"); ok {
+		t.Error("User should not be served before being popped")
+	}
+
+	q.Pop()
+	if servedAt, ok := q.LastServed("user1"); !ok || time.Since(servedAt) > time.Second {
+		t.Errorf("Expected user1 to be served just now, got %v (ok=%v)", servedAt, ok)
+	}
+
+	q.PopN(1)
+	if _, ok := q.LastServed("USER2"); !ok {
+		t.Error("Expected user2 to be served after PopN (case-insensitive)")
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueUndoClear(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel")
+	q.Enable()
+
+	q.Add("user1", false)
+	q.Add("user2", false)
+
+	// Nothing to restore before a clear
+	if _, err := q.UndoClear(); err == nil {
+		t.Error("Should not be able to undo without a clear")
+	}
+
+	// Undo within the window restores the cleared users
+	q.Clear()
+	count, err := q.UndoClear()
+	if err != nil {
+		t.Fatalf("Failed to undo clear: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 users restored, got %d", count)
+	}
+	if users := q.List(); strings.Join(users, ",") != "user1,user2" {
+		t.Errorf("Expected [user1 user2], got %v", users)
+	}
+
+	// A snapshot can only be restored once
+	if _, err := q.UndoClear(); err == nil {
+		t.Error("Should not be able to undo the same clear twice")
+	}
+
+	// Mutations after the clear don't block the undo; restored users go first
+	q.Clear()
+	q.Add("user3", false)
+	q.Add("USER1", false)
+	count, err = q.UndoClear()
+	if err != nil {
+		t.Fatalf("Failed to undo clear after new joins: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 users restored, got %d", count)
+	}
+	if users := q.List(); strings.Join(users, ",") != "user1,user2,user3" {
+		t.Errorf("Expected [user1 user2 user3], got %v", users)
+	}
+
+	// Undo after the window has nothing to restore
+	q.SetUndoClearWindow(50 * time.Millisecond)
+	q.Clear()
+	time.Sleep(100 * time.Millisecond)
+	if _, err := q.UndoClear(); err == nil {
+		t.Error("Should not be able to undo after the window expires")
+	}
+	if q.Size() != 0 {
+		t.Errorf("Expected empty queue after expired undo, got %v", q.List())
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueReserved(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel")
+	q.Enable()
+
+	q.Add("user1", false)
+	q.AddAtPosition("guest", 1, true)
+
+	if q.SetReserved("nobody", true) {
+		t.Error("Should not be able to reserve a user who isn't queued")
+	}
+	if !q.SetReserved("guest", true) || !q.IsReserved("GUEST") {
+		t.Error("Expected guest to be reserved")
+	}
+	if q.IsReserved("user1") {
+		t.Error("user1 should not be reserved")
+	}
+
+	// Reservations survive a save/load
+	time.Sleep(100 * time.Millisecond)
+	q2 := queue.NewQueue(tempDir, "testchannel")
+	if !q2.IsReserved("guest") {
+		t.Error("Expected reservation to persist")
+	}
+
+	// A reservation ends when the user leaves the queue
+	q.Pop()
+	q.Add("guest", false)
+	if q.IsReserved("guest") {
+		t.Error("Rejoining should not restore a reservation")
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestAddAtPositionChecks(t *testing.T) {
+	q := queue.NewQueue(t.TempDir(), "testchannel")
+	q.Enable()
+	q.SetMaxSize(2)
+	q.SetWaitlistEnabled(true)
+
+	q.Add("user1", false)
+	q.Add("user2", false)
+	if err := q.AddWaitlist("waiting"); err != nil {
+		t.Fatalf("Failed to add to the waitlist: %v", err)
+	}
+	q.AddVIP("vipuser")
+
+	// A full queue can't be pushed past max_size by a reserved guest
+	if err := q.AddAtPosition("guest", 1, true); !errors.Is(err, queue.ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull, got %v", err)
+	}
+	if q.Size() != 2 {
+		t.Errorf("Expected the queue to stay at 2 users, got %d", q.Size())
+	}
+
+	// Users on the waitlist or in the VIP line can't be queued twice
+	if err := q.AddAtPosition("WAITING", 1, true); err == nil || errors.Is(err, queue.ErrQueueFull) {
+		t.Errorf("Expected a waitlisted user to be rejected as a duplicate, got %v", err)
+	}
+	if err := q.AddAtPosition("vipuser", 1, true); err == nil || errors.Is(err, queue.ErrQueueFull) {
+		t.Errorf("Expected a user in the VIP line to be rejected as a duplicate, got %v", err)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueSessionSummary(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel")
+	q.Enable()
+
+	q.Add("user1", false)
+	q.Add("user2", false)
+	q.Add("user3", false)
+	q.Pop()
+	q.Add("user4", false)
+	q.PopN(2)
+	q.Add("user5", false)
+	q.AddAtPosition("guest", 1, true)
+	q.SetReserved("guest", true)
+
+	summary := q.SessionSummary()
+
+	var served []string
+	for _, user := range summary.Served {
+		served = append(served, user.Username)
+		if user.JoinedAt.IsZero() || user.ServedAt.Before(user.JoinedAt) {
+			t.Errorf("Expected %s to have join and serve times in order, got %v / %v", user.Username, user.JoinedAt, user.ServedAt)
+		}
+	}
+	if strings.Join(served, ",") != "user1,user2,user3" {
+		t.Errorf("Expected served [user1 user2 user3], got %v", served)
+	}
+
+	var remaining []string
+	for _, user := range summary.Remaining {
+		remaining = append(remaining, fmt.Sprintf("%d:%s", user.Position, user.Username))
+	}
+	if strings.Join(remaining, ",") != "1:guest,2:user4,3:user5" {
+		t.Errorf("Expected remaining [1:guest 2:user4 3:user5], got %v", remaining)
+	}
+	if !summary.Remaining[0].Reserved {
+		t.Error("Expected guest to be marked reserved in the summary")
+	}
+	if summary.Channel != "testchannel" || summary.StartedAt.IsZero() || summary.EndedAt.Before(summary.StartedAt) {
+		t.Errorf("Unexpected session bounds: %+v", summary)
+	}
+
+	// A new session starts fresh
+	q.Disable()
+	q.Enable()
+	if summary := q.SessionSummary(); len(summary.Served) != 0 || len(summary.Remaining) != 0 {
+		t.Errorf("Expected empty summary for a new session, got %+v", summary)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueSkipTo(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel")
+	q.Enable()
+
+	q.Add("user1", false)
+	q.Add("user2", false)
+	q.Add("Target", false)
+	q.Add("user4", false)
+
+	skipped, err := q.SkipTo("target")
+	if err != nil {
+		t.Fatalf("Expected skip to succeed, got %v", err)
+	}
+	if strings.Join(skipped, ",") != "user1,user2" {
+		t.Errorf("Expected [user1 user2] skipped, got %v", skipped)
+	}
+	if q.Position("Target") != 1 || q.Size() != 2 {
+		t.Errorf("Expected Target at position 1 of 2, got %d of %d", q.Position("Target"), q.Size())
+	}
+
+	// Skipped users are in the history but can rejoin straight away
+	summary := q.SessionSummary()
+	if len(summary.Served) != 2 || !summary.Served[0].Skipped || !summary.Served[1].Skipped {
+		t.Errorf("Expected two skipped entries, got %+v", summary.Served)
+	}
+	if _, served := q.LastServed("user1"); served {
+		t.Error("Expected skipped user not to be marked served")
+	}
+
+	// Skipping to the front user skips nobody
+	if skipped, err := q.SkipTo("Target"); err != nil || len(skipped) != 0 {
+		t.Errorf("Expected nothing skipped, got %v, %v", skipped, err)
+	}
+
+	if _, err := q.SkipTo("nobody"); err == nil {
+		t.Error("Expected error skipping to a user not in the queue")
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueuePromoteNextSubscriber(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel")
+	q.Enable()
+
+	for _, user := range []string{"regular1", "regular2", "sub1", "regular3", "sub2"} {
+		q.Add(user, false)
+	}
+	if _, _, err := q.PromoteNextSubscriber(); !errors.Is(err, queue.ErrNoSubscribers) {
+		t.Errorf("Expected ErrNoSubscribers, got %v", err)
+	}
+
+	q.SetSubscriber("sub1", true)
+	q.SetSubscriber("SUB2", true)
+	if q.SetSubscriber("nobody", true) {
+		t.Error("Expected SetSubscriber to fail for a user not in the queue")
+	}
+
+	user, position, err := q.PromoteNextSubscriber()
+	if err != nil || user != "sub1" || position != 3 {
+		t.Fatalf("Expected sub1 promoted from position 3, got %s, %d, %v", user, position, err)
+	}
+	if got := strings.Join(q.List(), ","); got != "sub1,regular1,regular2,regular3,sub2" {
+		t.Errorf("Unexpected queue order after promotion: %s", got)
+	}
+
+	// Subscriber flags survive a reload
+	time.Sleep(100 * time.Millisecond)
+	if err := q.SaveState(); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	reloaded := queue.NewQueue(tempDir, "testchannel")
+	if !reloaded.IsSubscriber("sub2") || reloaded.IsSubscriber("regular1") {
+		t.Error("Expected subscriber flags to be restored from saved state")
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueVIPInterleave(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel")
+	q.Enable()
+
+	if q.GetVIPInterleave() != queue.DefaultVIPInterleave {
+		t.Errorf("Expected default interleave %d, got %d", queue.DefaultVIPInterleave, q.GetVIPInterleave())
+	}
+
+	for i := 1; i <= 6; i++ {
+		q.Add(fmt.Sprintf("main%d", i), false)
+	}
+	for i := 1; i <= 3; i++ {
+		if err := q.AddVIP(fmt.Sprintf("vip%d", i)); err != nil {
+			t.Fatalf("Failed to add vip%d: %v", i, err)
+		}
+	}
+
+	// A user can only be in one line
+	if err := q.AddVIP("MAIN1"); err == nil {
+		t.Error("Expected error adding a queued user to the VIP line")
+	}
+	if err := q.Add("vip1", false); err == nil {
+		t.Error("Expected error adding a VIP line user to the queue")
+	}
+
+	// Every 3rd pop comes from the VIP line until it runs out
+	expected := []string{"main1", "main2", "vip1", "main3", "main4", "vip2", "main5", "main6", "vip3"}
+	var popped []string
+	for range expected {
+		user, err := q.Pop()
+		if err != nil {
+			t.Fatalf("Unexpected pop error: %v", err)
+		}
+		popped = append(popped, user)
+	}
+	if strings.Join(popped, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected pop order %v, got %v", expected, popped)
+	}
+	if _, err := q.Pop(); err == nil {
+		t.Error("Expected error popping from empty lines")
+	}
+
+	// The VIP line is used when the main queue is empty, and PopN interleaves too
+	q.SetVIPInterleave(2)
+	q.Add("a", false)
+	q.Add("b", false)
+	q.AddVIP("v1")
+	q.AddVIP("v2")
+	q.AddVIP("v3")
+	users, err := q.PopN(10)
+	if err != nil {
+		t.Fatalf("Unexpected PopN error: %v", err)
+	}
+	if strings.Join(users, ",") != "a,v1,b,v2,v3" {
+		t.Errorf("Expected PopN order a,v1,b,v2,v3, got %v", users)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueVIPPersistence(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel")
+	q.Enable()
+	q.Add("main1", false)
+	q.AddVIP("vip1")
+
+	if err := q.SaveState(); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	q2 := queue.NewQueue(tempDir, "testchannel")
+	if list := q2.List(); len(list) != 1 || list[0] != "main1" {
+		t.Errorf("Expected main line to persist, got %v", list)
+	}
+	if vips := q2.ListVIP(); len(vips) != 1 || vips[0] != "vip1" {
+		t.Errorf("Expected VIP line to persist, got %v", vips)
+	}
+
+	// Removing a user works for either line
+	if !q2.Remove("VIP1") || len(q2.ListVIP()) != 0 {
+		t.Error("Expected vip1 to be removed from the VIP line")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueVIPClear(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel")
+	q.Enable()
+	q.Add("main1", false)
+	q.AddVIP("vip1")
+	q.AddVIP("vip2")
+
+	// Clearing empties the VIP line too and counts its users
+	if count := q.Clear(); count != 3 {
+		t.Errorf("Expected 3 users cleared, got %d", count)
+	}
+	if vips := q.ListVIP(); len(vips) != 0 {
+		t.Errorf("Expected clear to empty the VIP line, got %v", vips)
+	}
+
+	// Undo puts the VIP line back in front of later VIP joins, keeping
+	// anyone who moved to the main queue there
+	q.AddVIP("vip3")
+	q.Add("vip2", false)
+	count, err := q.UndoClear()
+	if err != nil {
+		t.Fatalf("Failed to undo clear: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 users restored, got %d", count)
+	}
+	if list := q.List(); strings.Join(list, ",") != "main1,vip2" {
+		t.Errorf("Expected [main1 vip2], got %v", list)
+	}
+	if vips := q.ListVIP(); strings.Join(vips, ",") != "vip1,vip3" {
+		t.Errorf("Expected [vip1 vip3], got %v", vips)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueJSON(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_queue_json")
+	commands.SetCommandManager(cm)
+	cm.GetQueue().Enable()
+
+	modMsg := createMockMessage("moduser", "!queue --json", true, false, false)
+	if response := commands.HandleQueue(modMsg, []string{"--json"}); response != `{"size":0,"users":[]}` {
+		t.Errorf("Expected an empty JSON queue, got %q", response)
+	}
+
+	for _, user := range []string{"a", "b", "c"} {
+		cm.GetQueue().Add(user, false)
+	}
+	response := commands.HandleQueue(modMsg, []string{"--json"})
+	var parsed struct {
+		Size      int      `json:"size"`
+		Users     []string `json:"users"`
+		Truncated bool     `json:"truncated"`
+	}
+	if err := json.Unmarshal([]byte(response), &parsed); err != nil {
+		t.Fatalf("Expected valid JSON, got %q: %v", response, err)
+	}
+	if parsed.Size != 3 || strings.Join(parsed.Users, ",") != "a,b,c" || parsed.Truncated {
+		t.Errorf("Unexpected JSON queue: %q", response)
+	}
+
+	// Regular users get the text reply
+	userMsg := createMockMessage("user1", "!queue --json", false, false, false)
+	if response := commands.HandleQueue(userMsg, []string{"--json"}); !strings.HasPrefix(response, "Queue: ") {
+		t.Errorf("Expected the text reply for a regular user, got %q", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueJSONTruncated(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_queue_json_truncated")
+	commands.SetCommandManager(cm)
+	cm.GetQueue().Enable()
+	cm.GetQueue().SetMaxSize(100)
+
+	for i := 0; i < 60; i++ {
+		cm.GetQueue().Add(fmt.Sprintf("longusername%02d", i), false)
+	}
+	response := commands.HandleQueue(createMockMessage("moduser", "!queue --json", true, false, false), []string{"--json"})
+	if len(response) > 500 {
+		t.Errorf("Expected the reply to fit in one chat message, got %d chars", len(response))
+	}
+
+	var parsed struct {
+		Size      int      `json:"size"`
+		Users     []string `json:"users"`
+		Truncated bool     `json:"truncated"`
+	}
+	if err := json.Unmarshal([]byte(response), &parsed); err != nil {
+		t.Fatalf("Expected valid JSON, got %q: %v", response, err)
+	}
+	if parsed.Size != 60 || !parsed.Truncated {
+		t.Errorf("Expected size 60 and truncated, got %q", response)
+	}
+	if len(parsed.Users) == 0 || len(parsed.Users) >= 60 || parsed.Users[0] != "longusername00" {
+		t.Errorf("Expected the front of the queue to be kept, got %v", parsed.Users)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueRange(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_queue_range")
+	commands.SetCommandManager(cm)
+	cm.GetQueue().Enable()
+	cm.GetQueue().SetMaxSize(0)
+	for i := 1; i <= 25; i++ {
+		cm.GetQueue().Add(fmt.Sprintf("user%d", i), false)
+	}
+	msg := createMockMessage("viewer", "!queue", false, false, false)
+
+	tests := []struct {
+		arg      string
+		expected string
+	}{
+		{"5-7", "Queue (pos 5-7 of 25): 5) user5, 6) user6, 7) user7."},
+		{"24-40", "Queue (pos 24-25 of 25): 24) user24, 25) user25."},
+		{"3-3", "Queue (pos 3-3 of 25): 3) user3."},
+		{"10-5", "Invalid range. The end must not be before the start."},
+		{"0-5", "Invalid range. Positions start at 1."},
+		{"30-40", "The queue only has 25 user(s)."},
+	}
+	for _, tt := range tests {
+		if response := commands.HandleQueue(msg, []string{tt.arg}); response != tt.expected {
+			t.Errorf("!queue %s: expected %q, got %q", tt.arg, tt.expected, response)
+		}
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueDumpAll(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_queue_dump")
+	commands.SetCommandManager(cm)
+	cm.GetQueue().Enable()
+	cm.GetQueue().Add("user1", false)
+	cm.GetQueue().Add("user2", false)
+
+	if response := commands.HandleQueue(createMockMessage("viewer", "!queue --all", false, false, false), []string{"--all"}); response != "Only moderators can dump the full queue." {
+		t.Errorf("Expected regular users to be refused, got %q", response)
+	}
+
+	response := commands.HandleQueue(createMockMessage("moduser", "!queue --all", true, false, false), []string{"--all"})
+	if !strings.HasPrefix(response, "Wrote all 2 users in the queue to queue_dump_testchannel_queue_dump_") {
+		t.Fatalf("Unexpected dump response: %q", response)
+	}
+	filename := strings.TrimSuffix(response[strings.LastIndex(response, " ")+1:], ".")
+	data, err := os.ReadFile(filepath.Join(cm.GetQueue().GetDataPath(), filename))
+	if err != nil {
+		t.Fatalf("Failed to read queue dump: %v", err)
+	}
+	if string(data) != "1) user1\n2) user2\n" {
+		t.Errorf("Unexpected queue dump contents: %q", data)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
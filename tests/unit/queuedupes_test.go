@@ -0,0 +1,57 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+func TestQueueDupes(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_queuedupes")
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+	cm.GetQueue().Enable()
+
+	mod := createMockMessage("moduser", "!queuedupes", true, false, false)
+
+	// A clean queue has nothing to report
+	for _, user := range []string{"alice", "bob"} {
+		if err := cm.GetQueue().Add(user, false); err != nil {
+			t.Fatalf("Failed to add %s: %v", user, err)
+		}
+	}
+	if response, _ := cm.HandleMessage(mod); response != "No duplicate entries in the queue." {
+		t.Errorf("Expected no duplicates, got %q", response)
+	}
+
+	// Near-duplicates that slip past the case-insensitive join check
+	for _, user := range []string{"carol", "bob\u200b", "Alice ", "@CAROL"} {
+		if err := cm.GetQueue().Add(user, true); err != nil {
+			t.Fatalf("Failed to add %q: %v", user, err)
+		}
+	}
+	expected := `Possible duplicates (3): #1 "alice" = #5 "Alice "; #2 "bob" = #4 "bob\u200b"; #3 "carol" = #6 "@CAROL". Use !remove <position> to clean up.`
+	// Another mod runs it, since moduser's !queuedupes is now on cooldown
+	otherMod := createMockMessage("othermod", "!queuedupes", true, false, false)
+	if response, _ := cm.HandleMessage(otherMod); response != expected {
+		t.Errorf("Expected %q, got %q", expected, response)
+	}
+
+	groups := cm.GetQueue().Duplicates()
+	if len(groups) != 3 || len(groups[1]) != 2 || groups[1][1].Username != "bob\u200b" || groups[1][1].Position != 4 {
+		t.Errorf("Expected bob's entries to be grouped, got %+v", groups)
+	}
+
+	// Regular users can't run the report
+	viewer := createMockMessage("viewer", "!queuedupes", false, false, false)
+	if response, _ := cm.HandleMessage(viewer); response == expected {
+		t.Error("Expected !queuedupes to be mod only")
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
@@ -0,0 +1,71 @@
+package unit
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+func TestQueueText(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_queuetext")
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+	cm.GetQueue().Enable()
+
+	whisperer := &fakeWhisperer{whispers: make(map[string][]string)}
+	cm.SetWhisperer(whisperer)
+	mod := createMockMessage("moduser", "!queuetext", true, false, false)
+
+	for _, user := range []string{"alice", "Bob", "carol_99"} {
+		cm.GetQueue().Add(user, false)
+	}
+
+	// The queue is whispered as one line, not posted in chat
+	if response := commands.HandleQueueText(mod, nil); response != "Whispered the queue (3 users) to @moduser." {
+		t.Errorf("Unexpected chat reply: %q", response)
+	}
+	if got := whisperer.whispers["moduser"]; len(got) != 1 || got[0] != "alice,Bob,carol_99" {
+		t.Errorf("Expected a single comma-separated line, got %q", got)
+	}
+
+	commands.HandleQueueText(mod, []string{"pipe"})
+	if got := whisperer.whispers["moduser"]; len(got) != 2 || got[1] != "alice|Bob|carol_99" {
+		t.Errorf("Expected a pipe-separated line, got %q", got)
+	}
+
+	if response := commands.HandleQueueText(mod, []string{"tab"}); response != "Usage: !queuetext [comma|pipe]" {
+		t.Errorf("Expected usage for an unknown separator, got %q", response)
+	}
+
+	// Long queues are split between names, keeping every line under the limit
+	for i := 0; i < 60; i++ {
+		cm.GetQueue().Add(fmt.Sprintf("longusername_%02d", i), false)
+	}
+	whisperer.whispers["moduser"] = nil
+	if response := commands.HandleQueueText(mod, nil); !strings.HasPrefix(response, "Whispered the queue (63 users) to @moduser in ") {
+		t.Errorf("Expected a multi-part reply, got %q", response)
+	}
+	parts := whisperer.whispers["moduser"]
+	if len(parts) < 2 {
+		t.Fatalf("Expected the queue to be split, got %d part(s)", len(parts))
+	}
+	var names []string
+	for _, part := range parts {
+		if len(part) > 500 || strings.Contains(part, "\n") {
+			t.Errorf("Expected each part to be one line of at most 500 characters, got %d: %q", len(part), part)
+		}
+		names = append(names, strings.Split(part, ",")...)
+	}
+	if strings.Join(names, ",") != strings.Join(cm.GetQueue().List(), ",") {
+		t.Errorf("Expected the parts to cover the queue in order, got %v", names)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
@@ -0,0 +1,79 @@
+package unit
+
+import (
+	"fmt"
+	"testing"
+
+	channelstats "github.com/pbuckles22/PBChatBot/internal/channel"
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+func TestHandleRank(t *testing.T) {
+	tempDir := t.TempDir()
+	stats := channelstats.NewChannelStats(tempDir)
+	stats.StartSession("", "", 0)
+
+	// chatter1 has 10 messages, chatter2 has 9, ... chatter10 has 1
+	for i := 1; i <= 10; i++ {
+		for j := 0; j <= 10-i; j++ {
+			stats.RecordChatMessage(fmt.Sprintf("chatter%d", i))
+		}
+	}
+
+	tests := []struct {
+		name     string
+		user     string
+		isMod    bool
+		args     []string
+		expected string
+	}{
+		{"top_five", "chatter3", false, nil, "@chatter3, you're rank 3 of 10 chatters (8 messages)."},
+		{"last_position", "chatter10", false, nil, "@chatter10, you're rank 10 of 10 chatters (1 messages)."},
+		{"no_messages", "lurker", false, nil, "@lurker, you're rank 11 of 10 chatters (0 messages)."},
+		{"mod_lookup", "moduser", true, []string{"Chatter1"}, "Chatter1 is rank 1 of 10 chatters (10 messages)."},
+		{"non_mod_lookup", "chatter5", false, []string{"chatter1"}, "Only moderators can look up another user's rank."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := createMockMessage(tt.user, "!rank", tt.isMod, false, false)
+			response := commands.HandleRank(stats, msg, tt.args)
+			if response != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, response)
+			}
+		})
+	}
+}
+
+func TestChatterRankCacheInvalidation(t *testing.T) {
+	tempDir := t.TempDir()
+	stats := channelstats.NewChannelStats(tempDir)
+	stats.StartSession("", "", 0)
+
+	stats.RecordChatMessage("alice")
+	stats.RecordChatMessage("alice")
+	stats.RecordChatMessage("bob")
+
+	if rank, chatters, _ := stats.GetChatterRank("bob"); rank != 2 || chatters != 2 {
+		t.Errorf("Expected bob at rank 2 of 2, got %d of %d", rank, chatters)
+	}
+
+	// New messages must be reflected without waiting for the cache to expire
+	stats.RecordChatMessage("bob")
+	stats.RecordChatMessage("bob")
+	stats.RecordChatMessage("carol")
+
+	rank, chatters, messages := stats.GetChatterRank("bob")
+	if rank != 1 || chatters != 3 || messages != 3 {
+		t.Errorf("Expected bob at rank 1 of 3 with 3 messages, got %d of %d with %d", rank, chatters, messages)
+	}
+
+	// Tied chatters share a rank
+	if rank, _, _ := stats.GetChatterRank("alice"); rank != 2 {
+		t.Errorf("Expected alice at rank 2, got %d", rank)
+	}
+	stats.RecordChatMessage("carol")
+	if rank, _, _ := stats.GetChatterRank("carol"); rank != 2 {
+		t.Errorf("Expected carol to tie alice at rank 2, got %d", rank)
+	}
+}
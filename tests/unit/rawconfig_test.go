@@ -0,0 +1,123 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+	"github.com/pbuckles22/PBChatBot/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// setupRawConfig returns a command manager whose config has secrets set
+func setupRawConfig(t *testing.T) (*commands.CommandManager, string) {
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_rawconfig")
+	commands.SetCommandManager(cm)
+
+	cfg := &config.Config{
+		BotName:      "testbot",
+		Channel:      "testchannel_rawconfig",
+		DataPath:     tempDir,
+		OAuth:        "oauth:supersecret",
+		ClientSecret: "clientsecret123",
+	}
+	cfg.LastFM.APIKey = "lastfmkey456"
+	cfg.LastFM.Username = "listener"
+	cfg.Commands.Queue.MaxSize = 42
+	cfg.Commands.Cooldowns.Default = 30
+	cfg.Commands.Cooldowns.VIP = 15
+	cm.SetConfig(cfg)
+	return cm, tempDir
+}
+
+// readRawConfig runs !rawconfig as the broadcaster and returns the file it wrote
+func readRawConfig(t *testing.T, tempDir string, args []string) string {
+	msg := createMockMessage("testchannel_rawconfig", "!rawconfig", false, false, true)
+	response := commands.HandleRawConfig(msg, args)
+	if !strings.HasPrefix(response, "Raw config written to rawconfig_testchannel_rawconfig_") {
+		t.Fatalf("Expected raw config confirmation, got '%s'", response)
+	}
+
+	filename := strings.TrimSuffix(strings.TrimPrefix(response, "Raw config written to "), ".")
+	data, err := os.ReadFile(filepath.Join(tempDir, filename))
+	if err != nil {
+		t.Fatalf("Failed to read raw config file: %v", err)
+	}
+	os.Remove(filepath.Join(tempDir, filename))
+	return string(data)
+}
+
+func TestHandleRawConfigFull(t *testing.T) {
+	_, tempDir := setupRawConfig(t)
+
+	modMsg := createMockMessage("moduser", "!rawconfig", true, false, false)
+	if response := commands.HandleRawConfig(modMsg, []string{}); !strings.Contains(response, "only be used by the broadcaster") {
+		t.Errorf("Expected broadcaster-only message, got '%s'", response)
+	}
+
+	data := readRawConfig(t, tempDir, []string{})
+	var raw config.Config
+	if err := yaml.Unmarshal([]byte(data), &raw); err != nil {
+		t.Fatalf("Raw config should be valid YAML: %v", err)
+	}
+	if raw.Channel != "testchannel_rawconfig" || raw.Commands.Queue.MaxSize != 42 || raw.LastFM.Username != "listener" {
+		t.Errorf("Expected non-secret values in raw config, got %+v", raw)
+	}
+}
+
+func TestHandleRawConfigRedactsSecrets(t *testing.T) {
+	cm, tempDir := setupRawConfig(t)
+
+	data := readRawConfig(t, tempDir, []string{})
+	for _, secret := range []string{"supersecret", "clientsecret123", "lastfmkey456"} {
+		if strings.Contains(data, secret) {
+			t.Errorf("Raw config should not contain %q, got:\n%s", secret, data)
+		}
+	}
+
+	var raw config.Config
+	if err := yaml.Unmarshal([]byte(data), &raw); err != nil {
+		t.Fatalf("Raw config should be valid YAML: %v", err)
+	}
+	if raw.OAuth != config.RedactedValue || raw.ClientSecret != config.RedactedValue || raw.LastFM.APIKey != config.RedactedValue {
+		t.Errorf("Expected secrets to be '%s', got %+v", config.RedactedValue, raw)
+	}
+
+	// Unset secrets are left out rather than shown as redacted
+	if strings.Contains(data, "refresh_token") {
+		t.Errorf("Expected unset refresh_token to be omitted, got:\n%s", data)
+	}
+
+	// The in-memory config keeps its secrets
+	if cm.GetConfig().OAuth != "oauth:supersecret" {
+		t.Error("!rawconfig should not modify the in-memory config")
+	}
+}
+
+func TestHandleRawConfigFieldFilter(t *testing.T) {
+	_, tempDir := setupRawConfig(t)
+
+	data := readRawConfig(t, tempDir, []string{"commands.cooldowns"})
+	var cooldowns map[string]int
+	if err := yaml.Unmarshal([]byte(data), &cooldowns); err != nil {
+		t.Fatalf("Filtered raw config should be valid YAML: %v", err)
+	}
+	if cooldowns["default"] != 30 || cooldowns["vip"] != 15 || len(cooldowns) != 3 {
+		t.Errorf("Expected only the cooldowns section, got %v", cooldowns)
+	}
+
+	// Secrets stay redacted when selected directly
+	if data := readRawConfig(t, tempDir, []string{"lastfm"}); strings.Contains(data, "lastfmkey456") {
+		t.Errorf("Filtered raw config should not contain secrets, got:\n%s", data)
+	}
+
+	msg := createMockMessage("testchannel_rawconfig", "!rawconfig nope", false, false, true)
+	expected := `Error writing raw config: no config field "nope"`
+	if response := commands.HandleRawConfig(msg, []string{"nope"}); response != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, response)
+	}
+}
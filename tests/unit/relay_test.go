@@ -0,0 +1,158 @@
+package unit
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+// mockChannelSender records messages sent to other channels and the
+// channels it was told to leave
+type mockChannelSender struct {
+	mu       sync.Mutex
+	sent     map[string][]string
+	departed []string
+}
+
+func (s *mockChannelSender) SayTo(channel, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sent == nil {
+		s.sent = make(map[string][]string)
+	}
+	s.sent[channel] = append(s.sent[channel], message)
+}
+
+func (s *mockChannelSender) Depart(channel string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.departed = append(s.departed, channel)
+}
+
+func (s *mockChannelSender) messages(channel string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.sent[channel]...)
+}
+
+func setupRelayTest(t *testing.T, channel string) (*commands.CommandManager, *mockChannelSender, string) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, channel)
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+
+	sender := &mockChannelSender{}
+	cm.SetChannelSender(sender)
+	return cm, sender, tempDir
+}
+
+func TestHandleStartStopRelay(t *testing.T) {
+	cm, sender, _ := setupRelayTest(t, "testchannel_relay")
+
+	// Only the broadcaster can start a relay
+	response := commands.HandleStartRelay(createMockMessage("moduser", "!startrelay otherchannel", true, false, false), []string{"otherchannel"})
+	if response != "This command can only be used by the broadcaster." {
+		t.Errorf("Expected broadcaster-only response, got '%s'", response)
+	}
+
+	response = commands.HandleStartRelay(createMockMessage("testchannel_relay", "!startrelay", false, false, true), []string{})
+	if response != "Usage: !startrelay <channel>" {
+		t.Errorf("Expected usage response, got '%s'", response)
+	}
+
+	response = commands.HandleStartRelay(createMockMessage("testchannel_relay", "!startrelay #OtherChannel", false, false, true), []string{"#OtherChannel"})
+	if response != "Relaying chat to #otherchannel. Use !stoprelay to stop." {
+		t.Errorf("Unexpected start response: '%s'", response)
+	}
+
+	response = commands.HandleStartRelay(createMockMessage("testchannel_relay", "!startrelay third", false, false, true), []string{"third"})
+	if response != "Could not start relay: already relaying to #otherchannel" {
+		t.Errorf("Expected already-running response, got '%s'", response)
+	}
+
+	// Non-command messages are relayed
+	if _, isCommand := cm.HandleMessage(createMockMessage("viewer", "hello there", false, false, false)); isCommand {
+		t.Error("Plain chat should not be treated as a command")
+	}
+	// Commands are not
+	cm.HandleMessage(createMockMessage("viewer", "!ping", false, false, false))
+
+	sent := sender.messages("otherchannel")
+	if len(sent) != 1 || sent[0] != "[relay: testchannel_relay] viewer: hello there" {
+		t.Errorf("Expected one relayed message, got %v", sent)
+	}
+
+	response = commands.HandleStopRelay(createMockMessage("testchannel_relay", "!stoprelay", false, false, true), []string{})
+	if response != "Stopped relaying chat to #otherchannel." {
+		t.Errorf("Unexpected stop response: '%s'", response)
+	}
+	if len(sender.departed) != 1 || sender.departed[0] != "otherchannel" {
+		t.Errorf("Expected the bot to leave #otherchannel, departed %v", sender.departed)
+	}
+	response = commands.HandleStopRelay(createMockMessage("testchannel_relay", "!stoprelay", false, false, true), []string{})
+	if response != "No relay is running." {
+		t.Errorf("Expected no-relay response, got '%s'", response)
+	}
+
+	cm.HandleMessage(createMockMessage("viewer", "anyone here?", false, false, false))
+	if sent := sender.messages("otherchannel"); len(sent) != 1 {
+		t.Errorf("Expected no messages relayed after stopping, got %v", sent)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestRelayRateLimit(t *testing.T) {
+	cm, sender, _ := setupRelayTest(t, "testchannel_relay_limit")
+	if err := cm.StartRelay("otherchannel"); err != nil {
+		t.Fatalf("Failed to start relay: %v", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		cm.HandleMessage(createMockMessage("viewer", "spam", false, false, false))
+	}
+	if sent := sender.messages("otherchannel"); len(sent) != 5 {
+		t.Errorf("Expected 5 relayed messages within one second, got %d", len(sent))
+	}
+
+	// The window frees up after a second
+	time.Sleep(1100 * time.Millisecond)
+	cm.HandleMessage(createMockMessage("viewer", "later", false, false, false))
+	if sent := sender.messages("otherchannel"); len(sent) != 6 {
+		t.Errorf("Expected relaying to resume after a second, got %d messages", len(sent))
+	}
+}
+
+func TestRelayPersistence(t *testing.T) {
+	cm, _, tempDir := setupRelayTest(t, "testchannel_relay_persist")
+	if err := cm.StartRelay("otherchannel"); err != nil {
+		t.Fatalf("Failed to start relay: %v", err)
+	}
+
+	// A new manager on the same data path restores the relay
+	commands.SetCommandManager(nil)
+	restored := commands.NewCommandManager("!", tempDir, "testchannel_relay_persist")
+	if err := restored.LoadRelay(); err != nil {
+		t.Fatalf("Failed to load relay: %v", err)
+	}
+	if target := restored.GetRelayTarget(); target != "otherchannel" {
+		t.Errorf("Expected restored relay to otherchannel, got '%s'", target)
+	}
+
+	if _, err := restored.StopRelay(); err != nil {
+		t.Fatalf("Failed to stop relay: %v", err)
+	}
+	commands.SetCommandManager(nil)
+	stopped := commands.NewCommandManager("!", tempDir, "testchannel_relay_persist")
+	if err := stopped.LoadRelay(); err != nil {
+		t.Fatalf("Failed to load relay: %v", err)
+	}
+	if target := stopped.GetRelayTarget(); target != "" {
+		t.Errorf("Expected no relay after stopping, got '%s'", target)
+	}
+}
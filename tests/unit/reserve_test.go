@@ -0,0 +1,81 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+func TestHandleReserve(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_reserve")
+	commands.SetCommandManager(cm)
+	cm.GetQueue().Enable()
+
+	cm.GetQueue().Add("user1", false)
+	cm.GetQueue().Add("user2", false)
+	cm.GetQueue().Add("user3", false)
+
+	mod := createMockMessage("moduser", "!reserve", true, false, false)
+
+	t.Run("reserve_at_position", func(t *testing.T) {
+		response := commands.HandleReserve(mod, []string{"guest", "2"})
+		if response != "Reserved position 2 for guest" {
+			t.Errorf("Expected 'Reserved position 2 for guest', got '%s'", response)
+		}
+		if users := cm.GetQueue().List(); strings.Join(users, ",") != "user1,guest,user2,user3" {
+			t.Errorf("Expected guest at position 2, got %v", users)
+		}
+		if !cm.GetQueue().IsReserved("guest") {
+			t.Error("Expected guest to be marked reserved")
+		}
+	})
+
+	t.Run("reserve_defaults_to_front", func(t *testing.T) {
+		response := commands.HandleReserve(mod, []string{"vipguest"})
+		if response != "Reserved position 1 for vipguest" {
+			t.Errorf("Expected 'Reserved position 1 for vipguest', got '%s'", response)
+		}
+	})
+
+	t.Run("queue_shows_reserved", func(t *testing.T) {
+		response := commands.HandleQueue(mod, []string{})
+		expected := "Queue: vipguest (reserved), user1, guest (reserved), user2, user3 (5 total)"
+		if response != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, response)
+		}
+	})
+
+	t.Run("unreserve_non_reserved", func(t *testing.T) {
+		response := commands.HandleUnreserve(mod, []string{"user1"})
+		if response != "user1 doesn't have a reserved slot." {
+			t.Errorf("Unexpected response: '%s'", response)
+		}
+		if cm.GetQueue().Position("user1") == -1 {
+			t.Error("user1 should still be in the queue")
+		}
+	})
+
+	t.Run("unreserve_removes", func(t *testing.T) {
+		response := commands.HandleUnreserve(mod, []string{"Guest"})
+		if response != "Released reserved slot for guest" {
+			t.Errorf("Expected 'Released reserved slot for guest', got '%s'", response)
+		}
+		if cm.GetQueue().Position("guest") != -1 {
+			t.Error("Expected guest to be removed from the queue")
+		}
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		if response := commands.HandleReserve(mod, []string{}); response != "Usage: !reserve <username> [position]" {
+			t.Errorf("Unexpected response: '%s'", response)
+		}
+	})
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
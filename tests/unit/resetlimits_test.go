@@ -0,0 +1,63 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+	"github.com/pbuckles22/PBChatBot/internal/queue"
+)
+
+func TestQueueRejoinCooldown(t *testing.T) {
+	q := queue.NewQueue(t.TempDir(), "testchannel")
+	q.Enable()
+	q.SetRejoinCooldown(time.Minute)
+
+	q.Add("user1", false)
+	q.Pop()
+
+	if err := q.Add("user1", false); err == nil || !strings.HasPrefix(err.Error(), "you can rejoin in") {
+		t.Errorf("Expected rejoin cooldown error, got %v", err)
+	}
+	if err := q.Add("user1", true); err != nil {
+		t.Errorf("Mods should bypass the rejoin cooldown, got %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestHandleResetLimits(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_resetlimits")
+	commands.SetCommandManager(cm)
+	cm.GetQueue().Enable()
+	cm.GetQueue().SetRejoinCooldown(time.Hour)
+
+	cm.GetQueue().Add("served", false)
+	cm.GetQueue().Add("waiting", false)
+	cm.GetQueue().Pop()
+
+	join := createMockMessage("served", "!join", false, false, false)
+	if response := commands.HandleJoin(join, nil); response != "@served you were served recently and can rejoin in 1h0m0s." {
+		t.Fatalf("Expected served user to be blocked, got '%s'", response)
+	}
+
+	response := commands.HandleResetLimits(createMockMessage("moduser", "!resetlimits", true, false, false), nil)
+	if response != "Queue limits reset. Cleared rejoin cooldowns for 1 user(s)." {
+		t.Errorf("Unexpected reset response: '%s'", response)
+	}
+
+	// The queue itself is untouched
+	if list := cm.GetQueue().List(); len(list) != 1 || list[0] != "waiting" {
+		t.Errorf("Expected queue to be unchanged, got %v", list)
+	}
+
+	if response := commands.HandleJoin(join, nil); response != "served joined queue at position 2 (2 total)" {
+		t.Errorf("Expected served user to rejoin immediately, got '%s'", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
@@ -0,0 +1,119 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+func TestRestrictCommand(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_restrict")
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+	// Only the usage cap should hold !ping back here
+	cm.GetCooldownManager().SetCooldown("ping", commands.CooldownConfig{})
+
+	mod := createMockMessage("moduser", "!restrict", true, false, false)
+	if response := commands.HandleRestrict(mod, []string{"@User42", "!ping", "2"}); response != "User42 can use !ping 2 more time(s) this session." {
+		t.Errorf("Unexpected restrict response: %q", response)
+	}
+
+	// Uses under the cap run the command
+	for i := 0; i < 2; i++ {
+		response, _ := cm.HandleMessage(createMockMessage("user42", "!ping", false, false, false))
+		if strings.Contains(response, "limit") {
+			t.Fatalf("Expected use %d to run, got %q", i+1, response)
+		}
+	}
+
+	// The next one is refused
+	response, _ := cm.HandleMessage(createMockMessage("user42", "!ping", false, false, false))
+	if response != "@user42, you've reached your limit for !ping this session." {
+		t.Errorf("Expected the limit reply, got %q", response)
+	}
+
+	// Other users and commands aren't affected
+	if response, _ := cm.HandleMessage(createMockMessage("user43", "!ping", false, false, false)); strings.Contains(response, "limit") {
+		t.Errorf("Expected another user to be unrestricted, got %q", response)
+	}
+
+	// Lifting the cap lets the user run it again
+	if response := commands.HandleUnrestrict(mod, []string{"user42", "ping"}); response != "Lifted the !ping limit for user42." {
+		t.Errorf("Unexpected unrestrict response: %q", response)
+	}
+	if response, _ := cm.HandleMessage(createMockMessage("user42", "!ping", false, false, false)); strings.Contains(response, "limit") {
+		t.Errorf("Expected the command to run after unrestrict, got %q", response)
+	}
+	if response := commands.HandleUnrestrict(mod, []string{"user42", "ping"}); response != "user42 has no limit on !ping." {
+		t.Errorf("Expected no limit to lift, got %q", response)
+	}
+}
+
+func TestRestrictBroadcasterExempt(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_restrict_broadcaster")
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+
+	mod := createMockMessage("moduser", "!restrict", true, false, false)
+	commands.HandleRestrict(mod, []string{"streamer", "ping", "0"})
+	for i := 0; i < 3; i++ {
+		response, _ := cm.HandleMessage(createMockMessage("streamer", "!ping", false, false, true))
+		if strings.Contains(response, "limit") {
+			t.Fatalf("Expected the broadcaster to be exempt, got %q", response)
+		}
+	}
+}
+
+func TestRestrictPersistence(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_restrict_persist")
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+
+	commands.HandleRestrict(createMockMessage("moduser", "!restrict", true, false, false), []string{"user42", "ping", "1"})
+	cm.HandleMessage(createMockMessage("user42", "!ping", false, false, false))
+
+	// Uses are saved in batches; the cap itself was saved right away
+	if err := cm.SaveRestrictions(); err != nil {
+		t.Fatalf("Failed to save restrictions: %v", err)
+	}
+
+	// A new manager picks up the cap and the use already made
+	commands.SetCommandManager(nil)
+	cm2 := commands.NewCommandManager("!", tempDir, "testchannel_restrict_persist")
+	commands.SetCommandManager(cm2)
+	commands.RegisterBasicCommands(cm2)
+	if err := cm2.LoadRestrictions(); err != nil {
+		t.Fatalf("Failed to load restrictions: %v", err)
+	}
+	response, _ := cm2.HandleMessage(createMockMessage("user42", "!ping", false, false, false))
+	if response != "@user42, you've reached your limit for !ping this session." {
+		t.Errorf("Expected the saved cap to apply, got %q", response)
+	}
+
+	// A new stream starts the count over, and the reset is saved
+	cm2.ResetRestrictionUses()
+	if limit := cm2.GetCooldownManager().Restrictions()["user42"]["ping"]; limit.Uses != 0 || limit.MaxUses != 1 {
+		t.Errorf("Expected the cap kept with no uses, got %+v", limit)
+	}
+	cm3 := commands.NewCommandManager("!", tempDir, "testchannel_restrict_persist")
+	if err := cm3.LoadRestrictions(); err != nil {
+		t.Fatalf("Failed to load restrictions: %v", err)
+	}
+	if limit := cm3.GetCooldownManager().Restrictions()["user42"]["ping"]; limit.Uses != 0 {
+		t.Errorf("Expected the reset to be saved, got %+v", limit)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
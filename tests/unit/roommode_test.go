@@ -0,0 +1,74 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+	twitchbot "github.com/pbuckles22/PBChatBot/internal/twitch"
+)
+
+// fakeRoomModes reports fixed chat restrictions
+type fakeRoomModes struct {
+	modes twitchbot.RoomModes
+	known bool
+}
+
+func (f fakeRoomModes) RoomModes() (twitchbot.RoomModes, bool) {
+	return f.modes, f.known
+}
+
+func TestFormatRoomModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		modes    twitchbot.RoomModes
+		expected string
+	}{
+		{
+			"all_modes",
+			twitchbot.RoomModes{EmoteOnly: true, FollowersOnly: 10, SubsOnly: true, Slow: 30, UniqueChat: true},
+			"Chat modes: emote-only, followers-only (10m), slow (30s), subs-only, unique-chat",
+		},
+		{
+			"followers_any_duration",
+			twitchbot.RoomModes{FollowersOnly: 0},
+			"Chat modes: followers-only",
+		},
+		{
+			"none",
+			twitchbot.RoomModes{FollowersOnly: -1},
+			"Chat modes: none",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commands.FormatRoomModes(tt.modes); got != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestHandleRoomMode(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_roommode")
+	commands.SetCommandManager(cm)
+	commands.RegisterRoomModeCommand(cm, fakeRoomModes{})
+
+	response, _ := cm.HandleMessage(createMockMessage("moduser", "!roommode", true, false, false))
+	if response != "Room state hasn't been received from Twitch yet." {
+		t.Errorf("Expected unknown room state message, got '%s'", response)
+	}
+
+	commands.RegisterRoomModeCommand(cm, fakeRoomModes{twitchbot.RoomModes{FollowersOnly: -1, Slow: 120}, true})
+	// Another mod asks, since moduser's !roommode is now on cooldown
+	response, _ = cm.HandleMessage(createMockMessage("othermod", "!roommode", true, false, false))
+	if response != "Chat modes: slow (2m)" {
+		t.Errorf("Expected 'Chat modes: slow (2m)', got '%s'", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
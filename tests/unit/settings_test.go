@@ -0,0 +1,104 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+func TestSettingsRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "channel_settings_testchannel.json")
+	settings := commands.NewSettings(path)
+
+	if err := settings.Set("announce_joins", true); err != nil {
+		t.Fatalf("Failed to save setting: %v", err)
+	}
+	if err := settings.Set(commands.SettingSlowMode, 30); err != nil {
+		t.Fatalf("Failed to save setting: %v", err)
+	}
+	if err := settings.Set("trusted_users", []string{"alice", "bob"}); err != nil {
+		t.Fatalf("Failed to save setting: %v", err)
+	}
+	if err := settings.Set("serve_mode", "fifo"); err != nil {
+		t.Fatalf("Failed to save setting: %v", err)
+	}
+	if err := settings.Delete("serve_mode"); err != nil {
+		t.Fatalf("Failed to delete setting: %v", err)
+	}
+
+	// A fresh store reading the same file sees every saved setting
+	reloaded := commands.NewSettings(path)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Failed to load settings: %v", err)
+	}
+
+	var announce bool
+	if !reloaded.Get("announce_joins", &announce) || !announce {
+		t.Errorf("Expected announce_joins to be restored as true, got %v", announce)
+	}
+	var slow int
+	if !reloaded.Get(commands.SettingSlowMode, &slow) || slow != 30 {
+		t.Errorf("Expected slow mode to be restored as 30, got %d", slow)
+	}
+	var trusted []string
+	if !reloaded.Get("trusted_users", &trusted) || !reflect.DeepEqual(trusted, []string{"alice", "bob"}) {
+		t.Errorf("Expected trusted users to be restored, got %v", trusted)
+	}
+	var mode string
+	if reloaded.Get("serve_mode", &mode) {
+		t.Errorf("Expected deleted setting to stay deleted, got %q", mode)
+	}
+
+	expectedKeys := []string{"announce_joins", commands.SettingSlowMode, "trusted_users"}
+	if keys := reloaded.Keys(); !reflect.DeepEqual(keys, expectedKeys) {
+		t.Errorf("Expected keys %v, got %v", expectedKeys, keys)
+	}
+
+	// A value of the wrong type isn't decoded
+	if reloaded.Get("trusted_users", &slow) {
+		t.Error("Expected decoding a list into an int to fail")
+	}
+}
+
+func TestSettingsLoadMissingFile(t *testing.T) {
+	settings := commands.NewSettings(filepath.Join(t.TempDir(), "missing.json"))
+	if err := settings.Load(); err != nil {
+		t.Errorf("Expected no error for a missing settings file, got %v", err)
+	}
+	if keys := settings.Keys(); len(keys) != 0 {
+		t.Errorf("Expected no settings, got %v", keys)
+	}
+}
+
+func TestCommandManagerSettingsPersist(t *testing.T) {
+	dataPath := t.TempDir()
+
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", dataPath, "testchannel_settings")
+	if err := cm.GetSettings().Set(commands.SettingSlowMode, 15); err != nil {
+		t.Fatalf("Failed to save setting: %v", err)
+	}
+	expectedPath := filepath.Join(cm.GetQueue().GetDataPath(), "channel_settings_testchannel_settings.json")
+	if cm.GetSettings().Path() != expectedPath {
+		t.Errorf("Expected settings path %s, got %s", expectedPath, cm.GetSettings().Path())
+	}
+	if _, err := os.Stat(expectedPath); err != nil {
+		t.Errorf("Expected settings file to be written: %v", err)
+	}
+
+	// Simulate a restart
+	commands.SetCommandManager(nil)
+	restarted := commands.NewCommandManager("!", dataPath, "testchannel_settings")
+	var slow int
+	if !restarted.GetSettings().Get(commands.SettingSlowMode, &slow) || slow != 15 {
+		t.Errorf("Expected slow mode 15 after restart, got %d", slow)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
@@ -0,0 +1,91 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+	"github.com/pbuckles22/PBChatBot/internal/config"
+)
+
+func newShowConfigTestConfig() *config.Config {
+	cfg := &config.Config{
+		BotName:      "testbot",
+		Channel:      "testchannel_showconfig",
+		Timezone:     "America/New_York",
+		OAuth:        "oauth:supersecret",
+		ClientSecret: "clientsecret123",
+	}
+	cfg.Commands.Queue.MaxSize = 100
+	cfg.Commands.Queue.DefaultPosition = 1
+	cfg.Commands.Queue.DefaultPopCount = 2
+	cfg.Commands.Cooldowns.Default = 5
+	cfg.Commands.Cooldowns.Moderator = 2
+	cfg.Commands.Cooldowns.VIP = 3
+	return cfg
+}
+
+func TestHandleShowConfig(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_showconfig")
+	commands.SetCommandManager(cm)
+	cm.SetConfig(newShowConfigTestConfig())
+
+	msg := createMockMessage("moduser", "!showconfig", true, false, false)
+	response := commands.HandleShowConfig(msg, []string{})
+
+	// Sensitive fields are absent
+	if strings.Contains(response, "supersecret") || strings.Contains(response, "clientsecret123") {
+		t.Errorf("Response should not contain secrets, got '%s'", response)
+	}
+
+	// All expected non-sensitive fields are present
+	expected := []string{
+		"bot=testbot",
+		"channel=testchannel_showconfig",
+		"prefix=!",
+		"timezone=America/New_York",
+		"queue max=100",
+		"default position=1",
+		"default pop=2",
+		"cooldowns default=5s mod=2s vip=3s",
+	}
+	for _, field := range expected {
+		if !strings.Contains(response, field) {
+			t.Errorf("Expected '%s' in response, got '%s'", field, response)
+		}
+	}
+
+	if len(response) > 450 {
+		t.Errorf("Response should fit in 450 characters, got %d", len(response))
+	}
+}
+
+func TestHandleShowConfigTooLong(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_showconfig_long")
+	commands.SetCommandManager(cm)
+
+	cfg := newShowConfigTestConfig()
+	cfg.BotName = strings.Repeat("b", 450)
+	cm.SetConfig(cfg)
+
+	msg := createMockMessage("moduser", "!showconfig", true, false, false)
+	response := commands.HandleShowConfig(msg, []string{})
+
+	if len(response) > 450 {
+		t.Errorf("Response should fit in 450 characters, got %d", len(response))
+	}
+
+	if !strings.Contains(response, "channel=testchannel_showconfig") || !strings.Contains(response, "queue max=100") {
+		t.Errorf("Expected the most common fields in response, got '%s'", response)
+	}
+
+	if !strings.Contains(response, "!exportconfig") {
+		t.Errorf("Expected pointer to !exportconfig, got '%s'", response)
+	}
+}
@@ -0,0 +1,57 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+func TestSilentCommand(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_silent")
+	commands.SetCommandManager(cm)
+
+	count := 0
+	cm.RegisterCommand(&commands.Command{
+		Name:     "vote",
+		Triggers: []string{"+1"},
+		Silent:   true,
+		Handler: func(message twitch.PrivateMessage, args []string) string {
+			count++
+			return "Vote counted!"
+		},
+	})
+
+	// The handler runs but nothing is sent to chat, by prefix or by trigger
+	for _, msg := range []twitch.PrivateMessage{
+		createMockMessage("voter1", "!vote", false, false, false),
+		createMockMessage("voter2", "+1", false, false, false),
+	} {
+		response, isCommand := cm.HandleMessage(msg)
+		if !isCommand || response != "" {
+			t.Errorf("Message %q: expected a silent command, got (%q, %v)", msg.Message, response, isCommand)
+		}
+	}
+	if count != 2 {
+		t.Errorf("Expected the handler to run twice, ran %d times", count)
+	}
+
+	// Cooldowns still apply: repeats are refused without running the handler
+	response, isCommand := cm.HandleMessage(createMockMessage("voter1", "!vote", false, false, false))
+	if !isCommand || !strings.Contains(response, "on cooldown") {
+		t.Errorf("Expected the cooldown notice for a repeated silent command, got (%q, %v)", response, isCommand)
+	}
+	if response, isCommand := cm.HandleMessage(createMockMessage("voter2", "+1", false, false, false)); response != "" || isCommand {
+		t.Errorf("Expected a repeated keyword on cooldown to be normal chat, got (%q, %v)", response, isCommand)
+	}
+	if count != 2 {
+		t.Errorf("Expected the handler not to run while on cooldown, ran %d times", count)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
@@ -0,0 +1,57 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+	"github.com/pbuckles22/PBChatBot/internal/music"
+)
+
+// fakeMusicProvider returns a fixed track or error
+type fakeMusicProvider struct {
+	track *music.Track
+	err   error
+}
+
+func (p *fakeMusicProvider) CurrentTrack() (*music.Track, error) {
+	return p.track, p.err
+}
+
+func TestHandleSong(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_song")
+	commands.SetCommandManager(cm)
+
+	message := createMockMessage("viewer", "!song", false, false, false)
+	if response := commands.HandleSong(message, nil); response != "Song lookups are not set up for this channel." {
+		t.Errorf("Expected not-configured response, got '%s'", response)
+	}
+
+	provider := &fakeMusicProvider{track: &music.Track{
+		Name:   "One More Time",
+		Artist: "Daft Punk",
+		Album:  "Discovery",
+		URL:    "https://open.spotify.com/track/0DiWol3AO6WpXZgp0goxAV",
+	}}
+	cm.SetMusicProvider(provider)
+	expected := "🎵 Now playing: One More Time by Daft Punk (Discovery) — open.spotify.com/track/0DiWol3AO6WpXZgp0goxAV"
+	if response := commands.HandleSong(message, nil); response != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, response)
+	}
+
+	provider.track = nil
+	if response := commands.HandleSong(message, nil); response != "Nothing is playing right now." {
+		t.Errorf("Expected nothing-playing response, got '%s'", response)
+	}
+
+	provider.err = errors.New("spotify request failed with status 503")
+	if response := commands.HandleSong(message, nil); response != "Couldn't look up the current song right now." {
+		t.Errorf("Expected error response, got '%s'", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
@@ -0,0 +1,77 @@
+package unit
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	channelstats "github.com/pbuckles22/PBChatBot/internal/channel"
+)
+
+func TestStatsRetentionKeepsLastSessions(t *testing.T) {
+	tempDir := t.TempDir()
+	stats := channelstats.NewChannelStats(tempDir)
+	stats.SetRetention(2, 0)
+
+	// Run four sessions with different titles so none are resumed
+	for i := 1; i <= 4; i++ {
+		stats.StartSession("Game", fmt.Sprintf("Stream %d", i), 10)
+		stats.RecordChatMessage(fmt.Sprintf("user%d", i))
+		stats.RecordChatMessage("regular")
+		stats.EndSession()
+	}
+
+	current := stats.GetStats()
+	if len(current.Sessions) != 2 {
+		t.Fatalf("Expected 2 sessions to be kept, got %d", len(current.Sessions))
+	}
+	if current.Sessions[0].Title != "Stream 3" || current.Sessions[1].Title != "Stream 4" {
+		t.Errorf("Expected the two most recent sessions to be kept, got %q and %q", current.Sessions[0].Title, current.Sessions[1].Title)
+	}
+
+	// Totals still cover every session
+	if current.TotalSessions != 4 {
+		t.Errorf("Expected 4 total sessions, got %d", current.TotalSessions)
+	}
+	if current.ChatterTotals["regular"] != 4 || current.ChatterTotals["user1"] != 1 {
+		t.Errorf("Expected chatter totals from pruned sessions to be kept, got %v", current.ChatterTotals)
+	}
+	if current.UniqueChatters != 5 {
+		t.Errorf("Expected 5 unique chatters, got %d", current.UniqueChatters)
+	}
+
+	// The pruned history is what ends up on disk
+	reloaded := channelstats.NewChannelStats(tempDir).GetStats()
+	if len(reloaded.Sessions) != 2 || reloaded.TotalSessions != 4 {
+		t.Errorf("Expected 2 saved sessions and 4 total, got %d and %d", len(reloaded.Sessions), reloaded.TotalSessions)
+	}
+	if reloaded.TotalStreamTime != current.TotalStreamTime {
+		t.Errorf("Expected total stream time %v after reload, got %v", current.TotalStreamTime, reloaded.TotalStreamTime)
+	}
+}
+
+func TestStatsRetentionDropsOldSessions(t *testing.T) {
+	tempDir := t.TempDir()
+	stats := channelstats.NewChannelStats(tempDir)
+	stats.SetRetention(0, 30)
+
+	now := time.Now()
+	stats.Sessions = []channelstats.StreamSession{
+		{Title: "Old", StartTime: now.AddDate(0, 0, -90), EndTime: now.AddDate(0, 0, -90).Add(2 * time.Hour), Duration: 2 * time.Hour},
+		{Title: "Recent", StartTime: now.AddDate(0, 0, -3), EndTime: now.AddDate(0, 0, -3).Add(time.Hour), Duration: time.Hour},
+	}
+	stats.TotalSessions = 2
+	stats.TotalStreamTime = 3 * time.Hour
+
+	if err := stats.Save(); err != nil {
+		t.Fatalf("Failed to save stats: %v", err)
+	}
+
+	current := stats.GetStats()
+	if len(current.Sessions) != 1 || current.Sessions[0].Title != "Recent" {
+		t.Fatalf("Expected only the recent session to be kept, got %+v", current.Sessions)
+	}
+	if current.TotalSessions != 2 || current.TotalStreamTime != 3*time.Hour {
+		t.Errorf("Expected totals to be untouched, got %d sessions and %v", current.TotalSessions, current.TotalStreamTime)
+	}
+}
@@ -0,0 +1,92 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/queue"
+	"github.com/pbuckles22/PBChatBot/internal/timers"
+)
+
+// fakeClock is a manually advanced clock for timer tests
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestQueueAnnounceTimer(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel_announce")
+
+	clock := &fakeClock{now: time.Now()}
+	var sent []string
+	manager := timers.NewManager(clock, func(msg string) {
+		sent = append(sent, msg)
+	})
+	manager.Add(timers.NewQueueAnnounceTimer(q, 5*time.Minute))
+
+	// Queue disabled: nothing is posted
+	clock.Advance(time.Minute)
+	manager.RecordActivity()
+	clock.Advance(5 * time.Minute)
+	manager.Tick()
+	if len(sent) != 0 {
+		t.Errorf("Expected no announcement while disabled, got %v", sent)
+	}
+
+	// Queue enabled but empty: nothing is posted
+	q.Enable()
+	manager.Tick()
+	if len(sent) != 0 {
+		t.Errorf("Expected no announcement while empty, got %v", sent)
+	}
+
+	// Queue enabled and non-empty: announcement is posted
+	q.Add("user1", false)
+	q.Add("user2", false)
+	manager.Tick()
+	if len(sent) != 1 || sent[0] != "2 people in queue — type !join to enter!" {
+		t.Fatalf("Expected one queue announcement, got %v", sent)
+	}
+
+	// Not due again until the interval passes
+	clock.Advance(time.Minute)
+	manager.RecordActivity()
+	manager.Tick()
+	if len(sent) != 1 {
+		t.Errorf("Expected no announcement before interval, got %v", sent)
+	}
+
+	// Due again with chat activity since the last post
+	clock.Advance(5 * time.Minute)
+	manager.Tick()
+	if len(sent) != 2 {
+		t.Fatalf("Expected a second announcement, got %v", sent)
+	}
+
+	// Quiet chat: no activity since the last post, so it is suppressed
+	clock.Advance(10 * time.Minute)
+	manager.Tick()
+	if len(sent) != 2 {
+		t.Errorf("Expected no announcement while chat is quiet, got %v", sent)
+	}
+
+	// Queue emptied: suppressed even with activity
+	q.Clear()
+	manager.RecordActivity()
+	clock.Advance(10 * time.Minute)
+	manager.Tick()
+	if len(sent) != 2 {
+		t.Errorf("Expected no announcement once the queue is empty, got %v", sent)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
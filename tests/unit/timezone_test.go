@@ -0,0 +1,89 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+	"github.com/pbuckles22/PBChatBot/internal/config"
+)
+
+func TestHandleTimezone(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_timezone")
+	commands.SetCommandManager(cm)
+
+	// Point the manager at a real config file so the change can be saved
+	configPath := filepath.Join(tempDir, "testchannel_timezone_config_secrets.yaml")
+	configYAML := "# channel config\nbot_name: testbot\nchannel: testchannel_timezone\ntimezone: America/New_York # display timezone\n"
+	if err := os.WriteFile(configPath, []byte(configYAML), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cm.SetConfigPath(configPath)
+	cm.GetConfig().Timezone = "America/New_York"
+
+	mod := createMockMessage("moduser", "!timezone", true, false, false)
+
+	t.Run("show_current", func(t *testing.T) {
+		response := commands.HandleTimezone(mod, []string{})
+		if !strings.HasPrefix(response, "Timezone is America/New_York (current time: ") {
+			t.Errorf("Unexpected response: '%s'", response)
+		}
+	})
+
+	t.Run("valid_timezone", func(t *testing.T) {
+		previous := cm.GetConfig()
+		response := commands.HandleTimezone(mod, []string{"Asia/Tokyo"})
+		if !strings.HasPrefix(response, "Timezone set to Asia/Tokyo") {
+			t.Errorf("Unexpected response: '%s'", response)
+		}
+		// The config is replaced, not changed under readers holding it
+		if previous.Timezone != "America/New_York" {
+			t.Errorf("Expected the previous config to be left alone, got %s", previous.Timezone)
+		}
+		if cm.GetConfig().Timezone != "Asia/Tokyo" {
+			t.Errorf("Expected in-memory timezone Asia/Tokyo, got %s", cm.GetConfig().Timezone)
+		}
+
+		saved, err := config.Load(configPath)
+		if err != nil {
+			t.Fatalf("Failed to reload config: %v", err)
+		}
+		if saved.Timezone != "Asia/Tokyo" {
+			t.Errorf("Expected saved timezone Asia/Tokyo, got %s", saved.Timezone)
+		}
+		data, _ := os.ReadFile(configPath)
+		if !strings.Contains(string(data), "# channel config") {
+			t.Errorf("Expected comments to be preserved, got:\n%s", data)
+		}
+	})
+
+	t.Run("invalid_timezone", func(t *testing.T) {
+		response := commands.HandleTimezone(mod, []string{"Mars/Olympus_Mons"})
+		expected := "Invalid timezone: Mars/Olympus_Mons. Use an IANA name like America/New_York."
+		if response != expected {
+			t.Errorf("Expected '%s', got '%s'", expected, response)
+		}
+		if cm.GetConfig().Timezone != "Asia/Tokyo" {
+			t.Errorf("Expected timezone to stay Asia/Tokyo, got %s", cm.GetConfig().Timezone)
+		}
+	})
+
+	t.Run("display_uses_new_timezone", func(t *testing.T) {
+		ts := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+		formatted := cm.FormatTimeForDisplay(ts)
+		if formatted != "2024-01-15 21:00:00 JST" {
+			t.Errorf("Expected time formatted in JST, got '%s'", formatted)
+		}
+
+		response := commands.HandleTimezone(mod, []string{})
+		if !strings.HasPrefix(response, "Timezone is Asia/Tokyo") || !strings.Contains(response, "JST") {
+			t.Errorf("Unexpected response: '%s'", response)
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+	"github.com/pbuckles22/PBChatBot/internal/timers"
+)
+
+func TestHandleTopic(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	dir := t.TempDir()
+	cm := commands.NewCommandManager("!", dir, "testchannel_topic")
+	commands.SetCommandManager(cm)
+
+	viewer := createMockMessage("viewer", "!topic", false, false, false)
+	if response := commands.HandleTopic(viewer, nil); response != "No topic is set." {
+		t.Errorf("Expected no topic, got '%s'", response)
+	}
+
+	set := createMockMessage("moduser", "!topic What are  we playing today?", true, false, false)
+	if response := commands.HandleTopic(set, []string{"What", "are", "we", "playing", "today?"}); response != "📌 Topic set: What are  we playing today?" {
+		t.Errorf("Unexpected set response: '%s'", response)
+	}
+
+	denied := createMockMessage("viewer", "!topic hijacked", false, false, false)
+	if response := commands.HandleTopic(denied, []string{"hijacked"}); response != "Only moderators can set the topic." {
+		t.Errorf("Expected moderator-only response, got '%s'", response)
+	}
+
+	if response := commands.HandleTopic(viewer, nil); response != "📌 Topic: What are  we playing today?" {
+		t.Errorf("Unexpected topic response: '%s'", response)
+	}
+	cm.GetQueue().Enable()
+	if response := commands.HandleStatus(viewer, nil); response != "Queue: open (0 waiting) | Topic: What are  we playing today?" {
+		t.Errorf("Unexpected status response: '%s'", response)
+	}
+
+	// The topic survives a restart
+	restarted := commands.NewCommandManager("!", dir, "testchannel_topic")
+	if err := restarted.LoadTopic(); err != nil {
+		t.Fatalf("Failed to load topic: %v", err)
+	}
+	if topic := restarted.GetTopic(); topic != "What are  we playing today?" {
+		t.Errorf("Expected topic to persist, got '%s'", topic)
+	}
+	commands.SetCommandManager(cm)
+
+	clearMsg := createMockMessage("moduser", "!cleartopic", true, false, false)
+	if response := commands.HandleClearTopic(clearMsg, nil); response != "Topic cleared." {
+		t.Errorf("Unexpected clear response: '%s'", response)
+	}
+	if response := commands.HandleStatus(viewer, nil); response != "Queue: open (0 waiting)" {
+		t.Errorf("Expected status without topic, got '%s'", response)
+	}
+	if _, err := os.Stat(filepath.Join(cm.GetQueue().GetDataPath(), "topic_testchannel_topic.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected topic file to be removed, got %v", err)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestTopicAnnounceTimer(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_topic_timer")
+	commands.SetCommandManager(cm)
+
+	clock := &fakeGiveawayClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	var sent []string
+	manager := timers.NewManager(clock, func(msg string) { sent = append(sent, msg) })
+	manager.Add(cm.NewTopicAnnounceTimer(10 * time.Minute))
+
+	// Nothing is posted while no topic is set
+	clock.now = clock.now.Add(10 * time.Minute)
+	manager.RecordActivity()
+	manager.Tick()
+	if len(sent) != 0 {
+		t.Fatalf("Expected no posts without a topic, got %v", sent)
+	}
+
+	cm.SetTopic("Ranked grind")
+	manager.Tick()
+	clock.now = clock.now.Add(5 * time.Minute)
+	manager.RecordActivity()
+	manager.Tick()
+	clock.now = clock.now.Add(10 * time.Minute)
+	manager.RecordActivity()
+	manager.Tick()
+
+	expected := []string{"📌 Topic: Ranked grind", "📌 Topic: Ranked grind"}
+	if !reflect.DeepEqual(sent, expected) {
+		t.Errorf("Expected %v, got %v", expected, sent)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
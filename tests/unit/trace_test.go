@@ -0,0 +1,101 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+	"github.com/pbuckles22/PBChatBot/internal/trace"
+)
+
+// captureLogs sends debug-level slog output to a buffer for the rest of the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	original := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { slog.SetDefault(original) })
+	return &buf
+}
+
+// logEntries parses the captured JSON log lines
+func logEntries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Failed to parse log line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestCommandTraceID(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_trace")
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+	cm.GetQueue().Enable()
+
+	buf := captureLogs(t)
+	ctx := trace.WithID(context.Background(), "abc")
+	response, isCommand := cm.HandleMessageContext(ctx, createMockMessage("user1", "!join", false, false, false))
+	if !isCommand || response == "" {
+		t.Fatalf("Expected !join to respond, got %q", response)
+	}
+
+	entries := logEntries(t, buf)
+	messages := make(map[string]bool)
+	for _, entry := range entries {
+		if entry["trace_id"] != "abc" {
+			t.Errorf("Expected trace_id abc on every entry, got %v", entry)
+		}
+		messages[entry["msg"].(string)] = true
+	}
+	for _, want := range []string{"handling command", "command handled"} {
+		if !messages[want] {
+			t.Errorf("Expected a %q entry, got %v", want, entries)
+		}
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestCommandTraceIDGenerated(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_trace_generated")
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+
+	// Messages without a trace ID each get their own
+	buf := captureLogs(t)
+	cm.HandleMessage(createMockMessage("user1", "!ping", false, false, false))
+	cm.HandleMessage(createMockMessage("user2", "!ping", false, false, false))
+
+	ids := make(map[string]bool)
+	for _, entry := range logEntries(t, buf) {
+		id, _ := entry["trace_id"].(string)
+		if id == "" {
+			t.Errorf("Expected a generated trace_id, got %v", entry)
+		}
+		ids[id] = true
+	}
+	if len(ids) != 2 {
+		t.Errorf("Expected two distinct trace IDs, got %v", ids)
+	}
+}
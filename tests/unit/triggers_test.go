@@ -0,0 +1,116 @@
+package unit
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	twitch "github.com/gempir/go-twitch-irc/v4"
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+func TestKeywordTriggers(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_triggers")
+	commands.SetCommandManager(cm)
+	cm.RegisterCommand(&commands.Command{
+		Name:     "entry",
+		Triggers: []string{"enter", "Vote Yes"},
+		Handler: func(message twitch.PrivateMessage, args []string) string {
+			return message.User.Name + " entered"
+		},
+	})
+
+	tests := []struct {
+		message   string
+		isCommand bool
+	}{
+		{"enter", true},
+		{"ENTER ", true},
+		{"vote yes", true},
+		{"!entry", true},
+		{"enter please", false},
+		{"I want to enter", false},
+		{"vote", false},
+		{"hello chat", false},
+	}
+	for i, tt := range tests {
+		// A different viewer each time, so cooldowns don't get in the way
+		user := fmt.Sprintf("viewer%d", i)
+		expected := ""
+		if tt.isCommand {
+			expected = user + " entered"
+		}
+		response, isCommand := cm.HandleMessage(createMockMessage(user, tt.message, false, false, false))
+		if response != expected || isCommand != tt.isCommand {
+			t.Errorf("Message %q: expected (%q, %v), got (%q, %v)", tt.message, expected, tt.isCommand, response, isCommand)
+		}
+	}
+
+	// A keyword starts the command's cooldown like the prefixed command, and
+	// a keyword on cooldown is just chat
+	if response, _ := cm.HandleMessage(createMockMessage("viewer0", "!entry", false, false, false)); !strings.Contains(response, "on cooldown") {
+		t.Errorf("Expected !entry to be on cooldown after the keyword, got %q", response)
+	}
+	if response, isCommand := cm.HandleMessage(createMockMessage("viewer3", "enter", false, false, false)); response != "" || isCommand {
+		t.Errorf("Expected the keyword on cooldown to be normal chat, got (%q, %v)", response, isCommand)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestKeywordTriggerPermissions(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_triggers_mod")
+	commands.SetCommandManager(cm)
+	cm.RegisterCommand(&commands.Command{
+		Name:     "go",
+		ModOnly:  true,
+		Triggers: []string{"go time"},
+		Handler: func(message twitch.PrivateMessage, args []string) string {
+			return "Starting!"
+		},
+	})
+
+	// A viewer typing the keyword is just chatting
+	if response, isCommand := cm.HandleMessage(createMockMessage("viewer", "go time", false, false, false)); response != "" || isCommand {
+		t.Errorf("Expected a viewer's keyword to be normal chat, got (%q, %v)", response, isCommand)
+	}
+	if response, isCommand := cm.HandleMessage(createMockMessage("moduser", "go time", true, false, false)); response != "Starting!" || !isCommand {
+		t.Errorf("Expected a mod's keyword to run the command, got (%q, %v)", response, isCommand)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestKeywordTriggersFromConfig(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_triggers_config")
+	commands.SetCommandManager(cm)
+	cm.GetConfig().Commands.Triggers = map[string][]string{"entry": {"ticket"}}
+	cm.RegisterCommand(&commands.Command{
+		Name: "entry",
+		Handler: func(message twitch.PrivateMessage, args []string) string {
+			return "entered"
+		},
+	})
+
+	if response, isCommand := cm.HandleMessage(createMockMessage("viewer", "Ticket", false, false, false)); response != "entered" || !isCommand {
+		t.Errorf("Expected the configured keyword to run the command, got (%q, %v)", response, isCommand)
+	}
+
+	// Triggers are dropped with the command registry on reset
+	cm.Reset()
+	if response, isCommand := cm.HandleMessage(createMockMessage("viewer", "ticket", false, false, false)); response != "" || isCommand {
+		t.Errorf("Expected no trigger after reset, got (%q, %v)", response, isCommand)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
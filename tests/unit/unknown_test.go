@@ -0,0 +1,59 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+func TestUnknownCommandReply(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_unknown")
+	commands.SetCommandManager(cm)
+	commands.RegisterBasicCommands(cm)
+
+	msg := createMockMessage("testuser", "!foo", false, false, false)
+
+	t.Run("silent_by_default", func(t *testing.T) {
+		response, isCommand := cm.HandleMessage(msg)
+		if !isCommand || response != "" {
+			t.Errorf("Expected silent command attempt, got ('%s', %v)", response, isCommand)
+		}
+	})
+
+	cm.GetConfig().UnknownCommandReply = true
+
+	t.Run("reply_when_enabled", func(t *testing.T) {
+		response, isCommand := cm.HandleMessage(msg)
+		if !isCommand || response != "Unknown command: !foo. Try !help" {
+			t.Errorf("Expected unknown command reply, got ('%s', %v)", response, isCommand)
+		}
+	})
+
+	t.Run("rate_limited", func(t *testing.T) {
+		response, _ := cm.HandleMessage(createMockMessage("otheruser", "!bar", false, false, false))
+		if response != "" {
+			t.Errorf("Expected rate-limited silence, got '%s'", response)
+		}
+	})
+
+	t.Run("reply_after_interval", func(t *testing.T) {
+		cm.SetUnknownCommandReplyInterval(10 * time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		response, _ := cm.HandleMessage(createMockMessage("otheruser", "!bar", false, false, false))
+		if response != "Unknown command: !bar. Try !help" {
+			t.Errorf("Expected reply after the interval, got '%s'", response)
+		}
+	})
+
+	t.Run("known_commands_unaffected", func(t *testing.T) {
+		response, _ := cm.HandleMessage(createMockMessage("testuser", "!ping", false, false, false))
+		if !strings.HasPrefix(response, "Pong! 🏓") {
+			t.Errorf("Expected a 'Pong! 🏓' reply, got '%s'", response)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package unit
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+	"github.com/pbuckles22/PBChatBot/internal/config"
+)
+
+// startReleaseServer serves a GitHub latest release response with tag, or
+// an error status if tag is empty. It counts the requests it receives.
+func startReleaseServer(t *testing.T, tag string) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if tag == "" {
+			http.Error(w, `{"message":"API rate limit exceeded"}`, http.StatusForbidden)
+			return
+		}
+		fmt.Fprintf(w, `{"tag_name":%q,"name":"Release %s"}`, tag, tag)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// setupVersionTest creates a command manager running version with the
+// update check pointed at url
+func setupVersionTest(t *testing.T, version, url string, enabled bool) {
+	originalVersion := commands.Version
+	commands.Version = version
+	t.Cleanup(func() { commands.Version = originalVersion })
+
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_version")
+	commands.SetCommandManager(cm)
+	cm.SetConfig(&config.Config{UpdateCheckEnabled: enabled})
+	cm.SetUpdateChecker(commands.NewUpdateChecker(url))
+}
+
+func TestHandleVersionCurrent(t *testing.T) {
+	server, requests := startReleaseServer(t, "v1.2.0")
+	setupVersionTest(t, "v1.2.0", server.URL, true)
+
+	response := commands.HandleVersion(createMockMessage("viewer", "!version", false, false, false), nil)
+	if !strings.HasPrefix(response, "PBChatBot v1.2.0 (") {
+		t.Errorf("Expected the version and build info, got %q", response)
+	}
+	if strings.Contains(response, "Update available") {
+		t.Errorf("Expected no update notice when up to date, got %q", response)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Expected 1 GitHub request, got %d", requests.Load())
+	}
+}
+
+func TestHandleVersionUpdateAvailable(t *testing.T) {
+	server, requests := startReleaseServer(t, "v1.3.0")
+	setupVersionTest(t, "v1.2.9", server.URL, true)
+
+	message := createMockMessage("viewer", "!version", false, false, false)
+	response := commands.HandleVersion(message, nil)
+	if !strings.HasSuffix(response, " ⚠️ Update available: v1.3.0!") {
+		t.Errorf("Expected an update notice, got %q", response)
+	}
+
+	// The release lookup is cached
+	if again := commands.HandleVersion(message, nil); again != response {
+		t.Errorf("Expected the same response from the cache, got %q", again)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Expected the lookup to be cached after 1 request, got %d", requests.Load())
+	}
+}
+
+func TestHandleVersionAPIError(t *testing.T) {
+	server, requests := startReleaseServer(t, "")
+	setupVersionTest(t, "v1.2.0", server.URL, true)
+
+	message := createMockMessage("viewer", "!version", false, false, false)
+	response := commands.HandleVersion(message, nil)
+	if !strings.HasPrefix(response, "PBChatBot v1.2.0 (") || strings.Contains(response, "Update") {
+		t.Errorf("Expected just the version when GitHub fails, got %q", response)
+	}
+
+	// Failures aren't cached, so the next call tries again
+	commands.HandleVersion(message, nil)
+	if requests.Load() != 2 {
+		t.Errorf("Expected a retry after the failed lookup, got %d requests", requests.Load())
+	}
+}
+
+func TestHandleVersionUpdateCheckDisabled(t *testing.T) {
+	server, requests := startReleaseServer(t, "v9.0.0")
+	setupVersionTest(t, "v1.2.0", server.URL, false)
+
+	response := commands.HandleVersion(createMockMessage("viewer", "!version", false, false, false), nil)
+	if strings.Contains(response, "Update available") {
+		t.Errorf("Expected no update notice with the check disabled, got %q", response)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("Expected no GitHub requests with the check disabled, got %d", requests.Load())
+	}
+}
+
+func TestHandleVersionDevBuild(t *testing.T) {
+	server, _ := startReleaseServer(t, "v1.3.0")
+	setupVersionTest(t, "dev", server.URL, true)
+
+	response := commands.HandleVersion(createMockMessage("viewer", "!version", false, false, false), nil)
+	if !strings.HasPrefix(response, "PBChatBot dev (") || strings.Contains(response, "Update available") {
+		t.Errorf("Expected dev builds not to report updates, got %q", response)
+	}
+}
@@ -0,0 +1,61 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+func TestHandleJoinVIP(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_vipline")
+	commands.SetCommandManager(cm)
+	cm.GetQueue().Enable()
+
+	subscriber := createMockMessage("subuser", "!joinvip", false, false, false)
+	subscriber.User.Badges["subscriber"] = 1
+
+	tests := []struct {
+		name     string
+		message  string
+		user     string
+		isMod    bool
+		isVIP    bool
+		args     []string
+		expected string
+	}{
+		{"viewer rejected", "!joinvip", "viewer", false, false, nil, "viewer, the VIP line is for VIPs and subscribers. Use !join to join the queue."},
+		{"vip joins", "!joinvip", "vipuser", false, true, nil, "vipuser joined the VIP line at position 1"},
+		{"vip joins twice", "!joinvip", "vipuser", false, true, nil, "Error joining VIP line: user is already in the VIP line"},
+		{"non-mod adds other", "!joinvip friend", "vipuser", false, true, []string{"friend"}, "Only moderators can add other users to the VIP line."},
+		{"mod adds other", "!joinvip friend", "moduser", true, false, []string{"friend"}, "friend joined the VIP line at position 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := createMockMessage(tt.user, tt.message, tt.isMod, tt.isVIP, false)
+			if response := commands.HandleJoinVIP(msg, tt.args); response != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, response)
+			}
+		})
+	}
+
+	if response := commands.HandleJoinVIP(subscriber, nil); response != "subuser joined the VIP line at position 3" {
+		t.Errorf("Expected subscriber to join, got '%s'", response)
+	}
+
+	response := commands.HandleQueueVIP(createMockMessage("viewer", "!queuevip", false, false, false), nil)
+	expected := "VIP line: vipuser, friend, subuser (3 total, served every 3rd pop)"
+	if response != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, response)
+	}
+
+	// VIP line users can leave with !leave
+	if response := commands.HandleLeave(createMockMessage("friend", "!leave", false, false, false), nil); response != "friend left queue" {
+		t.Errorf("Expected friend to leave, got '%s'", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
@@ -0,0 +1,232 @@
+package unit
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+	"github.com/pbuckles22/PBChatBot/internal/queue"
+)
+
+func TestQueueWaitlistPromotion(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel_waitlist")
+	q.Enable()
+	q.SetMaxSize(2)
+
+	var promotions [][]string
+	q.SetPromotionHandler(func(users []string) {
+		promotions = append(promotions, users)
+	})
+
+	q.Add("user1", false)
+	q.Add("user2", false)
+	if err := q.Add("user3", false); err != queue.ErrQueueFull {
+		t.Fatalf("Expected ErrQueueFull, got %v", err)
+	}
+
+	// The waitlist is opt-in
+	if err := q.AddWaitlist("user3"); err == nil {
+		t.Fatal("Expected the waitlist to reject joins while disabled")
+	}
+	q.SetWaitlistEnabled(true)
+	for _, user := range []string{"user3", "user4", "user5"} {
+		if err := q.AddWaitlist(user); err != nil {
+			t.Fatalf("Failed to waitlist %s: %v", user, err)
+		}
+	}
+	if err := q.AddWaitlist("USER4"); err == nil {
+		t.Error("Expected a duplicate waitlist join to be rejected")
+	}
+	if err := q.Add("user4", true); err == nil {
+		t.Error("Expected a waitlisted user not to be added to the queue again")
+	}
+	if pos := q.WaitlistPosition("user4"); pos != 2 {
+		t.Errorf("Expected user4 at waitlist position 2, got %d", pos)
+	}
+
+	// Popping frees a slot for the front of the waitlist
+	if user, err := q.Pop(); err != nil || user != "user1" {
+		t.Fatalf("Expected to pop user1, got %q (%v)", user, err)
+	}
+	if users := q.List(); !reflect.DeepEqual(users, []string{"user2", "user3"}) {
+		t.Errorf("Expected user3 to be promoted, got %v", users)
+	}
+	if !reflect.DeepEqual(promotions, [][]string{{"user3"}}) {
+		t.Errorf("Expected a promotion for user3, got %v", promotions)
+	}
+
+	// Leaving the waitlist doesn't promote anyone
+	if !q.Remove("user4") {
+		t.Fatal("Expected user4 to be removed from the waitlist")
+	}
+	if len(promotions) != 1 {
+		t.Errorf("Expected no promotion after leaving the waitlist, got %v", promotions)
+	}
+
+	// Leaving the main queue does
+	q.Remove("user2")
+	if users := q.List(); !reflect.DeepEqual(users, []string{"user3", "user5"}) {
+		t.Errorf("Expected user5 to be promoted, got %v", users)
+	}
+	if waitlist := q.ListWaitlist(); len(waitlist) != 0 {
+		t.Errorf("Expected an empty waitlist, got %v", waitlist)
+	}
+	if len(promotions) != 2 {
+		t.Errorf("Expected 2 promotions, got %v", promotions)
+	}
+
+	// With room in the queue there's nothing to wait for
+	q.Pop()
+	if err := q.AddWaitlist("user6"); err == nil {
+		t.Error("Expected the waitlist to reject joins while the queue has room")
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueWaitlistRaisedLimit(t *testing.T) {
+	q := queue.NewQueue(t.TempDir(), "testchannel_waitlist_limit")
+	q.Enable()
+	q.SetMaxSize(1)
+	q.SetWaitlistEnabled(true)
+	q.Add("user1", false)
+	q.AddWaitlist("user2")
+	q.AddWaitlist("user3")
+	q.AddWaitlist("user4")
+
+	var promoted []string
+	q.SetPromotionHandler(func(users []string) {
+		promoted = append(promoted, users...)
+	})
+
+	// Raising the limit fills the new slots in waitlist order
+	q.SetMaxSize(3)
+	if !reflect.DeepEqual(promoted, []string{"user2", "user3"}) {
+		t.Errorf("Expected user2 and user3 to be promoted, got %v", promoted)
+	}
+	if waitlist := q.ListWaitlist(); !reflect.DeepEqual(waitlist, []string{"user4"}) {
+		t.Errorf("Expected user4 to still be waiting, got %v", waitlist)
+	}
+
+	// PopN promotes once for the whole batch
+	promoted = nil
+	q.PopN(2)
+	if !reflect.DeepEqual(promoted, []string{"user4"}) {
+		t.Errorf("Expected user4 to be promoted after PopN, got %v", promoted)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueWaitlistPersistence(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel_waitlist_save")
+	q.Enable()
+	q.SetMaxSize(1)
+	q.SetWaitlistEnabled(true)
+	q.Add("user1", false)
+	q.AddWaitlist("user2")
+	if err := q.SaveState(); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	q2 := queue.NewQueue(tempDir, "testchannel_waitlist_save")
+	if waitlist := q2.ListWaitlist(); !reflect.DeepEqual(waitlist, []string{"user2"}) {
+		t.Errorf("Expected the waitlist to be restored, got %v", waitlist)
+	}
+
+	// Clearing the queue empties the waitlist too
+	q2.Enable()
+	q2.Clear()
+	if waitlist := q2.ListWaitlist(); len(waitlist) != 0 {
+		t.Errorf("Expected clear to empty the waitlist, got %v", waitlist)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQueueWaitlistUndoClear(t *testing.T) {
+	tempDir := t.TempDir()
+	q := queue.NewQueue(tempDir, "testchannel_waitlist_undo")
+	q.Enable()
+	q.SetMaxSize(2)
+	q.SetWaitlistEnabled(true)
+	q.Add("user1", false)
+	q.Add("user2", false)
+	q.AddWaitlist("user3")
+	q.AddWaitlist("user4")
+
+	// Undoing a clear brings the waitlist back too, without listing anyone
+	// who has joined the main queue since
+	q.Clear()
+	q.Add("user4", false)
+	if _, err := q.UndoClear(); err != nil {
+		t.Fatalf("Failed to undo clear: %v", err)
+	}
+	if users := q.List(); !reflect.DeepEqual(users, []string{"user1", "user2", "user4"}) {
+		t.Errorf("Expected the cleared users back in front, got %v", users)
+	}
+	if waitlist := q.ListWaitlist(); !reflect.DeepEqual(waitlist, []string{"user3"}) {
+		t.Errorf("Expected the cleared waitlist to be restored, got %v", waitlist)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestHandleJoinWaitlist(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	cm := commands.NewCommandManager("!", t.TempDir(), "testchannel_join_waitlist")
+	commands.SetCommandManager(cm)
+	announcer := &fakeAnnouncer{}
+	cm.SetAnnouncer(announcer)
+
+	q := cm.GetQueue()
+	q.Enable()
+	q.SetMaxSize(1)
+	commands.HandleJoin(createMockMessage("user1", "!join", false, false, false), []string{})
+
+	// Without the waitlist a full queue turns users away
+	response := commands.HandleJoin(createMockMessage("user2", "!join", false, false, false), []string{})
+	if response != "@user2 the queue is full. Try again when a spot opens." {
+		t.Errorf("Expected a full queue error, got %q", response)
+	}
+
+	q.SetWaitlistEnabled(true)
+	response = commands.HandleJoin(createMockMessage("user2", "!join", false, false, false), []string{})
+	expected := "The queue is full, so user2 is #1 on the waitlist and will join the queue when a spot opens."
+	if response != expected {
+		t.Errorf("Expected %q, got %q", expected, response)
+	}
+	response = commands.HandlePosition(createMockMessage("user2", "!position", false, false, false), []string{})
+	if response != "user2 is #1 on the waitlist" {
+		t.Errorf("Expected the waitlist position, got %q", response)
+	}
+	response = commands.HandleQueue(createMockMessage("user2", "!queue", false, false, false), []string{})
+	if response != "Queue: user1 (1 total, 1 on the waitlist)" {
+		t.Errorf("Expected the queue to mention the waitlist, got %q", response)
+	}
+
+	// Popping user1 promotes user2 and announces it
+	commands.HandlePop(createMockMessage("mod", "!pop", true, false, false), []string{})
+	expectedAnnouncement := "@user2, a spot opened up! You've moved from the waitlist into the queue at position 1."
+	if len(announcer.messages) != 1 || announcer.messages[0] != expectedAnnouncement {
+		t.Errorf("Expected %q, got %v", expectedAnnouncement, announcer.messages)
+	}
+
+	// Waitlisted users can leave before they're promoted
+	commands.HandleJoin(createMockMessage("user3", "!join", false, false, false), []string{})
+	response = commands.HandleLeave(createMockMessage("user3", "!leave", false, false, false), []string{})
+	if response != "user3 left queue" || len(q.ListWaitlist()) != 0 {
+		t.Errorf("Expected user3 to leave the waitlist, got %q (waitlist %v)", response, q.ListWaitlist())
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
@@ -0,0 +1,128 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pbuckles22/PBChatBot/internal/commands"
+)
+
+// fakeWhisperer records whispers, optionally failing them
+type fakeWhisperer struct {
+	whispers map[string][]string
+	err      error
+}
+
+func (f *fakeWhisperer) WhisperUser(username, message string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.whispers[username] = append(f.whispers[username], message)
+	return nil
+}
+
+func TestWhisperNotifications(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_whisper")
+	commands.SetCommandManager(cm)
+	cm.GetQueue().Enable()
+	cm.GetConfig().WhisperNotifications = true
+
+	whisperer := &fakeWhisperer{whispers: make(map[string][]string)}
+	cm.SetWhisperer(whisperer)
+
+	// Join confirmation is whispered instead of posted in chat
+	if response := commands.HandleJoin(createMockMessage("user1", "!join", false, false, false), []string{}); response != "" {
+		t.Errorf("Expected no chat response when whispering, got '%s'", response)
+	}
+	commands.HandleJoin(createMockMessage("user2", "!join", false, false, false), []string{})
+	if got := whisperer.whispers["user1"]; len(got) != 1 || got[0] != "user1 joined queue at position 1 (1 total)" {
+		t.Errorf("Unexpected join whisper: %v", got)
+	}
+
+	// Position check is whispered
+	if response := commands.HandlePosition(createMockMessage("user2", "!position", false, false, false), []string{}); response != "" {
+		t.Errorf("Expected no chat response when whispering, got '%s'", response)
+	}
+	if got := whisperer.whispers["user2"]; len(got) != 2 || got[1] != "user2 is at position 2" {
+		t.Errorf("Unexpected position whisper: %v", got)
+	}
+
+	// Moves notify the moved user while still confirming in chat
+	response := commands.HandleMove(createMockMessage("moduser", "!move user2 front", true, false, false), []string{"user2", "front"})
+	if response != "user2 moved to position 1" {
+		t.Errorf("Expected move confirmation in chat, got '%s'", response)
+	}
+	if got := whisperer.whispers["user2"]; len(got) != 3 || got[2] != "You are now at position 1 in the queue" {
+		t.Errorf("Unexpected move whisper: %v", got)
+	}
+
+	// Failed whispers fall back to chat
+	whisperer.err = errors.New("whisper_disabled_by_user")
+	response = commands.HandlePosition(createMockMessage("user1", "!position", false, false, false), []string{})
+	if response != "user1 is at position 2" {
+		t.Errorf("Expected chat fallback, got '%s'", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestQuietJoin(t *testing.T) {
+	// Reset command manager for test
+	commands.SetCommandManager(nil)
+	tempDir := t.TempDir()
+	cm := commands.NewCommandManager("!", tempDir, "testchannel_quietjoin")
+	commands.SetCommandManager(cm)
+	cm.GetConfig().Commands.Queue.QuietJoin = true
+
+	whisperer := &fakeWhisperer{whispers: make(map[string][]string)}
+	cm.SetWhisperer(whisperer)
+
+	// A disabled queue is still reported in chat
+	response := commands.HandleJoin(createMockMessage("user1", "!join", false, false, false), []string{})
+	if response != "@user1 the queue is disabled right now, so there is nothing to join." {
+		t.Errorf("Expected the disabled reply in chat, got '%s'", response)
+	}
+	if len(whisperer.whispers["user1"]) != 0 {
+		t.Errorf("Expected no whisper for a failed join, got %v", whisperer.whispers["user1"])
+	}
+
+	// Successful joins are whispered and silent in chat
+	cm.GetQueue().Enable()
+	cm.GetQueue().Add("someone", false)
+	if response := commands.HandleJoin(createMockMessage("user1", "!join", false, false, false), []string{}); response != "" {
+		t.Errorf("Expected no chat response for a quiet join, got '%s'", response)
+	}
+	if got := whisperer.whispers["user1"]; len(got) != 1 || got[0] != "You're #2 in the queue." {
+		t.Errorf("Unexpected quiet join whisper: %v", got)
+	}
+
+	// Duplicate and full-queue joins still reply in chat
+	response = commands.HandleJoin(createMockMessage("user1", "!join", false, false, false), []string{})
+	if response != "@user1 you're already in the queue at position 2" {
+		t.Errorf("Expected the already-queued reply in chat, got '%s'", response)
+	}
+	cm.GetQueue().SetMaxSize(2)
+	response = commands.HandleJoin(createMockMessage("user2", "!join", false, false, false), []string{})
+	if response != "@user2 the queue is full. Try again when a spot opens." {
+		t.Errorf("Expected the full reply in chat, got '%s'", response)
+	}
+	if len(whisperer.whispers["user2"]) != 0 {
+		t.Errorf("Expected no whisper for a failed join, got %v", whisperer.whispers["user2"])
+	}
+
+	// The chat reply is used if the whisper fails
+	cm.GetQueue().SetMaxSize(0)
+	whisperer.err = errors.New("whispers blocked")
+	response = commands.HandleJoin(createMockMessage("user3", "!join", false, false, false), []string{})
+	if response != "user3 joined queue at position 3 (3 total)" {
+		t.Errorf("Expected the chat reply when the whisper fails, got '%s'", response)
+	}
+
+	// Wait a moment for auto-save to complete
+	time.Sleep(100 * time.Millisecond)
+}
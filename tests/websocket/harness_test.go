@@ -1,6 +1,7 @@
 package websocket
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/pbuckles22/PBChatBot/internal/twitch"
 	"gopkg.in/yaml.v3"
 )
 
@@ -87,22 +89,7 @@ func waitForResponse(conn *websocket.Conn, expectedPattern string, timeout time.
 	for time.Since(start) < timeout {
 		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
 
-		// Use panic recovery to catch the "repeated read on failed websocket connection" panic
-		var message []byte
-		var err error
-		func() {
-			defer func() {
-				if r := recover(); r != nil {
-					if strings.Contains(fmt.Sprintf("%v", r), "repeated read on failed") {
-						err = fmt.Errorf("websocket failed state: %v", r)
-					} else {
-						// Re-panic for other panics
-						panic(r)
-					}
-				}
-			}()
-			_, message, err = conn.ReadMessage()
-		}()
+		message, err := twitch.SafeReadMessage(conn)
 
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err) {
@@ -116,9 +103,9 @@ func waitForResponse(conn *websocket.Conn, expectedPattern string, timeout time.
 			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
 				continue
 			}
-			if strings.Contains(err.Error(), "repeated read on failed") || strings.Contains(err.Error(), "websocket failed state") {
+			if errors.Is(err, twitch.ErrWebSocketFailed) {
 				fmt.Printf("[ERROR] Connection is in failed state: %v\n", err)
-				return false, "", fmt.Errorf("websocket failed state")
+				return false, "", err
 			}
 			fmt.Printf("[WARNING] Read error (continuing): %v\n", err)
 			continue
@@ -178,11 +165,11 @@ func runTestWithReconnect(conn **websocket.Conn, config *WebSocketTestConfig, te
 	if err == nil {
 		return success, nil
 	}
-	isConnectionError := strings.Contains(err.Error(), "connection") ||
+	isConnectionError := errors.Is(err, twitch.ErrWebSocketFailed) ||
+		strings.Contains(err.Error(), "connection") ||
 		strings.Contains(err.Error(), "websocket") ||
 		strings.Contains(err.Error(), "timeout") ||
-		strings.Contains(err.Error(), "health check failed") ||
-		strings.Contains(err.Error(), "websocket failed state")
+		strings.Contains(err.Error(), "health check failed")
 	if isConnectionError {
 		fmt.Printf("[RECONNECT] Connection issue detected (%s), attempting to reconnect...\n", err.Error())
 		(*conn).Close()
@@ -261,6 +248,72 @@ func connectToTwitch(config *WebSocketTestConfig) (*websocket.Conn, error) {
 	return conn, nil
 }
 
+// anonymousNickPrefix is the nick prefix Twitch accepts without a token.
+// Anonymous connections are read-only: Twitch silently drops any PRIVMSG
+// sent on them, so commands can't be sent in this mode.
+const anonymousNickPrefix = "justinfan"
+
+// connectAnonymously connects to Twitch IRC as a justinfan user and joins
+// channel, for observational tests that only need to listen.
+func connectAnonymously(channel string) (*websocket.Conn, error) {
+	dialer := websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+
+	conn, _, err := dialer.Dial("wss://irc-ws.chat.twitch.tv:443", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Twitch: %v", err)
+	}
+
+	// Request tags so received messages look the same as for the bot
+	capCmd := "CAP REQ :twitch.tv/tags twitch.tv/commands"
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(capCmd)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send CAP REQ: %v", err)
+	}
+
+	nick := fmt.Sprintf("%s%d", anonymousNickPrefix, 10000+time.Now().UnixNano()%90000)
+	nickCmd := fmt.Sprintf("NICK %s", nick)
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(nickCmd)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send NICK: %v", err)
+	}
+
+	joinCmd := fmt.Sprintf("JOIN #%s", strings.ToLower(channel))
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(joinCmd)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send JOIN: %v", err)
+	}
+
+	fmt.Printf("[CONNECT] Connected to Twitch IRC anonymously as %s (read-only)\n", nick)
+	return conn, nil
+}
+
+// waitForIRCLine reads until a line containing pattern arrives, answering
+// server PINGs along the way
+func waitForIRCLine(conn *websocket.Conn, pattern string, timeout time.Duration) (string, error) {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		conn.SetReadDeadline(deadline)
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return "", err
+		}
+
+		// A single frame may carry several IRC lines
+		for _, line := range strings.Split(strings.TrimSpace(string(message)), "\r\n") {
+			if strings.HasPrefix(line, "PING") {
+				conn.WriteMessage(websocket.TextMessage, []byte("PONG"+strings.TrimPrefix(line, "PING")))
+				continue
+			}
+			if strings.Contains(line, pattern) {
+				return line, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("pattern '%s' not found within %v", pattern, timeout)
+}
+
 func clearQueueAndWait(conn *websocket.Conn, channel string) error {
 	// Try to clear the queue if it exists
 	sendCommandWithRetry(conn, channel, "!clearqueue", 1)
@@ -394,3 +447,38 @@ func TestWebSocketReconnection(t *testing.T) {
 		t.Error("Reconnected connection health check failed")
 	}
 }
+
+// TestWebSocketAnonymousListen connects without credentials and checks that
+// messages from the channel are received
+func TestWebSocketAnonymousListen(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping anonymous WebSocket test in short mode")
+	}
+
+	channel := os.Getenv("TWITCH_TEST_CHANNEL")
+	if channel == "" {
+		channel = "twitch"
+	}
+
+	conn, err := connectAnonymously(channel)
+	if err != nil {
+		t.Skipf("Skipping anonymous WebSocket test - network unavailable: %v", err)
+	}
+	defer conn.Close()
+
+	// Twitch echoes our own JOIN back, which proves we're receiving channel messages
+	line, err := waitForIRCLine(conn, "JOIN #"+strings.ToLower(channel), 15*time.Second)
+	if err != nil {
+		t.Fatalf("Expected JOIN confirmation for #%s: %v", channel, err)
+	}
+	if !strings.Contains(line, anonymousNickPrefix) {
+		t.Errorf("Expected JOIN from a %s user, got: %s", anonymousNickPrefix, line)
+	}
+
+	// Chat messages are best-effort; the channel may be quiet
+	if msg, err := waitForIRCLine(conn, "PRIVMSG #", 10*time.Second); err == nil {
+		t.Logf("Received chat message: %s", msg)
+	} else {
+		t.Logf("No chat messages observed in #%s: %v", channel, err)
+	}
+}